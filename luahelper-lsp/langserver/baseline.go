@@ -0,0 +1,284 @@
+package langserver
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"luahelper-lsp/langserver/log"
+	lsp "luahelper-lsp/langserver/protocol"
+)
+
+// baselineVersion 基线文件格式的版本号
+const baselineVersion = 1
+
+// 基线中的告警在语言服务中的展示方式
+const (
+	baselineModeHint = "hint" // 降级为hint并置灰
+	baselineModeHide = "hide" // 不展示
+)
+
+// baselineEntry 基线文件中的单个告警指纹
+type baselineEntry struct {
+	Rule        string `json:"rule"`        // 告警的规则名称
+	File        string `json:"file"`        // 告警所在的文件，相对于工程的根目录
+	Snippet     string `json:"snippet"`     // 告警位置归一化后的代码片段
+	Fingerprint string `json:"fingerprint"` // 由规则、文件、代码片段计算出来的指纹
+	Count       int    `json:"count"`       // 相同指纹的告警数量
+}
+
+// baselineFile 基线文件的内容
+type baselineFile struct {
+	Version int             `json:"version"`
+	Entries []baselineEntry `json:"entries"`
+}
+
+// diagBaseline 加载后的基线，key为指纹，value为该指纹的告警数量
+type diagBaseline struct {
+	countMap map[string]int
+}
+
+// baselineMatcher 单次匹配基线的过程，每匹配上一个告警，消耗掉一个数量
+// 这样同一行新增加的同类告警，超出基线中的数量后仍然会报出来
+type baselineMatcher struct {
+	baseline *diagBaseline
+	usedMap  map[string]int // 已经匹配上的数量
+}
+
+// getBaselineSnippet 获取告警位置所在行的代码片段，连续的空白归一化为一个空格
+// 指纹不包含行号，文件前面插入或删除代码后，已有的告警仍然能匹配上
+func getBaselineSnippet(contents []byte, loc *lexer.Location) string {
+	if loc.StartLine <= 0 {
+		return ""
+	}
+
+	lineVec := strings.Split(string(contents), "\n")
+	startLine := loc.StartLine
+	endLine := loc.EndLine
+	if endLine < startLine {
+		endLine = startLine
+	}
+	if endLine > len(lineVec) {
+		endLine = len(lineVec)
+	}
+	if startLine > endLine {
+		return ""
+	}
+
+	return strings.Join(strings.Fields(strings.Join(lineVec[startLine-1:endLine], " ")), " ")
+}
+
+// getBaselineFingerprint 计算告警的指纹
+func getBaselineFingerprint(rule string, relFile string, snippet string) string {
+	sum := sha1.Sum([]byte(rule + "\x00" + relFile + "\x00" + snippet))
+	return hex.EncodeToString(sum[:])
+}
+
+// createBaselineEntry 告警转换为基线中的指纹
+func createBaselineEntry(relFile string, contents []byte, checkErr *common.CheckError) baselineEntry {
+	rule := common.GetCheckErrorName(checkErr.ErrType)
+	snippet := getBaselineSnippet(contents, &checkErr.Loc)
+	return baselineEntry{
+		Rule:        rule,
+		File:        relFile,
+		Snippet:     snippet,
+		Fingerprint: getBaselineFingerprint(rule, relFile, snippet),
+		Count:       1,
+	}
+}
+
+// readBaselineFile 读取基线文件
+func readBaselineFile(strPath string) (*diagBaseline, error) {
+	bytes, err := ioutil.ReadFile(strPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var file baselineFile
+	if err := json.Unmarshal(bytes, &file); err != nil {
+		return nil, fmt.Errorf("read baseline %s error=%s, json format error", strPath, err.Error())
+	}
+	if file.Version != baselineVersion {
+		return nil, fmt.Errorf("baseline %s version %d is not supported", strPath, file.Version)
+	}
+
+	baseline := &diagBaseline{
+		countMap: map[string]int{},
+	}
+	for _, oneEntry := range file.Entries {
+		count := oneEntry.Count
+		if count <= 0 {
+			count = 1
+		}
+		baseline.countMap[oneEntry.Fingerprint] += count
+	}
+	return baseline, nil
+}
+
+// writeBaselineFile 所有告警的指纹合并后写入基线文件，按文件、规则、代码片段排序，方便版本管理中对比
+func writeBaselineFile(strPath string, entryVec []baselineEntry) error {
+	entryMap := map[string]*baselineEntry{}
+	file := baselineFile{
+		Version: baselineVersion,
+		Entries: []baselineEntry{},
+	}
+	for _, oneEntry := range entryVec {
+		if oldEntry, ok := entryMap[oneEntry.Fingerprint]; ok {
+			oldEntry.Count += oneEntry.Count
+			continue
+		}
+		oneEntry := oneEntry
+		entryMap[oneEntry.Fingerprint] = &oneEntry
+	}
+	for _, oneEntry := range entryMap {
+		file.Entries = append(file.Entries, *oneEntry)
+	}
+
+	sort.Slice(file.Entries, func(i, j int) bool {
+		one, two := &file.Entries[i], &file.Entries[j]
+		if one.File != two.File {
+			return one.File < two.File
+		}
+		if one.Rule != two.Rule {
+			return one.Rule < two.Rule
+		}
+		return one.Snippet < two.Snippet
+	})
+
+	bytes, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(strPath, append(bytes, '\n'), 0644)
+}
+
+// newMatcher 创建一次新的匹配过程
+func (b *diagBaseline) newMatcher() *baselineMatcher {
+	return &baselineMatcher{
+		baseline: b,
+		usedMap:  map[string]int{},
+	}
+}
+
+// match 告警是否在基线中
+func (m *baselineMatcher) match(fingerprint string) bool {
+	if m.usedMap[fingerprint] >= m.baseline.countMap[fingerprint] {
+		return false
+	}
+
+	m.usedMap[fingerprint]++
+	return true
+}
+
+// localFileReader 本地诊断模式读取文件内容，同一个文件只读取一次
+type localFileReader struct {
+	contentMap map[string][]byte
+}
+
+// getContent 获取文件的内容，读取失败时返回空
+func (r *localFileReader) getContent(strFile string) []byte {
+	if contents, ok := r.contentMap[strFile]; ok {
+		return contents
+	}
+
+	contents, _ := ioutil.ReadFile(strFile)
+	r.contentMap[strFile] = contents
+	return contents
+}
+
+// createLocalBaseline 本地诊断模式的所有告警，转换为基线中的指纹
+func createLocalBaseline(diagVec []localDiagnostic) (entryVec []baselineEntry) {
+	reader := &localFileReader{contentMap: map[string][]byte{}}
+	for i := range diagVec {
+		oneDiag := &diagVec[i]
+		entryVec = append(entryVec, createBaselineEntry(oneDiag.file, reader.getContent(oneDiag.fullFile),
+			&oneDiag.checkErr))
+	}
+	return entryVec
+}
+
+// filterLocalBaseline 过滤掉基线中已经存在的告警，只保留新增的告警
+func filterLocalBaseline(diagVec []localDiagnostic, baseline *diagBaseline) (newVec []localDiagnostic, matchNum int) {
+	reader := &localFileReader{contentMap: map[string][]byte{}}
+	matcher := baseline.newMatcher()
+	for i := range diagVec {
+		oneDiag := &diagVec[i]
+		oneEntry := createBaselineEntry(oneDiag.file, reader.getContent(oneDiag.fullFile), &oneDiag.checkErr)
+		if matcher.match(oneEntry.Fingerprint) {
+			matchNum++
+			continue
+		}
+		newVec = append(newVec, *oneDiag)
+	}
+	return newVec, matchNum
+}
+
+// loadDiagBaseline 加载luahelper.json中配置的基线文件
+func (l *LspServer) loadDiagBaseline() {
+	l.baseline = nil
+	baselineFile, baselineMode := common.GConfig.GetBaselineConfig()
+	if baselineFile == "" {
+		return
+	}
+
+	if baselineMode != baselineModeHide {
+		baselineMode = baselineModeHint
+	}
+	l.baselineMode = baselineMode
+
+	baseline, err := readBaselineFile(baselineFile)
+	if err != nil {
+		log.Error("load baseline error=%s", err.Error())
+		return
+	}
+
+	l.baseline = baseline
+	log.Debug("load baseline=%s ok, mode=%s", baselineFile, baselineMode)
+}
+
+// getFileDiagnostics 文件的错误列表转换为诊断信息，基线中已有的告警按配置置灰或是隐藏
+// ignoreSyntax 表示是否忽略语法错误
+func (l *LspServer) getFileDiagnostics(strFile string, fileErrVec []common.CheckError,
+	ignoreSyntax bool) []lsp.Diagnostic {
+	diagnostics := []lsp.Diagnostic{}
+
+	var matcher *baselineMatcher
+	var contents []byte
+	var relFile string
+	if l.baseline != nil && len(fileErrVec) > 0 {
+		matcher = l.baseline.newMatcher()
+		relFile = getLocalRelativeFile(common.GConfig.GetDirManager().GetVsRootDir(), strFile)
+
+		found := false
+		if contents, found = l.getFileCache().GetFileContent(strFile); !found {
+			contents, _ = ioutil.ReadFile(strFile)
+		}
+	}
+
+	for i := range fileErrVec {
+		oneErr := &fileErrVec[i]
+		if oneErr.ErrType == common.CheckErrorSyntax && ignoreSyntax {
+			continue
+		}
+
+		oneDiagnostic := changeErrToDiagnostic(strFile, oneErr)
+		if matcher != nil && matcher.match(createBaselineEntry(relFile, contents, oneErr).Fingerprint) {
+			if l.baselineMode == baselineModeHide {
+				continue
+			}
+
+			oneDiagnostic.Severity = lsp.SeverityHint
+			oneDiagnostic.Tags = []lsp.DiagnosticTag{lsp.Unnecessary}
+			oneDiagnostic.Message = oneDiagnostic.Message + ". <baseline>"
+		}
+		diagnostics = append(diagnostics, oneDiagnostic)
+	}
+
+	return diagnostics
+}
@@ -0,0 +1,68 @@
+package langserver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+)
+
+func TestDiagnosticsBaseline(t *testing.T) {
+	common.GlobalConfigDefautInit()
+	tmpDir, err := ioutil.TempDir("", "luahelper_baseline")
+	if err != nil {
+		t.Fatalf("create temp dir error, err=%v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	luaFile := filepath.Join(tmpDir, "a.lua")
+	oldContent := "local a = 1\nlocal   b =  2\nprint(c)\n"
+	if err := ioutil.WriteFile(luaFile, []byte(oldContent), 0644); err != nil {
+		t.Fatalf("write lua file error, err=%v", err)
+	}
+
+	noUseErr := func(line int) common.CheckError {
+		return common.CheckError{
+			ErrType: common.CheckErrorLocalNoUse,
+			ErrStr:  "unused variable",
+			Loc:     lexer.Location{StartLine: line, StartColumn: 6, EndLine: line, EndColumn: 7},
+		}
+	}
+	fileErrorMap := map[string][]common.CheckError{
+		luaFile: {noUseErr(1), noUseErr(2)},
+	}
+
+	// 1) 写入基线文件后重新读取
+	baselinePath := filepath.Join(tmpDir, "baseline.json")
+	diagVec := collectLocalDiagnostics(tmpDir, fileErrorMap)
+	if err := writeBaselineFile(baselinePath, createLocalBaseline(diagVec)); err != nil {
+		t.Fatalf("write baseline error, err=%v", err)
+	}
+	baseline, err := readBaselineFile(baselinePath)
+	if err != nil || len(baseline.countMap) != 2 {
+		t.Fatalf("read baseline error, err=%v", err)
+	}
+
+	// 2) 前面插入代码、修改空白后，已有的告警仍然匹配；新增的告警被报出来
+	newContent := "-- header\n\nlocal a = 1\nlocal b = 2\nlocal d = 3\nprint(c)\n"
+	if err := ioutil.WriteFile(luaFile, []byte(newContent), 0644); err != nil {
+		t.Fatalf("write lua file error, err=%v", err)
+	}
+	fileErrorMap[luaFile] = []common.CheckError{noUseErr(3), noUseErr(4), noUseErr(5)}
+
+	diagVec = collectLocalDiagnostics(tmpDir, fileErrorMap)
+	newVec, matchNum := filterLocalBaseline(diagVec, baseline)
+	if matchNum != 2 || len(newVec) != 1 || newVec[0].checkErr.Loc.StartLine != 5 {
+		t.Fatalf("filter baseline error, matchNum=%d, newVec=%v", matchNum, newVec)
+	}
+
+	// 3) 同一段代码的告警数量超出基线中的数量时，超出的部分为新增的告警
+	matcher := baseline.newMatcher()
+	fingerprint := createBaselineEntry("a.lua", []byte(newContent), &fileErrorMap[luaFile][0]).Fingerprint
+	if !matcher.match(fingerprint) || matcher.match(fingerprint) {
+		t.Fatalf("baseline count match error")
+	}
+}
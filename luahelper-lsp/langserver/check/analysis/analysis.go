@@ -163,6 +163,9 @@ func (a *Analysis) HandleFirstTraverseAST(fileResult *results.FileResult) {
 	a.curScope = fileResult.MainFunc.MainScope
 	a.cgBlock(fileResult.Block)
 	a.exitScope()
+
+	// 记录文件被require时导出的返回信息
+	a.recordExportInfo()
 }
 
 // HandleSecondProjectTraverseAST 第二轮深度遍历AST的处理（带工程的方式）或是第三轮遍历单个文件
@@ -174,6 +177,8 @@ func (a *Analysis) HandleSecondProjectTraverseAST(initialResult *results.FileRes
 	entryFile := dirManager.RemovePathDirPre(a.entryFile)
 
 	fileResult := results.CreateFileResult(strFile, mainAst, results.CheckTermSecond, entryFile)
+	fileResult.Suppress = initialResult.Suppress
+	fileResult.NarrowCache = initialResult.NarrowCache
 
 	// 插入主函数
 	fileResult.InertNewFunc(fileResult.MainFunc)
@@ -304,6 +309,8 @@ func (a *Analysis) HandleTermTraverseAST(checkTerm results.CheckTerm, firstFile
 	strFile := firstFile.Name
 	mainAst := firstFile.Block
 	fileResult := results.CreateFileResult(strFile, mainAst, checkTerm, "")
+	fileResult.Suppress = firstFile.Suppress
+	fileResult.NarrowCache = firstFile.NarrowCache
 
 	if checkTerm == results.CheckTermThird {
 		a.AnalysisThird.FileResult = fileResult
@@ -6,6 +6,9 @@ import (
 )
 
 func (a *Analysis) cgBlock(node *ast.Block) {
+	// 第一轮检查不可达的语句
+	a.checkUnreachableCode(node)
+
 	for _, stat := range node.Stats {
 		a.cgStat(stat)
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"luahelper-lsp/langserver/check/common"
 	"luahelper-lsp/langserver/check/compiler/ast"
+	"luahelper-lsp/langserver/check/compiler/lexer"
 	"strings"
 )
 
@@ -17,7 +18,7 @@ func (a *Analysis) checkAssignTypeSame(leftNode ast.Exp, rightNode ast.Exp) {
 		return
 	}
 
-	if _, ok := common.GConfig.OpenErrorTypeMap[common.CheckErrorAssignType]; !ok {
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorAssignType) {
 		return
 	}
 
@@ -58,3 +59,36 @@ func (a *Analysis) checkAssignTypeSame(leftNode ast.Exp, rightNode ast.Exp) {
 		a.curResult.InsertError(common.CheckErrorAssignType, errStr, loc)
 	}
 }
+
+// checkGlobalAssign 检查赋值语句创建了未声明的全局变量，例如函数内拼写错误的 playr = 1
+// 允许定义全局变量的文件、---@meta 声明文件中不检查；_G.a = 1 这样明确的全局变量赋值不检查
+// function playr() end 这样的全局函数定义，语法分析时转换为赋值语句，同样检查
+func (a *Analysis) checkGlobalAssign(strName string, loc lexer.Location) {
+	if !a.isNeedCheck() || a.realTimeFlag {
+		return
+	}
+
+	if common.GConfig.IsGlobalIgnoreErrType(common.CheckErrorGlobalAssign) {
+		return
+	}
+
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorGlobalAssign) {
+		return
+	}
+
+	if common.GConfig.IsDeclareGlobal(strName) || common.GConfig.IsIgnoreNameVar(strName) {
+		return
+	}
+
+	strFile := a.curResult.Name
+	if common.GConfig.IsGlobalDefineFile(strFile) {
+		return
+	}
+
+	if fileStruct, _ := a.Projects.GetFirstFileStuct(strFile); fileStruct != nil && fileStruct.AnnotateFile.IsMetaFile() {
+		return
+	}
+
+	errStr := fmt.Sprintf("Assignment creates undeclared global '%s'", strName)
+	a.curResult.InsertError(common.CheckErrorGlobalAssign, errStr, loc)
+}
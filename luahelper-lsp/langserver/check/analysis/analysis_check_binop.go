@@ -17,7 +17,7 @@ func (a *Analysis) checkBinopExpTypeSame(node *ast.BinopExp) {
 		return
 	}
 
-	if _, ok := common.GConfig.OpenErrorTypeMap[common.CheckErrorBinopType]; !ok {
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorBinopType) {
 		return
 	}
 
@@ -17,7 +17,7 @@ func (a *Analysis) checkConstAssgin(node ast.Exp) {
 		return
 	}
 
-	if _, ok := common.GConfig.OpenErrorTypeMap[common.CheckErrorConstAssign]; !ok {
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorConstAssign) {
 		return
 	}
 
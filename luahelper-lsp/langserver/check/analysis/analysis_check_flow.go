@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+)
+
+// 控制流相关的检查，包括不可达的代码，以及注解了返回值的函数缺少返回，例如下面的例子
+// ---@return number
+// function calc(a)
+//     if a > 0 then
+//         return a
+//     end
+//     error("invalid")
+//     print(a)  -- error()之后的语句不可达
+// end
+
+// checkUnreachableCode 第一轮检查代码块中，return、break、goto或是error()之后的语句
+// 标签可以通过goto跳转到达，标签之后的语句重新判断
+func (a *Analysis) checkUnreachableCode(node *ast.Block) {
+	if !a.isFirstTerm() || a.realTimeFlag {
+		return
+	}
+
+	if common.GConfig.IsGlobalIgnoreErrType(common.CheckErrorUnreachable) {
+		return
+	}
+
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorUnreachable) {
+		return
+	}
+
+	// error定义为局部变量时，调用的不是系统的error函数
+	_, errorLocal := a.curScope.FindLocVar("error", node.Loc)
+	unreachFlag := false
+	for _, stat := range node.Stats {
+		if _, ok := stat.(*ast.LabelStat); ok {
+			unreachFlag = false
+			continue
+		}
+
+		if unreachFlag {
+			// break语句没有位置信息，忽略
+			if loc := common.GetStatLoc(stat); loc.StartLine > 0 {
+				a.curResult.InsertError(common.CheckErrorUnreachable, "Unreachable code", loc)
+			}
+			return
+		}
+
+		unreachFlag = common.IsStatTerminate(stat, false, errorLocal)
+		errorLocal = errorLocal || common.IsStatDeclareLocal(stat, "error")
+	}
+
+	if unreachFlag && len(node.RetExps) > 0 {
+		a.curResult.InsertError(common.CheckErrorUnreachable, "Unreachable code", common.GetExpLoc(node.RetExps[0]))
+	}
+}
+
+// checkMissingReturn 注解了返回值的函数，检查是否有代码路径执行到函数结尾，没有返回值
+// 第一个返回值为可选的，或是包含nil类型时，不检查
+func (a *Analysis) checkMissingReturn(node *ast.FuncDefExp) {
+	if !a.isNeedCheck() || a.realTimeFlag {
+		return
+	}
+
+	if common.GConfig.IsGlobalIgnoreErrType(common.CheckErrorMissingReturn) {
+		return
+	}
+
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorMissingReturn) {
+		return
+	}
+
+	// 函数的参数或是外层的局部变量为error时，调用的不是系统的error函数
+	_, errorLocal := a.curScope.FindLocVar("error", node.Block.Loc)
+	if common.IsBlockTerminate(node.Block, true, errorLocal) {
+		return
+	}
+
+	returnInfo := a.Projects.GetFuncReturnInfo(a.curResult.Name, node.Loc.StartLine-1)
+	if returnInfo == nil || len(returnInfo.ReturnTypeList) == 0 {
+		return
+	}
+
+	if len(returnInfo.ReturnOptionList) > 0 && returnInfo.ReturnOptionList[0] {
+		return
+	}
+
+	if isAnnotateTypeContainNil(returnInfo.ReturnTypeList[0]) {
+		return
+	}
+
+	// 告警的位置为函数结尾的end
+	loc := lexer.Location{
+		StartLine:   node.Loc.EndLine,
+		StartColumn: node.Loc.EndColumn - 3,
+		EndLine:     node.Loc.EndLine,
+		EndColumn:   node.Loc.EndColumn,
+	}
+	if loc.StartColumn < 0 {
+		loc.StartColumn = 0
+	}
+	a.curResult.InsertError(common.CheckErrorMissingReturn, "Missing return value, function is annotated with ---@return", loc)
+}
+
+// isAnnotateTypeContainNil 注解的类型是否包含nil，例如 number|nil
+func isAnnotateTypeContainNil(astType annotateast.Type) bool {
+	multiType, ok := astType.(*annotateast.MultiType)
+	if !ok {
+		return false
+	}
+
+	for _, oneType := range multiType.TypeList {
+		if normalType, ok := oneType.(*annotateast.NormalType); ok && normalType.StrName == "nil" {
+			return true
+		}
+	}
+
+	return false
+}
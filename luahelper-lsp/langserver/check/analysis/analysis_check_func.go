@@ -19,7 +19,7 @@ func (a *Analysis) funcCallParamTypeCheck(node *ast.FuncCallStat, referFunc *com
 		return
 	}
 
-	if _, ok := common.GConfig.OpenErrorTypeMap[common.CheckErrorCallParamType]; !ok {
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorCallParamType) {
 		return
 	}
 
@@ -27,6 +27,8 @@ func (a *Analysis) funcCallParamTypeCheck(node *ast.FuncCallStat, referFunc *com
 		a.loadFuncParamAnnType(referFunc)
 	}
 
+	// 泛型参数推导出来的类型，key为泛型名称
+	genericBindMap := map[string][]string{}
 	for i, argExp := range node.Args {
 		if i >= len(referFunc.ParamList) {
 			//可能是可变参数导致
@@ -35,6 +37,7 @@ func (a *Analysis) funcCallParamTypeCheck(node *ast.FuncCallStat, referFunc *com
 
 		mutex.Lock()
 		allAnnTypeVec, ok := referFunc.ParamType[referFunc.ParamList[i]]
+		genericName, genericParent, genericFlag := getFuncGenericParam(referFunc, allAnnTypeVec)
 		mutex.Unlock()
 
 		if !ok {
@@ -43,23 +46,44 @@ func (a *Analysis) funcCallParamTypeCheck(node *ast.FuncCallStat, referFunc *com
 		}
 
 		//函数调用处的参数类型
-		argCallTypeVec := a.GetAnnTypeByExp(argExp, -1)
+		argCallTypeVec := a.narrowExpTypeVec(argExp, a.GetAnnTypeByExp(argExp, -1))
 		if len(argCallTypeVec) == 0 {
 			// 取不到参数类型
 			continue
 		}
 
-		hasMatch := false
+		if genericFlag {
+			// 参数为泛型，有父类型时需要匹配父类型；前面的参数推导出了泛型时，需要匹配推导出来的类型
+			if genericParent != "" {
+				allAnnTypeVec = []string{genericParent}
+			} else if bindTypeVec, ok := genericBindMap[genericName]; ok {
+				allAnnTypeVec = bindTypeVec
+			} else {
+				if isGenericBindType(argCallTypeVec) {
+					genericBindMap[genericName] = argCallTypeVec
+				}
+				continue
+			}
+		}
+
+		// 实参可能的每一个类型都需要匹配注解，例如string|table类型的实参，在type(x) == "table"的分支内才能传给table参数
+		allMatch := true
 		for _, argCallTypeOne := range argCallTypeVec {
+			hasMatch := false
 			for _, argAnnTypeOne := range allAnnTypeVec {
 				if a.CompAnnTypeAndCodeType(argAnnTypeOne, argCallTypeOne) {
 					hasMatch = true
 					break
 				}
 			}
+
+			if !hasMatch {
+				allMatch = false
+				break
+			}
 		}
 
-		if hasMatch {
+		if allMatch {
 			continue
 		}
 
@@ -73,6 +97,28 @@ func (a *Analysis) funcCallParamTypeCheck(node *ast.FuncCallStat, referFunc *com
 	}
 }
 
+// getFuncGenericParam 判断函数参数的注解类型是否为泛型，返回泛型的名称与父类型
+func getFuncGenericParam(referFunc *common.FuncInfo, annTypeVec []string) (genericName string,
+	genericParent string, genericFlag bool) {
+	if len(annTypeVec) != 1 {
+		return
+	}
+
+	genericParent, genericFlag = referFunc.GenericType[annTypeVec[0]]
+	return annTypeVec[0], genericParent, genericFlag
+}
+
+// isGenericBindType 实参的类型是否可以用于推导泛型，无法确定的类型不推导
+func isGenericBindType(argTypeVec []string) bool {
+	for _, oneType := range argTypeVec {
+		if oneType == "" || oneType == "any" || oneType == "nil" || oneType == "LuaTypeRefer" {
+			return false
+		}
+	}
+
+	return true
+}
+
 // 函数体内的返回值类型检查 检查函数的返回值类型与注解类型是否匹配 一次检查一个return语句
 func (a *Analysis) funcReturnCheck(retInfo *common.ReturnInfo) {
 	// 第二轮或第三轮函数参数check
@@ -85,7 +131,7 @@ func (a *Analysis) funcReturnCheck(retInfo *common.ReturnInfo) {
 		return
 	}
 
-	if _, ok := common.GConfig.OpenErrorTypeMap[common.CheckErrorFuncRetErr]; !ok {
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorFuncRetErr) {
 		return
 	}
 
@@ -122,6 +168,14 @@ func (a *Analysis) funcReturnCheck(retInfo *common.ReturnInfo) {
 		hasMatch := false
 		for _, codeType := range returnTypeVec {
 			for _, annType := range a.curFunc.ReturnType[i] {
+				// 返回值为有父类型的泛型时，需要匹配父类型
+				mutex.Lock()
+				genericParent := a.curFunc.GenericType[annType]
+				mutex.Unlock()
+				if genericParent != "" && codeType != annType {
+					annType = genericParent
+				}
+
 				if a.CompAnnTypeAndCodeType(annType, codeType) {
 					hasMatch = true
 					break
@@ -150,7 +204,7 @@ func (a *Analysis) checkLocFuncCall() {
 		return
 	}
 
-	if _, ok := common.GConfig.OpenErrorTypeMap[common.CheckErrorLocFuncNotCall]; !ok {
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorLocFuncNotCall) {
 		return
 	}
 
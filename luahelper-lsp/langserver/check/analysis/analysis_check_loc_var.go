@@ -3,6 +3,7 @@ package analysis
 import (
 	"fmt"
 	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/lexer"
 )
 
 func (a *Analysis) checkLocVarCall() {
@@ -86,3 +87,52 @@ func (a *Analysis) checkLocVarCall() {
 		}
 	}
 }
+
+// checkShadowVar 第一轮检查定义的局部变量，是否遮蔽了同一函数内外层的局部变量或是函数的参数
+// 例如 function test(state) local count = 1 if state then local count = 2 end end，内层的count遮蔽了外层的count
+func (a *Analysis) checkShadowVar(strName string, loc lexer.Location) {
+	if !a.isFirstTerm() || a.realTimeFlag {
+		return
+	}
+
+	if common.GConfig.IsGlobalIgnoreErrType(common.CheckErrorShadowVar) {
+		return
+	}
+
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorShadowVar) {
+		return
+	}
+
+	if strName == "_" || a.curFunc == nil {
+		return
+	}
+
+	for scope := a.curScope; scope != nil; scope = scope.Parent {
+		if varInfoList, ok := scope.LocVarMap[strName]; ok {
+			for _, oneVar := range varInfoList.VarVec {
+				// 同一层的重复定义不告警，只判断函数的参数
+				if scope == a.curScope && !oneVar.IsParam {
+					continue
+				}
+
+				if oneVar.Loc.StartLine > loc.StartLine {
+					continue
+				}
+
+				strKind := "local"
+				if oneVar.IsParam {
+					strKind = "parameter"
+				}
+				errStr := fmt.Sprintf("Local '%s' shadows %s '%s' defined at line %d", strName, strKind, strName,
+					oneVar.Loc.StartLine)
+				a.curResult.InsertError(common.CheckErrorShadowVar, errStr, loc)
+				return
+			}
+		}
+
+		// 只判断同一个函数内的
+		if scope == a.curFunc.MainScope {
+			break
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package analysis
+
+import (
+	"fmt"
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+)
+
+// 可能为nil的值，没有判断就取成员或是调用，例如下面的例子，find返回的值可能为nil
+// ---@return Foo?
+// function find(name) end
+// local one = find("a")
+// print(one.name)
+// 判断的方式与收窄类型的一致，包括 if one then、if not one then return end、one and one.name、assert(one) 等
+
+// checkPossibleNil 检查取成员或是调用的前缀表达式是否可能为nil
+// callFlag 为true表示是函数调用，否则为取成员
+func (a *Analysis) checkPossibleNil(prefixExp ast.Exp, callFlag bool) {
+	if !a.isNeedCheck() || a.realTimeFlag {
+		return
+	}
+
+	if common.GConfig.IsGlobalIgnoreErrType(common.CheckErrorPossibleNil) {
+		return
+	}
+
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorPossibleNil) {
+		return
+	}
+
+	if parensExp, ok := prefixExp.(*ast.ParensExp); ok {
+		a.checkPossibleNil(parensExp.Exp, callFlag)
+		return
+	}
+
+	nameExp, ok := prefixExp.(*ast.NameExp)
+	if !ok || a.curResult.Block == nil {
+		return
+	}
+
+	// 只判断局部变量，全局变量可能在其他的地方被赋值
+	varInfo, find := a.curScope.FindLocVar(nameExp.Name, nameExp.Loc)
+	if !find || varInfo.ReferFunc != nil || varInfo.IsForParam {
+		return
+	}
+
+	if !a.isVarPossibleNil(varInfo, nameExp.Name) {
+		return
+	}
+
+	// 前面的控制流中是否判断过了，或是重新赋值了
+	narrowInfo := a.curResult.NarrowCache.GetNarrowInfo(nameExp.Name, nameExp.Loc.StartLine,
+		nameExp.Loc.StartColumn)
+	if narrowInfo != nil && (narrowInfo.AssignFlag || !narrowInfo.IsMatchType("nil")) {
+		return
+	}
+
+	strAction := "indexing"
+	if callFlag {
+		strAction = "calling"
+	}
+
+	errStr := fmt.Sprintf("'%s' may be nil, check it before %s", nameExp.Name, strAction)
+	a.curResult.InsertError(common.CheckErrorPossibleNil, errStr, nameExp.Loc)
+}
+
+// isVarPossibleNil 局部变量的值是否可能为nil
+// 1) 变量注解的类型包含nil，或是可选的参数
+// 2) 没有注解时，变量的值为函数的调用，函数注解的返回值可能为nil
+// 3) 没有注解时，变量的值为map类型取值，例如 ---@type table<string, Foo> 的 one[key]
+func (a *Analysis) isVarPossibleNil(varInfo *common.VarInfo, strName string) bool {
+	varIdx := int(varInfo.VarIndex)
+	if varIdx <= 0 {
+		varIdx = 1
+	}
+
+	nilFlag, findFlag := a.Projects.GetAnnotateNilFlag(varInfo, strName, varIdx)
+	if findFlag || varInfo.IsParam {
+		return nilFlag
+	}
+
+	switch referExp := varInfo.ReferExp.(type) {
+	case *ast.FuncCallExp:
+		funcVar := a.findCallFuncVar(referExp)
+		if funcVar == nil || funcVar.ReferFunc == nil {
+			return false
+		}
+
+		nilFlag, _ = a.Projects.GetAnnotateNilFlag(funcVar, "", varIdx)
+		return nilFlag
+	case *ast.TableAccessExp:
+		preExp, ok := referExp.PrefixExp.(*ast.NameExp)
+		if !ok {
+			return false
+		}
+
+		preVar, find := a.curScope.FindLocVar(preExp.Name, preExp.Loc)
+		if !find || preVar.ReferFunc != nil {
+			return false
+		}
+
+		return a.Projects.IsAnnotateMapType(preVar, preExp.Name)
+	}
+
+	return false
+}
+
+// findCallFuncVar 获取函数调用对应的函数变量，只处理 func() 与 a.func() 的形式
+func (a *Analysis) findCallFuncVar(node *ast.FuncCallExp) *common.VarInfo {
+	if node.NameExp != nil {
+		return nil
+	}
+
+	switch prefixExp := node.PrefixExp.(type) {
+	case *ast.NameExp:
+		ok, varInfo, _ := a.findVarDefine(prefixExp.Name, prefixExp.Loc)
+		if !ok {
+			return nil
+		}
+		return varInfo
+	case *ast.TableAccessExp:
+		preExp, ok := prefixExp.PrefixExp.(*ast.NameExp)
+		if !ok {
+			return nil
+		}
+
+		keyExp, ok := prefixExp.KeyExp.(*ast.StringExp)
+		if !ok {
+			return nil
+		}
+
+		ok, varInfo, _, _ := a.findVarDefineWithPre(preExp.Name, keyExp.Str, preExp.Loc, keyExp.Loc, true)
+		if !ok {
+			return nil
+		}
+		return varInfo
+	}
+
+	return nil
+}
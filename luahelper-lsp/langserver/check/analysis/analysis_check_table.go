@@ -26,7 +26,7 @@ func (a *Analysis) CheckTableDeclAssign(tcExp *ast.TableConstructorExp, loc *lex
 		return
 	}
 
-	if _, ok := common.GConfig.OpenErrorTypeMap[common.CheckErrorAssignType]; !ok {
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorAssignType) {
 		return
 	}
 
@@ -87,7 +87,7 @@ func (a *Analysis) CheckTableClassField(strTableName string, strFieldNamelist []
 		return
 	}
 
-	if _, ok := common.GConfig.OpenErrorTypeMap[common.CheckErrorClassField]; !ok {
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorClassField) {
 		return
 	}
 
@@ -129,7 +129,7 @@ func (a *Analysis) checkTableAccess(node *ast.TableAccessExp) {
 		return
 	}
 
-	if _, ok := common.GConfig.OpenErrorTypeMap[common.CheckErrorClassField]; !ok {
+	if !common.GConfig.IsOpenErrType(a.curResult.Name, common.CheckErrorClassField) {
 		return
 	}
 
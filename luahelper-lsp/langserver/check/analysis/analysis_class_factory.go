@@ -0,0 +1,76 @@
+package analysis
+
+import (
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+)
+
+// recordFactoryClass 第一轮记录工厂函数构造的类，例如下面的例子，产生隐式的Foo类型，父类型为Base
+// local Foo = class("Foo", Base)
+// strName 为赋值的变量名，类名称没有配置参数时，为变量名
+func (a *Analysis) recordFactoryClass(strName string, nameLoc lexer.Location, varInfo *common.VarInfo, exp ast.Exp) {
+	if !a.isFirstTerm() || varInfo == nil {
+		return
+	}
+
+	callExp, ok := exp.(*ast.FuncCallExp)
+	if !ok {
+		return
+	}
+
+	oneFactory := common.MatchFactoryCall(callExp)
+	if oneFactory == nil {
+		return
+	}
+
+	if oneFactory.NameIndex >= 1 && oneFactory.NameIndex <= len(callExp.Args) {
+		if strExp, ok := callExp.Args[oneFactory.NameIndex-1].(*ast.StringExp); ok && strExp.Str != "" {
+			strName = strExp.Str
+			nameLoc = strExp.Loc
+		}
+	}
+
+	classState := &annotateast.AnnotateClassState{
+		Name:    strName,
+		NameLoc: nameLoc,
+	}
+
+	if baseExp := common.GetFactoryBaseExp(callExp, oneFactory); baseExp != nil {
+		if strBase := a.getFactoryBaseName(baseExp); strBase != "" && strBase != strName {
+			classState.ParentNameList = append(classState.ParentNameList, strBase)
+			classState.ParentLocList = append(classState.ParentLocList, common.GetExpLoc(baseExp))
+		}
+	}
+
+	oneClass := &common.OneClassInfo{
+		LastLine:   nameLoc.StartLine,
+		ClassState: classState,
+		FieldMap:   map[string]*annotateast.AnnotateFieldState{},
+		RelateVar:  varInfo,
+		LuaFile:    a.curResult.Name,
+		Factory:    oneFactory,
+	}
+
+	varInfo.FactoryClass = oneClass
+	a.curResult.FactoryVec = append(a.curResult.FactoryVec, oneClass)
+}
+
+// getFactoryBaseName 获取工厂函数父类参数对应的类名称
+// 父类也是工厂函数构造的，取父类的类名称；否则取变量名，例如 local Base = require("base") 为Base
+func (a *Analysis) getFactoryBaseName(baseExp ast.Exp) string {
+	switch exp := baseExp.(type) {
+	case *ast.NameExp:
+		if baseVar := a.findFileVar(exp.Name, exp.Loc); baseVar != nil && baseVar.FactoryClass != nil {
+			return baseVar.FactoryClass.ClassState.Name
+		}
+		return exp.Name
+	case *ast.TableAccessExp:
+		if keyExp, ok := exp.KeyExp.(*ast.StringExp); ok {
+			return keyExp.Str
+		}
+	}
+
+	return ""
+}
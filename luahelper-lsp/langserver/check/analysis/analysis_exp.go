@@ -115,6 +115,7 @@ func (a *Analysis) cgFuncDefExp(node *ast.FuncDefExp) *common.FuncInfo {
 			varIndex)
 		locVar.IsParam = true
 		locVar.IsUse = true
+		locVar.ParamFunc = subFi
 
 		// 函数所有的参数放入数组进去，函数代码提示的时候有用
 		subFi.ParamList = append(subFi.ParamList, param)
@@ -134,6 +135,9 @@ func (a *Analysis) cgFuncDefExp(node *ast.FuncDefExp) *common.FuncInfo {
 	a.cgBlock(node.Block)
 	a.exitScope()
 
+	// 注解了返回值的函数，检查是否缺少返回
+	a.checkMissingReturn(node)
+
 	// 还原
 	a.curFunc = backupFunc
 	a.curScope = backupScope
@@ -366,9 +370,15 @@ func (a *Analysis) cgFuncCallExp(node *ast.FuncCallExp) *common.ReferInfo {
 		a.cgExp(arg, nil, nil)
 	}
 
+	// 第一轮记录文件内函数的调用位置
+	a.recordCallSite(node)
+
 	// 第二轮或第三轮函数参数check
 	a.cgFuncCallParamCheck(node)
 
+	// 冒号调用时为取成员
+	a.checkPossibleNil(node.PrefixExp, node.NameExp == nil)
+
 	return newRefer
 }
 
@@ -398,4 +408,5 @@ func (a *Analysis) cgTableAccessExp(node *ast.TableAccessExp, binParentExp *ast.
 	//      one.test_one() 是否有定义
 	a.findTableDefine(node)
 	a.checkTableAccess(node)
+	a.checkPossibleNil(node.PrefixExp, false)
 }
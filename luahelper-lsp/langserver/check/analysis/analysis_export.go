@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+)
+
+// recordExportInfo 第一轮遍历完后，记录文件被require时导出的返回信息
+// 主函数可能有多处返回，后面的return语句优先，前面的可能为提前返回的缓存值
+func (a *Analysis) recordExportInfo() {
+	fileResult := a.curResult
+	mainFunc := fileResult.MainFunc
+
+	exportInfo := &common.ExportInfo{}
+	for i := len(mainFunc.ReturnVecs) - 1; i >= 0; i-- {
+		returnInfo := mainFunc.ReturnVecs[i]
+		if len(returnInfo.ReturnVarVec) == 0 {
+			continue
+		}
+
+		a.collectExportExp(returnInfo.ReturnVarVec[0].ReturnExp, &exportInfo.ExpList, 0)
+	}
+
+	// 注解关联的是return关键字所在的行，返回的表达式可能换行了
+	if fileResult.Block != nil && len(fileResult.Block.RetExps) > 0 {
+		exportInfo.ReturnLine = fileResult.Block.RetLoc.StartLine
+	}
+
+	fileResult.ExportInfo = exportInfo
+}
+
+// collectExportExp 展开返回的表达式，获取所有可能导出的表达式
+// 1) 条件返回，例如 return cond and A or B，为A与B
+// 2) 辅助函数构造的，函数返回的为参数，例如 return build(M)，build返回的为参数t时，也包括M
+func (a *Analysis) collectExportExp(exp ast.Exp, expList *[]ast.Exp, depth int) {
+	if exp == nil || depth > 10 {
+		return
+	}
+
+	switch subExp := exp.(type) {
+	case *ast.NilExp, *ast.FalseExp:
+		return
+	case *ast.ParensExp:
+		a.collectExportExp(subExp.Exp, expList, depth+1)
+		return
+	case *ast.BinopExp:
+		if subExp.Op == lexer.TkOpAnd {
+			a.collectExportExp(subExp.Exp2, expList, depth+1)
+			return
+		}
+
+		if subExp.Op == lexer.TkOpOr {
+			a.collectExportExp(subExp.Exp1, expList, depth+1)
+			a.collectExportExp(subExp.Exp2, expList, depth+1)
+			return
+		}
+	case *ast.FuncCallExp:
+		*expList = append(*expList, exp)
+		if paramExp := a.getReturnParamArg(subExp); paramExp != nil {
+			a.collectExportExp(paramExp, expList, depth+1)
+		}
+		return
+	}
+
+	*expList = append(*expList, exp)
+}
+
+// getReturnParamArg 函数调用的函数返回的是参数时，获取调用时对应的实参
+// 例如 local function build(t) t.a = 1 return t end，调用build(M) 返回M
+func (a *Analysis) getReturnParamArg(node *ast.FuncCallExp) ast.Exp {
+	nameExp, ok := node.PrefixExp.(*ast.NameExp)
+	if !ok || node.NameExp != nil {
+		return nil
+	}
+
+	fileResult := a.curResult
+	varInfo, ok := fileResult.MainFunc.MainScope.FindLocVar(nameExp.Name, nameExp.Loc)
+	if !ok {
+		_, varInfo = fileResult.FindGlobalVarInfo(nameExp.Name, false, "")
+	}
+
+	if varInfo == nil || varInfo.ReferFunc == nil {
+		return nil
+	}
+
+	funcInfo := varInfo.ReferFunc
+	for _, returnInfo := range funcInfo.ReturnVecs {
+		if len(returnInfo.ReturnVarVec) == 0 {
+			continue
+		}
+
+		retExp, ok := returnInfo.ReturnVarVec[0].ReturnExp.(*ast.NameExp)
+		if !ok {
+			continue
+		}
+
+		for i, paramName := range funcInfo.ParamList {
+			if paramName == retExp.Name && i < len(node.Args) {
+				return node.Args[i]
+			}
+		}
+	}
+
+	return nil
+}
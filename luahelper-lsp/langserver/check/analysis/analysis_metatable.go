@@ -0,0 +1,32 @@
+package analysis
+
+import (
+	"luahelper-lsp/langserver/check/compiler/ast"
+)
+
+// recordMetatable 第一轮记录 setmetatable(a, b) 语句设置的原表，用于推导原表的继承关系
+// 例如下面的例子，Child 的原表为 {__index = Parent}，代码补全时Child也需要包含Parent的成员
+// local Child = {}
+// setmetatable(Child, {__index = Parent})
+func (a *Analysis) recordMetatable(node *ast.FuncCallStat) {
+	if !a.isFirstTerm() {
+		return
+	}
+
+	nameExp, ok := node.PrefixExp.(*ast.NameExp)
+	if !ok || nameExp.Name != "setmetatable" || node.NameExp != nil || len(node.Args) != 2 {
+		return
+	}
+
+	tableExp, ok := node.Args[0].(*ast.NameExp)
+	if !ok {
+		return
+	}
+
+	varInfo := a.findFileVar(tableExp.Name, tableExp.Loc)
+	if varInfo == nil {
+		return
+	}
+
+	varInfo.MetaExp = node.Args[1]
+}
@@ -163,31 +163,15 @@ func (a *Analysis) getFuncCallReferFunc(node *ast.FuncCallStat) (referFunc *comm
 		}
 
 		if referInfo.ReferType == common.ReferTypeRequire {
-			find, returnExp := referFile.MainFunc.GetLastOneReturnExp()
-			if !find {
-				return nil, strName, 0
-			}
-
-			subExp, ok := returnExp.(*ast.NameExp)
-			if !ok {
-				return nil, strName, 0
-			}
-
-			varInfo, ok := referFile.MainFunc.MainScope.FindLocVar(subExp.Name, subExp.Loc)
-			if !ok {
-				_, varInfo = referFile.FindGlobalVarInfo(subExp.Name, false, "")
-			}
-
-			if varInfo == nil {
-				return nil, strName, 0
-			}
-
-			subVar := common.GetVarSubGlobalVar(varInfo, strKeyName)
-			if subVar == nil {
-				return nil, strName, 0
+			// 文件导出的所有变量，例如 return cond and A or B
+			for _, varInfo := range referFile.GetExportVarList() {
+				subVar := common.GetVarSubGlobalVar(varInfo, strKeyName)
+				if subVar != nil {
+					return subVar.ReferFunc, strKeyName, int(referFile.GetFileTerm())
+				}
 			}
 
-			return subVar.ReferFunc, strKeyName, int(referFile.GetFileTerm())
+			return nil, strName, 0
 		}
 
 		if ok, oneVar := referFile.FindGlobalVarInfo(strKeyName, false, ""); ok {
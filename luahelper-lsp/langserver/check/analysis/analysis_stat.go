@@ -36,6 +36,8 @@ func (a *Analysis) cgStat(node ast.Stat) {
 		a.cgGotoStat(stat)
 	case *ast.BreakStat:
 		a.cgBreakStat(stat)
+	case *ast.ContinueStat:
+		a.cgContinueStat(stat)
 	}
 }
 
@@ -60,8 +62,17 @@ func (a *Analysis) cgFuncCallStat(node *ast.FuncCallStat) {
 		a.cgExp(argExp, nil, nil)
 	}
 
+	// 第一轮记录 setmetatable(a, b) 设置的原表
+	a.recordMetatable(node)
+
+	// 第一轮记录文件内函数的调用位置
+	a.recordCallSite(node)
+
 	// 第二轮或第三轮函数参数check
 	a.cgFuncCallParamCheck(node)
+
+	// 冒号调用时为取成员
+	a.checkPossibleNil(node.PrefixExp, node.NameExp == nil)
 }
 
 // 检查调用函数匹配的参数
@@ -125,6 +136,10 @@ func (a *Analysis) cgFuncCallParamCheck(node *ast.FuncCallStat) {
 func (a *Analysis) cgBreakStat(node *ast.BreakStat) {
 }
 
+// GLua的continue语句，与break一样不需要分析
+func (a *Analysis) cgContinueStat(node *ast.ContinueStat) {
+}
+
 func (a *Analysis) cgDoStat(node *ast.DoStat) {
 	a.enterScope()
 
@@ -328,6 +343,7 @@ func (a *Analysis) cgForNumStat(node *ast.ForNumStat) {
 	a.cgExp(node.StepExp, nil, nil)
 	a.cgExp(node.LimitExp, nil, nil)
 
+	a.checkShadowVar(node.VarName, node.VarLoc)
 	locVar := subScope.AddLocVar(a.curResult.Name, node.VarName, common.LuaTypeInter, nil, node.VarLoc, 1)
 	locVar.IsUse = true
 
@@ -389,6 +405,7 @@ func (a *Analysis) cgForInStat(node *ast.ForInStat) {
 	referExp, ipairsFlag := getForCycleData(node.ExpList)
 	for index, name := range node.NameList {
 		varIndex := uint8(index + 1)
+		a.checkShadowVar(name, node.NameLocList[index])
 		locVar := subScope.AddLocVar(a.curResult.Name, name, common.LuaTypeRefer, nil, node.NameLocList[index], varIndex)
 		locVar.IsForParam = true
 		locVar.IsUse = true
@@ -448,6 +465,7 @@ func (a *Analysis) cgGotoStat(node *ast.GotoStat) {
 
 func (a *Analysis) cgLocalFuncDefStat(node *ast.LocalFuncDefStat) {
 	scope := a.curScope
+	a.checkShadowVar(node.Name, node.NameLoc)
 	locVar := scope.AddLocVar(a.curResult.Name, node.Name, common.LuaTypeFunc, node.Exp, node.NameLoc, 1)
 
 	subFi := a.cgFuncDefExp(node.Exp)
@@ -501,6 +519,7 @@ func (a *Analysis) cgLocalVarDeclStat(node *ast.LocalVarDeclStat) {
 		}
 
 		nowLoc := node.VarLocList[i]
+		a.checkShadowVar(strName, nowLoc)
 		varInfo := scope.AddLocVar(a.curResult.Name, strName, common.GetExpType(exp), exp, nowLoc, varIndex)
 		oneAttr := node.AttrList[i]
 		if oneAttr == ast.RDKTOCLOSE {
@@ -529,6 +548,7 @@ func (a *Analysis) cgLocalVarDeclStat(node *ast.LocalVarDeclStat) {
 		// 关联这个变量，引用其他的变量
 		// 当前是局部变量赋值的时候，关联这个变量指向其他的变量
 		varInfo.ReferExp = exp
+		a.recordFactoryClass(strName, nowLoc, varInfo, exp)
 
 		// 判断指向的ReferExp是否为有效的, 如果为empty，设置对应的标记
 		if common.IsLocalReferExpEmpty(strName, exp) {
@@ -541,6 +561,7 @@ func (a *Analysis) cgLocalVarDeclStat(node *ast.LocalVarDeclStat) {
 		varIndex := uint8(i + 1)
 		nowLoc := node.VarLocList[i]
 		oneAttr := node.AttrList[i]
+		a.checkShadowVar(node.NameList[i], nowLoc)
 		if lastExpFuncFlag {
 			locVar := scope.AddLocVar(a.curResult.Name, node.NameList[i], common.LuaTypeRefer, nil, nowLoc, varIndex)
 			if oneAttr == ast.RDKTOCLOSE {
@@ -905,8 +926,18 @@ func (a *Analysis) cgAssignStat(node *ast.AssignStat) {
 			// 拷贝过来
 			newVar.SubMaps = tmpVar.SubMaps
 
+			// 直接名称的赋值，判断是否为未声明的全局变量
+			if !gGlag && strProPre == "" {
+				a.checkGlobalAssign(strName, loc)
+			}
+
 			// 插入全局变量
 			a.insertAnalysisGlobalVar(strName, newVar)
+
+			// 工厂函数构造的类，例如 Foo = class("Foo")
+			if nExps >= (i + 1) {
+				a.recordFactoryClass(strName, loc, newVar, node.ExpList[i])
+			}
 		} else {
 			strVecLen := len(strVec)
 			if findVar != nil && strVecLen > 0 {
@@ -1007,6 +1038,11 @@ func (a *Analysis) cgAssignStat(node *ast.AssignStat) {
 				a.checkIfNotTableAccess(exp, nil)
 			}
 		}
+
+		// 第二轮或第三轮检查 a.b = 1 其中a可能为nil
+		if exp, ok := valExp.(*ast.TableAccessExp); ok {
+			a.checkPossibleNil(exp.PrefixExp, false)
+		}
 	}
 
 	if a.isFirstTerm() && !a.realTimeFlag {
@@ -0,0 +1,102 @@
+package analysis
+
+import (
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+)
+
+// recordCallSite 第一轮记录文件内函数的调用位置，用于推导table的结构，例如下面的例子
+// local function fill(conf) conf.timeout = 3 end
+// local function consume(cfg) print(cfg.host) end
+// fill(made)
+// consume(made)
+// made的成员包含timeout，cfg的成员包含made的所有成员
+func (a *Analysis) recordCallSite(node *ast.FuncCallExp) {
+	if !a.isFirstTerm() {
+		return
+	}
+
+	funcVar := a.findCallSiteFuncVar(node)
+	if funcVar == nil || funcVar.ReferFunc == nil {
+		return
+	}
+
+	// 只记录当前文件内定义的函数，第一轮各文件并行分析
+	funcInfo := funcVar.ReferFunc
+	if funcInfo.FileName != a.curResult.Name {
+		return
+	}
+
+	funcInfo.CallSites = append(funcInfo.CallSites, node)
+
+	for i, argExp := range node.Args {
+		paramIndex := common.GetCallParamIndex(node, i)
+		if paramIndex >= len(funcInfo.ParamList) {
+			break
+		}
+
+		nameExp, ok := argExp.(*ast.NameExp)
+		if !ok {
+			continue
+		}
+
+		passInfo := common.ParamPassInfo{
+			FuncInfo:   funcInfo,
+			ParamIndex: paramIndex,
+		}
+		for _, argVar := range a.findAliasVarList(nameExp) {
+			argVar.PassParamVec = append(argVar.PassParamVec, passInfo)
+		}
+	}
+}
+
+// findAliasVarList 获取实参对应的变量，以及变量的别名指向的所有变量
+// 例如 local a = made; fill(a)，fill中的赋值同时作用于a与made
+func (a *Analysis) findAliasVarList(nameExp *ast.NameExp) (varVec []*common.VarInfo) {
+	visitMap := map[*common.VarInfo]bool{}
+	for nameExp != nil {
+		argVar := a.findFileVar(nameExp.Name, nameExp.Loc)
+		if argVar == nil || argVar.FileName != a.curResult.Name || visitMap[argVar] {
+			break
+		}
+
+		visitMap[argVar] = true
+		varVec = append(varVec, argVar)
+		nameExp, _ = argVar.ReferExp.(*ast.NameExp)
+	}
+
+	return varVec
+}
+
+// findCallSiteFuncVar 获取函数调用对应的当前文件的函数变量，处理 func()、a.func() 与 a:func() 的形式
+func (a *Analysis) findCallSiteFuncVar(node *ast.FuncCallExp) *common.VarInfo {
+	var preVar *common.VarInfo
+	strKey := ""
+	switch prefixExp := node.PrefixExp.(type) {
+	case *ast.NameExp:
+		preVar = a.findFileVar(prefixExp.Name, prefixExp.Loc)
+	case *ast.TableAccessExp:
+		preExp, ok := prefixExp.PrefixExp.(*ast.NameExp)
+		if !ok {
+			return nil
+		}
+
+		keyExp, ok := prefixExp.KeyExp.(*ast.StringExp)
+		if !ok || node.NameExp != nil {
+			return nil
+		}
+
+		preVar = a.findFileVar(preExp.Name, preExp.Loc)
+		strKey = keyExp.Str
+	}
+
+	if node.NameExp != nil {
+		strKey = node.NameExp.Str
+	}
+
+	if preVar == nil || strKey == "" {
+		return preVar
+	}
+
+	return preVar.SubMaps[strKey]
+}
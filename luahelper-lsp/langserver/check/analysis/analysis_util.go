@@ -276,6 +276,46 @@ func (a *Analysis) GetAnnTypeByExp(referExp ast.Exp, idx int) (retVec []string)
 	return retVec
 }
 
+// narrowExpTypeVec 表达式为局部变量时，根据控制流收窄变量的类型
+// 例如 if type(one) == "table" then 分支内，string|table 类型的one收窄为table
+func (a *Analysis) narrowExpTypeVec(exp ast.Exp, typeVec []string) []string {
+	nameExp, ok := exp.(*ast.NameExp)
+	if !ok || len(typeVec) <= 1 || a.curResult.Block == nil {
+		return typeVec
+	}
+
+	if _, find := a.curScope.FindLocVar(nameExp.Name, nameExp.Loc); !find {
+		return typeVec
+	}
+
+	narrowInfo := a.curResult.NarrowCache.GetNarrowInfo(nameExp.Name, nameExp.Loc.StartLine,
+		nameExp.Loc.StartColumn)
+	if narrowInfo == nil {
+		return typeVec
+	}
+
+	newVec := []string{}
+	for _, strType := range typeVec {
+		strBase, ok := common.GetNarrowBaseType(strType)
+		if !ok {
+			// 注解的class都为table，其他的类型无法确定，保留
+			strBase = ""
+			if createType := a.Projects.GetAnnClassInfo(strType); createType != nil && createType.ClassInfo != nil {
+				strBase = "table"
+			}
+		}
+
+		if strBase == "" || narrowInfo.IsMatchType(strBase) {
+			newVec = append(newVec, strType)
+		}
+	}
+
+	if len(newVec) == 0 {
+		return typeVec
+	}
+	return newVec
+}
+
 // 加载函数的参数与返回值的注解类型
 func (a *Analysis) loadFuncParamAnnType(referFunc *common.FuncInfo) {
 	if referFunc == nil || len(referFunc.ParamType) > 0 || len(referFunc.ReturnType) > 0 {
@@ -300,6 +340,12 @@ func (a *Analysis) loadFuncParamAnnType(referFunc *common.FuncInfo) {
 		//继续获取返回值注解
 		referFunc.ReturnType = a.Projects.GetFuncReturnTypeVec(referFunc.FileName, referFunc.Loc.StartLine-1)
 
+		//泛型的注解，参数与返回值的类型可能为泛型
+		genericType := a.Projects.GetFuncGenericType(referFunc.FileName, referFunc.Loc.StartLine-1)
+		mutex.Lock()
+		referFunc.GenericType = genericType
+		mutex.Unlock()
+
 		return //从函数上方获取到了注解之后就不再查找类成员函数的注解
 	}
 
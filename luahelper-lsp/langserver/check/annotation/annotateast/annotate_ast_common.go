@@ -50,3 +50,22 @@ const (
 	// EnumTypeEnd 枚举类型的结束
 	EnumTypeEnd = 2
 )
+
+// DiagnosticAction ---@diagnostic 后面跟着的动作
+type DiagnosticAction uint8
+
+const (
+	_ DiagnosticAction = iota
+
+	// DiagnosticDisableNextLine 屏蔽下一行的告警
+	DiagnosticDisableNextLine = 1
+
+	// DiagnosticDisableLine 屏蔽当前行的告警
+	DiagnosticDisableLine = 2
+
+	// DiagnosticDisable 开始屏蔽告警，直到enable或是文件结束
+	DiagnosticDisable = 3
+
+	// DiagnosticEnable 结束前面disable的屏蔽
+	DiagnosticEnable = 4
+)
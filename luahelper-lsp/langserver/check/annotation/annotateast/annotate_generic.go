@@ -0,0 +1,226 @@
+package annotateast
+
+// 泛型的推导与替换，例如下面的例子，调用处传入的参数类型为number[]，推导出T为number
+// ---@generic T
+// ---@param list T[]
+// ---@return T
+
+// getSingleType 只包含一种类型的MultiType，获取里面的类型
+func getSingleType(astType Type) Type {
+	for {
+		multiType, ok := astType.(*MultiType)
+		if !ok || len(multiType.TypeList) != 1 {
+			return astType
+		}
+
+		astType = multiType.TypeList[0]
+	}
+}
+
+// GetSingleGenericName 类型是否只为一个泛型名称，例如 T，是的返回泛型的名称
+func GetSingleGenericName(astType Type, nameMap map[string]bool) string {
+	normalType, ok := getSingleType(astType).(*NormalType)
+	if !ok || len(normalType.GenericTypeList) > 0 || !nameMap[normalType.StrName] {
+		return ""
+	}
+
+	return normalType.StrName
+}
+
+// HasGenericName 类型中是否引用了泛型的名称
+func HasGenericName(astType Type, nameMap map[string]bool) bool {
+	switch subAst := astType.(type) {
+	case *MultiType:
+		for _, oneType := range subAst.TypeList {
+			if HasGenericName(oneType, nameMap) {
+				return true
+			}
+		}
+	case *NormalType:
+		if nameMap[subAst.StrName] {
+			return true
+		}
+
+		for _, oneType := range subAst.GenericTypeList {
+			if HasGenericName(oneType, nameMap) {
+				return true
+			}
+		}
+	case *ArrayType:
+		return HasGenericName(subAst.ItemType, nameMap)
+	case *TableType:
+		if subAst.EmptyFlag {
+			return false
+		}
+
+		return HasGenericName(subAst.KeyType, nameMap) || HasGenericName(subAst.ValueType, nameMap)
+	case *FuncType:
+		for _, oneType := range subAst.ParamTypeList {
+			if HasGenericName(oneType, nameMap) {
+				return true
+			}
+		}
+
+		for _, oneType := range subAst.ReturnTypeList {
+			if HasGenericName(oneType, nameMap) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// BindGenericType 用实际的类型匹配声明的类型，推导出声明类型中泛型名称对应的具体类型
+// declareType 为声明的类型，例如 T[]；realType 为实际的类型，例如 number[]，推导出T为number
+// nameMap 为所有的泛型名称；bindMap 为推导的结果，已经推导出来的泛型不再修改
+func BindGenericType(declareType Type, realType Type, nameMap map[string]bool, bindMap map[string]Type) {
+	declareType = getSingleType(declareType)
+	realType = getSingleType(realType)
+	if declareType == nil || realType == nil {
+		return
+	}
+
+	switch subAst := declareType.(type) {
+	case *MultiType:
+		// 声明的为多种类型，例如 T|nil，只推导其中的泛型名称
+		for _, oneType := range subAst.TypeList {
+			if GetSingleGenericName(oneType, nameMap) != "" {
+				BindGenericType(oneType, realType, nameMap, bindMap)
+			}
+		}
+	case *NormalType:
+		if len(subAst.GenericTypeList) == 0 && nameMap[subAst.StrName] {
+			if _, ok := bindMap[subAst.StrName]; !ok {
+				bindMap[subAst.StrName] = realType
+			}
+			return
+		}
+
+		// 泛型类的实例化，例如 List<T> 与 List<number>
+		realNormal, ok := realType.(*NormalType)
+		if !ok || realNormal.StrName != subAst.StrName {
+			return
+		}
+
+		for i := 0; i < len(subAst.GenericTypeList) && i < len(realNormal.GenericTypeList); i++ {
+			BindGenericType(subAst.GenericTypeList[i], realNormal.GenericTypeList[i], nameMap, bindMap)
+		}
+	case *ArrayType:
+		if realArray, ok := realType.(*ArrayType); ok {
+			BindGenericType(subAst.ItemType, realArray.ItemType, nameMap, bindMap)
+		}
+	case *TableType:
+		realTable, ok := realType.(*TableType)
+		if !ok || subAst.EmptyFlag || realTable.EmptyFlag {
+			return
+		}
+
+		BindGenericType(subAst.KeyType, realTable.KeyType, nameMap, bindMap)
+		BindGenericType(subAst.ValueType, realTable.ValueType, nameMap, bindMap)
+	case *FuncType:
+		realFunc, ok := realType.(*FuncType)
+		if !ok {
+			return
+		}
+
+		for i := 0; i < len(subAst.ParamTypeList) && i < len(realFunc.ParamTypeList); i++ {
+			BindGenericType(subAst.ParamTypeList[i], realFunc.ParamTypeList[i], nameMap, bindMap)
+		}
+
+		for i := 0; i < len(subAst.ReturnTypeList) && i < len(realFunc.ReturnTypeList); i++ {
+			BindGenericType(subAst.ReturnTypeList[i], realFunc.ReturnTypeList[i], nameMap, bindMap)
+		}
+	}
+}
+
+// ReplaceGenericType 把类型中的泛型名称替换为推导出来的具体类型，返回新的类型，传入的类型不会修改
+// genericMap 的key为泛型的名称，value为对应的具体类型
+func ReplaceGenericType(astType Type, genericMap map[string]Type) Type {
+	if len(genericMap) == 0 {
+		return astType
+	}
+
+	switch subAst := astType.(type) {
+	case *MultiType:
+		newType := &MultiType{
+			Loc: subAst.Loc,
+		}
+		for _, oneType := range subAst.TypeList {
+			newType.TypeList = append(newType.TypeList, ReplaceGenericType(oneType, genericMap))
+		}
+		return newType
+	case *NormalType:
+		if len(subAst.GenericTypeList) == 0 {
+			if realType, ok := genericMap[subAst.StrName]; ok {
+				return realType
+			}
+			return astType
+		}
+
+		newType := *subAst
+		newType.GenericTypeList = nil
+		for _, oneType := range subAst.GenericTypeList {
+			newType.GenericTypeList = append(newType.GenericTypeList, ReplaceGenericType(oneType, genericMap))
+		}
+		return &newType
+	case *ArrayType:
+		return &ArrayType{
+			Loc:      subAst.Loc,
+			ItemType: ReplaceGenericType(subAst.ItemType, genericMap),
+		}
+	case *TableType:
+		if subAst.EmptyFlag {
+			return astType
+		}
+
+		newType := *subAst
+		newType.KeyType = ReplaceGenericType(subAst.KeyType, genericMap)
+		newType.ValueType = ReplaceGenericType(subAst.ValueType, genericMap)
+		return &newType
+	case *FuncType:
+		newType := *subAst
+		newType.ParamTypeList = nil
+		for _, oneType := range subAst.ParamTypeList {
+			newType.ParamTypeList = append(newType.ParamTypeList, ReplaceGenericType(oneType, genericMap))
+		}
+
+		newType.ReturnTypeList = nil
+		for _, oneType := range subAst.ReturnTypeList {
+			newType.ReturnTypeList = append(newType.ReturnTypeList, ReplaceGenericType(oneType, genericMap))
+		}
+		return &newType
+	}
+
+	return astType
+}
+
+// GetClassGenericMap 获取泛型类实例化时，泛型名称对应的具体类型
+// 例如类型为 List<number>，泛型类为 ---@class List<T>，返回T对应number
+func GetClassGenericMap(astType Type, className string, genericNameList []string) (genericMap map[string]Type) {
+	if len(genericNameList) == 0 {
+		return
+	}
+
+	switch subAst := astType.(type) {
+	case *MultiType:
+		for _, oneType := range subAst.TypeList {
+			if genericMap = GetClassGenericMap(oneType, className, genericNameList); len(genericMap) > 0 {
+				return genericMap
+			}
+		}
+	case *NormalType:
+		if subAst.StrName != className || len(subAst.GenericTypeList) == 0 {
+			return
+		}
+
+		genericMap = map[string]Type{}
+		for index, strName := range genericNameList {
+			if index < len(subAst.GenericTypeList) {
+				genericMap[strName] = subAst.GenericTypeList[index]
+			}
+		}
+	}
+
+	return genericMap
+}
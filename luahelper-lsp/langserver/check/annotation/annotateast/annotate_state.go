@@ -37,13 +37,16 @@ type AnnotateTypeState struct {
 }
 
 // AnnotateClassState 定义的class
+// ---@class MY_TYPE[<T1 [, T2]>][:PARENT_TYPE] [@comment]
 type AnnotateClassState struct {
-	Name           string           // class的名称
-	NameLoc        lexer.Location   // class的名称位置
-	ParentNameList []string         // 可能存在多个父的对象的名称
-	ParentLocList  []lexer.Location // 可能存在的多个父的对象的位置信息
-	Comment        string           // 其他所有的注释内容
-	CommentLoc     lexer.Location   // 注释内容的位置信息
+	Name            string           // class的名称
+	NameLoc         lexer.Location   // class的名称位置
+	GenericNameList []string         // 泛型类的参数名称，例如 ---@class List<T> 中的T
+	GenericLocList  []lexer.Location // 泛型类的参数名称位置信息
+	ParentNameList  []string         // 可能存在多个父的对象的名称
+	ParentLocList   []lexer.Location // 可能存在的多个父的对象的位置信息
+	Comment         string           // 其他所有的注释内容
+	CommentLoc      lexer.Location   // 注释内容的位置信息
 }
 
 // AnnotateFieldState 定义的成员结构
@@ -80,7 +83,7 @@ type AnnotateReturnState struct {
 
 // AnnotateGenericState 泛型的结构
 // ---@generic T1 [: PARENT_TYPE] [, T2 [: PARENT_TYPE]] @comment @comment
-// todo 泛型还没有处理
+// 函数调用时，泛型根据实参的类型进行推导；没有推导出来时，有父类型的取父类型
 type AnnotateGenericState struct {
 	NameList       []string         // 可能一行定义多个
 	NameLocList    []lexer.Location // 所有的名称位置列表
@@ -112,6 +115,30 @@ type AnnotateEnumEndState struct {
 	CommentLoc lexer.Location // 注释内容的位置信息
 }
 
+// AnnotateDiagnosticState 行内屏蔽告警的注解
+// ---@diagnostic disable-next-line: local-no-use, no-define
+type AnnotateDiagnosticState struct {
+	Action      DiagnosticAction // 动作，disable-next-line、disable-line、disable、enable
+	ActionLoc   lexer.Location   // 动作的位置信息
+	RuleList    []string         // 规则的名称，为空表示所有的规则
+	RuleLocList []lexer.Location // 所有规则名称的位置信息
+}
+
+// AnnotateModuleState 文件return语句前的注解，表示require该文件返回的类型
+// ---@module MY_TYPE @comment
+type AnnotateModuleState struct {
+	ModuleType Type           // 定义的类型
+	Comment    string         // 其他所有的注释内容
+	CommentLoc lexer.Location // 注释内容的位置信息
+}
+
+// AnnotateMetaState 声明文件的注解，文件中定义的全局变量只是声明，当做系统的全局变量
+// ---@meta [name] [@comment]
+type AnnotateMetaState struct {
+	Comment    string         // 其他所有的注释内容
+	CommentLoc lexer.Location // 注释内容的位置信息
+}
+
 // AnnotateNotValidState 无效的Stat
 type AnnotateNotValidState struct {
 }
@@ -24,9 +24,10 @@ type Type interface{}
 
 // NormalType 普通的类型
 type NormalType struct {
-	StrName   string         // 关联的类型的字符串名字（或是alias的名字)
-	NameLoc   lexer.Location // 简单类型的位置信息
-	ShowColor bool           // 着色的时候，显示位置
+	StrName         string         // 关联的类型的字符串名字（或是alias的名字)
+	NameLoc         lexer.Location // 简单类型的位置信息
+	ShowColor       bool           // 着色的时候，显示位置
+	GenericTypeList []Type         // 泛型类实例化的类型，例如 List<number> 中的number
 }
 
 // MultiType 多种类型，选择其中一种都可以
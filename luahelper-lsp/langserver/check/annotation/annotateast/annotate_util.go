@@ -46,7 +46,19 @@ func TypeConvertStr(astType Type) string {
 
 		return multiStr
 	case *NormalType:
-		return subAst.StrName
+		if len(subAst.GenericTypeList) == 0 {
+			return subAst.StrName
+		}
+
+		// 泛型类的实例化，例如 List<number>
+		genericStr := ""
+		for index, oneType := range subAst.GenericTypeList {
+			if index > 0 {
+				genericStr = genericStr + ", "
+			}
+			genericStr = genericStr + TypeConvertStr(oneType)
+		}
+		return subAst.StrName + "<" + genericStr + ">"
 
 	case *ArrayType:
 		return TypeConvertStr(subAst.ItemType) + "[]"
@@ -265,6 +277,11 @@ func GetTypeColorLocVec(astType Type) (locVec []lexer.Location) {
 		if subAst.ShowColor {
 			locVec = append(locVec, subAst.NameLoc)
 		}
+
+		for _, oneType := range subAst.GenericTypeList {
+			subLocVec := GetTypeColorLocVec(oneType)
+			locVec = append(locVec, subLocVec...)
+		}
 	case *ArrayType:
 		locVec = GetTypeColorLocVec(subAst.ItemType)
 	case *TableType:
@@ -330,6 +347,13 @@ func GetTypeLocInfo(astType Type, col int) (typeStr string, noticeStr string) {
 			noticeStr = ""
 			return typeStr, noticeStr
 		}
+
+		for _, oneType := range subAst.GenericTypeList {
+			typeStr, noticeStr = GetTypeLocInfo(oneType, col)
+			if typeStr != "" || noticeStr != "" {
+				return typeStr, noticeStr
+			}
+		}
 	case *ArrayType:
 		typeStr, noticeStr = GetTypeLocInfo(subAst.ItemType, col)
 		if typeStr != "" || noticeStr != "" {
@@ -383,6 +407,14 @@ func GetStateLocInfo(oneState AnnotateState, col int) (typeStr string, noticeStr
 			return
 		}
 
+		for _, oneLoc := range state.GenericLocList {
+			if colInLocation(oneLoc, col) {
+				typeStr = ""
+				noticeStr = "generic name"
+				return
+			}
+		}
+
 		for index, oneLoc := range state.ParentLocList {
 			if colInLocation(oneLoc, col) {
 				typeStr = state.ParentNameList[index]
@@ -512,6 +544,27 @@ func GetStateLocInfo(oneState AnnotateState, col int) (typeStr string, noticeStr
 			commentStr = state.Comment
 			return
 		}
+
+	case *AnnotateModuleState:
+		typeStr, noticeStr = GetTypeLocInfo(state.ModuleType, col)
+		if typeStr != "" || noticeStr != "" {
+			return typeStr, noticeStr, ""
+		}
+
+		if colInLocation(state.CommentLoc, col) {
+			typeStr = ""
+			noticeStr = "comment info"
+			commentStr = state.Comment
+			return
+		}
+
+	case *AnnotateMetaState:
+		if colInLocation(state.CommentLoc, col) {
+			typeStr = ""
+			noticeStr = "comment info"
+			commentStr = state.Comment
+			return
+		}
 	}
 
 	return
@@ -536,6 +589,12 @@ func GetAllStrAndLocList(astType Type) (strList []string, locList []lexer.Locati
 	case *NormalType:
 		strList = append(strList, subAst.StrName)
 		locList = append(locList, subAst.NameLoc)
+
+		for _, oneType := range subAst.GenericTypeList {
+			tmpStrList, tmpLocList := GetAllStrAndLocList(oneType)
+			strList = append(strList, tmpStrList...)
+			locList = append(locList, tmpLocList...)
+		}
 		return strList, locList
 
 	case *ArrayType:
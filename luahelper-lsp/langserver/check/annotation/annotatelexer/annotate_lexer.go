@@ -309,6 +309,32 @@ func (l *AnnotateLexer) scanIdentifier() string {
 	return str
 }
 
+// NextDiagnosticName 获取@diagnostic后面的动作或是规则名称，名称中可以包含-，例如disable-next-line
+// 调用之前不能有预读的单词
+func (l *AnnotateLexer) NextDiagnosticName() string {
+	l.skipWhiteSpaces()
+	l.tokenStartCol = l.col
+
+	i := 0
+	for ; i < len(l.chunk); i++ {
+		c := l.chunk[i]
+		if isLetter(c) || isDigit(c) || c == '_' || c == '-' {
+			continue
+		}
+
+		break
+	}
+
+	if i == 0 {
+		l.ErrorPrint(AErrorKind, ATokenKwIdentifier, "syntax error near '%s'", l.chunk)
+	}
+
+	token := l.chunk[0:i]
+	l.next(i)
+	l.setNowToken(ATokenKwIdentifier, token)
+	return token
+}
+
 func (l *AnnotateLexer) lookAheardToken() {
 	if l.aheadToken.valid {
 		return
@@ -54,6 +54,11 @@ func ParseCommentFragment(commentInfo *lexer.CommentInfo) (fragment annotateast.
 			continue
 		}
 
+		// 屏蔽告警的注解不属于注释段落，由ParseDiagnosticComment单独解析
+		if _, flag := annotateState.(*annotateast.AnnotateDiagnosticState); flag {
+			continue
+		}
+
 		fragment.Lines = append(fragment.Lines, commentLine.Line)
 		fragment.Stats = append(fragment.Stats, annotateState)
 	}
@@ -64,6 +69,34 @@ func ParseCommentFragment(commentInfo *lexer.CommentInfo) (fragment annotateast.
 	return fragment, parseErrVec
 }
 
+// ParseDiagnosticComment 解析注释中所有的---@diagnostic 屏蔽告警的注解
+// 头部注释与尾部注释都会解析，尾部注释用于disable-line
+func ParseDiagnosticComment(commentInfo *lexer.CommentInfo) (stateVec []*annotateast.AnnotateDiagnosticState,
+	parseErrVec []annotatelexer.ParseAnnotateErr) {
+	for _, commentLine := range commentInfo.LineVec {
+		l := annotatelexer.CreateAnnotateLexer(&commentLine.Str, commentLine.Line, commentLine.Col)
+		if !l.CheckHeardValid() {
+			continue
+		}
+
+		if l.LookAheadKind() != annotatelexer.ATokenKwIdentifier || l.GetHeardTokenStr() != "diagnostic" {
+			continue
+		}
+
+		annotateState, parseErr := ParserLine(l)
+		if parseErr.ErrType != annotatelexer.AErrorOk {
+			parseErrVec = append(parseErrVec, parseErr)
+			continue
+		}
+
+		if diagnosticState, flag := annotateState.(*annotateast.AnnotateDiagnosticState); flag {
+			stateVec = append(stateVec, diagnosticState)
+		}
+	}
+
+	return stateVec, parseErrVec
+}
+
 // constType常量补偿到aliasState中
 func appendAliasState(aliasState *annotateast.AnnotateAliasState, constType *annotateast.ConstType) {
 	if aliasState.AliasType == nil {
@@ -136,6 +169,16 @@ func parserOneState(l *annotatelexer.AnnotateLexer) annotateast.AnnotateState {
 		return parserVarargState(l)
 	case annotatelexer.ATokenKwEnum:
 		return parserEnumState(l)
+	case annotatelexer.ATokenKwIdentifier:
+		if l.GetHeardTokenStr() == "diagnostic" {
+			return parserDiagnosticState(l)
+		}
+		if l.GetHeardTokenStr() == "module" {
+			return parserModuleState(l)
+		}
+		if l.GetHeardTokenStr() == "meta" {
+			return parserMetaState(l)
+		}
 	}
 
 	return &annotateast.AnnotateNotValidState{}
@@ -116,6 +116,23 @@ func parserClassState(l *annotatelexer.AnnotateLexer) annotateast.AnnotateState
 	classState.Name = l.NextFieldName()
 	classState.NameLoc = l.GetNowLoc()
 
+	// 判断是否为泛型类，例如 ---@class List<T>
+	if l.LookAheadKind() == annotatelexer.ATokenLt {
+		l.NextTokenOfKind(annotatelexer.ATokenLt)
+		for {
+			classState.GenericNameList = append(classState.GenericNameList, l.NextIdentifier())
+			classState.GenericLocList = append(classState.GenericLocList, l.GetNowLoc())
+
+			if l.LookAheadKind() == annotatelexer.ATokenSepComma {
+				l.NextTokenOfKind(annotatelexer.ATokenSepComma)
+				continue
+			}
+
+			break
+		}
+		l.NextTokenOfKind(annotatelexer.ATokenGt)
+	}
+
 	// 判断这个类是否有父类， 是否包含 :
 	if l.LookAheadKind() == annotatelexer.ATokenSepColon {
 		// 跳过冒号
@@ -336,6 +353,37 @@ func parserVarargState(l *annotatelexer.AnnotateLexer) annotateast.AnnotateState
 	return varargState
 }
 
+// 解析@module
+// ---@module TYPE
+func parserModuleState(l *annotatelexer.AnnotateLexer) annotateast.AnnotateState {
+	// 前面的关键词为module 跳过
+	l.NextIdentifier()
+
+	moduleState := &annotateast.AnnotateModuleState{}
+
+	// 解析对应的类型
+	moduleState.ModuleType = parserOneType(l)
+
+	// 获取这个state的多余注释
+	moduleState.Comment, moduleState.CommentLoc = l.GetRemainComment()
+
+	return moduleState
+}
+
+// 解析@meta
+// ---@meta [name] [@comment]
+func parserMetaState(l *annotatelexer.AnnotateLexer) annotateast.AnnotateState {
+	// 前面的关键词为meta 跳过
+	l.NextIdentifier()
+
+	metaState := &annotateast.AnnotateMetaState{}
+
+	// 后面的名称与注释都当做注释内容
+	metaState.Comment, metaState.CommentLoc = l.GetRemainComment()
+
+	return metaState
+}
+
 // 解析@enum
 // ---@enum start @comment  表示枚举段的开始
 // ---@enum end @comment  表示枚举段的结束
@@ -368,3 +416,52 @@ func parserEnumState(l *annotatelexer.AnnotateLexer) annotateast.AnnotateState {
 	enumState.Comment, enumState.CommentLoc = l.GetRemainComment()
 	return enumState
 }
+
+// 解析@diagnostic
+// ---@diagnostic disable-next-line: local-no-use, no-define
+// ---@diagnostic disable-line
+// ---@diagnostic disable: no-define
+// ---@diagnostic enable: no-define
+func parserDiagnosticState(l *annotatelexer.AnnotateLexer) annotateast.AnnotateState {
+	// 前面的关键词为diagnostic 跳过
+	l.NextIdentifier()
+
+	diagnosticState := &annotateast.AnnotateDiagnosticState{}
+
+	actionStr := l.NextDiagnosticName()
+	diagnosticState.ActionLoc = l.GetNowLoc()
+	switch actionStr {
+	case "disable-next-line":
+		diagnosticState.Action = annotateast.DiagnosticDisableNextLine
+	case "disable-line":
+		diagnosticState.Action = annotateast.DiagnosticDisableLine
+	case "disable":
+		diagnosticState.Action = annotateast.DiagnosticDisable
+	case "enable":
+		diagnosticState.Action = annotateast.DiagnosticEnable
+	default:
+		l.ErrorPrint(annotatelexer.AErrorKind, annotatelexer.ATokenKwIdentifier, "unknown diagnostic action '%s'",
+			actionStr)
+	}
+
+	// 后面没有跟着:，表示所有的规则
+	if l.LookAheadKind() != annotatelexer.ATokenSepColon {
+		return diagnosticState
+	}
+	l.NextTokenOfKind(annotatelexer.ATokenSepColon)
+
+	for {
+		ruleName := l.NextDiagnosticName()
+		diagnosticState.RuleList = append(diagnosticState.RuleList, ruleName)
+		diagnosticState.RuleLocList = append(diagnosticState.RuleLocList, l.GetNowLoc())
+
+		if l.LookAheadKind() == annotatelexer.ATokenSepComma {
+			// 是逗号， 表示有多个规则
+			l.NextTokenOfKind(annotatelexer.ATokenSepComma)
+		} else {
+			break
+		}
+	}
+
+	return diagnosticState
+}
@@ -480,3 +480,93 @@ func TestAnnotateParserEnum2(t *testing.T) {
 		t.Fatalf("parser annotate type stats is not equal")
 	}
 }
+
+func TestAnnotateParserDiagnostic(t *testing.T) {
+	commentInfo := &lexer.CommentInfo{
+		LineVec: []lexer.CommentLine{
+			{
+				Str:  "-@diagnostic disable-next-line: local-no-use, no-define",
+				Line: 1,
+				Col:  2,
+			},
+			{
+				Str:  "-@diagnostic disable",
+				Line: 2,
+				Col:  2,
+			},
+			{
+				Str:  "-@type table",
+				Line: 3,
+				Col:  2,
+			},
+			{
+				Str:  "-@diagnostic unknown-action",
+				Line: 4,
+				Col:  2,
+			},
+		},
+	}
+
+	stateVec, errVec := ParseDiagnosticComment(commentInfo)
+	if len(stateVec) != 2 || len(errVec) != 1 {
+		t.Fatalf("parser diagnostic state num=%d, err num=%d", len(stateVec), len(errVec))
+	}
+
+	oneState := stateVec[0]
+	if oneState.Action != annotateast.DiagnosticDisableNextLine || len(oneState.RuleList) != 2 ||
+		oneState.RuleList[0] != "local-no-use" || oneState.RuleList[1] != "no-define" {
+		t.Fatalf("parser diagnostic disable-next-line error")
+	}
+
+	if stateVec[1].Action != annotateast.DiagnosticDisable || len(stateVec[1].RuleList) != 0 {
+		t.Fatalf("parser diagnostic disable error")
+	}
+
+	// 注释段落中不包含屏蔽告警的注解
+	fragent, _ := ParseCommentFragment(commentInfo)
+	if len(fragent.Stats) != 1 {
+		t.Fatalf("parser fragment with diagnostic stats num=%d", len(fragent.Stats))
+	}
+}
+
+func TestAnnotateParserGeneric(t *testing.T) {
+	commentInfo := &lexer.CommentInfo{
+		LineVec: []lexer.CommentLine{
+			{
+				Str:  "-@class List<T, K> : Base",
+				Line: 1,
+				Col:  0,
+			},
+			{
+				Str:  "-@type List<number, string[]>",
+				Line: 2,
+				Col:  0,
+			},
+		},
+	}
+	fragent, errVec := ParseCommentFragment(commentInfo)
+	if len(errVec) != 0 {
+		t.Fatalf("parser annotate generic fatal, errstr=%s", errVec[0].ShowStr)
+	}
+	if len(fragent.Stats) != 2 {
+		t.Fatalf("parser annotate generic stats num=%d", len(fragent.Stats))
+	}
+
+	classState, ok := fragent.Stats[0].(*annotateast.AnnotateClassState)
+	if !ok || classState.Name != "List" || len(classState.GenericNameList) != 2 ||
+		classState.GenericNameList[0] != "T" || classState.GenericNameList[1] != "K" ||
+		len(classState.ParentNameList) != 1 {
+		t.Fatalf("parser annotate generic class error")
+	}
+
+	typeState, ok := fragent.Stats[1].(*annotateast.AnnotateTypeState)
+	if !ok || len(typeState.ListType) != 1 {
+		t.Fatalf("parser annotate generic type error")
+	}
+
+	genericMap := annotateast.GetClassGenericMap(typeState.ListType[0], "List", classState.GenericNameList)
+	if annotateast.TypeConvertStr(genericMap["T"]) != "number" ||
+		annotateast.TypeConvertStr(genericMap["K"]) != "string[]" {
+		t.Fatalf("parser annotate generic map error")
+	}
+}
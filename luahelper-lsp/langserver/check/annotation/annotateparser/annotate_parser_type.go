@@ -31,13 +31,29 @@ func parserSingleType(l *annotatelexer.AnnotateLexer) annotateast.Type {
 	} else if lookHeardKind == annotatelexer.ATokenKwIdentifier {
 		// 为其他的标识符
 		nameStr := l.NextTypeIdentifier()
-		subType = &annotateast.NormalType{
+		normalType := &annotateast.NormalType{
 			StrName:   nameStr,
 			NameLoc:   l.GetNowLoc(),
 			ShowColor: true,
 		}
 
 		l.SetLastNormalTypeLoc(l.GetNowLoc())
+
+		// 泛型类的实例化，例如 List<number>
+		if l.LookAheadKind() == annotatelexer.ATokenLt {
+			l.NextTokenOfKind(annotatelexer.ATokenLt)
+			for {
+				normalType.GenericTypeList = append(normalType.GenericTypeList, parserOneType(l))
+				if l.LookAheadKind() == annotatelexer.ATokenSepComma {
+					l.NextTokenOfKind(annotatelexer.ATokenSepComma)
+					continue
+				}
+
+				break
+			}
+			l.NextTokenOfKind(annotatelexer.ATokenGt)
+		}
+		subType = normalType
 	} else if lookHeardKind == annotatelexer.ATokenVararg {
 		l.NextToken()
 		subType = &annotateast.NormalType{
@@ -46,6 +46,14 @@ type AllProject struct {
 
 	// 整体分析的阶段数
 	checkTerm results.CheckTerm
+
+	// 每个文件内的函数调用关系，查找调用层级时按需生成，文件变化时清除
+	callGraphMap   map[string]*fileCallGraph
+	callGraphMutex sync.Mutex // 函数调用关系的互斥锁
+
+	// 所有类型的继承关系，查找类型层级与实现时按需生成，注释类型重建时清除
+	typeHierarchyMap   map[typeHierarchyKey]*typeHierarchyNode
+	typeHierarchyMutex sync.Mutex // 类型继承关系的互斥锁
 }
 
 // CreateAllProject 创建整个检查工程
@@ -63,6 +71,7 @@ func CreateAllProject(allFilesList []string, entryFileArr []string, clientExpPat
 		completeCache:     common.CreateCompleteCache(),
 		fileLRUMap:        common.NewLRUCache(20),
 		fileIndexInfo:     common.CreateFileIndexInfo(),
+		callGraphMap:      map[string]*fileCallGraph{},
 	}
 
 	// 传入的所有文件列表转换成map
@@ -99,6 +108,7 @@ func (a *AllProject) HandleCheck() {
 	mainDir := dirManager.GetMainDir()
 
 	a.rebuidCreateTypeMap()
+	a.rebuildDeclareGlobals()
 
 	// 判断是否要进行特殊的检测
 	if len(a.entryFilesList) == 0 && !common.GConfig.IsSpecialCheck() {
@@ -156,11 +166,43 @@ func (a *AllProject) rebuidCreateTypeMap() {
 		}
 	}
 
+	// 类型的继承关系由注释类型与文件的分析结果生成，一起清除
+	a.clearTypeHierarchyMap()
+
 	tc := time.Since(time1)
 	ftime := tc.Milliseconds()
 	log.Debug("rebuidCreateTypeMap time:%d", ftime)
 }
 
+// rebuildDeclareGlobals 重新构建声明过的全局变量，包括---@meta 声明文件与允许定义全局变量的文件中定义的
+// 第一轮分析完后调用，第二轮与第三轮检查全局变量的赋值时使用
+func (a *AllProject) rebuildDeclareGlobals() {
+	metaVarMap := map[string]*common.VarInfo{}
+	defineNameMap := map[string]bool{}
+	for strFile, fileStruct := range a.fileStructMap {
+		if fileStruct.FileResult == nil {
+			continue
+		}
+
+		metaFlag := fileStruct.AnnotateFile.IsMetaFile()
+		defineFlag := common.GConfig.IsGlobalDefineFile(strFile)
+		if !metaFlag && !defineFlag {
+			continue
+		}
+
+		for strName, varInfo := range fileStruct.FileResult.GlobalMaps {
+			if metaFlag {
+				metaVarMap[strName] = varInfo
+			}
+			if defineFlag {
+				defineNameMap[strName] = true
+			}
+		}
+	}
+
+	common.GConfig.RebuildDeclareGlobals(metaVarMap, defineNameMap)
+}
+
 // GetAllFilesMap 获取分析的文件map列表
 func (a *AllProject) GetAllFilesMap() (allFilesMap map[string]string) {
 	return a.allFilesMap
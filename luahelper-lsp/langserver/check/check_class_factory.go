@@ -0,0 +1,215 @@
+package check
+
+import (
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+)
+
+// 工厂函数构造的类，第一轮分析时产生隐式的类型，这里处理类型的推导，例如下面的例子
+// local Foo = class("Foo", Base)
+// local obj = Foo:new()
+// obj的类型为Foo，补全时包含Foo、Foo.static与Base的成员
+
+// createFactoryTypeSymbol 构造工厂函数类型对应的注解符号
+func createFactoryTypeSymbol(classInfo *common.OneClassInfo) *common.Symbol {
+	normalAst := &annotateast.NormalType{
+		StrName: classInfo.ClassState.Name,
+		NameLoc: classInfo.ClassState.NameLoc,
+	}
+
+	return &common.Symbol{
+		FileName:     classInfo.LuaFile,
+		VarInfo:      nil,
+		AnnotateType: normalAst,
+		VarFlag:      common.FirstAnnotateFlag,
+		AnnotateLine: classInfo.LastLine,
+	}
+}
+
+// getSymbolFactoryClass 获取符号对应的工厂函数构造的类
+// 符号为类变量自身，或是注解类型为工厂函数构造的类
+func (a *AllProject) getSymbolFactoryClass(symbol *common.Symbol) *common.OneClassInfo {
+	if symbol == nil {
+		return nil
+	}
+
+	if symbol.VarInfo != nil && symbol.VarInfo.FactoryClass != nil {
+		return symbol.VarInfo.FactoryClass
+	}
+
+	normalType, ok := symbol.AnnotateType.(*annotateast.NormalType)
+	if !ok {
+		return nil
+	}
+
+	classList := a.getAllNormalAnnotateClass(normalType, symbol.FileName, symbol.GetLine())
+	if len(classList) == 0 || classList[0].Factory == nil {
+		return nil
+	}
+
+	return classList[0]
+}
+
+// getFactoryCallSymbol 函数调用为工厂函数构造类，或是 Foo.new() 创建类的实例时，返回对应类型的符号
+func (a *AllProject) getFactoryCallSymbol(luaInFile string, node *ast.FuncCallExp, comParam *CommonFuncParam,
+	findExpList *[]common.FindExpFile) (matchFlag bool, symbol *common.Symbol) {
+	// 1) 构造类，例如 class("Foo", Base)，类名称为字符串参数时，直接为该类型
+	if oneFactory := common.MatchFactoryCall(node); oneFactory != nil {
+		if oneFactory.NameIndex < 1 || oneFactory.NameIndex > len(node.Args) {
+			return
+		}
+
+		strExp, ok := node.Args[oneFactory.NameIndex-1].(*ast.StringExp)
+		if !ok || !a.judgeExistAnnoteTypeStr(strExp.Str) {
+			return
+		}
+
+		normalAst := &annotateast.NormalType{
+			StrName: strExp.Str,
+			NameLoc: strExp.Loc,
+		}
+		symbol = &common.Symbol{
+			FileName:     luaInFile,
+			VarInfo:      nil,
+			AnnotateType: normalAst,
+			VarFlag:      common.FirstAnnotateFlag,
+			AnnotateLine: node.Loc.StartLine,
+		}
+		return true, symbol
+	}
+
+	// 2) 点号创建实例，例如 Foo.new()
+	if node.NameExp != nil {
+		return
+	}
+
+	taExp, ok := node.PrefixExp.(*ast.TableAccessExp)
+	if !ok {
+		return
+	}
+
+	keyExp, ok := taExp.KeyExp.(*ast.StringExp)
+	if !ok {
+		return
+	}
+
+	// 前缀单独查找，不影响后面查找整个前缀表达式
+	tmpExpList := append([]common.FindExpFile{}, *findExpList...)
+	preSymbol := a.FindVarReferSymbol(luaInFile, taExp.PrefixExp, comParam, &tmpExpList, 1)
+	classInfo := a.getSymbolFactoryClass(preSymbol)
+	if classInfo == nil || !classInfo.IsFactoryNewFunc(keyExp.Str) {
+		return
+	}
+
+	return true, createFactoryTypeSymbol(classInfo)
+}
+
+// getFactoryNewSymbol 调用的对象为工厂函数构造的类，判断是否为创建实例，例如 Foo:new() 或是 Foo()
+func (a *AllProject) getFactoryNewSymbol(node *ast.FuncCallExp, beforeSymbol *common.Symbol) *common.Symbol {
+	classInfo := a.getSymbolFactoryClass(beforeSymbol)
+	if classInfo == nil {
+		return nil
+	}
+
+	if node.NameExp != nil {
+		if !classInfo.IsFactoryNewFunc(node.NameExp.Str) {
+			return nil
+		}
+
+		// 类中定义的创建实例的函数有---@return 注解时，以注解的返回类型为准
+		if a.isFactoryNewFuncAnnotateReturn(classInfo, node.NameExp.Str) {
+			return nil
+		}
+	} else if _, ok := node.PrefixExp.(*ast.NameExp); !ok || classInfo.Factory.CallNewFlag != 1 {
+		return nil
+	}
+
+	return createFactoryTypeSymbol(classInfo)
+}
+
+// isFactoryNewFuncAnnotateReturn 工厂函数构造的类中，是否定义了创建实例的函数，并且注解了返回值
+func (a *AllProject) isFactoryNewFuncAnnotateReturn(classInfo *common.OneClassInfo, strName string) bool {
+	if classInfo.RelateVar == nil {
+		return false
+	}
+
+	newVar, ok := classInfo.RelateVar.SubMaps[strName]
+	if !ok || newVar.ReferFunc == nil {
+		return false
+	}
+
+	returnInfo := a.GetFuncReturnInfo(classInfo.LuaFile, newVar.ReferFunc.Loc.StartLine-1)
+	return returnInfo != nil && len(returnInfo.ReturnTypeList) > 0
+}
+
+// getFactorySubMem 工厂函数构造的类，获取静态成员或是创建实例的函数
+// 例如 Foo.static.create 可以直接通过 Foo.create 调用，Foo:new 没有定义时，为Foo:initialize
+func (a *AllProject) getFactorySubMem(classInfo *common.OneClassInfo, strKey string) *common.Symbol {
+	oneFactory := classInfo.Factory
+	if oneFactory == nil || classInfo.RelateVar == nil {
+		return nil
+	}
+
+	if staticVar, ok := classInfo.RelateVar.SubMaps[oneFactory.StaticField]; ok && oneFactory.StaticField != "" {
+		if subVar, ok := staticVar.SubMaps[strKey]; ok {
+			return a.createAnnotateSymbol(strKey, subVar)
+		}
+	}
+
+	if oneFactory.InitFunc != "" && classInfo.IsFactoryNewFunc(strKey) {
+		if initVar, ok := classInfo.RelateVar.SubMaps[oneFactory.InitFunc]; ok {
+			return a.createAnnotateSymbol(strKey, initVar)
+		}
+	}
+
+	return nil
+}
+
+// getFactoryClassSubKey 类变量自身没有strKey成员时，在工厂函数构造的类以及父类中查找
+func (a *AllProject) getFactoryClassSubKey(symbol *common.Symbol, strKey string) *common.Symbol {
+	if symbol.VarInfo == nil || symbol.VarInfo.FactoryClass == nil {
+		return nil
+	}
+
+	typeSymbol := createFactoryTypeSymbol(symbol.VarInfo.FactoryClass)
+	classList := a.getAllNormalAnnotateClass(typeSymbol.AnnotateType, typeSymbol.FileName, typeSymbol.GetLine())
+	return a.getClassListSubMem(classList, strKey)
+}
+
+// getFactoryClassComplete 类变量为工厂函数构造的类，补全父类型的成员
+func (a *AllProject) getFactoryClassComplete(symbol *common.Symbol, colonFlag bool) {
+	if symbol.VarInfo == nil || symbol.VarInfo.FactoryClass == nil {
+		return
+	}
+
+	a.getVarInfoCompleteExt(createFactoryTypeSymbol(symbol.VarInfo.FactoryClass), colonFlag)
+}
+
+// getFactoryCompleteExt 工厂函数构造的类，补全静态成员以及创建实例的函数
+func (a *AllProject) getFactoryCompleteExt(classInfo *common.OneClassInfo, colonFlag bool) {
+	oneFactory := classInfo.Factory
+	if oneFactory == nil || classInfo.RelateVar == nil {
+		return
+	}
+
+	if oneFactory.StaticField != "" {
+		a.getVarCompleteExt(classInfo.LuaFile, classInfo.RelateVar.SubMaps[oneFactory.StaticField], colonFlag)
+	}
+
+	initVar, ok := classInfo.RelateVar.SubMaps[oneFactory.InitFunc]
+	if !ok || oneFactory.InitFunc == "" {
+		return
+	}
+
+	// 创建实例的函数没有定义时，参数与初始化函数一致
+	newVar := &common.VarInfo{
+		SubMaps: map[string]*common.VarInfo{},
+	}
+	for _, strName := range oneFactory.NewFuncs {
+		if _, ok := classInfo.RelateVar.SubMaps[strName]; !ok {
+			newVar.SubMaps[strName] = initVar
+		}
+	}
+	a.getVarCompleteExt(classInfo.LuaFile, newVar, colonFlag)
+}
@@ -1,6 +1,7 @@
 package check
 
 import (
+	"context"
 	"luahelper-lsp/langserver/check/common"
 	"luahelper-lsp/langserver/check/compiler/lexer"
 	"luahelper-lsp/langserver/check/results"
@@ -14,6 +15,12 @@ type CommonFuncParam struct {
 	loc           lexer.Location
 	secondProject *results.SingleProjectResult
 	thirdStruct   *results.AnalysisThird
+	ctx           context.Context // 请求的上下文，代码补全时设置，用于判断请求是否被取消
+}
+
+// isCancelled 请求是否已经被取消，没有设置上下文的返回false
+func (c *CommonFuncParam) isCancelled() bool {
+	return c.ctx != nil && c.ctx.Err() != nil
 }
 
 // SliceInsert2 字符串切片拼接
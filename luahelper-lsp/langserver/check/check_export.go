@@ -0,0 +1,90 @@
+package check
+
+import (
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/results"
+)
+
+// require引用的文件，根据第一轮分析记录的导出信息，获取导出的符号，例如下面的例子
+// ---@module Logger
+// return cond and Impl or Fallback
+// 导出的符号包括Logger注解类型，以及Impl与Fallback变量
+
+// getExportAnnotateSymbol 获取return语句前注解的类型对应的符号
+func getExportAnnotateSymbol(referFile *results.FileResult) *common.Symbol {
+	exportInfo := referFile.ExportInfo
+	if exportInfo == nil || exportInfo.AnnotateType == nil {
+		return nil
+	}
+
+	return &common.Symbol{
+		FileName:     referFile.Name,
+		VarInfo:      nil,
+		AnnotateType: exportInfo.AnnotateType,
+		VarFlag:      common.FirstAnnotateFlag,
+		AnnotateLine: exportInfo.ReturnLine - 1,
+	}
+}
+
+// getReferExportSymbolList 获取require的文件导出的所有符号，注解的类型优先
+func (a *AllProject) getReferExportSymbolList(referFile *results.FileResult, comParam *CommonFuncParam,
+	findExpList *[]common.FindExpFile) (symList []*common.Symbol) {
+	exportInfo := referFile.ExportInfo
+	if exportInfo == nil {
+		find, returnExp := referFile.MainFunc.GetLastOneReturnExp()
+		if !find {
+			return
+		}
+
+		return a.FindDeepSymbolList(referFile.Name, returnExp, comParam, findExpList, true, 1)
+	}
+
+	if annotateSymbol := getExportAnnotateSymbol(referFile); annotateSymbol != nil {
+		symList = append(symList, annotateSymbol)
+	}
+
+	for _, exp := range exportInfo.ExpList {
+		tmpList := a.FindDeepSymbolList(referFile.Name, exp, comParam, findExpList, true, 1)
+		symList = append(symList, tmpList...)
+	}
+
+	return symList
+}
+
+// getReferExportSymbol 获取require的文件导出的符号，有多个可能导出的变量时，合并所有的成员
+func (a *AllProject) getReferExportSymbol(referFile *results.FileResult, comParam *CommonFuncParam,
+	findExpList *[]common.FindExpFile) (symbol *common.Symbol) {
+	exportInfo := referFile.ExportInfo
+	if exportInfo == nil {
+		find, returnExp := referFile.MainFunc.GetLastOneReturnExp()
+		if !find {
+			return nil
+		}
+
+		return a.FindVarReferSymbol(referFile.Name, returnExp, comParam, findExpList, 1)
+	}
+
+	for _, exp := range exportInfo.ExpList {
+		oneSymbol := a.FindVarReferSymbol(referFile.Name, exp, comParam, findExpList, 1)
+		if oneSymbol == nil {
+			continue
+		}
+
+		if symbol == nil {
+			symbol = oneSymbol
+		} else if symbol.VarInfo != nil && oneSymbol.VarInfo != nil {
+			symbol = mergeSymbolSubMaps(symbol, oneSymbol)
+		}
+	}
+
+	annotateSymbol := getExportAnnotateSymbol(referFile)
+	if annotateSymbol == nil {
+		return symbol
+	}
+
+	// 注解的类型与导出的变量都保留，补全时都获取
+	if symbol != nil {
+		annotateSymbol.VarInfo = symbol.VarInfo
+	}
+	return annotateSymbol
+}
@@ -178,11 +178,14 @@ func (a *AllProject) findStrReferSymbol(luaInFile string, strName string, loc le
 	// print(b) -- 这里对b找定义的时候，会找到， local b = b，此时对b的exp找引用
 	// 由于坐标问题，会一直找到local b = b，而找不到前面的一行b = 1
 	// 解决的方法，先找前一行，如果找不多，再找本行
+	posLine, posCol := loc.StartLine, loc.StartColumn
 	loc.StartColumn = 0
 	loc.EndColumn = 0
 	referSymbol := a.findLocReferSymbol(fileResult, loc.StartLine-1, 0, luaInFile, strName,
 		loc, gFlag, comParam, findExpList)
 	if referSymbol != nil {
+		// 根据控制流收窄变量的注解类型
+		a.narrowSymbolType(fileResult.NarrowCache, strName, referSymbol, posLine, posCol)
 		return referSymbol
 	}
 
@@ -192,8 +195,8 @@ func (a *AllProject) findStrReferSymbol(luaInFile string, strName string, loc le
 		return nil
 	}
 
-	// 系统的变量，没有注解系统
-	symbol = common.GetDefaultSymbol("", sysVar)
+	// 系统的变量，没有注解系统；声明文件中的全局变量，文件名为声明文件
+	symbol = common.GetDefaultSymbol(sysVar.FileName, sysVar)
 	return symbol
 }
 
@@ -249,6 +252,11 @@ func (a *AllProject) getClassInfoSubMem(classInfo *common.OneClassInfo, strKey s
 		}
 	}
 
+	// 工厂函数构造的类，静态成员以及创建实例的函数
+	if symbol = a.getFactorySubMem(classInfo, strKey); symbol != nil {
+		return symbol
+	}
+
 	// todo 这里是否要考虑到引用的信息，参考函数：varInfoHasSubKey
 
 	return
@@ -389,13 +397,8 @@ func (a *AllProject) getReferReferInfoSymbol(referFile *results.FileResult, refe
 	}
 
 	if referSubType == common.RtypeRequire {
-		find, returnExp := referFile.MainFunc.GetLastOneReturnExp()
-		if !find {
-			return
-		}
-
-		// 这里也需要做判断，函数返回的变量逐层跟踪，目前只跟踪了一层
-		symList := a.FindDeepSymbolList(referFile.Name, returnExp, comParam, findExpList, true, 1)
+		// 第一轮分析记录的文件导出的所有符号
+		symList := a.getReferExportSymbolList(referFile, comParam, findExpList)
 		// 所有关联的Var，都查找一边
 		for _, oneSymbol := range symList {
 			symbol = a.symbolHasSubKey(oneSymbol, strKey, comParam, findExpList)
@@ -419,8 +422,9 @@ func (a *AllProject) symbolHasSubKey(oldSymbol *common.Symbol, strKey string,
 	if simpleStrFlag {
 		classList := a.getAllNormalAnnotateClass(oldSymbol.AnnotateType, oldSymbol.FileName, line)
 		if subSombol := a.getClassListSubMem(classList, strKey); subSombol != nil {
-			// 表示通过注解类型找到了子成员
+			// 表示通过注解类型找到了子成员，泛型类的成员需要替换泛型
 			symbol = subSombol
+			setSymbolGenericMap(symbol, getClassGenericMap(oldSymbol.AnnotateType, classList))
 			return
 		}
 	}
@@ -447,6 +451,24 @@ func (a *AllProject) symbolHasSubKey(oldSymbol *common.Symbol, strKey string,
 	// 判断这个注解类型是否包含子key
 	// 递归查找子成员
 	symbol = a.varInfoHasSubKey(oldSymbol.VarInfo, strKey, comParam, findExpList)
+	if symbol != nil {
+		return symbol
+	}
+
+	// 自身没有，在原表的继承链中查找
+	symbol = a.getMetatableSubKey(oldSymbol, strKey, comParam)
+	if symbol != nil {
+		return symbol
+	}
+
+	// 工厂函数构造的类，在父类以及静态成员中查找
+	symbol = a.getFactoryClassSubKey(oldSymbol, strKey)
+	if symbol != nil {
+		return symbol
+	}
+
+	// 文件内推导的table结构，例如参数通过调用处的实参推导
+	symbol = a.getTableShapeSubKey(oldSymbol, strKey, comParam)
 	return symbol
 }
 
@@ -477,44 +499,6 @@ func (a *AllProject) varInfoHasSubKey(varInfo *common.VarInfo, strKey string,
 	return a.getReferReferInfoSymbol(referFile, referInfo, strKey, comParam, findExpList)
 }
 
-// 获取原表右侧的简单元素, 目前支持strKey为__index与__call
-func (a *AllProject) getSimpleMetatableRight(luaInFile string, rightExp ast.Exp, comParam *CommonFuncParam,
-	findExpList *[]common.FindExpFile, strKey string) (symbol *common.Symbol) {
-	expTable, ok := rightExp.(*ast.TableConstructorExp)
-	if !ok {
-		// 查询变量是否有关联到子成员 __index
-		rerferVarFile := a.FindVarReferSymbol(luaInFile, rightExp, comParam, findExpList, 1)
-		if rerferVarFile != nil {
-			subSymbol := a.symbolHasSubKey(rerferVarFile, strKey, comParam, findExpList)
-			if subSymbol != nil && subSymbol.VarInfo != nil {
-				findVarFile := a.FindVarReferSymbol(subSymbol.FileName, subSymbol.VarInfo.ReferExp, comParam,
-					findExpList, 1)
-				return findVarFile
-			}
-
-			return nil
-		}
-		return nil
-	}
-
-	for i, keyExp := range expTable.KeyExps {
-		valExp := expTable.ValExps[i]
-		if keyExp == nil {
-			continue
-		}
-
-		oneKeyStr := common.GetExpName(keyExp)
-		if oneKeyStr != strKey {
-			continue
-		}
-
-		// 递归查找
-		return a.FindVarReferSymbol(luaInFile, valExp, comParam, findExpList, 1)
-	}
-
-	return nil
-}
-
 // 判断是否为原表简单函数的构造
 // 完整的表达式为 a = setmetatable({}, {__index = b})
 // 第二个表达式为 {__index = b}
@@ -523,14 +507,24 @@ func (a *AllProject) getSimpleMetatable(luaInFile string, node *ast.FuncCallExp,
 	// 先获取左侧的元素
 	leftSymbol := a.FindVarReferSymbol(luaInFile, node.Args[0], comParam, findExpList, 1)
 
+	// 原表为变量时，先获取原表变量，例如 M.__index = M 中的M，__call与__index都在这个变量中查找
+	var metaSymbol *common.Symbol
+	if _, ok := node.Args[1].(*ast.TableConstructorExp); !ok {
+		metaSymbol = a.FindVarReferSymbol(luaInFile, node.Args[1], comParam, findExpList, 1)
+	}
+
 	// 获取原表右侧的元素__call元素
-	callRightSymbol := a.getSimpleMetatableRight(luaInFile, node.Args[1], comParam, findExpList, "__call")
+	callRightSymbol := a.getMetatableKeySymbol(luaInFile, node.Args[1], metaSymbol, "__call", comParam, findExpList)
 	if callRightSymbol != nil {
 		return callRightSymbol
 	}
 
-	// 获取原表右侧的元素__index元素
-	rightSymbol := a.getSimpleMetatableRight(luaInFile, node.Args[1], comParam, findExpList, "__index")
+	// 获取原表右侧的元素__index元素，父类型又设置了原表的，合并继承链中所有父类型的成员
+	rightSymbol := a.getMetatableKeySymbol(luaInFile, node.Args[1], metaSymbol, "__index", comParam, findExpList)
+	if rightSymbol != nil && rightSymbol.VarInfo != nil {
+		rightSymbol = a.mergeMetatableParent(rightSymbol, comParam)
+	}
+
 	if leftSymbol == nil {
 		return rightSymbol
 	}
@@ -565,19 +559,34 @@ func (a *AllProject) getSimpleMetatable(luaInFile string, node *ast.FuncCallExp,
 		return highSymbol
 	}
 
+	return mergeSymbolSubMaps(highSymbol, lowSymbol)
+}
+
+// mergeSymbolSubMaps 两边都含有变量时，合并两边的成员，返回优先级高的变量的拷贝
+func mergeSymbolSubMaps(highSymbol *common.Symbol, lowSymbol *common.Symbol) *common.Symbol {
+	if lowSymbol.VarInfo == nil {
+		return highSymbol
+	}
+
 	//  两边都含有变量，变量进行合并
 	if len(highSymbol.VarInfo.SubMaps) == 0 && len(lowSymbol.VarInfo.SubMaps) == 0 {
 		return highSymbol
 	}
 
-	if len(highSymbol.VarInfo.SubMaps) == 0 {
-		highSymbol.VarInfo.SubMaps = map[string]*common.VarInfo{}
+	// VarInfo为工程共享的分析结果，查找请求会并发执行，合并到拷贝的变量上，不修改原有的
+	subMaps := make(map[string]*common.VarInfo, len(highSymbol.VarInfo.SubMaps)+len(lowSymbol.VarInfo.SubMaps))
+	for key, oneVar := range highSymbol.VarInfo.SubMaps {
+		subMaps[key] = oneVar
 	}
 	for key, oneVar := range lowSymbol.VarInfo.SubMaps {
-		highSymbol.VarInfo.SubMaps[key] = oneVar
+		subMaps[key] = oneVar
 	}
 
-	return highSymbol
+	mergeVar := *highSymbol.VarInfo
+	mergeVar.SubMaps = subMaps
+	mergeSymbol := *highSymbol
+	mergeSymbol.VarInfo = &mergeVar
+	return &mergeSymbol
 }
 
 // 判断是否绑定了特定的注解推导类型
@@ -762,12 +771,7 @@ func (a *AllProject) getImportReferSymbol(luaInFile string, funcExp *ast.FuncCal
 	}
 
 	if oneRefer.ReferType == common.ReferTypeRequire {
-		find, returnExp := referFile.MainFunc.GetLastOneReturnExp()
-		if !find {
-			return nil
-		}
-
-		symbol = a.FindVarReferSymbol(referFile.Name, returnExp, comParam, findExpList, 1)
+		symbol = a.getReferExportSymbol(referFile, comParam, findExpList)
 	}
 
 	return symbol
@@ -854,6 +858,11 @@ func (a *AllProject) getFuncRelateSymbol(luaInFile string, node *ast.FuncCallExp
 		return matchVarFile
 	}
 
+	// 工厂函数构造的类，例如 local Foo = class("Foo", Base)，以及 Foo.new() 创建的实例
+	if ok, factorySymbol := a.getFactoryCallSymbol(luaInFile, node, comParam, findExpList); ok {
+		return factorySymbol
+	}
+
 	var beforeSymbol *common.Symbol
 	if selfFlag {
 		beforeSymbol = a.findStrReferSymbol(luaInFile, tableSigh, keyLoc, false, comParam, findExpList)
@@ -866,6 +875,12 @@ func (a *AllProject) getFuncRelateSymbol(luaInFile string, node *ast.FuncCallExp
 	if beforeSymbol == nil {
 		return
 	}
+
+	// 工厂函数构造的类创建实例，例如 Foo:new()
+	if newSymbol := a.getFactoryNewSymbol(node, beforeSymbol); newSymbol != nil {
+		return newSymbol
+	}
+
 	funcSymbol := beforeSymbol
 	if node.NameExp != nil {
 		strAfter := node.NameExp.Str
@@ -903,7 +918,7 @@ func (a *AllProject) getFuncRelateSymbol(luaInFile string, node *ast.FuncCallExp
 	}
 
 	// 获取对应的函数注释返回值
-	if ok, funcFile := a.getFuncIndexReturnSymbol(funcSymbol, varIndex, node, comParam, findExpList); ok {
+	if ok, funcFile := a.getFuncIndexReturnSymbol(luaInFile, funcSymbol, varIndex, node, comParam, findExpList); ok {
 		return funcFile
 	}
 
@@ -950,8 +965,9 @@ func (a *AllProject) getFuncRelateSymbol(luaInFile string, node *ast.FuncCallExp
 			return firstSmbol
 		}
 
+		// 例如 local self = setmetatable({}, M) self.name = 1 return self，返回的变量与原表推导出的都有成员
 		if len(lastSmbol.VarInfo.SubMaps) > len(firstSmbol.VarInfo.SubMaps) {
-			return lastSmbol
+			return mergeSymbolSubMaps(lastSmbol, firstSmbol)
 		}
 
 		return firstSmbol
@@ -965,7 +981,8 @@ func (a *AllProject) getFuncRelateSymbol(luaInFile string, node *ast.FuncCallExp
 	for _, oneSymbol := range symList {
 		// 如果找到了，判断是否有注解类型
 		// 获取对应的函数注释返回值
-		if ok, funcFile := a.getFuncIndexReturnSymbol(oneSymbol, varIndex, node, comParam, findExpList); ok {
+		if ok, funcFile := a.getFuncIndexReturnSymbol(luaInFile, oneSymbol, varIndex, node, comParam,
+			findExpList); ok {
 			return funcFile
 		}
 
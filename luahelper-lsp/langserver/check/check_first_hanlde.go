@@ -72,16 +72,27 @@ func (allProject *AllProject) analysisFirstLuaFile(f *results.FileStruct, luaFil
 	f.FileResult = firstFile
 
 	newParser := parser.CreateParser(f.Contents, luaFile)
+	newParser.SetLuaVersion(common.GConfig.GetLuaVersion())
 	mainAst, commentMap, errList := newParser.BeginAnalyze()
+	firstFile.Suppress = common.CreateFileSuppress(commentMap)
 	if len(errList) > 0 {
 		for _, oneErr := range errList {
 			firstFile.InsertError(common.CheckErrorSyntax, oneErr.ErrStr, oneErr.Loc)
 		}
 	}
 
+	// GLua中混用了!=与~=，以文件中先出现的写法为准
+	if firstStr, otherStr, locVec := newParser.GetMixedNotEqual(); len(locVec) > 0 {
+		for _, oneLoc := range locVec {
+			errStr := fmt.Sprintf("mixed '%s' and '%s' in one file, use '%s' instead", firstStr, otherStr, firstStr)
+			firstFile.InsertError(common.CheckErrorMixedNotEqual, errStr, oneLoc)
+		}
+	}
+
 	// 设置指向的AST
 	firstFile.Block = mainAst
 	firstFile.CommentMap = commentMap
+	firstFile.NarrowCache = common.CreateNarrowCache(mainAst)
 
 	// 设置主函数的包含的位置信息
 	firstFile.MainFunc.Loc = mainAst.Loc
@@ -111,6 +122,10 @@ func (allProject *AllProject) analysisFirstLuaFile(f *results.FileStruct, luaFil
 	// 第一轮遍历完后，进行这个文件的所有注解解析
 	f.AnnotateFile.AnalysisAllComment(commentMap)
 	f.AnnotateFile.RelateTypeVarInfo(firstFile.GlobalMaps, firstFile.MainFunc.MainScope)
+	f.AnnotateFile.InsertFactoryClass(firstFile.FactoryVec)
+	if firstFile.ExportInfo != nil && firstFile.ExportInfo.ReturnLine > 0 {
+		firstFile.ExportInfo.AnnotateType = f.AnnotateFile.GetModuleType(firstFile.ExportInfo.ReturnLine)
+	}
 	ftime4 := time.Since(time4).Milliseconds()
 
 	ftime5 := time.Since(time1).Milliseconds()
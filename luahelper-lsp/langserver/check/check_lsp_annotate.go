@@ -13,8 +13,9 @@ import (
 
 // 引入注解系统，一些方法
 
+// checkOneFileType 检查注解中引用的类型是否定义，classGenericMap为注解所属泛型类的参数名称
 func (a *AllProject) checkOneFileType(annotateFile *common.AnnotateFile, fragemnet *common.FragementInfo,
-	oneType annotateast.Type) {
+	oneType annotateast.Type, classGenericMap map[string]struct{}) {
 	strList, locList := annotateast.GetAllStrAndLocList(oneType)
 
 	var genericMap map[string]struct{} = map[string]struct{}{}
@@ -23,6 +24,9 @@ func (a *AllProject) checkOneFileType(annotateFile *common.AnnotateFile, fragemn
 			genericMap[oneGeneric.Name] = struct{}{}
 		}
 	}
+	for strName := range classGenericMap {
+		genericMap[strName] = struct{}{}
+	}
 
 	for index, str := range strList {
 		if common.GConfig.IsDefaultAnnotateType(str) {
@@ -46,6 +50,36 @@ func (a *AllProject) checkOneFileType(annotateFile *common.AnnotateFile, fragemn
 	}
 }
 
+// getFragmentClassGenericMap 注解块为泛型类成员函数的注解时，获取类的泛型参数名称
+// 例如 ---@class List<T> 的成员函数 function List:get() 前面的注解 ---@return T，可以引用T
+func getFragmentClassGenericMap(fileStruct *results.FileStruct, fragment *common.FragementInfo) (
+	classGenericMap map[string]struct{}) {
+	fileResult := fileStruct.FileResult
+	if fileResult == nil || fileResult.MainFunc == nil {
+		return
+	}
+
+	funcInfo := fileResult.GetLineFuncInfo(fragment.LastLine + 1)
+	if funcInfo == nil || funcInfo.ClassName == "" {
+		return
+	}
+
+	classVar, ok := fileResult.MainFunc.MainScope.FindLocVar(funcInfo.ClassName, funcInfo.Loc)
+	if !ok {
+		if classVar, ok = fileResult.GlobalMaps[funcInfo.ClassName]; !ok {
+			return
+		}
+	}
+
+	for _, strName := range fileStruct.AnnotateFile.GetVarClassGenericNames(classVar) {
+		if classGenericMap == nil {
+			classGenericMap = map[string]struct{}{}
+		}
+		classGenericMap[strName] = struct{}{}
+	}
+	return classGenericMap
+}
+
 // 单个注解文件进行check
 func (a *AllProject) checkFileAnnotate(fileStruct *results.FileStruct) {
 	annotateFile := fileStruct.AnnotateFile
@@ -58,51 +92,58 @@ func (a *AllProject) checkFileAnnotate(fileStruct *results.FileStruct) {
 
 	// 遍历所有的注解代码块
 	for _, oneFragment := range annotateFile.FragementMap {
+		classGenericMap := getFragmentClassGenericMap(fileStruct, oneFragment)
+
 		if oneFragment.AliasInfo != nil {
 			for _, oneAlias := range oneFragment.AliasInfo.AliasList {
 				aliasType := oneAlias.AliasState.AliasType
-				a.checkOneFileType(annotateFile, oneFragment, aliasType)
+				a.checkOneFileType(annotateFile, oneFragment, aliasType, classGenericMap)
 			}
 		}
 
 		if oneFragment.TypeInfo != nil {
 			for _, oneType := range oneFragment.TypeInfo.TypeList {
-				a.checkOneFileType(annotateFile, oneFragment, oneType)
+				a.checkOneFileType(annotateFile, oneFragment, oneType, classGenericMap)
 			}
 		}
 
 		if oneFragment.ClassInfo != nil {
 			for _, oneClass := range oneFragment.ClassInfo.ClassList {
 				for _, oneField := range oneClass.FieldMap {
-					a.checkOneFileType(annotateFile, oneFragment, oneField.FiledType)
+					a.checkOneFileType(annotateFile, oneFragment, oneField.FiledType, classGenericMap)
 				}
 			}
 		}
 
 		if oneFragment.ParamInfo != nil {
 			for _, oneParam := range oneFragment.ParamInfo.ParamList {
-				a.checkOneFileType(annotateFile, oneFragment, oneParam.ParamType)
+				a.checkOneFileType(annotateFile, oneFragment, oneParam.ParamType, classGenericMap)
 			}
 		}
 
 		if oneFragment.ReturnInfo != nil {
 			for _, oneType := range oneFragment.ReturnInfo.ReturnTypeList {
-				a.checkOneFileType(annotateFile, oneFragment, oneType)
+				a.checkOneFileType(annotateFile, oneFragment, oneType, classGenericMap)
 			}
 		}
 
 		if oneFragment.OverloadInfo != nil {
 			for _, oneLoad := range oneFragment.OverloadInfo.OverloadList {
-				a.checkOneFileType(annotateFile, oneFragment, oneLoad.OverFunType)
+				a.checkOneFileType(annotateFile, oneFragment, oneLoad.OverFunType, classGenericMap)
 			}
 		}
 
 		if oneFragment.VarargInfo != nil {
 			oneVararg := oneFragment.VarargInfo.VarargInfo
 			if oneVararg != nil {
-				a.checkOneFileType(annotateFile, oneFragment, oneVararg.VarargType)
+				a.checkOneFileType(annotateFile, oneFragment, oneVararg.VarargType, classGenericMap)
 			}
 		}
+
+		if oneFragment.ModuleInfo != nil {
+			a.checkOneFileType(annotateFile, oneFragment, oneFragment.ModuleInfo.ModuleInfo.ModuleType,
+				classGenericMap)
+		}
 	}
 
 	// 所有的错误告警信息，进行排序，因为在比对注解告警信息的时候，希望是有序的
@@ -135,6 +176,15 @@ func (a *AllProject) checkFileEnum(fileStruct *results.FileStruct) {
 
 // 判断定义的注解类型是否重复
 func (a *AllProject) checkCreateTypeListDuplicate(str string, createList common.CreateTypeList) {
+	// 工厂函数构造的类，不是注解定义的，不参与判断
+	annotateList := common.CreateTypeList{}
+	for _, oneCreate := range createList.List {
+		if oneCreate.ClassInfo == nil || oneCreate.ClassInfo.Factory == nil {
+			annotateList.List = append(annotateList.List, oneCreate)
+		}
+	}
+	createList = annotateList
+
 	lenList := len(createList.List)
 	if lenList <= 1 {
 		return
@@ -755,93 +805,11 @@ func (a *AllProject) getAllFuncAnnotateType(astType annotateast.Type, fileName s
 	return
 }
 
-// 获取函数泛型的返回，如果有泛型的返回，需要推导其关联的值
-func (a *AllProject) getFuncGenericVarInfo(oldSymbol *common.Symbol, fragment *common.FragementInfo,
-	funcAnnotateType annotateast.Type, node *ast.FuncCallExp, comParam *CommonFuncParam,
-	findExpList *[]common.FindExpFile) (findSymbol *common.Symbol) {
-	if oldSymbol.VarInfo == nil {
-		return
-	}
-
-	funcInfo := oldSymbol.VarInfo.ReferFunc
-	if funcInfo == nil {
-		return
-	}
-
-	if node == nil || fragment == nil || fragment.GenericInfo == nil || fragment.ParamInfo == nil {
-		return
-	}
-
-	normalStrList := annotateast.GetAllNormalStrList(funcAnnotateType)
-	for _, oneStr := range normalStrList {
-		// 判断这个字符串是否有关联到泛型
-		flag := false
-		for _, genericInfo := range fragment.GenericInfo.GenericInfoList {
-			if oneStr == genericInfo.Name {
-				flag = true
-				continue
-			}
-		}
-
-		// 没有匹配到这个泛型
-		if !flag {
-			continue
-		}
-
-		// 看哪个参数有匹配到这个泛型，指考虑下面这种简单的类型
-		// ---@generic AA
-		// ---@param one AA
-		// ---@return AA
-
-		// 复杂的这种，暂时不考虑
-		// ---@generic AA
-		// ---@param one AA[]
-		// ---@return AA
-
-		// 一个个参数的匹配
-		// node.Args
-		var findIndex int = -1
-		for _, oneParam := range fragment.ParamInfo.ParamList {
-			strParamNormalType := annotateast.TraverseOneType(oneParam.ParamType)
-			if strParamNormalType != oneStr {
-				continue
-			}
-
-			for index, strParam := range funcInfo.ParamList {
-				if strParam == oneParam.Name {
-					findIndex = index
-					break
-				}
-			}
-
-			if findIndex >= 0 {
-				break
-			}
-		}
-
-		if findIndex < 0 {
-			continue
-		}
-
-		// 找到这个findIndex对应的参数表达式
-		if findIndex >= len(node.Args) {
-			log.Error("findIndex =%d error, len=%d", findIndex, len(node.Args))
-			break
-		}
-
-		paramExp := node.Args[findIndex]
-		paramVarFile := a.FindVarReferSymbol(oldSymbol.FileName, paramExp, comParam, findExpList, 1)
-		if paramVarFile != nil {
-			return paramVarFile
-		}
-	}
-
-	return findSymbol
-}
-
 // 获取函数注释块是否有return语句, 如果有return语句，获取对应的type
-func (a *AllProject) getFuncReturnOneType(oldSymbol *common.Symbol, varIndex uint8, node *ast.FuncCallExp,
-	comParam *CommonFuncParam, findExpList *[]common.FindExpFile) (flag bool, symbol *common.Symbol) {
+// luaInFile 为函数调用所在的lua文件
+func (a *AllProject) getFuncReturnOneType(luaInFile string, oldSymbol *common.Symbol, varIndex uint8,
+	node *ast.FuncCallExp, comParam *CommonFuncParam, findExpList *[]common.FindExpFile) (flag bool,
+	symbol *common.Symbol) {
 	// 判断注解类型是否存在
 	// 首先获取变量是否直接注解为函数的返回
 	flag, fragment, typeList, _ := a.getFuncReturnAnnotateTypeList(oldSymbol)
@@ -855,7 +823,7 @@ func (a *AllProject) getFuncReturnOneType(oldSymbol *common.Symbol, varIndex uin
 
 	oneFunType := typeList[varIndex-1]
 	// 尝试获取泛型的推导
-	genericVarFile := a.getFuncGenericVarInfo(oldSymbol, fragment, oneFunType, node, comParam, findExpList)
+	genericVarFile := a.getFuncGenericVarInfo(luaInFile, oldSymbol, fragment, oneFunType, node, comParam, findExpList)
 	if genericVarFile != nil {
 		return flag, genericVarFile
 	}
@@ -873,11 +841,12 @@ func (a *AllProject) getFuncReturnOneType(oldSymbol *common.Symbol, varIndex uin
 
 // 获取一个函数返回的类型
 // varIndex 表示获取第一个函数返回值
-func (a *AllProject) getFuncIndexReturnSymbol(oldSymbol *common.Symbol, varIndex uint8,
+// luaInFile 为函数调用所在的lua文件
+func (a *AllProject) getFuncIndexReturnSymbol(luaInFile string, oldSymbol *common.Symbol, varIndex uint8,
 	node *ast.FuncCallExp, comParam *CommonFuncParam, findExpList *[]common.FindExpFile) (flag bool,
 	symbol *common.Symbol) {
 	// 1) 首先判断注解地方，是否有return返回值
-	flag, symbol = a.getFuncReturnOneType(oldSymbol, varIndex, node, comParam, findExpList)
+	flag, symbol = a.getFuncReturnOneType(luaInFile, oldSymbol, varIndex, node, comParam, findExpList)
 	if flag {
 		return
 	}
@@ -1448,7 +1417,7 @@ func (a *AllProject) getAnnotateFunStrKey(symbol *common.Symbol, strKey string,
 	findExpList *[]common.FindExpFile) (flag bool, subSymbol *common.Symbol) {
 	// 如果找到了，判断是否有注解类型
 	// 获取对应的函数注释返回值
-	flag1, varFuncFile := a.getFuncIndexReturnSymbol(symbol, 1, nil, comParam, findExpList)
+	flag1, varFuncFile := a.getFuncIndexReturnSymbol(symbol.FileName, symbol, 1, nil, comParam, findExpList)
 	flag = flag1
 	if varFuncFile == nil {
 		return
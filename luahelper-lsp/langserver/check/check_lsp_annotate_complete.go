@@ -68,6 +68,16 @@ func (a *AllProject) CompleteAnnotateArea() {
 	document += "\n\n" + "sample:\n---@vararg number"
 	a.completeCache.InsertCompleteNormal("vararg", detail, document, common.IKAnnotateClass)
 
+	detail = "module"
+	document = "---@module TYPE [@comment]"
+	document += "\n\n" + "sample:\n---@module Logger @require this file returns Logger\nreturn M"
+	a.completeCache.InsertCompleteNormal("module", detail, document, common.IKAnnotateClass)
+
+	detail = "meta"
+	document = "---@meta [name] [@comment]"
+	document += "\n\n" + "sample:\n---@meta @globals declared in this file are treated as system globals"
+	a.completeCache.InsertCompleteNormal("meta", detail, document, common.IKAnnotateClass)
+
 	// 9) author
 	// 插入用户与时间
 	userName := ""
@@ -339,6 +349,9 @@ func (a *AllProject) convertClassInfoToCompleteVecs(oneClass *common.OneClassInf
 	if oneClass.RelateVar != nil {
 		a.getVarCompleteExt(oneClass.LuaFile, oneClass.RelateVar, colonFlag)
 	}
+
+	// 3) 工厂函数构造的类，静态成员以及创建实例的函数
+	a.getFactoryCompleteExt(oneClass, colonFlag)
 }
 
 // getVarInfoCompleteExt 获取变量关联的所有子成员信息，用于代码补全
@@ -352,9 +365,13 @@ func (a *AllProject) getVarInfoCompleteExt(symbol *common.Symbol, colonFlag bool
 	// 1) 判断注解开关是否有打开, 如果注解打开获取注解的里面的信息
 	if symbol.AnnotateType != nil {
 		classList := a.getAllNormalAnnotateClass(symbol.AnnotateType, symbol.FileName, symbol.GetLine())
+
+		// 泛型类的实例化，成员中的泛型替换为具体的类型，例如 List<string>
+		a.completeCache.SetGenericMap(getClassGenericMap(symbol.AnnotateType, classList))
 		for _, oneClass := range classList {
 			a.convertClassInfoToCompleteVecs(oneClass, colonFlag)
 		}
+		a.completeCache.SetGenericMap(nil)
 
 		// 注释掉，同时补全注解类型与变量的类型
 		// return
@@ -0,0 +1,210 @@
+package check
+
+import (
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+)
+
+// 注解泛型的推导，包括泛型函数与泛型类
+
+// getFragmentGenericNameMap 获取注释块中所有的泛型名称，以及泛型对应的父类型
+func getFragmentGenericNameMap(fragment *common.FragementInfo) (nameMap map[string]bool,
+	parentMap map[string]string) {
+	nameMap = map[string]bool{}
+	parentMap = map[string]string{}
+	if fragment == nil || fragment.GenericInfo == nil {
+		return
+	}
+
+	for _, oneGeneric := range fragment.GenericInfo.GenericInfoList {
+		nameMap[oneGeneric.Name] = true
+		if oneGeneric.ParentName != "" {
+			parentMap[oneGeneric.Name] = oneGeneric.ParentName
+		}
+	}
+
+	return
+}
+
+// getCallArgExp 获取函数调用处，函数参数对应的实参表达式
+// 冒号的调用，第一个实参为调用的对象，例如 a:b(1) 中b的第一个参数为a
+func getCallArgExp(funcInfo *common.FuncInfo, node *ast.FuncCallExp, strParam string) ast.Exp {
+	for index, oneParam := range funcInfo.ParamList {
+		if oneParam != strParam {
+			continue
+		}
+
+		if node.NameExp != nil {
+			index--
+		}
+
+		if index < 0 || index >= len(node.Args) {
+			return nil
+		}
+
+		return node.Args[index]
+	}
+
+	return nil
+}
+
+// getExpAnnotateType 获取表达式对应的注解类型，用于泛型的推导，获取不到时返回nil
+func (a *AllProject) getExpAnnotateType(exp ast.Exp, symbol *common.Symbol) annotateast.Type {
+	strType := common.GetAnnTypeFromLuaType(common.GetExpType(exp))
+	if strType != "LuaTypeRefer" && strType != "any" && strType != "nil" {
+		return &annotateast.NormalType{
+			StrName: strType,
+			NameLoc: common.GetExpLoc(exp),
+		}
+	}
+
+	if symbol == nil {
+		return nil
+	}
+
+	if symbol.AnnotateType != nil {
+		return symbol.AnnotateType
+	}
+
+	if symbol.VarInfo == nil {
+		return nil
+	}
+
+	strType = common.GetAnnTypeFromLuaType(symbol.VarInfo.VarType)
+	if strType == "LuaTypeRefer" || strType == "any" || strType == "nil" {
+		return nil
+	}
+
+	return &annotateast.NormalType{
+		StrName: strType,
+		NameLoc: symbol.VarInfo.Loc,
+	}
+}
+
+// 获取函数泛型的返回，如果有泛型的返回，需要推导其关联的值
+// 泛型根据调用处实参的类型推导，例如下面的例子，调用 getList(1) 推导出返回值的类型为number[]
+// ---@generic T
+// ---@param one T
+// ---@return T[]
+// 泛型类的成员函数，泛型为类实例化的具体类型，例如 List<number> 的成员函数返回T，推导出为number
+func (a *AllProject) getFuncGenericVarInfo(luaInFile string, oldSymbol *common.Symbol,
+	fragment *common.FragementInfo, funcAnnotateType annotateast.Type, node *ast.FuncCallExp,
+	comParam *CommonFuncParam, findExpList *[]common.FindExpFile) (findSymbol *common.Symbol) {
+	if oldSymbol.VarInfo == nil {
+		return
+	}
+
+	funcInfo := oldSymbol.VarInfo.ReferFunc
+	if funcInfo == nil {
+		return
+	}
+
+	nameMap, parentMap := getFragmentGenericNameMap(fragment)
+
+	// 1) 泛型类实例化的具体类型
+	bindMap := map[string]annotateast.Type{}
+	for strName, oneType := range oldSymbol.GenericMap {
+		if !nameMap[strName] {
+			bindMap[strName] = oneType
+		}
+	}
+
+	// 2) 根据实参的类型，推导函数的泛型
+	if node != nil && len(nameMap) > 0 && fragment.ParamInfo != nil {
+		returnName := annotateast.GetSingleGenericName(funcAnnotateType, nameMap)
+		for _, oneParam := range fragment.ParamInfo.ParamList {
+			if !annotateast.HasGenericName(oneParam.ParamType, nameMap) {
+				continue
+			}
+
+			argExp := getCallArgExp(funcInfo, node, oneParam.Name)
+			if argExp == nil {
+				continue
+			}
+
+			argSymbol := a.FindVarReferSymbol(luaInFile, argExp, comParam, findExpList, 1)
+
+			// 返回值直接为参数的泛型，例如 ---@param one T ---@return T，返回值即为传入的参数
+			if returnName != "" && argSymbol != nil &&
+				annotateast.GetSingleGenericName(oneParam.ParamType, nameMap) == returnName {
+				if _, ok := bindMap[returnName]; !ok {
+					return argSymbol
+				}
+			}
+
+			argType := a.getExpAnnotateType(argExp, argSymbol)
+			if argType == nil {
+				continue
+			}
+
+			annotateast.BindGenericType(oneParam.ParamType, argType, nameMap, bindMap)
+		}
+	}
+
+	// 3) 没有推导出来的泛型，有父类型的取父类型
+	for strName, strParent := range parentMap {
+		if _, ok := bindMap[strName]; ok {
+			continue
+		}
+
+		bindMap[strName] = &annotateast.NormalType{
+			StrName: strParent,
+		}
+	}
+
+	if len(bindMap) == 0 {
+		return
+	}
+
+	bindNameMap := map[string]bool{}
+	for strName := range bindMap {
+		bindNameMap[strName] = true
+	}
+	if !annotateast.HasGenericName(funcAnnotateType, bindNameMap) {
+		return
+	}
+
+	findSymbol = &common.Symbol{
+		FileName:     oldSymbol.FileName,
+		VarInfo:      nil,
+		AnnotateType: annotateast.ReplaceGenericType(funcAnnotateType, bindMap),
+		VarFlag:      common.FirstAnnotateFlag,
+		AnnotateLine: oldSymbol.VarInfo.Loc.StartLine - 1,
+	}
+	return findSymbol
+}
+
+// getClassGenericMap 变量的注解类型为泛型类的实例化时，获取泛型名称对应的具体类型
+// 例如变量的类型为 List<number>，返回 ---@class List<T> 中T对应number
+func getClassGenericMap(astType annotateast.Type, classList []*common.OneClassInfo) (
+	genericMap map[string]annotateast.Type) {
+	for _, oneClass := range classList {
+		classState := oneClass.ClassState
+		if classState == nil || len(classState.GenericNameList) == 0 {
+			continue
+		}
+
+		oneMap := annotateast.GetClassGenericMap(astType, classState.Name, classState.GenericNameList)
+		for strName, oneType := range oneMap {
+			if genericMap == nil {
+				genericMap = map[string]annotateast.Type{}
+			}
+			genericMap[strName] = oneType
+		}
+	}
+
+	return genericMap
+}
+
+// setSymbolGenericMap 泛型类实例化的成员，替换成员类型中的泛型，并记录下泛型对应的具体类型
+func setSymbolGenericMap(symbol *common.Symbol, genericMap map[string]annotateast.Type) {
+	if symbol == nil || len(genericMap) == 0 {
+		return
+	}
+
+	symbol.GenericMap = genericMap
+	if symbol.AnnotateType != nil {
+		symbol.AnnotateType = annotateast.ReplaceGenericType(symbol.AnnotateType, genericMap)
+	}
+}
@@ -0,0 +1,87 @@
+package check
+
+import (
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/common"
+)
+
+// 根据控制流收窄变量的注解类型，例如下面的例子，分支内one的类型收窄为table
+// ---@param one string|table
+// function func1(one)
+//     if type(one) == "table" then
+//         print(one)
+//     end
+// end
+
+// getAnnotateBaseType 获取注解类型对应的lua基础类型名称，无法确定时返回空
+func (a *AllProject) getAnnotateBaseType(astType annotateast.Type, fileName string, line int) string {
+	switch subAst := astType.(type) {
+	case *annotateast.NormalType:
+		if strBase, ok := common.GetNarrowBaseType(subAst.StrName); ok {
+			return strBase
+		}
+
+		// 注解的class都为table
+		createType := a.getAnnotateStrTypeInfo(subAst.StrName, fileName, line)
+		if createType != nil && createType.ClassInfo != nil {
+			return "table"
+		}
+	case *annotateast.ArrayType, *annotateast.TableType:
+		return "table"
+	case *annotateast.FuncType:
+		return "function"
+	case *annotateast.ConstType:
+		if subAst.QuotesFlag {
+			return "string"
+		}
+	}
+
+	return ""
+}
+
+// narrowAnnotateType 根据收窄的信息，去掉注解多种类型中不可能的类型，都不满足时返回原有的类型
+func (a *AllProject) narrowAnnotateType(astType annotateast.Type, narrowInfo *common.NarrowInfo,
+	fileName string, line int) annotateast.Type {
+	multiType, ok := astType.(*annotateast.MultiType)
+	if !ok || len(multiType.TypeList) <= 1 {
+		return astType
+	}
+
+	newType := &annotateast.MultiType{
+		Loc: multiType.Loc,
+	}
+	for _, oneType := range multiType.TypeList {
+		strBase := a.getAnnotateBaseType(oneType, fileName, line)
+		if strBase == "" || narrowInfo.IsMatchType(strBase) {
+			newType.TypeList = append(newType.TypeList, oneType)
+		}
+	}
+
+	if len(newType.TypeList) == 0 || len(newType.TypeList) == len(multiType.TypeList) {
+		return astType
+	}
+
+	return newType
+}
+
+// narrowSymbolType 根据变量出现位置的控制流，收窄局部变量的注解类型
+// posLine 行号从1开始，posCol 列号从0开始
+func (a *AllProject) narrowSymbolType(narrowCache *common.NarrowCache, strName string, symbol *common.Symbol,
+	posLine int, posCol int) {
+	if narrowCache == nil || symbol == nil || symbol.AnnotateType == nil || symbol.VarInfo == nil {
+		return
+	}
+
+	// 只收窄局部变量，全局变量与成员可能在其他的地方被修改
+	if symbol.VarInfo.ExtraGlobal != nil || symbol.VarInfo.IsMemFlag {
+		return
+	}
+
+	narrowInfo := narrowCache.GetNarrowInfo(strName, posLine, posCol)
+	if narrowInfo == nil {
+		return
+	}
+
+	symbol.AnnotateType = a.narrowAnnotateType(symbol.AnnotateType, narrowInfo, symbol.FileName,
+		symbol.AnnotateLine)
+}
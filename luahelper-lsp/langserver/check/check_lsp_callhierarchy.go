@@ -0,0 +1,497 @@
+package check
+
+import (
+	"path/filepath"
+	"sort"
+
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"luahelper-lsp/langserver/check/results"
+	"luahelper-lsp/langserver/log"
+)
+
+// callEdge 一次函数调用，调用者与被调用者都用所在的文件与函数的位置信息标识
+type callEdge struct {
+	callerLoc  lexer.Location // 调用者函数的位置信息
+	callerMain bool           // 调用者是否为文件的主函数，即在文件最外层调用
+	calleeFile string         // 被调用函数所在的文件
+	calleeLoc  lexer.Location // 被调用函数的位置信息
+	callLoc    lexer.Location // 调用发生的位置，为被调用函数名的位置
+}
+
+// fileCallGraph 单个文件内所有的函数调用关系
+type fileCallGraph struct {
+	edgeVec       []callEdge
+	dependFileMap map[string]struct{} // 被调用函数所在的文件，查找调用者时，跳过没有调用该文件中函数的文件
+}
+
+// funcNameInfo 函数定义处的名称信息
+type funcNameInfo struct {
+	name      string
+	className string
+	nameLoc   lexer.Location
+}
+
+// PrepareCallHierarchy 获取光标处的函数，作为调用层级的起点
+func (a *AllProject) PrepareCallHierarchy(strFile string, varStruct *common.DefineVarStruct) (
+	funcVec []common.CallHierarchyInfo) {
+	oldSymbol, symList := a.FindVarDefine(strFile, varStruct)
+	if oldSymbol == nil {
+		return
+	}
+
+	funcInfo := getSymbolReferFunc(symList)
+	if funcInfo == nil {
+		return
+	}
+
+	info, ok := a.getCallHierarchyInfo(funcInfo.FileName, funcInfo.Loc, false, nil)
+	if !ok {
+		return
+	}
+
+	funcVec = append(funcVec, info)
+	return funcVec
+}
+
+// FindIncomingCalls 查找整个工程中，调用了指定函数的所有函数
+func (a *AllProject) FindIncomingCalls(funcItem common.CallHierarchyInfo) (callVec []common.CallHierarchyCall) {
+	if funcItem.MainFlag {
+		return
+	}
+
+	fileVec := make([]string, 0, len(a.fileStructMap))
+	for strFile := range a.fileStructMap {
+		fileVec = append(fileVec, strFile)
+	}
+	sort.Strings(fileVec)
+
+	for _, strFile := range fileVec {
+		graph := a.getFileCallGraph(strFile)
+		if _, ok := graph.dependFileMap[funcItem.FileName]; !ok {
+			continue
+		}
+
+		var nameMap map[lexer.Location]funcNameInfo
+		callerIndexMap := map[lexer.Location]int{}
+		for _, oneEdge := range graph.edgeVec {
+			if oneEdge.calleeFile != funcItem.FileName || oneEdge.calleeLoc != funcItem.Loc {
+				continue
+			}
+
+			if index, ok := callerIndexMap[oneEdge.callerLoc]; ok {
+				callVec[index].CallLocVec = append(callVec[index].CallLocVec, oneEdge.callLoc)
+				continue
+			}
+
+			if nameMap == nil {
+				nameMap = a.getFileFuncNameMap(strFile)
+			}
+			callerInfo, ok := a.getCallHierarchyInfo(strFile, oneEdge.callerLoc, oneEdge.callerMain, nameMap)
+			if !ok {
+				continue
+			}
+
+			callerIndexMap[oneEdge.callerLoc] = len(callVec)
+			callVec = append(callVec, common.CallHierarchyCall{
+				Func:       callerInfo,
+				CallLocVec: []lexer.Location{oneEdge.callLoc},
+			})
+		}
+	}
+
+	return callVec
+}
+
+// FindOutgoingCalls 查找指定函数内调用的所有函数
+func (a *AllProject) FindOutgoingCalls(funcItem common.CallHierarchyInfo) (callVec []common.CallHierarchyCall) {
+	graph := a.getFileCallGraph(funcItem.FileName)
+
+	type calleeKey struct {
+		strFile string
+		loc     lexer.Location
+	}
+	calleeIndexMap := map[calleeKey]int{}
+	nameMapCache := map[string]map[lexer.Location]funcNameInfo{}
+	for _, oneEdge := range graph.edgeVec {
+		if oneEdge.callerMain != funcItem.MainFlag || oneEdge.callerLoc != funcItem.Loc {
+			continue
+		}
+
+		key := calleeKey{oneEdge.calleeFile, oneEdge.calleeLoc}
+		if index, ok := calleeIndexMap[key]; ok {
+			callVec[index].CallLocVec = append(callVec[index].CallLocVec, oneEdge.callLoc)
+			continue
+		}
+
+		nameMap, ok := nameMapCache[oneEdge.calleeFile]
+		if !ok {
+			nameMap = a.getFileFuncNameMap(oneEdge.calleeFile)
+			nameMapCache[oneEdge.calleeFile] = nameMap
+		}
+		calleeInfo, ok := a.getCallHierarchyInfo(oneEdge.calleeFile, oneEdge.calleeLoc, false, nameMap)
+		if !ok {
+			continue
+		}
+
+		calleeIndexMap[key] = len(callVec)
+		callVec = append(callVec, common.CallHierarchyCall{
+			Func:       calleeInfo,
+			CallLocVec: []lexer.Location{oneEdge.callLoc},
+		})
+	}
+
+	return callVec
+}
+
+// getFileDefineFuncMap 获取文件最外层定义的函数名称，包含全局与最外层的局部函数，以及它们一层成员中的函数
+// 文件不存在或分析失败时返回nil
+func (a *AllProject) getFileDefineFuncMap(strFile string) map[string]struct{} {
+	fileStruct, _ := a.GetCacheFileStruct(strFile)
+	if fileStruct == nil || fileStruct.FileResult == nil {
+		return nil
+	}
+
+	funcMap := map[string]struct{}{}
+	insertFunc := func(strName string, varInfo *common.VarInfo) {
+		if varInfo.ReferFunc != nil {
+			funcMap[strName] = struct{}{}
+		}
+
+		for strKey, subVar := range varInfo.SubMaps {
+			if subVar.ReferFunc != nil {
+				funcMap[strName+"."+strKey] = struct{}{}
+			}
+		}
+	}
+
+	fileResult := fileStruct.FileResult
+	for strName, varInfo := range fileResult.GlobalMaps {
+		insertFunc(strName, varInfo)
+	}
+
+	if fileResult.MainFunc != nil && fileResult.MainFunc.MainScope != nil {
+		for strName, locVarList := range fileResult.MainFunc.MainScope.LocVarMap {
+			for _, varInfo := range locVarList.VarVec {
+				insertFunc(strName, varInfo)
+			}
+		}
+	}
+
+	return funcMap
+}
+
+// isSameFuncMap 判断两个文件定义的函数名称集合是否相同
+func isSameFuncMap(oldMap, newMap map[string]struct{}) bool {
+	if (oldMap == nil) != (newMap == nil) || len(oldMap) != len(newMap) {
+		return false
+	}
+
+	for strName := range oldMap {
+		if _, ok := newMap[strName]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// invalidateCallGraph 文件变化后，清除失效的调用关系
+// oldFuncMaps 为变化的文件，以及变化前定义的函数名称；allFlag 表示有文件新增或删除
+// 只清除变化文件自身，以及调用了变化文件中函数的文件的调用关系；
+// 有文件新增、删除，或是文件定义的函数有增减时，其他文件之前找不到的函数可能找到了，全部清除
+func (a *AllProject) invalidateCallGraph(oldFuncMaps map[string]map[string]struct{}, allFlag bool) {
+	if !allFlag {
+		for strFile, oldFuncMap := range oldFuncMaps {
+			if !isSameFuncMap(oldFuncMap, a.getFileDefineFuncMap(strFile)) {
+				allFlag = true
+				break
+			}
+		}
+	}
+
+	a.callGraphMutex.Lock()
+	defer a.callGraphMutex.Unlock()
+
+	if allFlag {
+		a.callGraphMap = map[string]*fileCallGraph{}
+		return
+	}
+
+	for strFile, graph := range a.callGraphMap {
+		if _, ok := oldFuncMaps[strFile]; ok {
+			delete(a.callGraphMap, strFile)
+			continue
+		}
+
+		for strChangeFile := range oldFuncMaps {
+			if _, ok := graph.dependFileMap[strChangeFile]; ok {
+				delete(a.callGraphMap, strFile)
+				break
+			}
+		}
+	}
+}
+
+// HasFileCallGraph 判断文件的调用关系是否已经生成并缓存
+func (a *AllProject) HasFileCallGraph(strFile string) bool {
+	a.callGraphMutex.Lock()
+	defer a.callGraphMutex.Unlock()
+
+	_, ok := a.callGraphMap[strFile]
+	return ok
+}
+
+// getFileCallGraph 获取文件的调用关系，没有生成过的先生成
+// 生成调用关系需要查找所有调用的函数，比较耗时，不在锁内进行
+func (a *AllProject) getFileCallGraph(strFile string) *fileCallGraph {
+	a.callGraphMutex.Lock()
+	graph, ok := a.callGraphMap[strFile]
+	a.callGraphMutex.Unlock()
+	if ok {
+		return graph
+	}
+
+	graph = a.createFileCallGraph(strFile)
+
+	a.callGraphMutex.Lock()
+	defer a.callGraphMutex.Unlock()
+	if oldGraph, ok := a.callGraphMap[strFile]; ok {
+		return oldGraph
+	}
+	a.callGraphMap[strFile] = graph
+	return graph
+}
+
+// createFileCallGraph 遍历文件中所有的函数调用，生成调用关系
+func (a *AllProject) createFileCallGraph(strFile string) *fileCallGraph {
+	graph := &fileCallGraph{
+		dependFileMap: map[string]struct{}{},
+	}
+
+	fileStruct := a.getVailidCacheFileStruct(strFile)
+	if fileStruct == nil {
+		return graph
+	}
+	fileResult := fileStruct.FileResult
+
+	ast.Inspect(fileResult.Block, func(node interface{}) bool {
+		callExp, ok := node.(*ast.FuncCallExp)
+		if !ok {
+			return true
+		}
+
+		calleeFunc := a.findCallReferFunc(strFile, callExp)
+		if calleeFunc == nil {
+			return true
+		}
+
+		callLoc := getFuncCallNameLoc(callExp)
+		_, callerFunc := fileResult.FindASTNode(callLoc.StartLine-1, callLoc.StartColumn)
+		if callerFunc == nil {
+			callerFunc = fileResult.MainFunc
+		}
+
+		graph.edgeVec = append(graph.edgeVec, callEdge{
+			callerLoc:  callerFunc.Loc,
+			callerMain: callerFunc == fileResult.MainFunc,
+			calleeFile: calleeFunc.FileName,
+			calleeLoc:  calleeFunc.Loc,
+			callLoc:    callLoc,
+		})
+		graph.dependFileMap[calleeFunc.FileName] = struct{}{}
+		return true
+	})
+
+	log.Debug("createFileCallGraph strFile=%s, edge len=%d", strFile, len(graph.edgeVec))
+	return graph
+}
+
+// findCallReferFunc 查找函数调用处，被调用函数的定义
+func (a *AllProject) findCallReferFunc(strFile string, callExp *ast.FuncCallExp) *common.FuncInfo {
+	varStruct, ok := getFuncCallDefineVarStruct(callExp)
+	if !ok {
+		return nil
+	}
+
+	// require 引入的为文件，不是函数
+	if len(varStruct.StrVec) == 1 && varStruct.StrVec[0] == "require" {
+		return nil
+	}
+
+	oldSymbol, symList := a.FindVarDefine(strFile, &varStruct)
+	if oldSymbol == nil {
+		return nil
+	}
+
+	return getSymbolReferFunc(symList)
+}
+
+// getCallHierarchyInfo 通过函数的位置信息，获取调用层级显示的函数信息
+// nameMap 为文件中所有函数的名称信息，为nil时重新获取
+func (a *AllProject) getCallHierarchyInfo(strFile string, funcLoc lexer.Location, mainFlag bool,
+	nameMap map[lexer.Location]funcNameInfo) (info common.CallHierarchyInfo, ok bool) {
+	fileStruct := a.getVailidCacheFileStruct(strFile)
+	if fileStruct == nil {
+		return info, false
+	}
+
+	funcInfo := findFuncInfoByLoc(fileStruct.FileResult, funcLoc, mainFlag)
+	if funcInfo == nil {
+		return info, false
+	}
+
+	info = common.CallHierarchyInfo{
+		FileName: strFile,
+		Loc:      funcInfo.Loc,
+		MainFlag: mainFlag,
+	}
+
+	// 默认选中函数的开头，有名称的函数选中函数名
+	info.NameLoc = lexer.Location{
+		StartLine:   funcInfo.Loc.StartLine,
+		StartColumn: funcInfo.Loc.StartColumn,
+		EndLine:     funcInfo.Loc.StartLine,
+		EndColumn:   funcInfo.Loc.StartColumn,
+	}
+
+	// 文件的主函数，名称为文件名
+	if mainFlag {
+		info.Name = filepath.Base(strFile)
+		return info, true
+	}
+
+	if nameMap == nil {
+		nameMap = a.getFileFuncNameMap(strFile)
+	}
+
+	if nameInfo, ok := nameMap[funcInfo.Loc]; ok {
+		info.Name = nameInfo.name
+		info.ClassName = nameInfo.className
+		info.NameLoc = nameInfo.nameLoc
+	} else {
+		// 匿名函数
+		info.Name = "function"
+	}
+
+	return info, true
+}
+
+// getFileFuncNameMap 获取文件中所有函数定义处的名称，key为函数的位置信息
+func (a *AllProject) getFileFuncNameMap(strFile string) (nameMap map[lexer.Location]funcNameInfo) {
+	nameMap = map[lexer.Location]funcNameInfo{}
+	fileStruct := a.getVailidCacheFileStruct(strFile)
+	if fileStruct == nil {
+		return nameMap
+	}
+
+	ast.Inspect(fileStruct.FileResult.Block, func(node interface{}) bool {
+		switch stat := node.(type) {
+		case *ast.LocalFuncDefStat:
+			// local function a() end
+			if stat.Exp != nil {
+				nameMap[stat.Exp.Loc] = funcNameInfo{
+					name:    stat.Name,
+					nameLoc: stat.NameLoc,
+				}
+			}
+		case *ast.LocalVarDeclStat:
+			// local a = function() end
+			for i, exp := range stat.ExpList {
+				funcExp, ok := exp.(*ast.FuncDefExp)
+				if !ok || i >= len(stat.NameList) || i >= len(stat.VarLocList) {
+					continue
+				}
+
+				nameMap[funcExp.Loc] = funcNameInfo{
+					name:    stat.NameList[i],
+					nameLoc: stat.VarLocList[i],
+				}
+			}
+		case *ast.AssignStat:
+			// function a.b() end 或是 a.b = function() end
+			for i, exp := range stat.ExpList {
+				funcExp, ok := exp.(*ast.FuncDefExp)
+				if !ok || i >= len(stat.VarList) {
+					continue
+				}
+
+				if nameInfo, ok := getAssignFuncNameInfo(stat.VarList[i]); ok {
+					nameMap[funcExp.Loc] = nameInfo
+				}
+			}
+		case *ast.TableConstructorExp:
+			// a = { b = function() end }
+			for i, keyExp := range stat.KeyExps {
+				strExp, ok := keyExp.(*ast.StringExp)
+				if !ok || i >= len(stat.ValExps) {
+					continue
+				}
+
+				if funcExp, ok := stat.ValExps[i].(*ast.FuncDefExp); ok {
+					nameMap[funcExp.Loc] = funcNameInfo{
+						name:    strExp.Str,
+						nameLoc: strExp.Loc,
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	return nameMap
+}
+
+// getAssignFuncNameInfo 获取赋值语句左边的函数名称
+func getAssignFuncNameInfo(varExp ast.Exp) (nameInfo funcNameInfo, ok bool) {
+	switch exp := varExp.(type) {
+	case *ast.NameExp:
+		return funcNameInfo{
+			name:    exp.Name,
+			nameLoc: exp.Loc,
+		}, true
+	case *ast.TableAccessExp:
+		strExp, ok := exp.KeyExp.(*ast.StringExp)
+		if !ok {
+			return nameInfo, false
+		}
+
+		nameInfo = funcNameInfo{
+			name:    strExp.Str,
+			nameLoc: strExp.Loc,
+		}
+		if prefixExp, ok := exp.PrefixExp.(*ast.NameExp); ok {
+			nameInfo.className = prefixExp.Name
+		}
+		return nameInfo, true
+	}
+
+	return nameInfo, false
+}
+
+// findFuncInfoByLoc 通过位置信息，查找文件中的函数
+func findFuncInfoByLoc(fileResult *results.FileResult, funcLoc lexer.Location, mainFlag bool) *common.FuncInfo {
+	if mainFlag {
+		return fileResult.MainFunc
+	}
+
+	for _, funcInfo := range fileResult.FuncIDVec {
+		if funcInfo != fileResult.MainFunc && funcInfo.Loc == funcLoc {
+			return funcInfo
+		}
+	}
+
+	return nil
+}
+
+// getSymbolReferFunc 在追踪到的变量列表中，获取第一个指向的函数
+func getSymbolReferFunc(symList []*common.Symbol) *common.FuncInfo {
+	for _, symbol := range symList {
+		if symbol.VarInfo != nil && symbol.VarInfo.ReferFunc != nil {
+			return symbol.VarInfo.ReferFunc
+		}
+	}
+
+	return nil
+}
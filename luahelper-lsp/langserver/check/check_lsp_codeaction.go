@@ -0,0 +1,113 @@
+package check
+
+import (
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"luahelper-lsp/langserver/log"
+	"luahelper-lsp/langserver/pathpre"
+	"strings"
+)
+
+// FindTableFieldLoc 查找table构造中，重复的key所在的整个field的位置，从key的开始到value的结尾
+// keyLoc 为告警CheckErrorTableDuplicateKey 中key的位置
+func (a *AllProject) FindTableFieldLoc(strFile string, keyLoc lexer.Location) (fieldLoc lexer.Location, flag bool) {
+	fileStruct := a.getVailidCacheFileStruct(strFile)
+	if fileStruct == nil {
+		return
+	}
+
+	ast.Inspect(fileStruct.FileResult.Block, func(node interface{}) bool {
+		if flag {
+			return false
+		}
+
+		tableExp, ok := node.(*ast.TableConstructorExp)
+		if !ok {
+			return true
+		}
+
+		if !tableExp.Loc.IsContainLoc(keyLoc) {
+			return true
+		}
+
+		for i, keyExp := range tableExp.KeyExps {
+			if keyExp == nil || i >= len(tableExp.ValExps) {
+				continue
+			}
+
+			strKey, _, loc := common.GetTableConstuctorKeyStr(keyExp, tableExp.Loc)
+			if strKey == "" || !lexer.CompareTwoLoc(&loc, &keyLoc) {
+				continue
+			}
+
+			valLoc := common.GetExpLoc(tableExp.ValExps[i])
+			if valLoc.IsInitialLoc() {
+				continue
+			}
+
+			fieldLoc = lexer.GetRangeLoc(&loc, &valLoc)
+			flag = true
+			return false
+		}
+
+		return true
+	})
+
+	return fieldLoc, flag
+}
+
+// FindReferFixStr 引入的文件不存在时（CheckErrorNoFile），查找工程中最匹配的文件
+// loc 为告警的位置，返回原引用的字符串，以及修正后的引用字符串，没有找到时fixStr为空
+func (a *AllProject) FindReferFixStr(strFile string, loc lexer.Location) (referStr string, fixStr string) {
+	fileStruct := a.getVailidCacheFileStruct(strFile)
+	if fileStruct == nil {
+		return
+	}
+
+	var referInfo *common.ReferInfo
+	for _, oneRefer := range fileStruct.FileResult.ReferVec {
+		if lexer.CompareTwoLoc(&oneRefer.Loc, &loc) {
+			referInfo = oneRefer
+			break
+		}
+	}
+
+	if referInfo == nil {
+		return
+	}
+
+	referStr = pathpre.GetRemovePreStr(referInfo.ReferStr)
+	suffixFlag := common.JudgeReferSuffixFlag(referInfo.ReferType, referInfo.ReferTypeStr)
+
+	// 只用文件名去模糊匹配，路径错误的引用也能找到候选的文件
+	strNewFile := referStr
+	if !suffixFlag {
+		strNewFile = strings.Replace(strNewFile, common.GConfig.GetPathSeparator(), "/", -1)
+	}
+	strVec := strings.Split(strNewFile, "/")
+	fileName := strVec[len(strVec)-1]
+	if fileName == "" {
+		return
+	}
+
+	bestFile := common.GetBestMatchReferFile(strFile, fileName, a.allFilesMap, a.fileIndexInfo)
+	if bestFile == "" {
+		log.Debug("FindReferFixStr not find match file, strFile=%s, refer=%s", strFile, referStr)
+		return
+	}
+
+	dirManager := common.GConfig.GetDirManager()
+	fixStr = dirManager.RemovePathDirPre(bestFile)
+	if !suffixFlag {
+		fixStr = strings.TrimSuffix(fixStr, ".lua")
+		fixStr = strings.TrimSuffix(fixStr, "/init")
+		fixStr = strings.Replace(fixStr, "/", common.GConfig.GetPathSeparator(), -1)
+	}
+
+	if fixStr == referStr {
+		fixStr = ""
+	}
+
+	return referStr, fixStr
+}
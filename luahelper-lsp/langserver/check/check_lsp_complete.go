@@ -1,6 +1,7 @@
 package check
 
 import (
+	"context"
 	"fmt"
 	"luahelper-lsp/langserver/check/annotation/annotateast"
 	"luahelper-lsp/langserver/check/common"
@@ -53,7 +54,8 @@ func (a *AllProject) CodeCompleteFile(strFile string, referNameStr string, refer
 // CodeComplete 代码进行补全
 // sufThreeStrVec 切分之后的从第三个开始数组
 // colonFlag 表示是否为冒号的语法
-func (a *AllProject) CodeComplete(strFile string, completeVar common.CompleteVarStruct) {
+// ctx被取消时，停止遍历剩余的全局符号，代码补全缓冲中只有部分的结果
+func (a *AllProject) CodeComplete(ctx context.Context, strFile string, completeVar common.CompleteVarStruct) {
 	// 1）先查找该文件是否存在
 	fileStruct := a.getVailidCacheFileStruct(strFile)
 	if fileStruct == nil {
@@ -94,6 +96,7 @@ func (a *AllProject) CodeComplete(strFile string, completeVar common.CompleteVar
 		loc:           loc,
 		secondProject: secondProject,
 		thirdStruct:   thirdStruct,
+		ctx:           ctx,
 	}
 
 	a.completeCache.SetColonFlag(completeVar.ColonFlag)
@@ -209,6 +212,11 @@ func (a *AllProject) gValueComplete(comParam *CommonFuncParam, completeVar *comm
 	}
 
 	for strName, varInfoList := range globalGmaps {
+		// 请求已经取消了，不再查找
+		if comParam.isCancelled() {
+			return
+		}
+
 		// 判断是否重复了
 		if !common.IsCompleteNeedShow(strName, completeVar) {
 			continue
@@ -323,6 +331,23 @@ func (a *AllProject) varInfoDeepComplete(symbol *common.Symbol, symList []*commo
 		}
 	}
 
+	// 原表继承链中父类型的成员，例如 setmetatable(Child, {__index = Parent})
+	for _, symbol := range symList {
+		for _, parentSymbol := range a.getMetatableParentList(symbol, comParam) {
+			a.getVarInfoCompleteExt(parentSymbol, completeVar.ColonFlag)
+		}
+	}
+
+	// 工厂函数构造的类，父类型以及静态成员，例如 local Foo = class("Foo", Base)
+	for _, symbol := range symList {
+		a.getFactoryClassComplete(symbol, completeVar.ColonFlag)
+	}
+
+	// 文件内推导的table结构，例如传入函数后赋值的成员，或是参数调用处的实参成员
+	for _, symbol := range symList {
+		a.getTableShapeComplete(symbol, completeVar.ColonFlag, comParam)
+	}
+
 	// 引用其他的文件，不做冒号语法
 	lastSymbol := symList[len(symList)-1]
 	if lastSymbol.VarInfo == nil {
@@ -349,14 +374,9 @@ func (a *AllProject) varInfoDeepComplete(symbol *common.Symbol, symList []*commo
 				a.getImportFileComlete(referFile)
 			}
 		} else if referSubType == common.RtypeRequire {
-			find, returnExp := referFile.MainFunc.GetOneReturnExp()
-			if !find {
-				return
-			}
-
 			findExpList := []common.FindExpFile{}
-			// 这里也需要做判断，函数返回的变量逐层跟踪，目前只跟踪了一层
-			symList := a.FindDeepSymbolList(referFile.Name, returnExp, comParam, &findExpList, true, 1)
+			// 第一轮分析记录的文件导出的所有符号
+			symList := a.getReferExportSymbolList(referFile, comParam, &findExpList)
 			for _, symbol := range symList {
 				a.getVarInfoCompleteExt(symbol, completeVar.ColonFlag)
 			}
@@ -487,6 +507,9 @@ func (a *AllProject) noPreComplete(comParam *CommonFuncParam, completeVar *commo
 	// 3.4) 把_G的函数也包含进来
 	// 默认只提示_G的函数，如果要提示_G的变量，需要配置打开，整体上会慢一点
 	a.gValueComplete(comParam, completeVar, false, fileName)
+	if comParam.isCancelled() {
+		return
+	}
 
 	// 3.5) 把框架中引入的其他文件的方式，函数也包含进来
 	referFrameFiles := common.GConfig.GetFrameReferFiles()
@@ -531,6 +554,9 @@ func (a *AllProject) noPreComplete(comParam *CommonFuncParam, completeVar *commo
 // 代码补全进行的分发
 func (a *AllProject) lspCodeComplete(comParam *CommonFuncParam, completeVar *common.CompleteVarStruct) {
 	a.GetCompleteCache().SetCompleteVar(completeVar)
+	if comParam.isCancelled() {
+		return
+	}
 
 	// 1) 查找所有的_G符号
 	if completeVar.StrVec[0] == "_G" {
@@ -568,7 +594,9 @@ func (a *AllProject) getVarCompleteData(varInfo *common.VarInfo, item *common.On
 
 	// 判断这个变量是否关联到了注解类型，如果有提取注解信息
 	symbol := common.GetDefaultSymbol(item.LuaFile, varInfo)
+	symbol.GenericMap = item.GenericMap
 	astType, strComment, strPreComment := a.getInfoFileAnnotateType(item.Label, symbol)
+	astType = annotateast.ReplaceGenericType(astType, item.GenericMap)
 	if astType != nil {
 		str := a.completeAnnotatTypeStr(astType, item.LuaFile, symbol.GetLine())
 		if strPreComment == "" {
@@ -631,7 +659,7 @@ func (a *AllProject) getVarCompleteData(varInfo *common.VarInfo, item *common.On
 				}
 
 				if symbolTmp.VarInfo.ReferFunc != nil {
-					strFunc := a.getFuncShowStr(symbol.VarInfo, item.Label, true, colonFlag, true, true)
+					strFunc := a.getFuncShowStr(symbol.VarInfo, item.Label, true, colonFlag, true, true, symbol.GenericMap)
 					item.Detail = "function " + strFunc
 				}
 
@@ -644,7 +672,7 @@ func (a *AllProject) getVarCompleteData(varInfo *common.VarInfo, item *common.On
 	}
 
 	if varInfo.ReferFunc != nil {
-		strFunc := a.getFuncShowStr(symbol.VarInfo, item.Label, true, colonFlag, true, true)
+		strFunc := a.getFuncShowStr(symbol.VarInfo, item.Label, true, colonFlag, true, true, symbol.GenericMap)
 		item.Detail = "function " + strFunc
 	}
 
@@ -723,19 +751,21 @@ func (a *AllProject) GetCompleteCacheIndexItem(index int) (item common.OneComple
 	colonFlag := a.completeCache.GetColonFlag()
 	// 4) 配置的为注解field信息
 	if item.CacheKind == common.CkindClassField {
+		// 泛型类实例化的成员，替换类型中的泛型
+		fieldType := annotateast.ReplaceGenericType(item.FieldState.FiledType, item.GenericMap)
 		if colonFlag {
 			// 为冒号的补全
 			if item.FieldColonFlag == annotateast.FieldColonHide {
 				// 判断是否为隐藏式的，如果是隐藏式的，去掉第一个参数
 				// ---@class ClassA
 				// ---@field FunctionC fun(self:ClassA):void
-				if oneFuncType := annotateast.GetAllFuncType(item.FieldState.FiledType); oneFuncType != nil {
+				if oneFuncType := annotateast.GetAllFuncType(fieldType); oneFuncType != nil {
 					subFuncType, _ := oneFuncType.(*annotateast.FuncType)
 					item.Detail = annotateast.FuncTypeConvertStr(subFuncType, 1)
 				}
 			} else {
 				// 获取对应注解的类型
-				item.Detail = annotateast.TypeConvertStr(item.FieldState.FiledType)
+				item.Detail = annotateast.TypeConvertStr(fieldType)
 			}
 		} else {
 			// 为的补全
@@ -743,13 +773,13 @@ func (a *AllProject) GetCompleteCacheIndexItem(index int) (item common.OneComple
 			// ---@class ClassA
 			// ---@field FunctionC : fun():void
 			if item.FieldColonFlag == annotateast.FieldColonYes {
-				if oneFuncType := annotateast.GetAllFuncType(item.FieldState.FiledType); oneFuncType != nil {
+				if oneFuncType := annotateast.GetAllFuncType(fieldType); oneFuncType != nil {
 					subFuncType, _ := oneFuncType.(*annotateast.FuncType)
 					item.Detail = annotateast.FuncTypeConvertStr(subFuncType, 2)
 				}
 			} else {
 				// 获取对应注解的类型
-				item.Detail = annotateast.TypeConvertStr(item.FieldState.FiledType)
+				item.Detail = annotateast.TypeConvertStr(fieldType)
 			}
 		}
 
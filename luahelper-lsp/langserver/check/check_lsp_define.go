@@ -420,6 +420,9 @@ func (a *AllProject) FindVarDefine(strFile string, varStruct *common.DefineVarSt
 		}
 
 		oldSymbol = a.createAnnotateSymbol(findStrName, findVar)
+
+		// 根据控制流收窄变量的注解类型
+		a.narrowSymbolType(comParam.fileResult.NarrowCache, findStrName, oldSymbol, varStruct.PosLine+1, varStruct.PosCh)
 	}
 	//调用链中没有函数，走这里
 	if oldSymbol != nil {
@@ -87,6 +87,12 @@ func (a *AllProject) HandleFileEventChanges(fileEventVec []FileEventStruct) (cha
 		common.GConfig.RebuildSameFileNameVar(a.allFilesMap)
 	}
 
+	// 变化前文件定义的函数，用于判断函数调用关系失效的范围
+	oldFuncMaps := map[string]map[string]struct{}{}
+	for _, strFile := range needAgainFileVec {
+		oldFuncMaps[strFile] = a.getFileDefineFuncMap(strFile)
+	}
+
 	// 2.1) 如果之前的文件有包含错误码为6的错误，这次有新的文件增加，之前的文件，还需要进行扫描下
 	// needAgainFileVec = a.handleNeedAgainFileVec(needAgainFileVec, deleteFileMap)
 
@@ -110,6 +116,12 @@ func (a *AllProject) HandleFileEventChanges(fileEventVec []FileEventStruct) (cha
 		a.rebuidCreateTypeMap()
 	}
 
+	// 文件有变化或是删除，重新构建声明过的全局变量
+	if len(needAgainFileVec) > 0 || len(deleteFileMap) > 0 {
+		a.rebuildDeclareGlobals()
+		a.invalidateCallGraph(oldFuncMaps, handleAllFlag || len(deleteFileMap) > 0)
+	}
+
 	time2 := time.Now()
 	log.Debug("needAgainFileVec len=%d, checkAstTime=%d", len(needAgainFileVec), time.Since(time1).Milliseconds())
 
@@ -204,6 +216,7 @@ func (a *AllProject) RemoveCacheContent(strFile string) {
 
 // HandleFileChangeAnalysis 代码实时变化时候，进行分析判断是否要保存到cache中
 func (a *AllProject) HandleFileChangeAnalysis(strFile string, content []byte) (errList []common.CheckError) {
+	oldFuncMap := a.getFileDefineFuncMap(strFile)
 	fileStruct := results.CreateFileStruct(strFile)
 	handleResult, _, _ := a.analysisFirstLuaFile(fileStruct, strFile, content, false, true)
 	fileStruct.HandleResult = handleResult
@@ -212,6 +225,7 @@ func (a *AllProject) HandleFileChangeAnalysis(strFile string, content []byte) (e
 	if handleResult == results.FileHandleOk {
 		// 实时分析成功了，保存在cache中
 		a.fileLRUMap.Set(strFile, fileStruct)
+		a.invalidateCallGraph(map[string]map[string]struct{}{strFile: oldFuncMap}, false)
 		log.Debug("HandleFileChangeAnalysis ok strFile=%s", strFile)
 	}
 
@@ -0,0 +1,271 @@
+package check
+
+import (
+	"sort"
+
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+)
+
+// FindFoldingRanges 获取文件所有的折叠区域，包括代码块、多行注释、---@enum区域以及连续的require语句
+func (a *AllProject) FindFoldingRanges(strFile string) (rangeVec []common.FoldingRangeInfo) {
+	fileStruct := a.getVailidCacheFileStruct(strFile)
+	if fileStruct == nil || fileStruct.FileResult.Block == nil {
+		return
+	}
+	fileResult := fileStruct.FileResult
+
+	// 同一行开始的代码块只保留最外层的
+	codeLineMap := map[int]bool{}
+	insertCodeFunc := func(startLine, endLine int) {
+		if endLine <= startLine || codeLineMap[startLine] {
+			return
+		}
+
+		codeLineMap[startLine] = true
+		rangeVec = append(rangeVec, common.FoldingRangeInfo{
+			StartLine: startLine,
+			EndLine:   endLine,
+			Kind:      common.FKCode,
+		})
+	}
+
+	// 1) 代码块，折叠到结束关键字的前一行，保留end、}、until这样的结束行
+	ast.Inspect(fileResult.Block, func(node interface{}) bool {
+		switch n := node.(type) {
+		case *ast.FuncDefExp:
+			insertCodeFunc(n.Loc.StartLine, n.Loc.EndLine-1)
+		case *ast.TableConstructorExp:
+			insertCodeFunc(n.Loc.StartLine, n.Loc.EndLine-1)
+		case *ast.DoStat:
+			insertCodeFunc(n.Loc.StartLine, n.Loc.EndLine-1)
+		case *ast.WhileStat:
+			insertCodeFunc(n.Loc.StartLine, n.Loc.EndLine-1)
+		case *ast.RepeatStat:
+			insertCodeFunc(n.Loc.StartLine, n.Loc.EndLine-1)
+		case *ast.ForNumStat:
+			insertCodeFunc(n.Loc.StartLine, n.Loc.EndLine-1)
+		case *ast.ForInStat:
+			insertCodeFunc(n.Loc.StartLine, n.Loc.EndLine-1)
+		case *ast.IfStat:
+			// if、elseif、else每个分支单独折叠，分支的block结束位置为下一个关键字的开始
+			startLine := n.Loc.StartLine
+			for _, oneBlock := range n.Blocks {
+				insertCodeFunc(startLine, oneBlock.Loc.EndLine-1)
+				startLine = oneBlock.Loc.EndLine
+			}
+		case *ast.Block:
+			rangeVec = append(rangeVec, getImportsFoldingRanges(n.Stats)...)
+		}
+		return true
+	})
+
+	// 2) 连续多行的短注释，以及跨越多行的长注释 --[[ ]]，注释map的key为注释结束的行
+	for endLine, oneComment := range fileResult.CommentMap {
+		if !oneComment.ShortFlag {
+			if endLine > oneComment.StartLine {
+				rangeVec = append(rangeVec, common.FoldingRangeInfo{
+					StartLine: oneComment.StartLine,
+					EndLine:   endLine,
+					Kind:      common.FKComment,
+				})
+			}
+			continue
+		}
+
+		if !oneComment.HeadFlag || len(oneComment.LineVec) < 2 {
+			continue
+		}
+
+		rangeVec = append(rangeVec, common.FoldingRangeInfo{
+			StartLine: oneComment.LineVec[0].Line,
+			EndLine:   oneComment.LineVec[len(oneComment.LineVec)-1].Line,
+			Kind:      common.FKComment,
+		})
+	}
+
+	// 3) ---@enum start 与 ---@enum end之间的区域
+	if annotateFile := a.getAnnotateFile(strFile); annotateFile != nil {
+		for _, oneEnumFragment := range annotateFile.EnumFragmentVec {
+			startLine := oneEnumFragment.StartEnum.EnumLoc.StartLine
+			endLine := oneEnumFragment.EndEnum.EnumLoc.StartLine
+			if endLine <= startLine {
+				continue
+			}
+
+			rangeVec = append(rangeVec, common.FoldingRangeInfo{
+				StartLine: startLine,
+				EndLine:   endLine,
+				Kind:      common.FKRegion,
+			})
+		}
+	}
+
+	sort.Slice(rangeVec, func(i, j int) bool {
+		if rangeVec[i].StartLine != rangeVec[j].StartLine {
+			return rangeVec[i].StartLine < rangeVec[j].StartLine
+		}
+		return rangeVec[i].EndLine > rangeVec[j].EndLine
+	})
+	return rangeVec
+}
+
+// FindSelectionRanges 获取光标处由内到外，所有包含光标的语法节点的位置
+// line从1开始，ch从0开始
+func (a *AllProject) FindSelectionRanges(strFile string, line int, ch int) (locVec []lexer.Location) {
+	fileStruct := a.getVailidCacheFileStruct(strFile)
+	if fileStruct == nil || fileStruct.FileResult.Block == nil {
+		return
+	}
+
+	insertFunc := func(loc lexer.Location) bool {
+		if !loc.IsInLocStruct(line, ch) {
+			return false
+		}
+
+		// 父节点与子节点范围相同时，只保留一个
+		if len(locVec) > 0 && locVec[len(locVec)-1] == loc {
+			return true
+		}
+
+		locVec = append(locVec, loc)
+		return true
+	}
+
+	// 由外到内收集，ast.Inspect为先序遍历
+	ast.Inspect(fileStruct.FileResult.Block, func(node interface{}) bool {
+		switch n := node.(type) {
+		case *ast.Block:
+			return insertFunc(n.Loc)
+		case *ast.LocalVarDeclStat:
+			if !insertFunc(n.Loc) {
+				return false
+			}
+			for _, varLoc := range n.VarLocList {
+				insertFunc(varLoc)
+			}
+			return true
+		case *ast.LocalFuncDefStat:
+			if !insertFunc(n.Loc) {
+				return false
+			}
+			insertFunc(n.NameLoc)
+			return true
+		case *ast.FuncDefExp:
+			if !insertFunc(n.Loc) {
+				return false
+			}
+			for _, parLoc := range n.ParLocList {
+				insertFunc(parLoc)
+			}
+			return true
+		case *ast.ForNumStat:
+			if !insertFunc(n.Loc) {
+				return false
+			}
+			insertFunc(n.VarLoc)
+			return true
+		case *ast.ForInStat:
+			if !insertFunc(n.Loc) {
+				return false
+			}
+			for _, nameLoc := range n.NameLocList {
+				insertFunc(nameLoc)
+			}
+			return true
+		}
+
+		if loc := common.GetStatLoc(node); loc != (lexer.Location{}) {
+			return insertFunc(loc)
+		}
+		if loc := common.GetExpLoc(node); loc != (lexer.Location{}) {
+			return insertFunc(loc)
+		}
+		return true
+	})
+
+	// 由内到外返回
+	for i, j := 0, len(locVec)-1; i < j; i, j = i+1, j-1 {
+		locVec[i], locVec[j] = locVec[j], locVec[i]
+	}
+	return locVec
+}
+
+// getImportsFoldingRanges 同一个block中，连续多行的require语句作为一个折叠区域
+func getImportsFoldingRanges(statVec []ast.Stat) (rangeVec []common.FoldingRangeInfo) {
+	startLine := 0
+	endLine := 0
+	insertFunc := func() {
+		if endLine > startLine {
+			rangeVec = append(rangeVec, common.FoldingRangeInfo{
+				StartLine: startLine,
+				EndLine:   endLine,
+				Kind:      common.FKImports,
+			})
+		}
+		startLine = 0
+		endLine = 0
+	}
+
+	for _, oneStat := range statVec {
+		if !isRequireStat(oneStat) {
+			insertFunc()
+			continue
+		}
+
+		loc := common.GetStatLoc(oneStat)
+		if startLine > 0 && loc.StartLine > endLine+1 {
+			insertFunc()
+		}
+		if startLine == 0 {
+			startLine = loc.StartLine
+		}
+		endLine = loc.EndLine
+	}
+	insertFunc()
+
+	return rangeVec
+}
+
+// isRequireStat 判断语句是否为require引入其他的文件，例如 local a = require("a") 或是 require("a")
+func isRequireStat(stat ast.Stat) bool {
+	var expVec []ast.Exp
+	switch n := stat.(type) {
+	case *ast.LocalVarDeclStat:
+		expVec = n.ExpList
+	case *ast.AssignStat:
+		expVec = n.ExpList
+	case *ast.FuncCallStat:
+		expVec = []ast.Exp{n}
+	default:
+		return false
+	}
+
+	for _, oneExp := range expVec {
+		// require("a").b 这样的也认为是require
+		for {
+			accessExp, ok := oneExp.(*ast.TableAccessExp)
+			if !ok {
+				break
+			}
+			oneExp = accessExp.PrefixExp
+		}
+
+		callExp, ok := oneExp.(*ast.FuncCallExp)
+		if !ok || callExp.NameExp != nil {
+			continue
+		}
+
+		nameExp, ok := callExp.PrefixExp.(*ast.NameExp)
+		if !ok {
+			continue
+		}
+
+		if nameExp.Name == "require" || common.GConfig.IsFrameReferOtherFile(nameExp.Name) {
+			return true
+		}
+	}
+
+	return false
+}
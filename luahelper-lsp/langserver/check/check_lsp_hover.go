@@ -101,13 +101,7 @@ func (a *AllProject) AnnotateTypeHover(strFile, strLine, strWord string, line, c
 // GetLspHoverVarStr 提示信息hover
 func (a *AllProject) GetLspHoverVarStr(strFile string, varStruct *common.DefineVarStruct) (lableStr, docStr, luaFileStr string) {
 
-	//先找类注解中成员函数的注解
-	symbol, findList := a.findVarDefineForHover(strFile, varStruct)
-
-	//类没有注解 尝试找函数上方的注解
-	if symbol != nil && len(findList) == 0 {
-		symbol, findList = a.FindVarDefine(strFile, varStruct)
-	}
+	symbol, findList := a.findHoverVarDefine(strFile, varStruct)
 
 	if symbol == nil && len(varStruct.StrVec) == 1 {
 		// 1) 判断是否为系统的函数提示
@@ -191,6 +185,19 @@ func (a *AllProject) GetLspHoverVarStr(strFile string, varStruct *common.DefineV
 	return
 }
 
+// findHoverVarDefine 查找hover时变量的定义，findList为变量定义的推导链
+func (a *AllProject) findHoverVarDefine(strFile string, varStruct *common.DefineVarStruct) (symbol *common.Symbol,
+	findList []*common.Symbol) {
+	//先找类注解中成员函数的注解
+	symbol, findList = a.findVarDefineForHover(strFile, varStruct)
+
+	//类没有注解 尝试找函数上方的注解
+	if symbol != nil && len(findList) == 0 {
+		symbol, findList = a.FindVarDefine(strFile, varStruct)
+	}
+	return symbol, findList
+}
+
 func (a *AllProject) getNodefineMapVar(strFile string, varStruct *common.DefineVarStruct) (symbol *common.Symbol) {
 	// 1）先查找该文件是否存在
 	fileStruct := a.getVailidCacheFileStruct(strFile)
@@ -228,7 +235,7 @@ func (a *AllProject) getVarHoverInfo(strFile string, symbol *common.Symbol, varS
 	// 1) 首先提取注解类型
 	if symbol.AnnotateType != nil {
 		// 注解类型尝试推导扩展class的field成员信息
-		str, _ := a.expandTableHover(symbol)
+		str := a.getSymbolTypeStr(symbol, varStruct.StrVec[len(varStruct.StrVec)-1], true)
 		strLabel = varStruct.Str + " : " + str
 
 		if symbol.VarInfo != nil {
@@ -248,23 +255,10 @@ func (a *AllProject) getVarHoverInfo(strFile string, symbol *common.Symbol, varS
 		return
 	}
 
-	strType = ""
-	if symbol.VarInfo.ReferInfo != nil {
-		strType = symbol.VarInfo.ReferInfo.GetReferComment()
-	} else {
-		strType = symbol.VarInfo.GetVarTypeDetail()
-		// 判断是否指向的一个table，如果是展开table的具体内容
-		if strType == "table" || len(symbol.VarInfo.SubMaps) > 0 || len(symbol.VarInfo.ExpandStrMap) > 0 {
-			strType, _ = a.expandTableHover(symbol)
-		}
-
-		referFunc := symbol.VarInfo.ReferFunc
-		if referFunc != nil {
-			if symbol.VarInfo.ExtraGlobal == nil && !symbol.VarInfo.IsMemFlag {
-				strPre = "local "
-			}
-			strFunc := a.getFuncShowStr(symbol.VarInfo, varStruct.StrVec[len(varStruct.StrVec)-1], true, false, true, true)
-			strType = "function " + strFunc
+	strType = a.getSymbolTypeStr(symbol, varStruct.StrVec[len(varStruct.StrVec)-1], true)
+	if symbol.VarInfo.ReferInfo == nil && symbol.VarInfo.ReferFunc != nil {
+		if symbol.VarInfo.ExtraGlobal == nil && !symbol.VarInfo.IsMemFlag {
+			strPre = "local "
 		}
 	}
 
@@ -283,6 +277,49 @@ func (a *AllProject) getVarHoverInfo(strFile string, symbol *common.Symbol, varS
 	return
 }
 
+// getSymbolTypeStr 获取变量定义的类型，hover与inlay hint共用
+// strName 为变量的名称，函数展开签名时使用
+// expandFlag 表示是否展开table与class的成员，以及函数的签名；不展开时只返回类型的名称
+func (a *AllProject) getSymbolTypeStr(symbol *common.Symbol, strName string, expandFlag bool) string {
+	if symbol.AnnotateType != nil {
+		if !expandFlag {
+			return annotateast.TypeConvertStr(symbol.AnnotateType)
+		}
+
+		str, _ := a.expandTableHover(symbol)
+		return str
+	}
+
+	varInfo := symbol.VarInfo
+	if varInfo == nil {
+		return ""
+	}
+
+	if varInfo.ReferInfo != nil {
+		return varInfo.ReferInfo.GetReferComment()
+	}
+
+	if varInfo.ReferFunc != nil {
+		if !expandFlag {
+			return "function"
+		}
+
+		strFunc := a.getFuncShowStr(varInfo, strName, true, false, true, true, symbol.GenericMap)
+		return "function " + strFunc
+	}
+
+	// 判断是否指向的一个table，如果是展开table的具体内容
+	strType := varInfo.GetVarTypeDetail()
+	if strType == "table" || len(varInfo.SubMaps) > 0 || len(varInfo.ExpandStrMap) > 0 {
+		if !expandFlag {
+			return "table"
+		}
+
+		strType, _ = a.expandTableHover(symbol)
+	}
+	return strType
+}
+
 // 判断变量是否直接为系统模块或函数的hover
 func judgetSystemModuleOrFuncHover(strName string) (flag bool, labStr, docStr string) {
 	if oneSystemTip, ok := common.GConfig.SystemTipsMap[strName]; ok {
@@ -340,6 +377,9 @@ func (a *AllProject) findVarDefineForHover(strFile string, varStruct *common.Def
 		}
 
 		oldSymbol = a.createAnnotateSymbol(findStrName, findVar)
+
+		// 根据控制流收窄变量的注解类型
+		a.narrowSymbolType(comParam.fileResult.NarrowCache, findStrName, oldSymbol, varStruct.PosLine+1, varStruct.PosCh)
 	}
 	//调用链中没有函数，走这里
 	if oldSymbol != nil {
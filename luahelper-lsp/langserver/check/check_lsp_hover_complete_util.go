@@ -230,7 +230,7 @@ func (a *AllProject) GetVarRelateTypeStr(varInfo *common.VarInfo) (strType strin
 
 	if varInfo.ReferFunc != nil {
 		//strType = varInfo.ReferFunc.GetFuncCompleteStr("function", true, false)
-		strType = a.getFuncShowStr(varInfo, "function", true, false, true, false)
+		strType = a.getFuncShowStr(varInfo, "function", true, false, true, false, nil)
 		return
 	}
 
@@ -669,7 +669,9 @@ func (a *AllProject) getOneFuncReturnStr(fileName string, oneReturn common.Retur
 // colonFlag 如果是冒号语法，有时候需要忽略掉self
 // returnFlag 是否需要获取函数的返回值类型
 // returnMultiline 多个返回值时，是否需要多行显示
-func (a *AllProject) getFuncShowStr(varInfo *common.VarInfo, funcName string, paramTipFlag, colonFlag, returnFlag, returnMultiline bool) (str string) {
+// genericMap 泛型类实例化的成员函数，泛型名称对应的具体类型，没有时为nil
+func (a *AllProject) getFuncShowStr(varInfo *common.VarInfo, funcName string, paramTipFlag, colonFlag, returnFlag, returnMultiline bool,
+	genericMap map[string]annotateast.Type) (str string) {
 	if varInfo == nil || varInfo.ReferFunc == nil {
 		return
 	}
@@ -697,7 +699,8 @@ func (a *AllProject) getFuncShowStr(varInfo *common.VarInfo, funcName string, pa
 
 		funcName += oneParam
 
-		paramShortStr, annType := a.getAnnotateFuncParamDocument(oneParam, annotateParamInfo, inLuaFile, lastLine-1)
+		paramShortStr, annType := a.getAnnotateFuncParamDocument(oneParam, annotateParamInfo, inLuaFile, lastLine-1,
+			genericMap)
 		if paramShortStr != "" {
 			funcName += ": " + annotateast.TypeConvertStr(annType)
 		} else {
@@ -731,7 +734,7 @@ func (a *AllProject) getFuncShowStr(varInfo *common.VarInfo, funcName string, pa
 
 	if flag {
 		for i, oneType := range typeList {
-			oneStr := annotateast.TypeConvertStr(oneType)
+			oneStr := annotateast.TypeConvertStr(annotateast.ReplaceGenericType(oneType, genericMap))
 			if returnMultiline && len(commentList) > i && commentList[i] != "" {
 				oneStr += "  -- " + commentList[i]
 			}
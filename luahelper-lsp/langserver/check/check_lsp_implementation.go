@@ -0,0 +1,256 @@
+package check
+
+import (
+	"sort"
+
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/annotation/annotatelexer"
+	"luahelper-lsp/langserver/check/annotation/annotateparser"
+	"luahelper-lsp/langserver/check/common"
+)
+
+// FindImplementations 代码中光标处为类型的方法，查找所有子类型重写的实现
+// 例如 function Base:OnInit() end 或是 obj:OnInit()，查找 function Child:OnInit() end
+func (a *AllProject) FindImplementations(strFile string, varStruct *common.DefineVarStruct) (
+	defineVecs []DefineStruct) {
+	strLen := len(varStruct.StrVec)
+	if strLen < 2 {
+		return
+	}
+
+	// 前面部分为类型，最后一个为方法的名称
+	strMethod := varStruct.StrVec[strLen-1]
+	preStruct := common.DefineVarStruct{
+		PosLine:   varStruct.PosLine,
+		PosCh:     varStruct.PosCh,
+		ValidFlag: true,
+		StrVec:    append([]string{}, varStruct.StrVec[0:strLen-1]...),
+		IsFuncVec: append([]bool{}, varStruct.IsFuncVec[0:strLen-1]...),
+	}
+
+	typeVec := a.PrepareTypeHierarchy(strFile, &preStruct)
+	if len(typeVec) == 0 {
+		return
+	}
+
+	return a.findTypeMethodImplementations(typeVec[0].Name, strMethod)
+}
+
+// FindAnnotateImplementations 注解中光标处为---@field定义的成员，查找具体赋值的函数以及子类型重写的实现
+// line为光标所在的行，从0开始
+func (a *AllProject) FindAnnotateImplementations(strFile string, strLine string, line int, col int) (
+	defineVecs []DefineStruct) {
+	l := annotatelexer.CreateAnnotateLexer(&strLine, 0, 0)
+	if !l.CheckHeardValid() {
+		return
+	}
+
+	annotateState, parseErr := annotateparser.ParserLine(l)
+	fieldState, ok := annotateState.(*annotateast.AnnotateFieldState)
+	if !ok || parseErr.ErrType != annotatelexer.AErrorOk {
+		return
+	}
+
+	if col < fieldState.NameLoc.StartColumn || col > fieldState.NameLoc.EndColumn {
+		return
+	}
+
+	// 查找这个field所属的class
+	for strName, createTypeList := range a.createTypeMap {
+		for _, oneCreate := range createTypeList.List {
+			classInfo := oneCreate.ClassInfo
+			if classInfo == nil || classInfo.LuaFile != strFile {
+				continue
+			}
+
+			oneField, ok := classInfo.FieldMap[fieldState.Name]
+			if !ok || oneField.NameLoc.StartLine != line+1 {
+				continue
+			}
+
+			return a.findTypeMethodImplementations(strName, fieldState.Name)
+		}
+	}
+
+	return
+}
+
+// findTypeMethodImplementations 查找类型方法所有的实现
+// 类型用---@field声明了该方法时，包含类型自身具体赋值的函数；以及所有子类型重写的函数
+func (a *AllProject) findTypeMethodImplementations(strType string, strMethod string) (defineVecs []DefineStruct) {
+	nodeMap := a.getTypeHierarchyMap()
+	typeNode, ok := nodeMap[typeHierarchyKey{strClass: strType}]
+	if !ok {
+		return
+	}
+
+	existMap := map[DefineStruct]bool{}
+	insertFunc := func(oneDefine DefineStruct) {
+		if existMap[oneDefine] {
+			return
+		}
+		existMap[oneDefine] = true
+		defineVecs = append(defineVecs, oneDefine)
+	}
+
+	// 1) 类型自身用---@field声明了该方法
+	if a.isClassFieldDeclared(strType, strMethod) {
+		for _, oneDefine := range a.getTypeMethodDefines(typeNode, strMethod) {
+			insertFunc(oneDefine)
+		}
+	}
+
+	// 2) 所有的子类型，层层向下查找
+	childMap := map[*typeHierarchyNode][]*typeHierarchyNode{}
+	for _, oneNode := range nodeMap {
+		for _, parentKey := range oneNode.parentVec {
+			if parentNode, ok := nodeMap[parentKey]; ok {
+				childMap[parentNode] = append(childMap[parentNode], oneNode)
+			}
+		}
+	}
+
+	visitMap := map[*typeHierarchyNode]bool{
+		typeNode: true,
+	}
+	nodeQueue := []*typeHierarchyNode{typeNode}
+	for len(nodeQueue) > 0 {
+		parentNode := nodeQueue[0]
+		nodeQueue = nodeQueue[1:]
+
+		childVec := childMap[parentNode]
+		sort.Slice(childVec, func(i, j int) bool {
+			if childVec[i].info.Name != childVec[j].info.Name {
+				return childVec[i].info.Name < childVec[j].info.Name
+			}
+			return childVec[i].info.FileName < childVec[j].info.FileName
+		})
+
+		for _, childNode := range childVec {
+			if visitMap[childNode] {
+				continue
+			}
+			visitMap[childNode] = true
+			nodeQueue = append(nodeQueue, childNode)
+			for _, oneDefine := range a.getTypeMethodDefines(childNode, strMethod) {
+				insertFunc(oneDefine)
+			}
+		}
+	}
+
+	return defineVecs
+}
+
+// isClassFieldDeclared 注解的class是否用---@field声明了该成员
+func (a *AllProject) isClassFieldDeclared(strType string, strField string) bool {
+	createTypeList, ok := a.createTypeMap[strType]
+	if !ok {
+		return false
+	}
+
+	for _, oneCreate := range createTypeList.List {
+		if oneCreate.ClassInfo == nil {
+			continue
+		}
+
+		if _, ok := oneCreate.ClassInfo.FieldMap[strField]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTypeMethodDefines 获取类型关联的变量上，定义的指定名称的函数
+func (a *AllProject) getTypeMethodDefines(typeNode *typeHierarchyNode, strMethod string) (defineVecs []DefineStruct) {
+	for _, relateVar := range a.getTypeRelateVars(typeNode) {
+		subVar, ok := relateVar.SubMaps[strMethod]
+		if !ok || subVar.ReferFunc == nil {
+			continue
+		}
+
+		strFile := subVar.FileName
+		if strFile == "" {
+			strFile = relateVar.FileName
+		}
+		defineVecs = append(defineVecs, DefineStruct{
+			StrFile: strFile,
+			Loc:     subVar.Loc,
+		})
+	}
+
+	return defineVecs
+}
+
+// getTypeRelateVars 获取类型关联的所有变量
+// 注解的class为关联的变量，setmetatable推导出的类型为定义处的变量；全局变量包括其他文件中的同名全局变量
+func (a *AllProject) getTypeRelateVars(typeNode *typeHierarchyNode) (varVec []*common.VarInfo) {
+	var globalNameVec []string
+	insertFunc := func(varInfo *common.VarInfo) {
+		for _, oneVar := range varVec {
+			if oneVar == varInfo {
+				return
+			}
+		}
+		varVec = append(varVec, varInfo)
+	}
+
+	matchVarFunc := func(strFile string, matchFunc func(varInfo *common.VarInfo) bool) {
+		fileStruct := a.getVailidCacheFileStruct(strFile)
+		if fileStruct == nil {
+			return
+		}
+
+		fileResult := fileStruct.FileResult
+		for _, varList := range fileResult.MainFunc.MainScope.LocVarMap {
+			for _, oneVar := range varList.VarVec {
+				if matchFunc(oneVar) {
+					insertFunc(oneVar)
+				}
+			}
+		}
+
+		for strName, oneVar := range fileResult.GlobalMaps {
+			if matchFunc(oneVar) {
+				insertFunc(oneVar)
+				globalNameVec = append(globalNameVec, strName)
+			}
+		}
+	}
+
+	if typeNode.info.ClassFlag {
+		for _, oneCreate := range a.createTypeMap[typeNode.info.Name].List {
+			if oneCreate.ClassInfo == nil || oneCreate.ClassInfo.RelateVar == nil {
+				continue
+			}
+
+			relateVar := oneCreate.ClassInfo.RelateVar
+			insertFunc(relateVar)
+			if relateVar.IsGlobal() {
+				// 找到全局变量的名称
+				matchVarFunc(oneCreate.ClassInfo.LuaFile, func(varInfo *common.VarInfo) bool {
+					return varInfo.Loc == relateVar.Loc
+				})
+			}
+		}
+	} else {
+		matchVarFunc(typeNode.info.FileName, func(varInfo *common.VarInfo) bool {
+			return varInfo.Loc == typeNode.info.Loc
+		})
+	}
+
+	// 全局变量，其他文件中也可能定义了成员函数
+	for _, strName := range globalNameVec {
+		for _, fileStruct := range a.fileStructMap {
+			if fileStruct.FileResult == nil {
+				continue
+			}
+
+			if oneVar, ok := fileStruct.FileResult.GlobalMaps[strName]; ok {
+				insertFunc(oneVar)
+			}
+		}
+	}
+
+	return varVec
+}
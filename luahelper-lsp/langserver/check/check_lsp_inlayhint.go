@@ -0,0 +1,268 @@
+package check
+
+import (
+	"sort"
+	"strings"
+
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"luahelper-lsp/langserver/stringutil"
+)
+
+// inlay hint显示推导类型的最大长度，超过的截断
+const inlayHintTypeMaxLen = 40
+
+// FindInlayHints 查找文件指定行范围内所有的inlay hint
+// beginLine与endLine从1开始，包含endLine
+func (a *AllProject) FindInlayHints(strFile string, beginLine, endLine int,
+	hintConfig common.InlayHintConfig) (hintVec []common.InlayHintInfo) {
+	fileStruct := a.getVailidCacheFileStruct(strFile)
+	if fileStruct == nil {
+		return
+	}
+
+	isInRange := func(loc lexer.Location) bool {
+		return loc.StartLine <= endLine && loc.EndLine >= beginLine
+	}
+
+	ast.Inspect(fileStruct.FileResult.Block, func(node interface{}) bool {
+		switch nodeExp := node.(type) {
+		case *ast.FuncCallExp:
+			if hintConfig.ParamName && isInRange(nodeExp.Loc) {
+				hintVec = append(hintVec, a.getCallParamHints(strFile, nodeExp, beginLine, endLine)...)
+			}
+		case *ast.LocalVarDeclStat:
+			if (hintConfig.LocalType || hintConfig.ReturnType) && isInRange(nodeExp.Loc) {
+				hintVec = append(hintVec, a.getLocalTypeHints(strFile, nodeExp, hintConfig, beginLine, endLine)...)
+			}
+		}
+		return true
+	})
+
+	sort.SliceStable(hintVec, func(i, j int) bool {
+		if hintVec[i].Line != hintVec[j].Line {
+			return hintVec[i].Line < hintVec[j].Line
+		}
+		return hintVec[i].Character < hintVec[j].Character
+	})
+	return hintVec
+}
+
+// getCallParamHints 函数调用处，每个实参前面显示对应的形参名称
+func (a *AllProject) getCallParamHints(strFile string, callExp *ast.FuncCallExp, beginLine,
+	endLine int) (hintVec []common.InlayHintInfo) {
+	if len(callExp.Args) == 0 {
+		return
+	}
+
+	varStruct, ok := getFuncCallDefineVarStruct(callExp)
+	if !ok {
+		return
+	}
+
+	flag, _, paramInfo := a.SignaturehelpFunc(strFile, &varStruct)
+	if !flag {
+		return
+	}
+
+	for index, argExp := range callExp.Args {
+		if index >= len(paramInfo) {
+			break
+		}
+
+		strParam := paramInfo[index].Label
+		if !isHintParamName(strParam) {
+			break
+		}
+
+		if _, ok := argExp.(*ast.VarargExp); ok {
+			break
+		}
+
+		// 实参与形参的名称相同，不需要显示
+		if nameExp, ok := argExp.(*ast.NameExp); ok && nameExp.Name == strParam {
+			continue
+		}
+
+		argLoc := common.GetExpLoc(argExp)
+		if argLoc.StartLine < beginLine || argLoc.StartLine > endLine {
+			continue
+		}
+
+		hintVec = append(hintVec, common.InlayHintInfo{
+			Line:      argLoc.StartLine,
+			Character: argLoc.StartColumn,
+			Label:     strParam + ":",
+			HintType:  common.IHParamName,
+		})
+	}
+
+	return hintVec
+}
+
+// getLocalTypeHints 局部变量定义处，变量名后面显示推导的类型
+func (a *AllProject) getLocalTypeHints(strFile string, localStat *ast.LocalVarDeclStat,
+	hintConfig common.InlayHintConfig, beginLine, endLine int) (hintVec []common.InlayHintInfo) {
+	expNum := len(localStat.ExpList)
+	if expNum == 0 {
+		return
+	}
+
+	// 已经用---@type注解了类型的，不需要再显示
+	if annotateFile := a.getAnnotateFile(strFile); annotateFile != nil {
+		fragment := annotateFile.GetLineFragementInfo(localStat.Loc.StartLine - 1)
+		if fragment != nil && fragment.TypeInfo != nil {
+			return
+		}
+	}
+
+	lastExp := localStat.ExpList[expNum-1]
+	_, lastCallFlag := lastExp.(*ast.FuncCallExp)
+	multiReturnFlag := lastCallFlag && len(localStat.NameList) > expNum
+
+	for index, strName := range localStat.NameList {
+		if index >= len(localStat.VarLocList) {
+			break
+		}
+
+		varLoc := localStat.VarLocList[index]
+		if varLoc.StartLine < beginLine || varLoc.StartLine > endLine || strName == "_" {
+			continue
+		}
+
+		var valueExp ast.Exp
+		if index < expNum {
+			valueExp = localStat.ExpList[index]
+		} else if lastCallFlag {
+			valueExp = lastExp
+		} else {
+			break
+		}
+
+		hintType := common.IHLocalType
+		if multiReturnFlag && index >= expNum-1 {
+			hintType = common.IHReturnType
+		}
+
+		if hintType == common.IHLocalType && (!hintConfig.LocalType || isHintObviousExp(valueExp)) {
+			continue
+		}
+		if hintType == common.IHReturnType && !hintConfig.ReturnType {
+			continue
+		}
+
+		strType := a.getLocalHintType(strFile, strName, varLoc)
+		if strType == "" {
+			continue
+		}
+
+		hintVec = append(hintVec, common.InlayHintInfo{
+			Line:      varLoc.EndLine,
+			Character: varLoc.EndColumn,
+			Label:     ": " + strType,
+			HintType:  hintType,
+		})
+	}
+
+	return hintVec
+}
+
+// getLocalHintType 获取局部变量推导的类型，与hover时显示的类型一致
+func (a *AllProject) getLocalHintType(strFile string, strName string, varLoc lexer.Location) string {
+	varStruct := common.DefineVarStruct{
+		PosLine:   varLoc.StartLine - 1,
+		PosCh:     varLoc.StartColumn,
+		ValidFlag: true,
+		Str:       strName,
+		StrVec:    []string{strName},
+		IsFuncVec: []bool{false},
+	}
+
+	_, findList := a.findHoverVarDefine(strFile, &varStruct)
+
+	// 与hover一致，注解的类型优先，否则取第一个推导出具体类型的定义
+	strType := ""
+	for _, oneSymbol := range findList {
+		oneType := a.getSymbolTypeStr(oneSymbol, strName, false)
+		if oneSymbol.AnnotateType != nil {
+			strType = oneType
+			break
+		}
+
+		if strType == "" || strType == "any" {
+			strType = oneType
+		}
+	}
+
+	if strType == "" || strType == "any" || strType == "nil" {
+		return ""
+	}
+
+	// 类型的名称可能有多字节的字符，按字符截断
+	if typeRunes := []rune(strType); len(typeRunes) > inlayHintTypeMaxLen {
+		strType = string(typeRunes[:inlayHintTypeMaxLen]) + "..."
+	}
+	return strType
+}
+
+// isHintObviousExp 判断赋值的表达式类型是否一眼能看出来，例如常量、函数定义、table构造
+func isHintObviousExp(exp ast.Exp) bool {
+	switch exp.(type) {
+	case *ast.NilExp, *ast.TrueExp, *ast.FalseExp, *ast.IntegerExp, *ast.FloatExp, *ast.StringExp,
+		*ast.FuncDefExp, *ast.TableConstructorExp, *ast.VarargExp:
+		return true
+	}
+
+	return false
+}
+
+// isHintParamName 判断参数名称是否能作为inlay hint显示
+func isHintParamName(strParam string) bool {
+	if strParam == "" {
+		return false
+	}
+
+	for i := 0; i < len(strParam); i++ {
+		ch := strParam[i]
+		if ch != '_' && !stringutil.IsLetter(ch) && !stringutil.IsDigit(ch) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getFuncCallDefineVarStruct 函数调用处，构造查找被调用函数定义的结构，a:b() 这样的冒号调用，需要加上b
+func getFuncCallDefineVarStruct(callExp *ast.FuncCallExp) (varStruct common.DefineVarStruct, ok bool) {
+	varStruct = ExpToDefineVarStruct(callExp.PrefixExp)
+	if callExp.NameExp != nil {
+		varStruct.StrVec = append(varStruct.StrVec, callExp.NameExp.Str)
+		varStruct.IsFuncVec = append(varStruct.IsFuncVec, false)
+		varStruct.ColonFlag = true
+	}
+	if !varStruct.ValidFlag || len(varStruct.StrVec) == 0 {
+		return varStruct, false
+	}
+
+	varStruct.Str = strings.Join(varStruct.StrVec, ".")
+	nameLoc := getFuncCallNameLoc(callExp)
+	varStruct.PosLine = nameLoc.EndLine - 1
+	varStruct.PosCh = nameLoc.EndColumn
+	return varStruct, true
+}
+
+// getFuncCallNameLoc 获取函数调用处被调用函数名的位置，例如a.b()与a:b()中b的位置
+func getFuncCallNameLoc(callExp *ast.FuncCallExp) lexer.Location {
+	if callExp.NameExp != nil {
+		return callExp.NameExp.Loc
+	}
+
+	if tableExp, ok := callExp.PrefixExp.(*ast.TableAccessExp); ok {
+		if keyExp, ok := tableExp.KeyExp.(*ast.StringExp); ok {
+			return keyExp.Loc
+		}
+	}
+
+	return common.GetExpLoc(callExp.PrefixExp)
+}
@@ -1,6 +1,7 @@
 package check
 
 import (
+	"context"
 	"luahelper-lsp/langserver/check/analysis"
 	"luahelper-lsp/langserver/check/common"
 	"luahelper-lsp/langserver/check/compiler/lexer"
@@ -45,7 +46,8 @@ func (a *AllProject) getFileAnalysis(strFile string) *results.FileResult {
 }
 
 // FindReferences 查找引用 1111
-func (a *AllProject) FindReferences(strFile string, varStruct *common.DefineVarStruct,
+// ctx被取消时（客户端发送了$/cancelRequest），停止分析剩余的文件，返回已经找到的部分结果
+func (a *AllProject) FindReferences(ctx context.Context, strFile string, varStruct *common.DefineVarStruct,
 	checkSrc common.CheckReferenceSrc) (findVecs []DefineStruct) {
 	lastDefine, oldInfoFlie, isWhole := a.FindReferenceVarDefine(strFile, varStruct)
 	if oldInfoFlie == nil || oldInfoFlie.FileName == "" || oldInfoFlie.VarInfo == nil {
@@ -177,7 +179,7 @@ func (a *AllProject) FindReferences(strFile string, varStruct *common.DefineVarS
 		for strFile := range allFileMap {
 			fileList = append(fileList, strFile)
 		}
-		handleAllFilesReference(fileList, a, referenceParam, &findVecs)
+		handleAllFilesReference(ctx, fileList, a, referenceParam, &findVecs)
 	}
 
 	return findVecs
@@ -243,8 +245,9 @@ func recvFourFile(defineVecs *[]DefineStruct, fourFileChan FourFileChan, ignoreD
 }
 
 //  多协程分析所有的文件
-func handleAllFilesReference(fileList []string, allProject *AllProject, referenceParam ReferenceParam,
-	defineVecs *[]DefineStruct) {
+// ctx取消后，不再分发新的文件，只接收已经在分析中的文件的结果
+func handleAllFilesReference(ctx context.Context, fileList []string, allProject *AllProject,
+	referenceParam ReferenceParam, defineVecs *[]DefineStruct) {
 	listLen := len(fileList)
 	if listLen == 0 {
 		return
@@ -280,7 +283,8 @@ func handleAllFilesReference(fileList []string, allProject *AllProject, referenc
 
 	//reflect接收数据
 	taskDone := 0
-	for recvNum := 0; recvNum < listLen; {
+	sendNum := corNum
+	for recvNum := 0; recvNum < sendNum; {
 		chosen, recv, recvOK := reflect.Select(selectCase)
 		if !recvOK {
 			log.Error("ch%d error\n", chosen)
@@ -290,14 +294,15 @@ func handleAllFilesReference(fileList []string, allProject *AllProject, referenc
 		}
 		recvFourFile(defineVecs, recv.Interface().(FourFileChan), referenceParam.ignoreDefineLoc)
 
-		if recvNum+corNum < listLen {
+		if sendNum < listLen && ctx.Err() == nil {
 			chanRequest := FourFileChan{
 				sendRunFlag:    true,
 				allProject:     allProject,
-				strFile:        fileList[recvNum+corNum],
+				strFile:        fileList[sendNum],
 				referenceParam: referenceParam,
 			}
 			chs[chosen] <- chanRequest
+			sendNum++
 		} else {
 			chanRequest := FourFileChan{
 				sendRunFlag: false,
@@ -0,0 +1,363 @@
+package check
+
+import (
+	"sort"
+	"strings"
+
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"luahelper-lsp/langserver/check/results"
+)
+
+// varSemantic 变量定义处获取到的语义信息，同一个变量只计算一次
+type varSemantic struct {
+	isConst    bool // 是否为常量
+	isEnum     bool // 是否为枚举成员
+	deprecated bool // 是否标记了@deprecated
+}
+
+// semanticFile 单个文件查找语义着色时的中间数据
+type semanticFile struct {
+	strFile    string
+	fileResult *results.FileResult
+	comParam   *CommonFuncParam
+	constLocs  map[lexer.Location]bool                  // 所有<const>属性的局部变量定义位置
+	varCache   map[*common.VarInfo]varSemantic          // 变量的语义信息缓存
+	tokenMap   map[lexer.Location]*common.SemanticToken // 所有的着色符号，key为位置信息，用于去重
+}
+
+// FindSemanticTokens 查找文件中所有的语义着色符号，按位置顺序返回
+// 全局变量的着色来自FindAllColorVar，局部变量、参数、上值通过作用域信息获取
+func (a *AllProject) FindSemanticTokens(strFile string) (tokenVec []common.SemanticToken) {
+	fileStruct := a.getVailidCacheFileStruct(strFile)
+	if fileStruct == nil {
+		return
+	}
+
+	comParam := a.getCommFunc(strFile, 0, 0)
+	if comParam == nil {
+		return
+	}
+
+	sf := &semanticFile{
+		strFile:    strFile,
+		fileResult: fileStruct.FileResult,
+		comParam:   comParam,
+		constLocs:  map[lexer.Location]bool{},
+		varCache:   map[*common.VarInfo]varSemantic{},
+		tokenMap:   map[lexer.Location]*common.SemanticToken{},
+	}
+
+	// 1) 全局变量的着色
+	colorResult := a.FindAllColorVar(strFile)
+	for colorType, oneColor := range colorResult {
+		semanticType := common.STGlobal
+		if colorType == common.CTGlobalFunc {
+			semanticType = common.STFunction
+		} else if colorType != common.CTGlobalVar {
+			continue
+		}
+
+		for _, oneLoc := range oneColor.LocVec {
+			sf.insertToken(oneLoc, semanticType, false, false)
+		}
+	}
+
+	// 2) 遍历AST，获取所有局部变量的定义与引用，全局变量补充是否为函数与@deprecated的信息
+	a.semanticTraverseAST(sf)
+
+	// 3) 注解中的class类型
+	a.semanticAnnotateClass(sf)
+
+	tokenVec = make([]common.SemanticToken, 0, len(sf.tokenMap))
+	for _, oneToken := range sf.tokenMap {
+		// 语义着色不支持跨行的符号
+		if oneToken.Loc.StartLine != oneToken.Loc.EndLine || oneToken.Loc.EndColumn <= oneToken.Loc.StartColumn {
+			continue
+		}
+		tokenVec = append(tokenVec, *oneToken)
+	}
+
+	sort.Slice(tokenVec, func(i, j int) bool {
+		if tokenVec[i].Loc.StartLine != tokenVec[j].Loc.StartLine {
+			return tokenVec[i].Loc.StartLine < tokenVec[j].Loc.StartLine
+		}
+		return tokenVec[i].Loc.StartColumn < tokenVec[j].Loc.StartColumn
+	})
+	return tokenVec
+}
+
+// insertToken 插入一个着色符号，相同位置的后插入的覆盖之前的
+func (sf *semanticFile) insertToken(loc lexer.Location, semanticType common.SemanticType, declaration bool,
+	deprecated bool) {
+	sf.tokenMap[loc] = &common.SemanticToken{
+		Loc:         loc,
+		Type:        semanticType,
+		Declaration: declaration,
+		Deprecated:  deprecated,
+	}
+}
+
+// semanticTraverseAST 遍历文件的AST，对变量的定义与引用着色
+func (a *AllProject) semanticTraverseAST(sf *semanticFile) {
+	mainScope := sf.fileResult.MainFunc.MainScope
+
+	// 1) 先找出所有<const>属性的局部变量
+	ast.Inspect(sf.fileResult.Block, func(node interface{}) bool {
+		localStat, ok := node.(*ast.LocalVarDeclStat)
+		if !ok {
+			return true
+		}
+
+		for i, attr := range localStat.AttrList {
+			if attr == ast.RDKCONST && i < len(localStat.VarLocList) {
+				sf.constLocs[localStat.VarLocList[i]] = true
+			}
+		}
+		return true
+	})
+
+	// 2) 所有局部变量定义的地方
+	a.semanticScopeDefine(sf, mainScope)
+
+	// 3) 所有变量引用的地方
+	ast.Inspect(sf.fileResult.Block, func(node interface{}) bool {
+		nameExp, ok := node.(*ast.NameExp)
+		if !ok {
+			return true
+		}
+
+		minScope := mainScope.FindMinScope(nameExp.Loc.StartLine, nameExp.Loc.StartColumn)
+		if minScope == nil {
+			minScope = mainScope
+		}
+
+		if locVar, ok := minScope.FindLocVar(nameExp.Name, nameExp.Loc); ok {
+			if locVar.Loc == nameExp.Loc {
+				return true
+			}
+
+			// 外层函数的局部变量或是参数，在内层函数中引用时为上值
+			semanticType := a.getLocVarSemanticType(sf, locVar)
+			if (semanticType == common.STLocal || semanticType == common.STParameter) &&
+				isSemanticUpvalue(minScope, nameExp.Name, locVar) {
+				semanticType = common.STUpvalue
+			}
+			sf.insertToken(nameExp.Loc, semanticType, false, a.getVarSemantic(sf, locVar).deprecated)
+			return true
+		}
+
+		if nameExp.Name == "self" {
+			return true
+		}
+
+		// 全局变量，查找定义的地方，判断是否为函数
+		oneToken, ok := sf.tokenMap[nameExp.Loc]
+		if !ok {
+			return true
+		}
+
+		findVar := a.findGlobalVarDefineInfo(sf.comParam, nameExp.Name, "", false)
+		if findVar == nil {
+			return true
+		}
+
+		if findVar.ReferFunc != nil {
+			oneToken.Type = common.STFunction
+		}
+		varSem := a.getVarSemantic(sf, findVar)
+		if varSem.isConst {
+			oneToken.Type = common.STConst
+		} else if varSem.isEnum {
+			oneToken.Type = common.STEnumMember
+		}
+		oneToken.Declaration = findVar.Loc == nameExp.Loc && findVar.FileName == sf.strFile
+		oneToken.Deprecated = varSem.deprecated
+		return true
+	})
+}
+
+// semanticScopeDefine 递归获取scope中所有局部变量定义的地方
+func (a *AllProject) semanticScopeDefine(sf *semanticFile, scope *common.ScopeInfo) {
+	for _, varList := range scope.LocVarMap {
+		for _, locVar := range varList.VarVec {
+			semanticType := a.getLocVarSemanticType(sf, locVar)
+			sf.insertToken(locVar.Loc, semanticType, true, a.getVarSemantic(sf, locVar).deprecated)
+		}
+	}
+
+	for _, subScope := range scope.SubScopes {
+		a.semanticScopeDefine(sf, subScope)
+	}
+}
+
+// getLocVarSemanticType 获取局部变量的着色类型
+func (a *AllProject) getLocVarSemanticType(sf *semanticFile, locVar *common.VarInfo) common.SemanticType {
+	if locVar.ReferFunc != nil {
+		return common.STFunction
+	}
+
+	varSem := a.getVarSemantic(sf, locVar)
+	if varSem.isConst {
+		return common.STConst
+	}
+
+	if varSem.isEnum {
+		return common.STEnumMember
+	}
+
+	if locVar.IsParam || locVar.IsForParam {
+		return common.STParameter
+	}
+
+	return common.STLocal
+}
+
+// getVarSemantic 获取变量定义处的语义信息
+func (a *AllProject) getVarSemantic(sf *semanticFile, varInfo *common.VarInfo) varSemantic {
+	if varSem, ok := sf.varCache[varInfo]; ok {
+		return varSem
+	}
+
+	varSem := varSemantic{}
+	if varInfo.FileName == sf.strFile && sf.constLocs[varInfo.Loc] {
+		varSem.isConst = true
+	}
+
+	if annotateFile := a.getAnnotateFile(varInfo.FileName); annotateFile != nil {
+		// 注解标记的const
+		if !varSem.isConst {
+			varSem.isConst = a.IsAnnotateTypeConst("", varInfo)
+		}
+
+		// 是否定义在---@enum start 与 ---@enum end之间
+		for _, oneEnumFragment := range annotateFile.EnumFragmentVec {
+			if varInfo.Loc.StartLine > oneEnumFragment.StartEnum.EnumLoc.StartLine &&
+				varInfo.Loc.StartLine < oneEnumFragment.EndEnum.EnumLoc.StartLine {
+				varSem.isEnum = true
+				break
+			}
+		}
+	}
+
+	// 定义的前面一行注释，是否包含@deprecated，函数参数与函数名在同一行，不处理
+	if !varInfo.IsParam && !varInfo.IsForParam {
+		strComment := a.getSpecialLineComment(varInfo.FileName, varInfo.Loc.StartLine-1, true)
+		varSem.deprecated = isCommentDeprecated(strComment)
+	}
+
+	sf.varCache[varInfo] = varSem
+	return varSem
+}
+
+// semanticAnnotateClass 注解中定义与引用的class着色
+func (a *AllProject) semanticAnnotateClass(sf *semanticFile) {
+	annotateFile := a.getAnnotateFile(sf.strFile)
+	if annotateFile == nil {
+		return
+	}
+
+	insertTypeFunc := func(oneType annotateast.Type) {
+		strList, locList := annotateast.GetAllStrAndLocList(oneType)
+		for index, str := range strList {
+			if a.isAnnotateClassName(str) {
+				sf.insertToken(locList[index], common.STClass, false, false)
+			}
+		}
+	}
+
+	for _, oneFragment := range annotateFile.FragementMap {
+		if oneFragment.ClassInfo != nil {
+			for _, oneClass := range oneFragment.ClassInfo.ClassList {
+				classState := oneClass.ClassState
+				sf.insertToken(classState.NameLoc, common.STClass, true, false)
+				for index, strParent := range classState.ParentNameList {
+					if index < len(classState.ParentLocList) && a.isAnnotateClassName(strParent) {
+						sf.insertToken(classState.ParentLocList[index], common.STClass, false, false)
+					}
+				}
+
+				for _, oneField := range oneClass.FieldMap {
+					insertTypeFunc(oneField.FiledType)
+				}
+			}
+		}
+
+		if oneFragment.AliasInfo != nil {
+			for _, oneAlias := range oneFragment.AliasInfo.AliasList {
+				insertTypeFunc(oneAlias.AliasState.AliasType)
+			}
+		}
+
+		if oneFragment.TypeInfo != nil {
+			for _, oneType := range oneFragment.TypeInfo.TypeList {
+				insertTypeFunc(oneType)
+			}
+		}
+
+		if oneFragment.ParamInfo != nil {
+			for _, oneParam := range oneFragment.ParamInfo.ParamList {
+				insertTypeFunc(oneParam.ParamType)
+			}
+		}
+
+		if oneFragment.ReturnInfo != nil {
+			for _, oneType := range oneFragment.ReturnInfo.ReturnTypeList {
+				insertTypeFunc(oneType)
+			}
+		}
+
+		if oneFragment.VarargInfo != nil && oneFragment.VarargInfo.VarargInfo != nil {
+			insertTypeFunc(oneFragment.VarargInfo.VarargInfo.VarargType)
+		}
+	}
+}
+
+// isAnnotateClassName 判断名称是否为注解定义的class
+func (a *AllProject) isAnnotateClassName(strName string) bool {
+	createTypeList, ok := a.createTypeMap[strName]
+	if !ok {
+		return false
+	}
+
+	for _, oneCreate := range createTypeList.List {
+		if oneCreate.ClassInfo != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSemanticUpvalue 判断引用的局部变量是否为外层函数定义的
+func isSemanticUpvalue(useScope *common.ScopeInfo, strName string, locVar *common.VarInfo) bool {
+	useFunc := useScope.FindMinFunc()
+	for scope := useScope; scope != nil; scope = scope.Parent {
+		varList := scope.LocVarMap[strName]
+		if varList == nil {
+			continue
+		}
+
+		for _, oneVar := range varList.VarVec {
+			if oneVar == locVar {
+				return scope.FindMinFunc() != useFunc
+			}
+		}
+	}
+
+	return false
+}
+
+// isCommentDeprecated 注释中是否包含@deprecated标记
+func isCommentDeprecated(strComment string) bool {
+	for _, strLine := range strings.Split(strComment, "\n") {
+		strLine = strings.TrimLeft(strLine, "- \t")
+		if strings.HasPrefix(strLine, "@deprecated") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -56,8 +56,18 @@ func (a *AllProject) SignaturehelpFunc(strFile string, varStruct *common.DefineV
 	inLuaFile := lastSymbol.FileName
 	lastLine := referFunc.Loc.StartLine
 
+	// 泛型类实例化的成员函数，泛型对应的具体类型记录在通过注解找到的成员上，后面追踪到的函数定义上没有
+	var genericMap map[string]annotateast.Type
+	for i := len(symList) - 1; i >= 0; i-- {
+		if symList[i].GenericMap != nil {
+			genericMap = symList[i].GenericMap
+			break
+		}
+	}
+
 	strName := varStruct.StrVec[len(varStruct.StrVec)-1]
-	funAllStr := a.getFuncShowStr(lastSymbol.VarInfo, strName, true, varStruct.ColonFlag, false, false)
+	funAllStr := a.getFuncShowStr(lastSymbol.VarInfo, strName, true, varStruct.ColonFlag, false, false,
+		genericMap)
 	sinatureInfo.Label = funAllStr
 	strDocumentation := getFinalStrComment(a.GetLineComment(inLuaFile, lastLine), false)
 
@@ -71,7 +81,8 @@ func (a *AllProject) SignaturehelpFunc(strFile string, varStruct *common.DefineV
 		}
 
 		annotateFlag := false
-		paramShortStr, annType := a.getAnnotateFuncParamDocument(strOneParam, annotateParamInfo, inLuaFile, lastLine-1)
+		paramShortStr, annType := a.getAnnotateFuncParamDocument(strOneParam, annotateParamInfo, inLuaFile, lastLine-1,
+			genericMap)
 		if paramShortStr != "" {
 			annotateFlag = true
 		} else {
@@ -161,8 +172,9 @@ func (a *AllProject) getAnnotateFuncSignature(symbol *common.Symbol, colonFlag b
 }
 
 // 获取函数参数的注解信息
+// genericMap 泛型类实例化的成员函数，泛型名称对应的具体类型，参数类型中的泛型会被替换
 func (a *AllProject) getAnnotateFuncParamDocument(strOneParam string, paramInfo *common.FragementParamInfo,
-	fileName string, line int) (strShort string, annType annotateast.Type) {
+	fileName string, line int, genericMap map[string]annotateast.Type) (strShort string, annType annotateast.Type) {
 	// 先判断是否有注解信息
 	if paramInfo == nil {
 		return "", annType
@@ -173,11 +185,11 @@ func (a *AllProject) getAnnotateFuncParamDocument(strOneParam string, paramInfo
 			continue
 		}
 
-		strShort = a.getSymbolAliasMultiCandidate(oneParam.ParamType, fileName, line)
+		annType = annotateast.ReplaceGenericType(oneParam.ParamType, genericMap)
+		strShort = a.getSymbolAliasMultiCandidate(annType, fileName, line)
 		if strShort == "" {
-			strShort = annotateast.TypeConvertStr(oneParam.ParamType)
+			strShort = annotateast.TypeConvertStr(annType)
 		}
-		annType = oneParam.ParamType
 
 		if oneParam.Comment != "" {
 			strShort = strShort + " -- " + oneParam.Comment
@@ -2,6 +2,7 @@ package check
 
 import (
 	"bytes"
+	"context"
 	"luahelper-lsp/langserver/check/common"
 	"luahelper-lsp/langserver/check/results"
 	"luahelper-lsp/langserver/log"
@@ -40,7 +41,9 @@ func (a *AllProject) FindFileAllSymbol(strFile string) (symbolVec []common.FileS
 }
 
 // FindWorkspaceAllSymbol 查找工程内所有全局符号
-func (a *AllProject) FindWorkspaceAllSymbol(strContent string) (symbolVec []common.FileSymbolStruct) {
+// ctx被取消时，停止查找剩余的文件，返回已经找到的部分结果
+func (a *AllProject) FindWorkspaceAllSymbol(ctx context.Context, strContent string) (
+	symbolVec []common.FileSymbolStruct) {
 	resultSort := &resultSorter{
 		results: make([]scoredSymbol, 0),
 	}
@@ -54,7 +57,7 @@ func (a *AllProject) FindWorkspaceAllSymbol(strContent string) (symbolVec []comm
 		fileList = append(fileList, fileName)
 	}
 
-	handleAllFilesSymbols(strContent, a, resultSort, fileList)
+	handleAllFilesSymbols(ctx, strContent, a, resultSort, fileList)
 
 	sort.Sort(resultSort)
 	log.Debug("handle workspace symbols, query all %d files, find all %d symbols", len(a.fileStructMap), len(resultSort.results))
@@ -715,7 +718,9 @@ type symbolsChan struct {
 	returnResult []scoredSymbol
 }
 
-func handleAllFilesSymbols(pattern string, allProject *AllProject, results *resultSorter, fileList []string) {
+// ctx取消后，不再分发新的文件，只接收已经在查找中的文件的结果
+func handleAllFilesSymbols(ctx context.Context, pattern string, allProject *AllProject, results *resultSorter,
+	fileList []string) {
 	// 定义最终的results 和每次协程需要处理的结果
 	resultSorters := make([]*resultSorter, len(fileList))
 
@@ -754,7 +759,8 @@ func handleAllFilesSymbols(pattern string, allProject *AllProject, results *resu
 
 	//reflect接收数据
 	taskDone := 0
-	for recvNum := 0; recvNum < handleFileLen; {
+	sendNum := corNum
+	for recvNum := 0; recvNum < sendNum; {
 		chosen, recv, recvOK := reflect.Select(selectCase)
 		if !recvOK {
 			log.Error("ch%d error\n", chosen)
@@ -765,18 +771,19 @@ func handleAllFilesSymbols(pattern string, allProject *AllProject, results *resu
 
 		recvFindSymbol(results, recv.Interface().(symbolsChan))
 
-		if recvNum+corNum < handleFileLen {
-			resultSorters[recvNum+corNum] = &resultSorter{
+		if sendNum < handleFileLen && ctx.Err() == nil {
+			resultSorters[sendNum] = &resultSorter{
 				m:       NewMatcher(pattern),
 				results: make([]scoredSymbol, 0),
 			}
 			chanRequest := symbolsChan{
-				strfile:          fileList[recvNum+corNum],
+				strfile:          fileList[sendNum],
 				sendRunFlag:      true,
-				sendResultSorter: resultSorters[recvNum+corNum],
+				sendResultSorter: resultSorters[sendNum],
 				sendAllProject:   allProject,
 			}
 			chs[chosen] <- chanRequest
+			sendNum++
 		} else {
 			chanRequest := symbolsChan{
 				sendRunFlag: false,
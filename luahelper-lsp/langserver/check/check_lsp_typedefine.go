@@ -0,0 +1,67 @@
+package check
+
+import (
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/common"
+)
+
+// FindVarTypeDefine 查找变量关联的注解类型的定义，跳转到---@class或---@alias定义的地方
+// 关联的类型为多种类型时，例如 ---@type one|two，返回每一种类型的定义
+func (a *AllProject) FindVarTypeDefine(strFile string, varStruct *common.DefineVarStruct) (defineVecs []DefineStruct) {
+	oldSymbol, symList := a.FindVarDefine(strFile, varStruct)
+	if len(symList) == 0 && oldSymbol != nil {
+		symList = append(symList, oldSymbol)
+	}
+
+	// 依次追踪变量的引用，找到第一个有注解类型的
+	for _, symbol := range symList {
+		if symbol == nil {
+			continue
+		}
+
+		astType := symbol.AnnotateType
+		if astType == nil && symbol.VarInfo != nil {
+			astType, _, _ = a.getInfoFileAnnotateType(varStruct.StrVec[len(varStruct.StrVec)-1], symbol)
+		}
+		if astType == nil {
+			continue
+		}
+
+		existMap := map[DefineStruct]bool{}
+		a.getAnnotateTypeDefines(astType, symbol.FileName, symbol.GetLine(), existMap, &defineVecs)
+		return defineVecs
+	}
+
+	return defineVecs
+}
+
+// getAnnotateTypeDefines 获取注解类型中所有关联的class或alias定义的地方
+// line为注解所在的行，用于优先查找当前文件中定义的类型
+func (a *AllProject) getAnnotateTypeDefines(astType annotateast.Type, strFile string, line int,
+	existMap map[DefineStruct]bool, defineVecs *[]DefineStruct) {
+	switch subAst := astType.(type) {
+	case *annotateast.NormalType:
+		strDefineList := a.getStrNameDefineLocVec(subAst.StrName, strFile, line)
+		for _, oneDefine := range strDefineList {
+			one := DefineStruct{
+				StrFile: oneDefine.FileName,
+				Loc:     oneDefine.Loc,
+			}
+			if existMap[one] {
+				continue
+			}
+
+			existMap[one] = true
+			*defineVecs = append(*defineVecs, one)
+		}
+	case *annotateast.MultiType:
+		for _, oneType := range subAst.TypeList {
+			a.getAnnotateTypeDefines(oneType, strFile, line, existMap, defineVecs)
+		}
+	case *annotateast.ArrayType:
+		a.getAnnotateTypeDefines(subAst.ItemType, strFile, line, existMap, defineVecs)
+	case *annotateast.TableType:
+		a.getAnnotateTypeDefines(subAst.KeyType, strFile, line, existMap, defineVecs)
+		a.getAnnotateTypeDefines(subAst.ValueType, strFile, line, existMap, defineVecs)
+	}
+}
@@ -0,0 +1,386 @@
+package check
+
+import (
+	"sort"
+	"strings"
+
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/annotation/annotatelexer"
+	"luahelper-lsp/langserver/check/annotation/annotateparser"
+	"luahelper-lsp/langserver/check/common"
+)
+
+// typeHierarchyKey 类型层级中类型的唯一标识
+// 注解的class以class的名称区分；setmetatable推导出的类型以变量区分，不同文件中同名的变量为不同的类型
+type typeHierarchyKey struct {
+	strClass string          // 注解的class名称
+	varInfo  *common.VarInfo // setmetatable推导出类型的变量
+}
+
+// typeHierarchyNode 类型层级中的单个类型，以及它所有的父类型
+type typeHierarchyNode struct {
+	info      common.TypeHierarchyInfo
+	parentVec []typeHierarchyKey
+}
+
+// PrepareTypeHierarchy 获取代码中光标处的类型，作为类型层级的起点
+func (a *AllProject) PrepareTypeHierarchy(strFile string, varStruct *common.DefineVarStruct) (
+	typeVec []common.TypeHierarchyInfo) {
+	nodeMap := a.getTypeHierarchyMap()
+
+	// 1) 光标处为类型定义的地方，或是直接为类型的名称
+	for _, oneNode := range nodeMap {
+		if oneNode.info.FileName == strFile && oneNode.info.Loc.IsInLocStruct(varStruct.PosLine+1, varStruct.PosCh) {
+			typeVec = append(typeVec, oneNode.info)
+			return typeVec
+		}
+	}
+
+	if oneNode, ok := nodeMap[typeHierarchyKey{strClass: strings.Join(varStruct.StrVec, ".")}]; ok {
+		typeVec = append(typeVec, oneNode.info)
+		return typeVec
+	}
+
+	// 2) 变量定义的地方有注解的class，或是为setmetatable推导出的类型
+	defineVecs := a.FindVarDefineInfo(strFile, varStruct)
+	for _, oneDefine := range defineVecs {
+		strClass := a.getDefineClassName(oneDefine.StrFile, oneDefine.Loc.StartLine)
+		if oneNode, ok := nodeMap[typeHierarchyKey{strClass: strClass}]; ok {
+			typeVec = append(typeVec, oneNode.info)
+			return typeVec
+		}
+
+		for _, oneNode := range nodeMap {
+			if oneNode.info.FileName == oneDefine.StrFile && oneNode.info.Loc == oneDefine.Loc {
+				typeVec = append(typeVec, oneNode.info)
+				return typeVec
+			}
+		}
+	}
+
+	return typeVec
+}
+
+// PrepareAnnotateTypeHierarchy 获取注解中光标处的类型，作为类型层级的起点
+func (a *AllProject) PrepareAnnotateTypeHierarchy(strLine string, col int) (typeVec []common.TypeHierarchyInfo) {
+	l := annotatelexer.CreateAnnotateLexer(&strLine, 0, 0)
+
+	// 判断这行内容是否以-@开头，是否合法
+	if !l.CheckHeardValid() {
+		return
+	}
+
+	annotateState, parseErr := annotateparser.ParserLine(l)
+	if _, flag := annotateState.(*annotateast.AnnotateNotValidState); flag ||
+		parseErr.ErrType != annotatelexer.AErrorOk {
+		return
+	}
+
+	typeStr, _, _ := annotateast.GetStateLocInfo(annotateState, col)
+	if typeStr == "" {
+		return
+	}
+
+	nodeMap := a.getTypeHierarchyMap()
+	if oneNode, ok := nodeMap[typeHierarchyKey{strClass: typeStr}]; ok {
+		typeVec = append(typeVec, oneNode.info)
+	}
+	return typeVec
+}
+
+// FindSupertypes 查找类型所有的父类型
+func (a *AllProject) FindSupertypes(typeItem common.TypeHierarchyInfo) (typeVec []common.TypeHierarchyInfo) {
+	nodeMap := a.getTypeHierarchyMap()
+	oneNode := findTypeHierarchyNode(nodeMap, typeItem)
+	if oneNode == nil {
+		return
+	}
+
+	for _, parentKey := range oneNode.parentVec {
+		if parentNode, ok := nodeMap[parentKey]; ok {
+			typeVec = append(typeVec, parentNode.info)
+		}
+	}
+	return typeVec
+}
+
+// FindSubtypes 查找直接继承了该类型的所有子类型
+func (a *AllProject) FindSubtypes(typeItem common.TypeHierarchyInfo) (typeVec []common.TypeHierarchyInfo) {
+	nodeMap := a.getTypeHierarchyMap()
+	typeNode := findTypeHierarchyNode(nodeMap, typeItem)
+	if typeNode == nil {
+		return
+	}
+
+	for _, oneNode := range nodeMap {
+		for _, parentKey := range oneNode.parentVec {
+			if nodeMap[parentKey] == typeNode {
+				typeVec = append(typeVec, oneNode.info)
+				break
+			}
+		}
+	}
+
+	sort.Slice(typeVec, func(i, j int) bool {
+		if typeVec[i].Name != typeVec[j].Name {
+			return typeVec[i].Name < typeVec[j].Name
+		}
+		return typeVec[i].FileName < typeVec[j].FileName
+	})
+	return typeVec
+}
+
+// findTypeHierarchyNode 查找客户端传入的类型对应的节点
+// 注解的class按照名称查找，setmetatable推导出的类型按照所在的文件与位置查找
+func findTypeHierarchyNode(nodeMap map[typeHierarchyKey]*typeHierarchyNode,
+	typeItem common.TypeHierarchyInfo) *typeHierarchyNode {
+	if typeItem.ClassFlag {
+		return nodeMap[typeHierarchyKey{strClass: typeItem.Name}]
+	}
+
+	for _, oneNode := range nodeMap {
+		if !oneNode.info.ClassFlag && oneNode.info.FileName == typeItem.FileName &&
+			oneNode.info.Loc == typeItem.Loc {
+			return oneNode
+		}
+	}
+
+	return nil
+}
+
+// clearTypeHierarchyMap 清除所有类型的继承关系，下次查找时重新生成
+func (a *AllProject) clearTypeHierarchyMap() {
+	a.typeHierarchyMutex.Lock()
+	defer a.typeHierarchyMutex.Unlock()
+
+	a.typeHierarchyMap = nil
+}
+
+// getTypeHierarchyMap 获取所有的类型及其继承关系，没有生成过的先生成
+// 生成需要遍历所有的文件，比较耗时，不在锁内进行；生成后的结果只读
+func (a *AllProject) getTypeHierarchyMap() map[typeHierarchyKey]*typeHierarchyNode {
+	a.typeHierarchyMutex.Lock()
+	nodeMap := a.typeHierarchyMap
+	a.typeHierarchyMutex.Unlock()
+	if nodeMap != nil {
+		return nodeMap
+	}
+
+	nodeMap = a.createTypeHierarchyMap()
+
+	a.typeHierarchyMutex.Lock()
+	defer a.typeHierarchyMutex.Unlock()
+	if a.typeHierarchyMap != nil {
+		return a.typeHierarchyMap
+	}
+	a.typeHierarchyMap = nodeMap
+	return nodeMap
+}
+
+// createTypeHierarchyMap 生成所有的类型及其继承关系
+// 包括注解的class，以及 a = setmetatable({}, {__index = b}) 这样推导出的类型
+func (a *AllProject) createTypeHierarchyMap() (nodeMap map[typeHierarchyKey]*typeHierarchyNode) {
+	nodeMap = map[typeHierarchyKey]*typeHierarchyNode{}
+
+	// 1) 所有注解的class
+	for strName, createTypeList := range a.createTypeMap {
+		for _, oneCreate := range createTypeList.List {
+			if oneCreate.ClassInfo == nil {
+				continue
+			}
+
+			classState := oneCreate.ClassInfo.ClassState
+			oneNode := insertTypeHierarchyNode(nodeMap, typeHierarchyKey{strClass: strName},
+				common.TypeHierarchyInfo{
+					Name:      strName,
+					FileName:  oneCreate.ClassInfo.LuaFile,
+					Loc:       classState.NameLoc,
+					ClassFlag: true,
+				})
+			for _, strParent := range classState.ParentNameList {
+				oneNode.appendParent(typeHierarchyKey{strClass: strParent})
+			}
+		}
+	}
+
+	// 2) 所有文件最外层的变量，设置了原表的推导出类型
+	fileVec := make([]string, 0, len(a.fileStructMap))
+	for strFile := range a.fileStructMap {
+		fileVec = append(fileVec, strFile)
+	}
+	sort.Strings(fileVec)
+
+	varNameMap := map[*common.VarInfo]string{}
+	var varVec []*common.VarInfo
+	for _, strFile := range fileVec {
+		fileStruct := a.fileStructMap[strFile]
+		if fileStruct.FileResult == nil || fileStruct.FileResult.MainFunc == nil {
+			continue
+		}
+
+		for _, oneVar := range getFileTopVarList(fileStruct.FileResult.MainFunc.MainScope,
+			fileStruct.FileResult.GlobalMaps, varNameMap) {
+			if getVarMetatableExp(oneVar) != nil {
+				varVec = append(varVec, oneVar)
+			}
+		}
+	}
+
+	for _, oneVar := range varVec {
+		a.insertMetatableTypeNode(nodeMap, oneVar, varNameMap)
+	}
+
+	return nodeMap
+}
+
+// getFileTopVarList 获取文件最外层定义的局部变量与全局变量，以及它们的成员，例如 M.Child，名称记录到varNameMap中
+func getFileTopVarList(mainScope *common.ScopeInfo, globalMaps map[string]*common.VarInfo,
+	varNameMap map[*common.VarInfo]string) (varVec []*common.VarInfo) {
+	insertFunc := func(strName string, oneVar *common.VarInfo) {
+		if oneVar == nil {
+			return
+		}
+		if _, ok := varNameMap[oneVar]; ok {
+			return
+		}
+
+		varNameMap[oneVar] = strName
+		varVec = append(varVec, oneVar)
+	}
+
+	if mainScope != nil {
+		for strName, varList := range mainScope.LocVarMap {
+			for _, oneVar := range varList.VarVec {
+				insertFunc(strName, oneVar)
+			}
+		}
+	}
+	for strName, oneVar := range globalMaps {
+		insertFunc(strName, oneVar)
+	}
+
+	topLen := len(varVec)
+	for i := 0; i < topLen; i++ {
+		strName := varNameMap[varVec[i]]
+		for strKey, subVar := range varVec[i].SubMaps {
+			insertFunc(strName+"."+strKey, subVar)
+		}
+	}
+
+	sort.Slice(varVec, func(i, j int) bool {
+		if varVec[i].FileName != varVec[j].FileName {
+			return varVec[i].FileName < varVec[j].FileName
+		}
+		return varVec[i].Loc.StartLine < varVec[j].Loc.StartLine
+	})
+	return varVec
+}
+
+// insertMetatableTypeNode 变量设置了原表，例如 a = setmetatable({}, {__index = b}) 或是 setmetatable(a, b)
+// a为类的table时，a为推导出的类型，原表__index指向的b为a的父类型
+// local obj = setmetatable({}, Foo) 这样创建的实例，不作为类型
+func (a *AllProject) insertMetatableTypeNode(nodeMap map[typeHierarchyKey]*typeHierarchyNode,
+	varInfo *common.VarInfo, varNameMap map[*common.VarInfo]string) {
+	// 1) 子类型，定义的地方有注解的class，使用class的名称
+	childKey, childInfo := a.getVarTypeHierarchyKey(varInfo, varNameMap[varInfo])
+	if childKey.strClass == "" && !isMetatableClassVar(varInfo) {
+		return
+	}
+
+	comParam := a.getCommFunc(varInfo.FileName, varInfo.Loc.StartLine, varInfo.Loc.StartColumn)
+	if comParam == nil {
+		return
+	}
+
+	// 2) 父类型，原表__index指向的变量
+	symbol := common.GetDefaultSymbol(varInfo.FileName, varInfo)
+	parentList := a.getMetatableParentList(symbol, comParam)
+	if len(parentList) == 0 {
+		return
+	}
+
+	parentVar := parentList[0].VarInfo
+	strParent, ok := varNameMap[parentVar]
+	if !ok {
+		return
+	}
+
+	parentKey, parentInfo := a.getVarTypeHierarchyKey(parentVar, strParent)
+	if parentKey == childKey {
+		return
+	}
+
+	childNode := insertTypeHierarchyNode(nodeMap, childKey, childInfo)
+	insertTypeHierarchyNode(nodeMap, parentKey, parentInfo)
+	childNode.appendParent(parentKey)
+}
+
+// getVarTypeHierarchyKey 获取变量对应的类型，变量定义的地方有注解的class时，为注解的class
+func (a *AllProject) getVarTypeHierarchyKey(varInfo *common.VarInfo, strName string) (typeHierarchyKey,
+	common.TypeHierarchyInfo) {
+	info := common.TypeHierarchyInfo{
+		Name:     strName,
+		FileName: varInfo.FileName,
+		Loc:      varInfo.Loc,
+	}
+
+	if strClass := a.getDefineClassName(varInfo.FileName, varInfo.Loc.StartLine); strClass != "" {
+		info.Name = strClass
+		info.ClassFlag = true
+		return typeHierarchyKey{strClass: strClass}, info
+	}
+
+	return typeHierarchyKey{varInfo: varInfo}, info
+}
+
+// isMetatableClassVar 设置了原表的变量是否为类的table，而不是创建的实例
+// 类的table会设置自身的__index，或是定义了成员函数，例如 M.__index = M 或是 function M:foo() end
+func isMetatableClassVar(varInfo *common.VarInfo) bool {
+	for strKey, subVar := range varInfo.SubMaps {
+		if strKey == "__index" || subVar.ReferFunc != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getDefineClassName 变量定义处的前面一行，是否有注解的class，返回class的名称
+func (a *AllProject) getDefineClassName(strFile string, line int) string {
+	annotateFile := a.getAnnotateFile(strFile)
+	if annotateFile == nil {
+		return ""
+	}
+
+	fragment := annotateFile.GetLineFragementInfo(line - 1)
+	if fragment == nil || fragment.ClassInfo == nil || len(fragment.ClassInfo.ClassList) == 0 {
+		return ""
+	}
+
+	return fragment.ClassInfo.ClassList[0].ClassState.Name
+}
+
+// insertTypeHierarchyNode 插入一个类型，已经存在的类型时，返回已经存在的
+func insertTypeHierarchyNode(nodeMap map[typeHierarchyKey]*typeHierarchyNode, key typeHierarchyKey,
+	info common.TypeHierarchyInfo) *typeHierarchyNode {
+	if oneNode, ok := nodeMap[key]; ok {
+		return oneNode
+	}
+
+	oneNode := &typeHierarchyNode{
+		info: info,
+	}
+	nodeMap[key] = oneNode
+	return oneNode
+}
+
+// appendParent 增加父类型，忽略重复的
+func (t *typeHierarchyNode) appendParent(parentKey typeHierarchyKey) {
+	for _, oldKey := range t.parentVec {
+		if oldKey == parentKey {
+			return
+		}
+	}
+
+	t.parentVec = append(t.parentVec, parentKey)
+}
@@ -0,0 +1,209 @@
+package check
+
+import (
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/ast"
+)
+
+// 根据原表推导隐式的类型继承关系，没有---@class注解时，也能补全父类型的成员，例如下面的例子
+// local Animal = {}
+// Animal.__index = Animal
+// function Animal.new() return setmetatable({}, Animal) end
+// local Dog = setmetatable({}, {__index = Animal})
+// Dog.__index = Dog
+// Dog的实例，包含Dog与Animal的成员
+
+// maxMetatableDepth 原表继承链最多追踪的层数
+const maxMetatableDepth = 10
+
+// getVarMetatableExp 获取变量设置的原表表达式
+// 包括 a = setmetatable({}, b) 以及 setmetatable(a, b) 语句，都返回b
+func getVarMetatableExp(varInfo *common.VarInfo) ast.Exp {
+	if varInfo == nil {
+		return nil
+	}
+
+	if varInfo.MetaExp != nil {
+		return varInfo.MetaExp
+	}
+
+	callExp, ok := varInfo.ReferExp.(*ast.FuncCallExp)
+	if !ok || callExp.NameExp != nil || len(callExp.Args) != 2 {
+		return nil
+	}
+
+	if nameExp, ok := callExp.PrefixExp.(*ast.NameExp); !ok || nameExp.Name != "setmetatable" {
+		return nil
+	}
+
+	return callExp.Args[1]
+}
+
+// getIndexFuncParentExp __index为函数时，获取函数返回的父类型表达式
+// 例如 __index = function(t, k) return Parent[k] end 或是 return rawget(Parent, k)，返回Parent
+func getIndexFuncParentExp(funcExp *ast.FuncDefExp) ast.Exp {
+	if len(funcExp.ParList) < 2 || funcExp.Block == nil {
+		return nil
+	}
+
+	strKey := funcExp.ParList[1]
+	for _, retExp := range funcExp.Block.RetExps {
+		switch exp := retExp.(type) {
+		case *ast.TableAccessExp:
+			if keyExp, ok := exp.KeyExp.(*ast.NameExp); ok && keyExp.Name == strKey {
+				return exp.PrefixExp
+			}
+		case *ast.FuncCallExp:
+			nameExp, ok := exp.PrefixExp.(*ast.NameExp)
+			if !ok || nameExp.Name != "rawget" || len(exp.Args) != 2 {
+				continue
+			}
+
+			if keyExp, ok := exp.Args[1].(*ast.NameExp); ok && keyExp.Name == strKey {
+				return exp.Args[0]
+			}
+		}
+	}
+
+	return nil
+}
+
+// getMetatableKeySymbol 获取原表中strKey对应的值，例如 {__index = b} 中__index对应的b
+// 原表为table的构造时，直接查找构造的成员；否则metaSymbol为原表变量，例如 M.__index = M 中的M
+// 与lua的逻辑一致，只查找原表自身的成员
+func (a *AllProject) getMetatableKeySymbol(luaInFile string, metaExp ast.Exp, metaSymbol *common.Symbol,
+	strKey string, comParam *CommonFuncParam, findExpList *[]common.FindExpFile) *common.Symbol {
+	var valExp ast.Exp
+	if tableExp, ok := metaExp.(*ast.TableConstructorExp); ok {
+		for i, keyExp := range tableExp.KeyExps {
+			if keyExp != nil && common.GetExpName(keyExp) == strKey {
+				valExp = tableExp.ValExps[i]
+				break
+			}
+		}
+	} else if metaSymbol != nil && metaSymbol.VarInfo != nil {
+		if subVar, ok := metaSymbol.VarInfo.SubMaps[strKey]; ok {
+			luaInFile = subVar.FileName
+			valExp = subVar.ReferExp
+		}
+	}
+
+	if valExp == nil {
+		return nil
+	}
+
+	// __index为函数的，追踪函数返回的父类型
+	if funcExp, ok := valExp.(*ast.FuncDefExp); ok && strKey == "__index" {
+		if valExp = getIndexFuncParentExp(funcExp); valExp == nil {
+			return nil
+		}
+	}
+
+	return a.FindVarReferSymbol(luaInFile, valExp, comParam, findExpList, 1)
+}
+
+// getMetatableParentList 获取变量原表__index指向的所有父类型，按照继承的顺序由近到远
+// 例如 Child 的父类型为 Parent，Parent 的父类型为 Base，返回 Parent、Base
+func (a *AllProject) getMetatableParentList(symbol *common.Symbol, comParam *CommonFuncParam) (
+	parentList []*common.Symbol) {
+	if symbol == nil || symbol.VarInfo == nil {
+		return
+	}
+
+	visitMap := map[*common.VarInfo]bool{
+		symbol.VarInfo: true,
+	}
+	for i := 0; i < maxMetatableDepth; i++ {
+		metaExp := getVarMetatableExp(symbol.VarInfo)
+		if metaExp == nil {
+			return
+		}
+
+		findExpList := []common.FindExpFile{}
+		var metaSymbol *common.Symbol
+		if _, ok := metaExp.(*ast.TableConstructorExp); !ok {
+			metaSymbol = a.FindVarReferSymbol(symbol.FileName, metaExp, comParam, &findExpList, 1)
+			metaSymbol = a.getMetatableAliasSymbol(metaSymbol, comParam)
+		}
+
+		parentSymbol := a.getMetatableKeySymbol(symbol.FileName, metaExp, metaSymbol, "__index", comParam,
+			&findExpList)
+		parentSymbol = a.getMetatableAliasSymbol(parentSymbol, comParam)
+		if parentSymbol == nil || parentSymbol.VarInfo == nil || visitMap[parentSymbol.VarInfo] {
+			return
+		}
+
+		visitMap[parentSymbol.VarInfo] = true
+		parentList = append(parentList, parentSymbol)
+		symbol = parentSymbol
+	}
+
+	return
+}
+
+// getMetatableAliasSymbol 变量只是其他变量的别名时，获取真正定义的变量
+// 例如 local Base = require("base") 或是 local Base = M.Base，返回base文件中导出的变量或是M.Base
+func (a *AllProject) getMetatableAliasSymbol(symbol *common.Symbol, comParam *CommonFuncParam) *common.Symbol {
+	for i := 0; i < maxMetatableDepth; i++ {
+		if symbol == nil || symbol.VarInfo == nil {
+			return symbol
+		}
+
+		referExp := symbol.VarInfo.ReferExp
+		switch referExp.(type) {
+		case *ast.NameExp, *ast.TableAccessExp:
+		default:
+			if symbol.VarInfo.ReferInfo == nil {
+				return symbol
+			}
+		}
+
+		findExpList := []common.FindExpFile{}
+		referSymbol := a.FindVarReferSymbol(symbol.FileName, referExp, comParam, &findExpList,
+			symbol.VarInfo.VarIndex)
+		if referSymbol == nil || referSymbol.VarInfo == nil || referSymbol.VarInfo == symbol.VarInfo {
+			return symbol
+		}
+
+		symbol = referSymbol
+	}
+
+	return symbol
+}
+
+// getMetatableSubKey 变量自身没有strKey成员时，在原表的继承链中查找
+func (a *AllProject) getMetatableSubKey(symbol *common.Symbol, strKey string,
+	comParam *CommonFuncParam) *common.Symbol {
+	for _, parentSymbol := range a.getMetatableParentList(symbol, comParam) {
+		if subVar, ok := parentSymbol.VarInfo.SubMaps[strKey]; ok {
+			return a.createAnnotateSymbol(strKey, subVar)
+		}
+	}
+
+	return nil
+}
+
+// mergeMetatableParent 合并继承链中所有父类型的成员，自身的成员优先
+// VarInfo为工程共享的分析结果，合并到拷贝的变量上，不修改原有的
+func (a *AllProject) mergeMetatableParent(symbol *common.Symbol, comParam *CommonFuncParam) *common.Symbol {
+	parentList := a.getMetatableParentList(symbol, comParam)
+	if len(parentList) == 0 {
+		return symbol
+	}
+
+	subMaps := map[string]*common.VarInfo{}
+	for i := len(parentList) - 1; i >= 0; i-- {
+		for key, oneVar := range parentList[i].VarInfo.SubMaps {
+			subMaps[key] = oneVar
+		}
+	}
+	for key, oneVar := range symbol.VarInfo.SubMaps {
+		subMaps[key] = oneVar
+	}
+
+	mergeVar := *symbol.VarInfo
+	mergeVar.SubMaps = subMaps
+	mergeSymbol := *symbol
+	mergeSymbol.VarInfo = &mergeVar
+	return &mergeSymbol
+}
@@ -0,0 +1,121 @@
+package check
+
+import (
+	"luahelper-lsp/langserver/check/common"
+)
+
+// table的结构推导，第一轮分析时记录了文件内函数的调用位置，这里合并传递的table成员，例如下面的例子
+// local function fill(conf) conf.timeout = 3 end
+// local function consume(cfg) print(cfg.host) end
+// local made = { host = "a" }
+// fill(made)
+// consume(made)
+// made的成员包含fill中赋值的timeout，参数cfg没有注解时，成员包含made的host与timeout
+
+// maxTableShapeDepth 推导table结构时，最多追踪的传递层数
+const maxTableShapeDepth = 5
+
+// getTableShapeList 获取符号在文件内推导出的table结构对应的所有符号，不包含符号自身
+func (a *AllProject) getTableShapeList(symbol *common.Symbol, comParam *CommonFuncParam) (
+	shapeList []*common.Symbol) {
+	if symbol == nil || symbol.VarInfo == nil {
+		return
+	}
+
+	visitMap := map[*common.VarInfo]bool{
+		symbol.VarInfo: true,
+	}
+	a.collectTableShape(symbol, comParam, visitMap, true, 0, &shapeList)
+	return shapeList
+}
+
+// collectTableShape 递归收集table结构对应的符号
+// callFlag 表示是否通过调用处的实参推导参数的结构，变量传入函数时，不再推导该函数其他调用处的实参
+func (a *AllProject) collectTableShape(symbol *common.Symbol, comParam *CommonFuncParam,
+	visitMap map[*common.VarInfo]bool, callFlag bool, depth int, shapeList *[]*common.Symbol) {
+	if depth >= maxTableShapeDepth {
+		return
+	}
+
+	varInfo := symbol.VarInfo
+
+	// 1) 变量传入文件内的函数，函数内对参数成员的赋值，例如 fill(made)
+	for _, passInfo := range varInfo.PassParamVec {
+		paramVar := passInfo.FuncInfo.GetParamVarInfo(passInfo.ParamIndex)
+		if paramVar == nil || visitMap[paramVar] {
+			continue
+		}
+
+		visitMap[paramVar] = true
+		paramSymbol := a.createAnnotateSymbol(passInfo.FuncInfo.ParamList[passInfo.ParamIndex], paramVar)
+		*shapeList = append(*shapeList, paramSymbol)
+		a.collectTableShape(paramSymbol, comParam, visitMap, false, depth+1, shapeList)
+	}
+
+	// 2) 函数的参数没有注解时，通过调用处的实参推导，例如 consume(made)
+	if !callFlag || !varInfo.IsParam || symbol.AnnotateType != nil {
+		return
+	}
+
+	funcInfo := varInfo.ParamFunc
+	if funcInfo == nil {
+		return
+	}
+
+	paramIndex := int(varInfo.VarIndex) - 1
+	for _, callExp := range funcInfo.CallSites {
+		argIndex := paramIndex
+		if callExp.NameExp != nil {
+			argIndex = paramIndex - 1
+		}
+		if argIndex < 0 || argIndex >= len(callExp.Args) {
+			continue
+		}
+
+		// 实参可能为局部变量的别名或是函数的返回，追踪所有关联的变量
+		findExpList := []common.FindExpFile{}
+		argList := a.FindDeepSymbolList(symbol.FileName, callExp.Args[argIndex], comParam, &findExpList, true, 1)
+		for _, argSymbol := range argList {
+			if argSymbol.VarInfo == nil {
+				*shapeList = append(*shapeList, argSymbol)
+				continue
+			}
+
+			if visitMap[argSymbol.VarInfo] {
+				continue
+			}
+
+			visitMap[argSymbol.VarInfo] = true
+			*shapeList = append(*shapeList, argSymbol)
+			a.collectTableShape(argSymbol, comParam, visitMap, true, depth+1, shapeList)
+		}
+	}
+}
+
+// getTableShapeSubKey 变量自身没有strKey成员时，在推导的table结构中查找
+func (a *AllProject) getTableShapeSubKey(symbol *common.Symbol, strKey string,
+	comParam *CommonFuncParam) *common.Symbol {
+	for _, shapeSymbol := range a.getTableShapeList(symbol, comParam) {
+		// 实参为注解的类型，在注解类型中查找
+		if shapeSymbol.VarInfo == nil {
+			findExpList := []common.FindExpFile{}
+			if subSymbol := a.symbolHasSubKey(shapeSymbol, strKey, comParam, &findExpList); subSymbol != nil {
+				return subSymbol
+			}
+			continue
+		}
+
+		if subVar, ok := shapeSymbol.VarInfo.SubMaps[strKey]; ok {
+			return a.createAnnotateSymbol(strKey, subVar)
+		}
+	}
+
+	return nil
+}
+
+// getTableShapeComplete 补全推导的table结构中的成员
+func (a *AllProject) getTableShapeComplete(symbol *common.Symbol, colonFlag bool, comParam *CommonFuncParam) {
+	for _, shapeSymbol := range a.getTableShapeList(symbol, comParam) {
+		a.getVarInfoCompleteExt(shapeSymbol, colonFlag)
+	}
+}
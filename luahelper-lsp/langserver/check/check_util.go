@@ -97,6 +97,9 @@ func (a *AllProject) GetAllFileErrorInfo() map[string][]common.CheckError {
 		annotateFile := fileStruct.AnnotateFile
 		if annotateFile != nil {
 			checkErrVec := annotateFile.GetErrorVec()
+			if fileResult != nil {
+				checkErrVec = fileResult.Suppress.FilterErrVec(checkErrVec)
+			}
 			if len(checkErrVec) > 0 {
 				fileStrMap := getFileStrMap(strFile)
 				a.copyFileErr(strFile, checkErrVec, fileErrorMap, fileStrMap)
@@ -108,6 +111,7 @@ func (a *AllProject) GetAllFileErrorInfo() map[string][]common.CheckError {
 	dirManager := common.GConfig.GetDirManager()
 	mainDir := dirManager.GetMainDir()
 	if mainDir == "" {
+		a.copyAllSuppressErr(fileErrorMap, getFileStrMap)
 		return fileErrorMap
 	}
 
@@ -135,9 +139,27 @@ func (a *AllProject) GetAllFileErrorInfo() map[string][]common.CheckError {
 		}
 	}
 
+	// 4) 所有阶段的告警都屏蔽完后，获取没有屏蔽任何告警的注解
+	a.copyAllSuppressErr(fileErrorMap, getFileStrMap)
+
 	return fileErrorMap
 }
 
+// copyAllSuppressErr 拷贝所有文件中---@diagnostic 注解产生的告警
+func (a *AllProject) copyAllSuppressErr(fileErrorMap map[string][]common.CheckError,
+	getFileStrMap func(strFile string) map[string]bool) {
+	for strFile, fileStruct := range a.fileStructMap {
+		if fileStruct.FileResult == nil {
+			continue
+		}
+
+		checkErrVec := fileStruct.FileResult.Suppress.GetErrorVec(strFile)
+		if len(checkErrVec) > 0 {
+			a.copyFileErr(strFile, checkErrVec, fileErrorMap, getFileStrMap(strFile))
+		}
+	}
+}
+
 // IsNeedHandle 给一个文件名，判断是否要进行处理
 func (a *AllProject) IsNeedHandle(strFile string) bool {
 	// 判断该文件是否是忽略处理的
@@ -321,6 +343,25 @@ func (a *AllProject) GetFuncParamType(fileName string, lastLine int) (retMap map
 	return retMap
 }
 
+// GetFuncGenericType 获取函数注解的泛型，key为泛型名称，value为泛型的父类型，没有父类型时为空
+func (a *AllProject) GetFuncGenericType(fileName string, lastLine int) (retMap map[string]string) {
+	annotateFile := a.getAnnotateFile(fileName)
+	if annotateFile == nil {
+		return
+	}
+
+	fragmentInfo := annotateFile.GetLineFragementInfo(lastLine)
+	if fragmentInfo == nil || fragmentInfo.GenericInfo == nil {
+		return
+	}
+
+	retMap = map[string]string{}
+	for _, oneGeneric := range fragmentInfo.GenericInfo.GenericInfoList {
+		retMap[oneGeneric.Name] = oneGeneric.ParentName
+	}
+	return retMap
+}
+
 // 获取返回值类型 返回一个二维数组 如---@return number,string|number 对应[[number],[string,number]]
 func (a *AllProject) GetFuncReturnTypeVec(fileName string, lastLine int) (retVec [][]string) {
 
@@ -601,6 +642,91 @@ func (a *AllProject) GetAnnotateTypeString(varInfo *common.VarInfo, varName stri
 	return
 }
 
+// getVarAnnotateType 获取变量定义处的注解类型，idx 为第几个，例如函数有多个返回值时候
+// optionFlag 表示是否为可选的，例如 ---@param one? number 或是 ---@return number? code
+func (a *AllProject) getVarAnnotateType(varInfo *common.VarInfo, varName string, idx int) (astType annotateast.Type,
+	optionFlag bool) {
+	annotateFile := a.getAnnotateFile(varInfo.FileName)
+	if annotateFile == nil {
+		log.Error("getVarAnnotateType annotateFile is nil, file=%s", varInfo.FileName)
+		return
+	}
+
+	fragmentInfo := annotateFile.GetLineFragementInfo(varInfo.Loc.StartLine - 1)
+	if fragmentInfo == nil || idx <= 0 {
+		return
+	}
+
+	// 如果是函数 取返回值
+	if varInfo.ReferFunc != nil {
+		returnInfo := fragmentInfo.ReturnInfo
+		if returnInfo == nil || len(returnInfo.ReturnTypeList) < idx {
+			return
+		}
+
+		optionFlag = len(returnInfo.ReturnOptionList) >= idx && returnInfo.ReturnOptionList[idx-1]
+		return returnInfo.ReturnTypeList[idx-1], optionFlag
+	}
+
+	// 如果是函数参数，需要用参数名称匹配
+	if varInfo.IsParam {
+		if fragmentInfo.ParamInfo == nil {
+			return
+		}
+
+		for _, paramState := range fragmentInfo.ParamInfo.ParamList {
+			if paramState.Name == varName {
+				return paramState.ParamType, paramState.IsOptional
+			}
+		}
+		return
+	}
+
+	if fragmentInfo.TypeInfo != nil && len(fragmentInfo.TypeInfo.TypeList) >= idx {
+		return fragmentInfo.TypeInfo.TypeList[idx-1], false
+	}
+
+	return
+}
+
+// GetAnnotateNilFlag 变量定义处注解的类型是否可能为nil，例如 ---@type Foo|nil、---@param one? Foo、---@return Foo?
+// findFlag 表示是否找到了注解的类型
+func (a *AllProject) GetAnnotateNilFlag(varInfo *common.VarInfo, varName string, idx int) (nilFlag bool,
+	findFlag bool) {
+	astType, optionFlag := a.getVarAnnotateType(varInfo, varName, idx)
+	if astType == nil {
+		return false, false
+	}
+
+	if optionFlag {
+		return true, true
+	}
+
+	multiType, ok := astType.(*annotateast.MultiType)
+	if !ok {
+		return false, true
+	}
+
+	for _, oneType := range multiType.TypeList {
+		if normalType, ok := oneType.(*annotateast.NormalType); ok && normalType.StrName == "nil" {
+			return true, true
+		}
+	}
+
+	return false, true
+}
+
+// IsAnnotateMapType 变量定义处注解的类型是否为map，例如 ---@type table<string, Foo>，用key取到的值可能为nil
+func (a *AllProject) IsAnnotateMapType(varInfo *common.VarInfo, varName string) bool {
+	astType, _ := a.getVarAnnotateType(varInfo, varName, int(varInfo.VarIndex))
+	if multiType, ok := astType.(*annotateast.MultiType); ok && len(multiType.TypeList) == 1 {
+		astType = multiType.TypeList[0]
+	}
+
+	tableType, ok := astType.(*annotateast.TableType)
+	return ok && !tableType.EmptyFlag
+}
+
 // GetFirstFileStuct 获取第一阶段文件处理的结果
 func (a *AllProject) GetFirstFileStuct(strFile string) (*results.FileStruct, bool) {
 	if a.checkTerm == results.CheckTermFirst {
@@ -837,6 +963,7 @@ func StrToDefineVarStruct(str string) (defineVar common.DefineVarStruct) {
 	}
 
 	newParser := parser.CreateParser([]byte(str), "")
+	newParser.SetLuaVersion(common.GConfig.GetLuaVersion())
 	exp := newParser.BeginAnalyzeExp()
 	errList := newParser.GetErrList()
 	if exp == nil || len(errList) > 0 {
@@ -25,6 +25,8 @@ type OneClassInfo struct {
 	RelateVar *VarInfo
 
 	LuaFile string // 这个结构所在的lua文件名
+
+	Factory *ClassFactory // 工厂函数构造的类对应的配置，---@class注解的为nil
 }
 
 // FragmentClassInfo 单个块所对应的class信息, 一个注释块，允许有多个 FragmentClassInfo
@@ -61,8 +63,9 @@ type FragementParamInfo struct {
 
 // FragementReturnInfo 单个块对应的所有返回信息， 一个注释块，允许有多个 AnnotateReturnState
 type FragementReturnInfo struct {
-	ReturnTypeList []annotateast.Type // 每个AnnotateReturnState的ReturnTypeList拼接在这里面
-	CommentList    []string           // 每一个返回类型的comment
+	ReturnTypeList   []annotateast.Type // 每个AnnotateReturnState的ReturnTypeList拼接在这里面
+	ReturnOptionList []bool             // 每一个返回类型是否为可选的，例如 ---@return integer? code
+	CommentList      []string           // 每一个返回类型的comment
 }
 
 // FragementVarargInfo vararg信息
@@ -70,6 +73,11 @@ type FragementVarargInfo struct {
 	VarargInfo *annotateast.AnnotateVarargState
 }
 
+// FragementModuleInfo 文件return语句前的module信息
+type FragementModuleInfo struct {
+	ModuleInfo *annotateast.AnnotateModuleState
+}
+
 // OneGenericInfo 单个泛型信息
 type OneGenericInfo struct {
 	Name         string
@@ -100,6 +108,7 @@ type FragementInfo struct {
 	VarargInfo   *FragementVarargInfo
 	GenericInfo  *FragementGenericInfo
 	OverloadInfo *FragementOverloadInfo
+	ModuleInfo   *FragementModuleInfo
 }
 
 // GetFirstOneClassInfo 获取注释代码段第一个ClassInfo
@@ -317,6 +326,7 @@ type AnnotateFile struct {
 	checkErrVec     []CheckError              // 注解检测到的错误信息
 	EnumFragmentVec []EnumFragment            // 所有的枚举段落
 	IsEnumType      bool                      // 是否有枚举类型的type定义信息
+	IsMetaType      bool                      // 是否有---@meta 注解，为声明文件
 }
 
 // CreateAnnotateFile 创建文件的所有注解信息
@@ -444,6 +454,10 @@ func (af *AnnotateFile) analysisAnnotateFragement(lastLine int, annotateFragment
 		VarargInfo: nil,
 	}
 
+	moduleInfo := FragementModuleInfo{
+		ModuleInfo: nil,
+	}
+
 	fragmentInfo := &FragementInfo{
 		LastLine: lastLine,
 	}
@@ -460,6 +474,14 @@ func (af *AnnotateFile) analysisAnnotateFragement(lastLine int, annotateFragment
 			})
 
 		case *annotateast.AnnotateClassState:
+			// 泛型类的参数名称，在class的field中可以引用
+			for index, name := range state.GenericNameList {
+				genericInfo.GenericInfoList = append(genericInfo.GenericInfoList, OneGenericInfo{
+					Name:    name,
+					NameLoc: state.GenericLocList[index],
+				})
+			}
+
 			if oneClassInfo.ClassState == nil {
 				oneClassInfo.ClassState = state
 			} else {
@@ -495,6 +517,8 @@ func (af *AnnotateFile) analysisAnnotateFragement(lastLine int, annotateFragment
 			returnInfo.ReturnTypeList = append(returnInfo.ReturnTypeList, state.ReturnTypeList...)
 			for i := 0; i < len(state.ReturnTypeList); i++ {
 				returnInfo.CommentList = append(returnInfo.CommentList, state.Comment)
+				returnInfo.ReturnOptionList = append(returnInfo.ReturnOptionList,
+					i < len(state.ReturnOptionList) && state.ReturnOptionList[i])
 			}
 
 		case *annotateast.AnnotateGenericState:
@@ -517,6 +541,9 @@ func (af *AnnotateFile) analysisAnnotateFragement(lastLine int, annotateFragment
 
 		case *annotateast.AnnotateVarargState:
 			varargInfo.VarargInfo = state
+
+		case *annotateast.AnnotateModuleState:
+			moduleInfo.ModuleInfo = state
 		}
 	}
 
@@ -563,6 +590,11 @@ func (af *AnnotateFile) analysisAnnotateFragement(lastLine int, annotateFragment
 		fragmentInfo.VarargInfo = &varargInfo
 	}
 
+	// 9) 文件返回的module段
+	if moduleInfo.ModuleInfo != nil {
+		fragmentInfo.ModuleInfo = &moduleInfo
+	}
+
 	af.FragementMap[lastLine] = fragmentInfo
 	af.sortFragement.results = append(af.sortFragement.results, fragmentInfo)
 }
@@ -588,6 +620,22 @@ func (af *AnnotateFile) insertNewType(name string, oneTypeInfo *CreateTypeInfo)
 	}
 }
 
+// InsertFactoryClass 插入第一轮分析出的工厂函数构造的类，文件中已经有同名的注解类型时，以注解的为准
+func (af *AnnotateFile) InsertFactoryClass(classList []*OneClassInfo) {
+	for _, oneClass := range classList {
+		name := oneClass.ClassState.Name
+		if _, ok := af.CreateTypeMap[name]; ok {
+			continue
+		}
+
+		oneTypeInfo := &CreateTypeInfo{
+			LastLine:  oneClass.LastLine,
+			ClassInfo: oneClass,
+		}
+		af.insertNewType(name, oneTypeInfo)
+	}
+}
+
 // 这个文件的所有注释块依据行号，提取这个文件的所有符号
 func (af *AnnotateFile) generateNewType() {
 	for _, fragment := range af.sortFragement.results {
@@ -874,11 +922,15 @@ func (af *AnnotateFile) AnalysisAllComment(commentMap map[int]*lexer.CommentInfo
 		// 分析单个注释的段落
 		af.analysisAnnotateFragement(lastLine, &annotateFragment)
 
-		// 提取所有的枚举段落的开始与结束
+		// 提取所有的枚举段落的开始与结束，以及是否为声明文件
 		for _, oneState := range annotateFragment.Stats {
 			if enumState, ok := oneState.(*annotateast.AnnotateEnumState); ok {
 				enumVec = append(enumVec, enumState)
 			}
+
+			if _, ok := oneState.(*annotateast.AnnotateMetaState); ok {
+				af.IsMetaType = true
+			}
 		}
 
 		// 判断是否忽略注解类型告警
@@ -1012,7 +1064,51 @@ func (af *AnnotateFile) GetBestCreateTypeInfo(strName string, lastLine int) (cre
 	return resultCreate.results[0]
 }
 
+// GetVarClassGenericNames 获取变量关联的泛型类的参数名称，例如 ---@class List<T> 关联的变量List，返回T
+func (af *AnnotateFile) GetVarClassGenericNames(varInfo *VarInfo) (nameVec []string) {
+	if varInfo == nil {
+		return
+	}
+
+	for _, createList := range af.CreateTypeMap {
+		for _, oneCreate := range createList.List {
+			classInfo := oneCreate.ClassInfo
+			if classInfo == nil || classInfo.RelateVar == nil || classInfo.RelateVar.Loc != varInfo.Loc {
+				continue
+			}
+
+			nameVec = append(nameVec, classInfo.ClassState.GenericNameList...)
+		}
+	}
+
+	return nameVec
+}
+
+// GetModuleType 获取文件return语句前注解的类型，为---@module 或是---@type 注解的
+// line 为return语句所在的行号
+func (af *AnnotateFile) GetModuleType(line int) annotateast.Type {
+	fragment, ok := af.FragementMap[line-1]
+	if !ok {
+		return nil
+	}
+
+	if fragment.ModuleInfo != nil {
+		return fragment.ModuleInfo.ModuleInfo.ModuleType
+	}
+
+	if fragment.TypeInfo != nil && len(fragment.TypeInfo.TypeList) > 0 {
+		return fragment.TypeInfo.TypeList[0]
+	}
+
+	return nil
+}
+
 // IsHasEnumType 判断是否含义枚举类型
 func (af *AnnotateFile) IsHasEnumType() bool {
 	return af.IsEnumType
 }
+
+// IsMetaFile 判断是否为---@meta 注解的声明文件
+func (af *AnnotateFile) IsMetaFile() bool {
+	return af.IsMetaType
+}
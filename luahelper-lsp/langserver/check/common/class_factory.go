@@ -0,0 +1,78 @@
+package common
+
+import (
+	"luahelper-lsp/langserver/check/compiler/ast"
+)
+
+// 工厂函数构造的类，没有---@class注解时，通过luahelper.json中ClassFactories的配置推导出类型，例如下面的例子
+// local Foo = class("Foo", Base)
+// function Foo:initialize(name) end
+// local obj = Foo:new("a")
+// obj的类型为Foo，包含Foo与Base的成员
+
+// GetFactoryFuncName 获取可能为工厂函数调用的函数名称
+// 例如 class("Foo") 为class，lib.class("Foo") 为class，Base:extend("Foo") 为extend
+func GetFactoryFuncName(node *ast.FuncCallExp) string {
+	if node.NameExp != nil {
+		return node.NameExp.Str
+	}
+
+	switch exp := node.PrefixExp.(type) {
+	case *ast.NameExp:
+		return exp.Name
+	case *ast.TableAccessExp:
+		if keyExp, ok := exp.KeyExp.(*ast.StringExp); ok {
+			return keyExp.Str
+		}
+	}
+
+	return ""
+}
+
+// MatchFactoryCall 判断函数调用是否为配置的工厂函数，是返回对应的配置
+func MatchFactoryCall(node *ast.FuncCallExp) *ClassFactory {
+	strFuncName := GetFactoryFuncName(node)
+	if strFuncName == "" {
+		return nil
+	}
+
+	flag, oneFactory := GConfig.MatchClassFactory(strFuncName)
+	if !flag {
+		return nil
+	}
+
+	// 冒号调用的，只有配置了冒号调用的对象为父类才匹配
+	if (node.NameExp != nil) != (oneFactory.SelfBaseFlag == 1) {
+		return nil
+	}
+
+	return oneFactory
+}
+
+// GetFactoryBaseExp 获取工厂函数调用中父类的表达式，没有返回nil
+func GetFactoryBaseExp(node *ast.FuncCallExp, oneFactory *ClassFactory) ast.Exp {
+	if oneFactory.SelfBaseFlag == 1 {
+		return node.PrefixExp
+	}
+
+	if oneFactory.BaseIndex < 1 || oneFactory.BaseIndex > len(node.Args) {
+		return nil
+	}
+
+	return node.Args[oneFactory.BaseIndex-1]
+}
+
+// IsFactoryNewFunc 判断是否为工厂函数构造的类创建实例的函数
+func (oneClass *OneClassInfo) IsFactoryNewFunc(strName string) bool {
+	if oneClass.Factory == nil {
+		return false
+	}
+
+	for _, oneName := range oneClass.Factory.NewFuncs {
+		if oneName == strName {
+			return true
+		}
+	}
+
+	return false
+}
@@ -46,6 +46,7 @@ type OneCompleteData struct {
 	FieldState     *annotateast.AnnotateFieldState // 提示为注解的class信息
 	FieldColonFlag annotateast.FieldColonType      // 当为FieldState时候，是否为：函数
 	CreateTypeInfo *CreateTypeInfo
+	GenericMap     map[string]annotateast.Type // 泛型类实例化的成员，泛型名称对应的具体类型
 }
 
 // CompleteCache 缓存所有的补全信息
@@ -60,6 +61,9 @@ type CompleteCache struct {
 	beforeHashtag    bool                //  补全的词前面是否包含#
 	clearParamQuotes bool                // 补全时候，是否要清除候选词的引号
 	completeVar      *CompleteVarStruct  // 缓存的输入代码补全的结构
+
+	// 补全泛型类实例化的成员时，泛型名称对应的具体类型，插入的成员都记录下来
+	genericMap map[string]annotateast.Type
 }
 
 // CreateCompleteCache 创建一个代码补全缓存
@@ -89,6 +93,12 @@ func (cache *CompleteCache) ResertData() {
 	cache.beforeHashtag = false
 	cache.clearParamQuotes = false
 	cache.completeVar = nil
+	cache.genericMap = nil
+}
+
+// SetGenericMap 设置后面插入的成员，泛型名称对应的具体类型，为nil时表示不是泛型类的成员
+func (cache *CompleteCache) SetGenericMap(genericMap map[string]annotateast.Type) {
+	cache.genericMap = genericMap
 }
 
 // SetCompleteVar set completeVar
@@ -187,10 +197,11 @@ func (cache *CompleteCache) IsExcludeStr(strLabel string) bool {
 // InsertCompleteVar 插入补全缓存类型的CKindVar
 func (cache *CompleteCache) InsertCompleteVar(luaFile string, label string, varInfo *VarInfo) {
 	oneComplete := OneCompleteData{
-		Label:     label,
-		LuaFile:   luaFile,
-		VarInfo:   varInfo,
-		CacheKind: CKindVar,
+		Label:      label,
+		LuaFile:    luaFile,
+		VarInfo:    varInfo,
+		CacheKind:  CKindVar,
+		GenericMap: cache.genericMap,
 	}
 
 	if varInfo.ReferFunc != nil {
@@ -210,10 +221,11 @@ func (cache *CompleteCache) InsertCompleteVar(luaFile string, label string, varI
 // 当为VarInfo时候，是否补充第一个参数为self
 func (cache *CompleteCache) InsertCompleteVarInclude(luaFile string, label string, varInfo *VarInfo) {
 	oneComplete := OneCompleteData{
-		Label:     label,
-		LuaFile:   luaFile,
-		VarInfo:   varInfo,
-		CacheKind: CKindVar,
+		Label:      label,
+		LuaFile:    luaFile,
+		VarInfo:    varInfo,
+		CacheKind:  CKindVar,
+		GenericMap: cache.genericMap,
 		//IncludeSelfParam: true,
 	}
 
@@ -318,6 +330,7 @@ func (cache *CompleteCache) InsertCompleteClassField(luaFile, label string, fiel
 		FieldState:     field,
 		CacheKind:      CkindClassField,
 		FieldColonFlag: colonType,
+		GenericMap:     cache.genericMap,
 	}
 
 	if annotateast.GetAllFuncType(field.FiledType) != nil {
@@ -0,0 +1,254 @@
+package common
+
+import (
+	"fmt"
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/annotation/annotateparser"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// suppressMaxLine 屏蔽区域一直到文件的结束
+const suppressMaxLine = int(^uint(0) >> 1)
+
+// OneSuppress 单个---@diagnostic 注解，生效的行范围与规则
+type OneSuppress struct {
+	State     *annotateast.AnnotateDiagnosticState // 解析出来的注解
+	Loc       lexer.Location                       // 整个注释的位置信息
+	StartLine int                                  // 生效的开始行
+	EndLine   int                                  // 生效的结束行，包含该行
+
+	// 屏蔽的告警类型，value为disable区域对应规则结束的行；为空表示屏蔽所有的规则
+	ruleMap map[CheckErrorType]int
+
+	// 是否屏蔽过告警，多个协程分析时会同时设置，用原子操作
+	usedFlag int32
+}
+
+// FileSuppress 单个lua文件中所有的---@diagnostic 注解
+type FileSuppress struct {
+	suppressVec []*OneSuppress // 按行号排好序
+	checkErrVec []CheckError   // 尾部注释中解析注解的错误，或是未知的规则名称
+}
+
+// CreateFileSuppress 从文件的所有注释中，解析出屏蔽告警的注解，没有时返回nil
+func CreateFileSuppress(commentMap map[int]*lexer.CommentInfo) *FileSuppress {
+	fileSuppress := &FileSuppress{}
+	for _, commentInfo := range commentMap {
+		stateVec, parseErrVec := annotateparser.ParseDiagnosticComment(commentInfo)
+		for _, oneState := range stateVec {
+			fileSuppress.insertSuppress(oneState, getSuppressCommentLoc(commentInfo, oneState))
+		}
+
+		// 头部注释的解析错误，在注解分析的时候已经报出了
+		if commentInfo.HeadFlag {
+			continue
+		}
+
+		for _, oneParseErr := range parseErrVec {
+			fileSuppress.checkErrVec = append(fileSuppress.checkErrVec, CheckError{
+				ErrType:            CheckErrorAnnotate,
+				ErrStr:             oneParseErr.ErrStr,
+				Loc:                oneParseErr.ErrLoc,
+				AnnotateSyntaxFlag: true,
+			})
+		}
+	}
+
+	if len(fileSuppress.suppressVec) == 0 && len(fileSuppress.checkErrVec) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(fileSuppress.suppressVec, func(i, j int) bool {
+		return fileSuppress.suppressVec[i].Loc.StartLine < fileSuppress.suppressVec[j].Loc.StartLine
+	})
+	fileSuppress.calcSuppressRange()
+	return fileSuppress
+}
+
+// getSuppressCommentLoc 获取注解所在的整行注释的位置，从--开始
+func getSuppressCommentLoc(commentInfo *lexer.CommentInfo, state *annotateast.AnnotateDiagnosticState) lexer.Location {
+	for _, commentLine := range commentInfo.LineVec {
+		if commentLine.Line != state.ActionLoc.StartLine {
+			continue
+		}
+
+		strComment := strings.TrimRight(commentLine.Str, " \t\r")
+		return lexer.Location{
+			StartLine:   commentLine.Line,
+			StartColumn: commentLine.Col - 2,
+			EndLine:     commentLine.Line,
+			EndColumn:   commentLine.Col + len(strComment),
+		}
+	}
+
+	return state.ActionLoc
+}
+
+// insertSuppress 插入一个注解
+func (s *FileSuppress) insertSuppress(state *annotateast.AnnotateDiagnosticState, loc lexer.Location) {
+	s.suppressVec = append(s.suppressVec, &OneSuppress{
+		State: state,
+		Loc:   loc,
+	})
+}
+
+// calcSuppressRange 计算每个注解生效的行范围，disable区域与后面的enable进行匹配
+func (s *FileSuppress) calcSuppressRange() {
+	var openVec []*OneSuppress
+	for _, oneSuppress := range s.suppressVec {
+		state := oneSuppress.State
+		line := oneSuppress.Loc.StartLine
+
+		ruleMap := map[CheckErrorType]int{}
+		for i, ruleName := range state.RuleList {
+			errType, ok := GetCheckErrorTypeByName(ruleName)
+			if !ok {
+				s.checkErrVec = append(s.checkErrVec, CheckError{
+					ErrType: CheckErrorAnnotate,
+					ErrStr:  fmt.Sprintf("unknown diagnostic rule: %s", ruleName),
+					Loc:     state.RuleLocList[i],
+				})
+				continue
+			}
+			ruleMap[errType] = suppressMaxLine
+		}
+
+		switch state.Action {
+		case annotateast.DiagnosticDisableNextLine:
+			oneSuppress.StartLine, oneSuppress.EndLine = line+1, line+1
+		case annotateast.DiagnosticDisableLine:
+			oneSuppress.StartLine, oneSuppress.EndLine = line, line
+		case annotateast.DiagnosticDisable:
+			oneSuppress.StartLine, oneSuppress.EndLine = line, suppressMaxLine
+			openVec = append(openVec, oneSuppress)
+		case annotateast.DiagnosticEnable:
+			// enable自身不屏蔽任何告警，结束前面disable的区域
+			for _, openSuppress := range openVec {
+				openSuppress.closeRange(ruleMap, line)
+			}
+			oneSuppress.usedFlag = 1
+			continue
+		}
+
+		if len(state.RuleList) > 0 && len(ruleMap) == 0 {
+			// 填写的规则都无效，不屏蔽任何告警，前面已经报了未知规则的告警
+			oneSuppress.StartLine, oneSuppress.EndLine = 0, -1
+			oneSuppress.usedFlag = 1
+		}
+		oneSuppress.ruleMap = ruleMap
+	}
+}
+
+// closeRange enable结束disable的区域，ruleMap为空表示结束所有的规则
+func (o *OneSuppress) closeRange(ruleMap map[CheckErrorType]int, line int) {
+	if len(ruleMap) == 0 {
+		if o.EndLine == suppressMaxLine {
+			o.EndLine = line
+		}
+		for errType, endLine := range o.ruleMap {
+			if endLine == suppressMaxLine {
+				o.ruleMap[errType] = line
+			}
+		}
+		return
+	}
+
+	// 屏蔽所有规则的disable，只能由不带规则的enable结束
+	if len(o.ruleMap) == 0 {
+		return
+	}
+
+	for errType := range ruleMap {
+		if endLine, ok := o.ruleMap[errType]; ok && endLine == suppressMaxLine {
+			o.ruleMap[errType] = line
+		}
+	}
+}
+
+// isSuppress 是否屏蔽了指定行的告警
+func (o *OneSuppress) isSuppress(errType CheckErrorType, line int) bool {
+	if line < o.StartLine || line > o.EndLine {
+		return false
+	}
+
+	if len(o.State.RuleList) == 0 {
+		return true
+	}
+
+	endLine, ok := o.ruleMap[errType]
+	return ok && line <= endLine
+}
+
+// IsSuppressed 判断告警是否被注解屏蔽，屏蔽了会标记注解被使用
+func (s *FileSuppress) IsSuppressed(errType CheckErrorType, loc lexer.Location) bool {
+	if s == nil || errType == CheckErrorSyntax || errType == CheckErrorUnusedSuppress {
+		return false
+	}
+
+	suppressFlag := false
+	for _, oneSuppress := range s.suppressVec {
+		if oneSuppress.Loc.StartLine > loc.StartLine {
+			break
+		}
+
+		if oneSuppress.isSuppress(errType, loc.StartLine) {
+			atomic.StoreInt32(&oneSuppress.usedFlag, 1)
+			suppressFlag = true
+		}
+	}
+
+	return suppressFlag
+}
+
+// FilterErrVec 过滤掉被注解屏蔽的告警
+func (s *FileSuppress) FilterErrVec(checkErrVec []CheckError) []CheckError {
+	if s == nil {
+		return checkErrVec
+	}
+
+	var resultVec []CheckError
+	for _, oneErr := range checkErrVec {
+		if s.IsSuppressed(oneErr.ErrType, oneErr.Loc) {
+			continue
+		}
+		resultVec = append(resultVec, oneErr)
+	}
+
+	return resultVec
+}
+
+// GetErrorVec 获取注解的错误，以及没有屏蔽任何告警的注解，所有的分析完成之后调用
+func (s *FileSuppress) GetErrorVec(luaFile string) (checkErrVec []CheckError) {
+	if s == nil {
+		return nil
+	}
+
+	if !GConfig.IsIgnoreErrorFile(luaFile, CheckErrorAnnotate) {
+		checkErrVec = append(checkErrVec, s.checkErrVec...)
+	}
+
+	if GConfig.IsIgnoreErrorFile(luaFile, CheckErrorUnusedSuppress) {
+		return checkErrVec
+	}
+
+	for _, oneSuppress := range s.suppressVec {
+		if atomic.LoadInt32(&oneSuppress.usedFlag) != 0 {
+			continue
+		}
+
+		errStr := "unused diagnostic suppression"
+		if len(oneSuppress.State.RuleList) > 0 {
+			errStr = errStr + ": " + strings.Join(oneSuppress.State.RuleList, ", ")
+		}
+		checkErrVec = append(checkErrVec, CheckError{
+			ErrType: CheckErrorUnusedSuppress,
+			ErrStr:  errStr,
+			Loc:     oneSuppress.Loc,
+		})
+	}
+
+	return checkErrVec
+}
@@ -1,5 +1,11 @@
 package common
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // CheckErrorType 检查错误的类型
 type CheckErrorType int
 
@@ -93,6 +99,169 @@ const (
 	// 枚举代码段中的指向的变量值不能重复
 	CheckErrorEnumValue = 29
 
+	// CheckErrorUnusedSuppress ---@diagnostic 屏蔽告警的注解没有屏蔽任何告警
+	CheckErrorUnusedSuppress = 30
+
+	// CheckErrorMixedNotEqual GLua中同一个文件混用了!=与~=
+	CheckErrorMixedNotEqual = 31
+
+	// CheckErrorPossibleNil 可能为nil的值，没有判断就取成员或是调用
+	CheckErrorPossibleNil = 32
+
+	// CheckErrorUnreachable return、break、goto或是error()之后的语句不可达
+	CheckErrorUnreachable = 33
+
+	// CheckErrorMissingReturn 注解了返回值的函数，存在没有返回值的代码路径
+	CheckErrorMissingReturn = 34
+
+	// CheckErrorShadowVar 局部变量遮蔽了同一函数内外层的局部变量或是函数参数
+	CheckErrorShadowVar = 35
+
+	// CheckErrorGlobalAssign 赋值创建了未声明的全局变量，例如函数内拼写错误的变量名
+	CheckErrorGlobalAssign = 36
+
 	// CheckErrorMax
-	CheckErrorMax = 30
+	CheckErrorMax = 37
 )
+
+// checkErrorRule 告警类型对应的规则，名称稳定不变，用于机器可读的输出
+type checkErrorRule struct {
+	name string // 规则的名称
+	desc string // 规则的简短说明
+}
+
+// checkErrorRuleMap 所有告警类型对应的规则
+var checkErrorRuleMap = map[CheckErrorType]checkErrorRule{
+	CheckErrorSyntax:            {"syntax", "Syntax error"},
+	CheckErrorNoDefine:          {"no-define", "Undefined variable"},
+	CheckErrorCycleDefine:       {"cycle-define", "Variable undefined because of cyclic dependency or load order"},
+	CheckErrorLocalNoUse:        {"local-no-use", "Unused local variable"},
+	CheckErrorTableDuplicateKey: {"table-duplicate-key", "Duplicate key in table constructor"},
+	CheckErrorNoFile:            {"no-file", "Referenced lua file not found"},
+	CheckErrorAssignParamNum:    {"assign-param-num", "Assignment value count mismatch"},
+	CheckErrorLocalParamNum:     {"local-param-num", "Local declaration value count mismatch"},
+	CheckErrorGotoLabel:         {"goto-label", "Goto label not found"},
+	CheckErrorCallParam:         {"call-param", "Function call argument count mismatch"},
+	CheckErrorImportVar:         {"import-var", "Member not defined in imported file"},
+	CheckErrorNotIfVar:          {"not-if-var", "Member access on variable checked as nil"},
+	CheckErrorDuplicateParam:    {"duplicate-param", "Duplicate function parameter"},
+	CheckErrorDuplicateExp:      {"duplicate-exp", "Identical expressions on both sides of binary operator"},
+	CheckErrorOrAlwaysTrue:      {"or-always-true", "Or expression is always true"},
+	CheckErrorAndAlwaysFalse:    {"and-always-false", "And expression is always false"},
+	CheckErrorNoUseAssign:       {"no-use-assign", "Local variable only assigned, never used"},
+	CheckErrorAnnotate:          {"annotate", "Annotation error"},
+	CheckErrorDuplicateIf:       {"duplicate-if", "Duplicate if condition"},
+	CheckErrorSelfAssign:        {"self-assign", "Variable assigned to itself"},
+	CheckErrorFloatEq:           {"float-eq", "Float compared for equality"},
+	CheckErrorClassField:        {"class-field", "Field not declared in class"},
+	CheckErrorConstAssign:       {"const-assign", "Assignment to constant"},
+	CheckErrorCallParamType:     {"call-param-type", "Function call argument type mismatch"},
+	CheckErrorFuncRetErr:        {"func-return-type", "Function return type mismatch"},
+	CheckErrorAssignType:        {"assign-type", "Assignment changes variable type"},
+	CheckErrorBinopType:         {"binop-type", "Binary operator operand types differ"},
+	CheckErrorLocFuncNotCall:    {"local-func-not-call", "Local function never called"},
+	CheckErrorEnumValue:         {"enum-value", "Duplicate enum value"},
+	CheckErrorUnusedSuppress:    {"unused-suppression", "Diagnostic suppression comment suppresses nothing"},
+	CheckErrorMixedNotEqual:     {"mixed-not-equal", "GLua file mixes != and ~= operators"},
+	CheckErrorPossibleNil:       {"possible-nil", "Possibly nil value indexed or called"},
+	CheckErrorUnreachable:       {"unreachable-code", "Unreachable code"},
+	CheckErrorMissingReturn:     {"missing-return", "Annotated function may end without returning"},
+	CheckErrorShadowVar:         {"shadowed-variable", "Local shadows outer local or parameter"},
+	CheckErrorGlobalAssign:      {"global-assign", "Assignment creates undeclared global"},
+}
+
+// GetCheckErrorName 获取告警类型对应的规则名称，例如 no-define
+func GetCheckErrorName(errType CheckErrorType) string {
+	if oneRule, ok := checkErrorRuleMap[errType]; ok {
+		return oneRule.name
+	}
+
+	return fmt.Sprintf("type-%d", errType)
+}
+
+// GetCheckErrorTypeByName 规则名称转换为告警类型，也支持直接填写告警类型的数字
+func GetCheckErrorTypeByName(strName string) (CheckErrorType, bool) {
+	for errType, oneRule := range checkErrorRuleMap {
+		if oneRule.name == strName {
+			return errType, true
+		}
+	}
+
+	if num, err := strconv.Atoi(strName); err == nil {
+		if _, ok := checkErrorRuleMap[(CheckErrorType)(num)]; ok {
+			return (CheckErrorType)(num), true
+		}
+	}
+
+	return 0, false
+}
+
+// GetCheckErrorDesc 获取告警类型对应的规则简短说明
+func GetCheckErrorDesc(errType CheckErrorType) string {
+	if oneRule, ok := checkErrorRuleMap[errType]; ok {
+		return oneRule.desc
+	}
+
+	return GetCheckErrorName(errType)
+}
+
+// CheckErrorSeverity 告警的严重级别，数值与lsp的DiagnosticSeverity一致，越小越严重
+type CheckErrorSeverity int
+
+const (
+	// CESOff 关闭，只用于告警级别的配置
+	CESOff CheckErrorSeverity = 0
+
+	// CESError 错误
+	CESError CheckErrorSeverity = 1
+
+	// CESWarning 警告
+	CESWarning CheckErrorSeverity = 2
+
+	// CESInformation 提示信息
+	CESInformation CheckErrorSeverity = 3
+
+	// CESHint 暗示
+	CESHint CheckErrorSeverity = 4
+)
+
+// checkErrorSeverityNames 严重级别的名称，下标为严重级别
+var checkErrorSeverityNames = []string{"off", "error", "warning", "info", "hint"}
+
+// String 严重级别的名称
+func (c CheckErrorSeverity) String() string {
+	if c < CESOff || c > CESHint {
+		return "warning"
+	}
+
+	return checkErrorSeverityNames[c]
+}
+
+// ParseCheckErrorSeverity 名称转换为严重级别，例如 error、warning、info、hint
+func ParseCheckErrorSeverity(strName string) (CheckErrorSeverity, bool) {
+	strName = strings.ToLower(strings.TrimSpace(strName))
+	if strName == "information" {
+		strName = "info"
+	}
+
+	for i := CESError; i <= CESHint; i++ {
+		if checkErrorSeverityNames[i] == strName {
+			return i, true
+		}
+	}
+
+	return CESWarning, false
+}
+
+// GetCheckErrorSeverity 获取告警类型的严重级别，语法错误为error，注解错误为info，未使用的屏蔽注解为hint，其他的为warning
+func GetCheckErrorSeverity(errType CheckErrorType) CheckErrorSeverity {
+	if errType == CheckErrorSyntax {
+		return CESError
+	} else if errType == CheckErrorAnnotate {
+		return CESInformation
+	} else if errType == CheckErrorUnusedSuppress {
+		return CESHint
+	}
+
+	return CESWarning
+}
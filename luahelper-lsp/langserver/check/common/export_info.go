@@ -0,0 +1,17 @@
+package common
+
+import (
+	"luahelper-lsp/langserver/check/annotation/annotateast"
+	"luahelper-lsp/langserver/check/compiler/ast"
+)
+
+// ExportInfo 文件被require时导出的返回信息，第一轮分析时记录，例如下面的例子
+// local M = {}
+// ---@module Logger
+// return cond and M or other
+// 导出的表达式为M与other，注解的类型为Logger
+type ExportInfo struct {
+	ExpList      []ast.Exp        // 导出的所有可能的表达式，后面的return语句优先
+	ReturnLine   int              // 文件最后return语句所在的行号，用于关联前面的注解，没有为0
+	AnnotateType annotateast.Type // return语句前---@module 注解的类型，没有为nil
+}
@@ -0,0 +1,146 @@
+package common
+
+import (
+	"luahelper-lsp/langserver/check/compiler/ast"
+)
+
+// 代码块或语句执行后是否会跳出，不可达代码、缺少返回值的检查与控制流收窄类型共用
+
+// IsBlockTerminate 代码块的执行是否不会到达结尾
+// returnFlag 为true时，只判断函数是否带返回值退出，break与continue只是跳出循环，不带返回值的return也不算
+// errorLocal 为true时，表示error定义为了局部变量，调用的不是系统的error函数
+func IsBlockTerminate(node *ast.Block, returnFlag bool, errorLocal bool) bool {
+	if node == nil {
+		return false
+	}
+
+	if node.RetExps != nil && (!returnFlag || len(node.RetExps) > 0) {
+		return true
+	}
+
+	for _, stat := range node.Stats {
+		if IsStatTerminate(stat, returnFlag, errorLocal) {
+			return true
+		}
+
+		errorLocal = errorLocal || IsStatDeclareLocal(stat, "error")
+	}
+
+	return false
+}
+
+// IsStatTerminate 语句执行后，是否不会执行后面的语句
+func IsStatTerminate(stat ast.Stat, returnFlag bool, errorLocal bool) bool {
+	switch subStat := stat.(type) {
+	case *ast.BreakStat, *ast.ContinueStat:
+		return !returnFlag
+	case *ast.GotoStat:
+		return true
+	case *ast.FuncCallStat:
+		return !errorLocal && isErrorCall(subStat)
+	case *ast.DoStat:
+		return IsBlockTerminate(subStat.Block, returnFlag, errorLocal)
+	case *ast.IfStat:
+		// 必须包含else分支，else分支的条件表达式为true
+		if len(subStat.Exps) == 0 {
+			return false
+		}
+		if _, ok := subStat.Exps[len(subStat.Exps)-1].(*ast.TrueExp); !ok {
+			return false
+		}
+
+		for _, oneBlock := range subStat.Blocks {
+			if !IsBlockTerminate(oneBlock, returnFlag, errorLocal) {
+				return false
+			}
+		}
+		return true
+	case *ast.WhileStat:
+		// 死循环，例如 while true do ... end
+		if _, ok := subStat.Exp.(*ast.TrueExp); !ok {
+			return false
+		}
+		return !hasLoopExit(subStat.Block, false)
+	case *ast.RepeatStat:
+		// 死循环，例如 repeat ... until false
+		if _, ok := subStat.Exp.(*ast.FalseExp); !ok {
+			return false
+		}
+		return !hasLoopExit(subStat.Block, false)
+	}
+
+	return false
+}
+
+// IsStatDeclareLocal 语句是否定义了指定名称的局部变量，例如 local error = print
+func IsStatDeclareLocal(stat ast.Stat, strName string) bool {
+	switch subStat := stat.(type) {
+	case *ast.LocalVarDeclStat:
+		for _, oneName := range subStat.NameList {
+			if oneName == strName {
+				return true
+			}
+		}
+	case *ast.LocalFuncDefStat:
+		return subStat.Name == strName
+	}
+
+	return false
+}
+
+// isErrorCall 是否为调用error()函数
+func isErrorCall(node *ast.FuncCallExp) bool {
+	if node.NameExp != nil {
+		return false
+	}
+
+	nameExp, ok := node.PrefixExp.(*ast.NameExp)
+	return ok && nameExp.Name == "error"
+}
+
+// hasLoopExit 循环体内是否有跳出循环的语句，包括break与goto
+// nestFlag 为true表示在嵌套的循环内，break只跳出嵌套的循环
+func hasLoopExit(node *ast.Block, nestFlag bool) bool {
+	if node == nil {
+		return false
+	}
+
+	for _, stat := range node.Stats {
+		switch subStat := stat.(type) {
+		case *ast.BreakStat:
+			if !nestFlag {
+				return true
+			}
+		case *ast.GotoStat:
+			return true
+		case *ast.DoStat:
+			if hasLoopExit(subStat.Block, nestFlag) {
+				return true
+			}
+		case *ast.IfStat:
+			for _, oneBlock := range subStat.Blocks {
+				if hasLoopExit(oneBlock, nestFlag) {
+					return true
+				}
+			}
+		case *ast.WhileStat:
+			if hasLoopExit(subStat.Block, true) {
+				return true
+			}
+		case *ast.RepeatStat:
+			if hasLoopExit(subStat.Block, true) {
+				return true
+			}
+		case *ast.ForNumStat:
+			if hasLoopExit(subStat.Block, true) {
+				return true
+			}
+		case *ast.ForInStat:
+			if hasLoopExit(subStat.Block, true) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -40,6 +40,12 @@ type ReturnInfo struct {
 	ReturnVarVec []ReturnItem // 函数一次返回可能返回多个字段，这里有列表存储
 }
 
+// ParamPassInfo 变量作为实参传入文件内的函数，例如 fill(conf)，函数内对参数成员的赋值也作用于该变量
+type ParamPassInfo struct {
+	FuncInfo   *FuncInfo // 调用的函数
+	ParamIndex int       // 传入的参数序号，从0开始
+}
+
 // FuncInfo 函数信息
 type FuncInfo struct {
 	parent           *FuncInfo           // 父的funcInfo
@@ -61,6 +67,8 @@ type FuncInfo struct {
 	FuncName         string              // 例如 function table.func() end // func即FuncName
 	ParamType        map[string][]string // 函数所有的参数注解类型列表 参数可能有多个类型 number|string
 	ReturnType       [][]string          // 函数注解处的返回值类型 返回值只能按顺序查找
+	GenericType      map[string]string   // 函数注解的泛型名称，value为泛型的父类型，没有父类型时为空
+	CallSites        []*ast.FuncCallExp  // 文件内调用该函数的所有位置，第一轮记录，用于推导没有注解的参数结构
 }
 
 // CreateFuncInfo 创建一个函数指针
@@ -283,3 +291,29 @@ func (fun *FuncInfo) FindFirstColonFunc() *FuncInfo {
 func (fun *FuncInfo) GetParent() *FuncInfo {
 	return fun.parent
 }
+
+// GetParamVarInfo 获取函数指定序号的参数对应的变量，index从0开始
+func (fun *FuncInfo) GetParamVarInfo(index int) *VarInfo {
+	if index < 0 || index >= len(fun.ParamList) || fun.MainScope == nil {
+		return nil
+	}
+
+	varList, ok := fun.MainScope.LocVarMap[fun.ParamList[index]]
+	if !ok || len(varList.VarVec) == 0 {
+		return nil
+	}
+
+	// 参数最先插入到函数的主scope中
+	if varInfo := varList.VarVec[0]; varInfo.IsParam {
+		return varInfo
+	}
+	return nil
+}
+
+// GetCallParamIndex 获取函数调用第argIndex个实参对应的参数序号，冒号调用时第一个参数为self
+func GetCallParamIndex(node *ast.FuncCallExp, argIndex int) int {
+	if node.NameExp != nil {
+		return argIndex + 1
+	}
+	return argIndex
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"luahelper-lsp/langserver/check/compiler/lexer"
 	"luahelper-lsp/langserver/filefolder"
 	"luahelper-lsp/langserver/log"
 	"path"
@@ -146,6 +147,33 @@ type GlobalConfig struct {
 	// 配置的注解配置
 	anntotateSets []AnntotateSet
 
+	// 配置的构造类的工厂函数
+	classFactories []ClassFactory
+
+	// 代码格式化的配置
+	formatConfig FormatConfig
+
+	// 基线文件的全路径，基线中的告警在语言服务中置灰或是隐藏，为空表示没有配置
+	baselineFile string
+
+	// 基线中的告警的展示方式，hint为降级置灰，hide为不展示
+	baselineMode string
+
+	// luahelper.json中配置的每个规则的告警级别
+	jsonSeverity severityConf
+
+	// 客户端配置的每个规则的告警级别，没有读取到luahelper.json时生效
+	clientSeverity severityConf
+
+	// 当前生效的Lua版本，决定支持的语法以及系统库
+	luaVersion lexer.LuaVersion
+
+	// luahelper.json中配置的Lua版本
+	jsonLuaVersion lexer.LuaVersion
+
+	// 客户端配置的Lua版本，luahelper.json中没有配置时生效
+	clientLuaVersion lexer.LuaVersion
+
 	// 所有的目录管理
 	dirManager *DirManager
 
@@ -157,6 +185,21 @@ type GlobalConfig struct {
 
 	// 存放所有标准库和模块的全局变量，map管理；系统的函数和模块转换成想要的VarInfo，统一起来
 	SysVarMap map[string]*VarInfo
+
+	// 读取到的luahelper.json配置文件的完整路径，没有读取到时为空
+	configFilePath string
+
+	// 配置的允许赋值的全局变量名
+	allowGlobalMap map[string]bool
+
+	// 配置的允许定义全局变量的文件，glob格式
+	globalDefineFiles []string
+
+	// 允许定义全局变量的文件中，定义的所有全局变量名
+	defineGlobalMap map[string]bool
+
+	// ---@meta 声明文件中定义的全局变量，放入了SysVarMap中
+	metaGlobalMap map[string]*VarInfo
 }
 
 // GConfig *GlobalConfig 全局配置对象初始化
@@ -181,6 +224,7 @@ func createDefaultGlobalConfig() {
 		IgnoreWildcarVarMap:    []string{},
 		PathSeparator:          ".",
 		anntotateSets:          []AnntotateSet{},
+		classFactories:         []ClassFactory{},
 		dirManager:             createDirManager(),
 		OtherDir:               "",
 	}
@@ -245,6 +289,44 @@ type (
 		SuffixStr string `json:"SuffixStr"`
 	}
 
+	// ClassFactory 构造类的工厂函数，没有---@class注解时，通过工厂函数的调用推导出类型
+	// 例如 local Foo = class("Foo", Base)，或是 local Foo = Base:extend("Foo")
+	ClassFactory struct {
+		// 工厂函数的名称，例如为class；为冒号调用时，是调用的函数名，例如为extend
+		FuncName string `json:"FuncName"`
+
+		// 类名称为哪一个参数，默认从1开始，为0时类名称为赋值的变量名
+		NameIndex int `json:"NameIndex"`
+
+		// 父类为哪一个参数，默认从1开始，为0时表示没有父类参数
+		BaseIndex int `json:"BaseIndex"`
+
+		// 是否冒号调用的对象为父类，1为是，例如 Base:extend("Foo")
+		SelfBaseFlag int `json:"SelfBaseFlag"`
+
+		// 创建实例的函数名称，例如 Foo:new()，默认为new
+		NewFuncs []string `json:"NewFuncs"`
+
+		// 实例的初始化函数名称，例如 Foo:initialize，创建实例的函数没有定义时，参数与初始化函数一致
+		InitFunc string `json:"InitFunc"`
+
+		// 类的静态成员的字段名称，例如 Foo.static.create，也当做Foo的成员
+		StaticField string `json:"StaticField"`
+
+		// 是否直接调用类创建实例，1为是，例如 Foo()
+		CallNewFlag int `json:"CallNewFlag"`
+	}
+
+	// FormatConfig 代码格式化的配置，为空或为0的项使用默认值
+	FormatConfig struct {
+		IndentWidth       int    `json:"IndentWidth"`       // 缩进的宽度，为0时使用客户端请求中的设置
+		QuoteStyle        string `json:"QuoteStyle"`        // 字符串引号的风格，keep、double、single
+		TableLineBreak    string `json:"TableLineBreak"`    // table构造的换行方式，keep、auto、never
+		TrailingSeparator string `json:"TrailingSeparator"` // table构造最后成员后面的分隔符，keep、add、remove
+		ColumnLimit       int    `json:"ColumnLimit"`       // 单行的最大长度
+		SpaceInsideBraces bool   `json:"SpaceInsideBraces"` // 单行的table构造，大括号内侧是否增加空格
+	}
+
 	// JSONConfig 对外封装的json全局配置信息
 	JSONConfig struct {
 		BaseDir               string              `json:"BaseDir"`               // 所有工程的根目录
@@ -266,8 +348,17 @@ type (
 		ReferFrameFiles       []referFrameFile    `json:"ReferFrameFiles"`       // 项目中引用其他的框架文件
 		PathSeparator         string              `json:"PathSeparator"`         // 项目中引入其他文件，路径分隔符，默认为. 例如require("one.b") 表示引入one/b.lua 文件
 		AnntotateSets         []AnntotateSet      `json:"AnntotateSets"`         // 自动推导的注解方式
+		ClassFactories        []ClassFactory      `json:"ClassFactories"`        // 构造类的工厂函数
 		OtherDir              string              `json:"OtherDir"`              // 引入另外一个目录，可以用于设置引入额外LuaHelper注解格式文件夹
 		OpenErrorTypes        []int               `json:"OpenErrorTypes"`        // 开启的告警项
+		Format                FormatConfig        `json:"Format"`                // 代码格式化的配置
+		Baseline              string              `json:"Baseline"`              // 基线文件，相对于luahelper.json所在的目录
+		BaselineMode          string              `json:"BaselineMode"`          // 基线中的告警的展示方式，hint或hide，默认为hint
+		Severity              map[string]string   `json:"Severity"`              // 每个规则的告警级别，key为规则名称
+		SeverityOverrides     []SeverityOverride  `json:"SeverityOverrides"`     // 指定目录下覆盖的告警级别
+		LuaVersion            string              `json:"LuaVersion"`            // Lua版本，5.1、5.2、5.3、5.4、LuaJIT或GLua，为空不区分版本
+		AllowGlobals          []string            `json:"AllowGlobals"`          // 允许赋值的全局变量名
+		GlobalDefineFiles     []string            `json:"GlobalDefineFiles"`     // 允许定义全局变量的文件，glob格式，相对于工程的根目录
 	}
 )
 
@@ -296,7 +387,16 @@ func createDefaultJSONCfig() {
 		ReferFrameFiles:       []referFrameFile{{Name: "import", Type: 0, SuffixFlag: 1}},
 		PathSeparator:         ".",
 		AnntotateSets:         []AnntotateSet{},
+		ClassFactories:        []ClassFactory{},
 		OpenErrorTypes:        []int{},
+		Format:                FormatConfig{},
+		Baseline:              "",
+		BaselineMode:          "",
+		Severity:              map[string]string{},
+		SeverityOverrides:     []SeverityOverride{},
+		LuaVersion:            "",
+		AllowGlobals:          []string{},
+		GlobalDefineFiles:     []string{},
 	}
 }
 
@@ -581,7 +681,10 @@ func (g *GlobalConfig) handleNotJSONCheckFlag(checkFlagList []bool, ignoreFileOr
 	g.IgnoreErrorTypeMap = map[CheckErrorType]bool{}
 	for i := CheckErrorSyntax; i < CheckErrorMax; i++ {
 		if i > listLen-1 {
-			g.IgnoreErrorTypeMap[(CheckErrorType)(i)] = true
+			// 客户端列表之后新增的告警类型，保持默认的行为，需要开启的告警可以通过客户端的告警级别配置开启
+			if i <= CheckErrorEnumValue {
+				g.IgnoreErrorTypeMap[(CheckErrorType)(i)] = true
+			}
 		} else {
 			oneFlag := checkFlagList[i]
 			if !oneFlag {
@@ -609,12 +712,20 @@ func (g *GlobalConfig) HandleChangeCheckList(checkFlagList []bool, ignoreFileOrD
 func (g *GlobalConfig) ReadConfig(strDir, configFileName string, checkFlagList []bool, ignoreFileOrDir []string,
 	ignoreFileOrDirErr []string) error {
 	strPath := g.dirManager.GetCompletePath(strDir, configFileName)
+	g.configFilePath = ""
+	g.baselineFile = ""
+	g.baselineMode = ""
+	g.SetJSONSeverityConfig(nil, nil)
+	g.setGlobalDeclareConfig(nil, nil)
+	g.jsonLuaVersion = lexer.LuaVersionAll
+	g.classFactories = []ClassFactory{}
 
 	bytes, err := ioutil.ReadFile(strPath)
 	if err != nil {
 		log.Debug("not find %s file", configFileName)
 		// 没有读取到配置文件，设置一些默认值，忽略特定的告警
 		g.handleNotJSONCheckFlag(checkFlagList, ignoreFileOrDir, ignoreFileOrDirErr)
+		g.updateLuaVersion()
 		return nil
 	}
 
@@ -626,9 +737,33 @@ func (g *GlobalConfig) ReadConfig(strDir, configFileName string, checkFlagList [
 	}
 
 	g.anntotateSets = jsonConfig.AnntotateSets
+	g.classFactories = jsonConfig.ClassFactories
+	for i := range g.classFactories {
+		if len(g.classFactories[i].NewFuncs) == 0 {
+			g.classFactories[i].NewFuncs = []string{"new"}
+		}
+	}
+	g.formatConfig = jsonConfig.Format
+	g.baselineMode = jsonConfig.BaselineMode
+	g.SetJSONSeverityConfig(jsonConfig.Severity, jsonConfig.SeverityOverrides)
+	g.setGlobalDeclareConfig(jsonConfig.AllowGlobals, jsonConfig.GlobalDefineFiles)
+	if jsonConfig.Baseline != "" {
+		g.baselineFile = jsonConfig.Baseline
+		if !filepath.IsAbs(g.baselineFile) {
+			g.baselineFile = filepath.Join(filepath.Dir(strPath), g.baselineFile)
+		}
+	}
 
 	// 读取到了json文件
 	g.ReadJSONFlag = true
+	g.configFilePath = strPath
+
+	if luaVersion, ok := lexer.ParseLuaVersion(jsonConfig.LuaVersion); ok {
+		g.jsonLuaVersion = luaVersion
+	} else {
+		log.Error("LuaVersion=%s is not valid", jsonConfig.LuaVersion)
+	}
+	g.updateLuaVersion()
 
 	if jsonConfig.BaseDir == "" {
 		jsonConfig.BaseDir = "./"
@@ -754,6 +889,11 @@ func (g *GlobalConfig) ReadConfig(strDir, configFileName string, checkFlagList [
 	return nil
 }
 
+// GetConfigFilePath 获取读取到的json配置文件的完整路径
+func (g *GlobalConfig) GetConfigFilePath() string {
+	return g.configFilePath
+}
+
 // SetRequirePathSeparator 设置require其他lua文件时候的路径分割符
 func (g *GlobalConfig) SetRequirePathSeparator(pathSeparator string) {
 	if g.ReadJSONFlag {
@@ -775,8 +915,60 @@ func (g *GlobalConfig) SetPreviewFieldsNum(num int) {
 	}
 }
 
+// SetFormatConfig 设置代码格式化的配置
+func (g *GlobalConfig) SetFormatConfig(formatConfig FormatConfig) {
+	g.formatConfig = formatConfig
+}
+
+// GetFormatConfig 获取代码格式化的配置
+func (g *GlobalConfig) GetFormatConfig() FormatConfig {
+	return g.formatConfig
+}
+
+// GetLuaVersion 获取当前生效的Lua版本
+func (g *GlobalConfig) GetLuaVersion() lexer.LuaVersion {
+	return g.luaVersion
+}
+
+// SetClientLuaVersion 设置客户端配置的Lua版本，返回生效的版本是否有变化
+func (g *GlobalConfig) SetClientLuaVersion(strVersion string) bool {
+	luaVersion, ok := lexer.ParseLuaVersion(strVersion)
+	if !ok {
+		log.Error("client LuaVersion=%s is not valid", strVersion)
+	}
+
+	g.clientLuaVersion = luaVersion
+	return g.updateLuaVersion()
+}
+
+// updateLuaVersion 更新生效的Lua版本，luahelper.json中配置了时以json中的为准
+// 版本有变化时重新生成系统库的提示，返回版本是否有变化
+func (g *GlobalConfig) updateLuaVersion() bool {
+	luaVersion := g.clientLuaVersion
+	if g.ReadJSONFlag && g.jsonLuaVersion != lexer.LuaVersionAll {
+		luaVersion = g.jsonLuaVersion
+	}
+
+	if luaVersion == g.luaVersion {
+		return false
+	}
+
+	log.Debug("lua version change to %s", luaVersion)
+	g.luaVersion = luaVersion
+	g.InitSystemTips()
+	return true
+}
+
+// GetBaselineConfig 获取基线文件的全路径与基线中告警的展示方式
+func (g *GlobalConfig) GetBaselineConfig() (baselineFile string, baselineMode string) {
+	return g.baselineFile, g.baselineMode
+}
+
 // InsertIngoreSystemModule 如果为本地形式运行，加载不了插件前端的Lua额外文件夹，忽略系统模块。批量插入
 func (g *GlobalConfig) InsertIngoreSystemModule() {
+	// 当前Lua版本中不存在的系统符号，不进行忽略，用户配置的除外
+	unsupportedVec := g.getUnsupportedSysGlobals()
+
 	g.IgnoreVarMap["debug"] = "module"
 	g.IgnoreVarMap["math"] = "module"
 	g.IgnoreVarMap["os"] = "module"
@@ -822,6 +1014,10 @@ func (g *GlobalConfig) InsertIngoreSystemModule() {
 	g.IgnoreVarMap["xpcall"] = "function"
 	g.IgnoreVarMap["unpack"] = "function"
 	g.IgnoreVarMap["require"] = "function"
+
+	for _, strName := range unsupportedVec {
+		delete(g.IgnoreVarMap, strName)
+	}
 }
 
 // InsertIngoreSystemAnnotateType 当为本地运行时，忽略系统的注解类型type。批量插入
@@ -871,7 +1067,12 @@ func (g *GlobalConfig) IsIgnoreErrorFile(strFile string, errType CheckErrorType)
 		return true
 	}
 
-	if _, ok := g.IgnoreErrorTypeMap[errType]; ok {
+	// 配置了告警级别时，以配置的为准
+	if severity, ok := g.getConfigSeverity(strFile, errType); ok {
+		if severity == CESOff {
+			return true
+		}
+	} else if _, ok := g.IgnoreErrorTypeMap[errType]; ok {
 		return true
 	}
 
@@ -1264,6 +1465,11 @@ func (g *GlobalConfig) GetPathSeparator() string {
 
 // IsGlobalIgnoreErrType 判断指定类型的告警是否被全局屏蔽了
 func (g *GlobalConfig) IsGlobalIgnoreErrType(errorType CheckErrorType) bool {
+	// 配置了告警级别时，只要有目录开启了该告警，就需要检查
+	if enable, ok := g.isSeverityEnable(errorType); ok {
+		return !enable
+	}
+
 	_, flag := g.IgnoreErrorTypeMap[errorType]
 	return flag
 }
@@ -1319,6 +1525,17 @@ func (g *GlobalConfig) MatchAnnotateSet(funcName string) (flag bool, oneSet Annt
 	return
 }
 
+// MatchClassFactory 匹配配置的构造类的工厂函数
+func (g *GlobalConfig) MatchClassFactory(funcName string) (flag bool, oneFactory *ClassFactory) {
+	for i := range g.classFactories {
+		if g.classFactories[i].FuncName == funcName {
+			return true, &g.classFactories[i]
+		}
+	}
+
+	return
+}
+
 // IsSpecialCheck 判断是否需要进行特殊的关联检查
 func (g *GlobalConfig) IsSpecialCheck() bool {
 	if !g.showWarnFlag {
@@ -1331,7 +1548,7 @@ func (g *GlobalConfig) IsSpecialCheck() bool {
 
 	// 判断是否屏蔽了上面的告警类型
 	for _, errType := range errTypeList {
-		if _, ok := g.IgnoreErrorTypeMap[errType]; !ok {
+		if !g.IsGlobalIgnoreErrType(errType) {
 			return true
 		}
 	}
@@ -0,0 +1,140 @@
+package common
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// 全局变量的声明，用于检查赋值时拼写错误创建的全局变量，全局变量在下面的情况下认为是声明过的
+// 1) luahelper.json中AllowGlobals配置的变量名
+// 2) luahelper.json中GlobalDefineFiles匹配的文件，这些文件中定义的全局变量
+// 3) ---@meta 注解的声明文件中定义的全局变量，这些变量放入SysVarMap中，当做系统的全局变量
+
+// setGlobalDeclareConfig 设置允许赋值的全局变量名，以及允许定义全局变量的文件
+func (g *GlobalConfig) setGlobalDeclareConfig(allowGlobals []string, defineFiles []string) {
+	g.allowGlobalMap = map[string]bool{}
+	for _, strName := range allowGlobals {
+		g.allowGlobalMap[strName] = true
+	}
+
+	g.globalDefineFiles = []string{}
+	for _, strPattern := range defineFiles {
+		strPattern = strings.TrimPrefix(filepath.ToSlash(strPattern), "./")
+		if strPattern == "" {
+			continue
+		}
+		g.globalDefineFiles = append(g.globalDefineFiles, strPattern)
+	}
+}
+
+// IsGlobalDefineFile 判断文件是否匹配配置的允许定义全局变量的文件
+// 配置的为glob格式，相对于工程的根目录，**匹配任意层级的目录；不包含/时，也匹配文件名
+func (g *GlobalConfig) IsGlobalDefineFile(strFile string) bool {
+	if len(g.globalDefineFiles) == 0 || strFile == "" {
+		return false
+	}
+
+	absFile := filepath.ToSlash(strFile)
+	relFile := ""
+	if rootDir := g.dirManager.GetVsRootDir(); rootDir != "" {
+		if oneRel, err := filepath.Rel(rootDir, strFile); err == nil && !strings.HasPrefix(oneRel, "..") {
+			relFile = filepath.ToSlash(oneRel)
+		}
+	}
+
+	for _, strPattern := range g.globalDefineFiles {
+		if filepath.IsAbs(filepath.FromSlash(strPattern)) {
+			if matchGlobPath(strPattern, absFile) {
+				return true
+			}
+			continue
+		}
+
+		if relFile != "" && matchGlobPath(strPattern, relFile) {
+			return true
+		}
+
+		if !strings.Contains(strPattern, "/") && matchGlobPath(strPattern, path.Base(absFile)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlobPath 路径是否匹配glob格式，路径与glob都以/分割，**匹配零个或多个目录
+func matchGlobPath(strPattern string, strPath string) bool {
+	return matchGlobSegments(strings.Split(strPattern, "/"), strings.Split(strPath, "/"))
+}
+
+// matchGlobSegments 逐层匹配路径的每一段
+func matchGlobSegments(patternVec []string, pathVec []string) bool {
+	for len(patternVec) > 0 {
+		if patternVec[0] == "**" {
+			for i := 0; i <= len(pathVec); i++ {
+				if matchGlobSegments(patternVec[1:], pathVec[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(pathVec) == 0 {
+			return false
+		}
+
+		if ok, err := path.Match(patternVec[0], pathVec[0]); err != nil || !ok {
+			return false
+		}
+
+		patternVec = patternVec[1:]
+		pathVec = pathVec[1:]
+	}
+
+	return len(pathVec) == 0
+}
+
+// RebuildDeclareGlobals 第一阶段分析完所有的文件后，重新构建声明过的全局变量
+// metaVarMap 为声明文件中定义的全局变量，放入SysVarMap中；defineNameMap 为允许定义全局变量的文件中定义的变量名
+// 调用方持有请求的写锁，这里构建新的map后整体替换，不修改原来的map，之前获取到旧map的地方不受影响
+func (g *GlobalConfig) RebuildDeclareGlobals(metaVarMap map[string]*VarInfo, defineNameMap map[string]bool) {
+	// 1) 拷贝系统的全局变量，去掉之前放入的声明文件的全局变量
+	sysVarMap := make(map[string]*VarInfo, len(g.SysVarMap)+len(metaVarMap))
+	for strName, varInfo := range g.SysVarMap {
+		if g.metaGlobalMap[strName] == varInfo {
+			continue
+		}
+
+		sysVarMap[strName] = varInfo
+	}
+
+	// 2) 放入新的声明文件的全局变量，与系统的全局变量同名时，以系统的为准
+	metaGlobalMap := map[string]*VarInfo{}
+	for strName, varInfo := range metaVarMap {
+		if _, ok := sysVarMap[strName]; ok {
+			continue
+		}
+
+		sysVarMap[strName] = varInfo
+		metaGlobalMap[strName] = varInfo
+	}
+
+	g.SysVarMap = sysVarMap
+	g.metaGlobalMap = metaGlobalMap
+	g.defineGlobalMap = defineNameMap
+}
+
+// IsDeclareGlobal 判断全局变量是否声明过，包括系统的、声明文件中的、允许定义全局变量的文件中的以及配置允许的
+func (g *GlobalConfig) IsDeclareGlobal(strName string) bool {
+	if g.allowGlobalMap[strName] || g.defineGlobalMap[strName] {
+		return true
+	}
+
+	if _, ok := g.SysVarMap[strName]; ok {
+		return true
+	}
+
+	_, ok := g.LuaInMap[strName]
+	return ok
+}
@@ -133,6 +133,120 @@ type OneColorResut struct {
 	LocVec []lexer.Location
 }
 
+// SemanticType 语义着色的符号类型
+type SemanticType int
+
+const (
+	// STLocal 局部变量
+	STLocal SemanticType = 0
+
+	// STParameter 函数参数
+	STParameter SemanticType = 1
+
+	// STUpvalue 上值，函数内引用的外层函数的局部变量
+	STUpvalue SemanticType = 2
+
+	// STGlobal 全局变量
+	STGlobal SemanticType = 3
+
+	// STFunction 函数
+	STFunction SemanticType = 4
+
+	// STClass 注解定义的class
+	STClass SemanticType = 5
+
+	// STConst 常量，<const>属性或是注解标记的const
+	STConst SemanticType = 6
+
+	// STEnumMember 枚举成员
+	STEnumMember SemanticType = 7
+)
+
+// SemanticToken 单个语义着色的符号
+type SemanticToken struct {
+	Loc         lexer.Location // 符号的位置信息
+	Type        SemanticType   // 符号的类型
+	Declaration bool           // 是否为定义的地方
+	Deprecated  bool           // 定义处的注释是否标记了@deprecated
+}
+
+// InlayHintType inlay hint的种类
+type InlayHintType int
+
+const (
+	// IHParamName 函数调用处的参数名称
+	IHParamName InlayHintType = 0
+
+	// IHLocalType 局部变量定义处推导的类型
+	IHLocalType InlayHintType = 1
+
+	// IHReturnType local a, b = f() 这样多返回值赋值时，每个变量推导的类型
+	IHReturnType InlayHintType = 2
+)
+
+// InlayHintConfig 每种inlay hint是否开启
+type InlayHintConfig struct {
+	ParamName  bool // 是否显示参数名称
+	LocalType  bool // 是否显示局部变量推导的类型
+	ReturnType bool // 是否显示多返回值赋值的类型
+}
+
+// InlayHintInfo 单个inlay hint
+type InlayHintInfo struct {
+	Line      int           // 显示的行，从1开始
+	Character int           // 显示的列，从0开始
+	Label     string        // 显示的内容
+	HintType  InlayHintType // hint的种类
+}
+
+// CallHierarchyInfo 调用层级中的单个函数
+type CallHierarchyInfo struct {
+	Name      string         // 函数的名称，匿名函数为function
+	ClassName string         // 例如 function table.func() end // table即ClassName
+	FileName  string         // 函数所在的lua文件
+	Loc       lexer.Location // 整个函数的位置信息
+	NameLoc   lexer.Location // 函数名的位置信息
+	MainFlag  bool           // 是否为文件的主函数，即文件最外层的代码
+}
+
+// CallHierarchyCall 调用层级中的一个调用关系
+type CallHierarchyCall struct {
+	Func       CallHierarchyInfo // 调用者或是被调用的函数
+	CallLocVec []lexer.Location  // 所有调用发生的位置，在调用者的文件中
+}
+
+// TypeHierarchyInfo 类型层级中的单个类型
+type TypeHierarchyInfo struct {
+	Name      string         // 类型的名称
+	FileName  string         // 类型定义所在的lua文件
+	Loc       lexer.Location // 类型名称的位置信息
+	ClassFlag bool           // 是否为注解定义的class，否则为setmetatable推导出的类型
+}
+
+// FoldingKind 折叠区域的种类
+type FoldingKind int
+
+const (
+	// FKCode 代码块，例如函数、if、table构造等
+	FKCode FoldingKind = 0
+
+	// FKComment 连续多行的注释
+	FKComment FoldingKind = 1
+
+	// FKRegion ---@enum start 与 ---@enum end之间的区域
+	FKRegion FoldingKind = 2
+
+	// FKImports 连续的require语句
+	FKImports FoldingKind = 3
+)
+
+// FoldingRangeInfo 单个折叠区域
+type FoldingRangeInfo struct {
+	StartLine int         // 开始的行，从1开始
+	EndLine   int         // 结束的行，从1开始，包含该行
+	Kind      FoldingKind // 折叠区域的种类
+}
+
 // CheckReferenceSrc 查找引用的方式
 type CheckReferenceSrc int
 
@@ -0,0 +1,603 @@
+package common
+
+import (
+	"luahelper-lsp/langserver/check/compiler/ast"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"sync"
+)
+
+// 根据控制流收窄变量的类型，例如下面的例子，分支内x只可能为table类型，x = nil 的判断后x不为nil
+// if type(x) == "table" then
+//     print(x.a)
+// end
+// if not x then return end
+
+// NarrowInfo 变量根据控制流收窄后的类型信息，类型为lua的基础类型名称，例如string、table、nil
+type NarrowInfo struct {
+	TypeMap    map[string]bool // 变量只可能为这些类型，为nil时表示没有限制
+	NotTypeMap map[string]bool // 变量不可能为这些类型
+	AssignFlag bool            // 变量在前面重新赋值了，定义处的类型可能不再有效
+}
+
+// narrowBaseTypeMap 注解中的类型名称对应的lua基础类型名称
+var narrowBaseTypeMap = map[string]string{
+	"nil":           "nil",
+	"void":          "nil",
+	"boolean":       "boolean",
+	"bool":          "boolean",
+	"true":          "boolean",
+	"false":         "boolean",
+	"number":        "number",
+	"integer":       "number",
+	"float":         "number",
+	"string":        "string",
+	"table":         "table",
+	"function":      "function",
+	"userdata":      "userdata",
+	"lightuserdata": "userdata",
+	"thread":        "thread",
+}
+
+// GetNarrowBaseType 注解中的类型名称对应的lua基础类型名称，不是基础类型时返回false
+func GetNarrowBaseType(strType string) (string, bool) {
+	strBase, ok := narrowBaseTypeMap[strType]
+	return strBase, ok
+}
+
+// IsMatchType lua的基础类型是否满足收窄的条件
+func (n *NarrowInfo) IsMatchType(strType string) bool {
+	if n.NotTypeMap[strType] {
+		return false
+	}
+
+	if n.TypeMap != nil && !n.TypeMap[strType] {
+		return false
+	}
+
+	return true
+}
+
+// createNarrowInfo 创建收窄的信息，notFlag为true时表示变量不可能为这些类型
+func createNarrowInfo(notFlag bool, typeList ...string) *NarrowInfo {
+	typeMap := map[string]bool{}
+	for _, strType := range typeList {
+		typeMap[strType] = true
+	}
+
+	if notFlag {
+		return &NarrowInfo{
+			NotTypeMap: typeMap,
+		}
+	}
+
+	return &NarrowInfo{
+		TypeMap: typeMap,
+	}
+}
+
+// assignNarrowInfo 变量重新赋值后的收窄信息，之前的收窄信息都不再有效
+func assignNarrowInfo() *NarrowInfo {
+	return &NarrowInfo{
+		AssignFlag: true,
+	}
+}
+
+// mergeNarrowInfo 两个收窄的条件同时成立，例如 a and b 为真
+func mergeNarrowInfo(one *NarrowInfo, two *NarrowInfo) *NarrowInfo {
+	if one == nil {
+		return two
+	}
+	if two == nil {
+		return one
+	}
+
+	newInfo := &NarrowInfo{
+		NotTypeMap: map[string]bool{},
+		AssignFlag: one.AssignFlag || two.AssignFlag,
+	}
+	for strType := range one.NotTypeMap {
+		newInfo.NotTypeMap[strType] = true
+	}
+	for strType := range two.NotTypeMap {
+		newInfo.NotTypeMap[strType] = true
+	}
+
+	if one.TypeMap == nil && two.TypeMap == nil {
+		return newInfo
+	}
+
+	newInfo.TypeMap = map[string]bool{}
+	for _, oneMap := range []map[string]bool{one.TypeMap, two.TypeMap} {
+		for strType := range oneMap {
+			if one.IsMatchType(strType) && two.IsMatchType(strType) {
+				newInfo.TypeMap[strType] = true
+			}
+		}
+	}
+
+	return newInfo
+}
+
+// unionNarrowInfo 两个收窄的条件其中一个成立，例如 a or b 为真
+func unionNarrowInfo(one *NarrowInfo, two *NarrowInfo) *NarrowInfo {
+	if one == nil || two == nil {
+		return nil
+	}
+
+	newInfo := &NarrowInfo{}
+	if one.TypeMap != nil && two.TypeMap != nil {
+		newInfo.TypeMap = map[string]bool{}
+		for _, oneMap := range []map[string]bool{one.TypeMap, two.TypeMap} {
+			for strType := range oneMap {
+				if one.IsMatchType(strType) || two.IsMatchType(strType) {
+					newInfo.TypeMap[strType] = true
+				}
+			}
+		}
+		return newInfo
+	}
+
+	for _, oneMap := range []map[string]bool{one.NotTypeMap, two.NotTypeMap} {
+		for strType := range oneMap {
+			if one.IsMatchType(strType) || two.IsMatchType(strType) {
+				continue
+			}
+
+			if newInfo.NotTypeMap == nil {
+				newInfo.NotTypeMap = map[string]bool{}
+			}
+			newInfo.NotTypeMap[strType] = true
+		}
+	}
+
+	if len(newInfo.NotTypeMap) == 0 {
+		return nil
+	}
+	return newInfo
+}
+
+// isTypeCallExp 表达式是否为 type(strName) 的调用
+func isTypeCallExp(exp ast.Exp, strName string) bool {
+	callExp, ok := exp.(*ast.FuncCallExp)
+	if !ok || callExp.NameExp != nil || len(callExp.Args) != 1 {
+		return false
+	}
+
+	nameExp, ok := callExp.PrefixExp.(*ast.NameExp)
+	if !ok || nameExp.Name != "type" {
+		return false
+	}
+
+	argExp, ok := callExp.Args[0].(*ast.NameExp)
+	return ok && argExp.Name == strName
+}
+
+// getCompareNarrowType 获取比较表达式判断的类型，例如 type(x) == "table" 返回table，x == nil 返回nil
+func getCompareNarrowType(exp1 ast.Exp, exp2 ast.Exp, strName string) (string, bool) {
+	if isTypeCallExp(exp1, strName) {
+		if strExp, ok := exp2.(*ast.StringExp); ok {
+			return strExp.Str, true
+		}
+		return "", false
+	}
+
+	if nameExp, ok := exp1.(*ast.NameExp); ok && nameExp.Name == strName {
+		if _, ok := exp2.(*ast.NilExp); ok {
+			return "nil", true
+		}
+	}
+
+	return "", false
+}
+
+// getCondNarrowInfo 获取条件表达式为真或为假时，变量收窄的信息，没有收窄时返回nil
+func getCondNarrowInfo(exp ast.Exp, strName string, trueFlag bool) *NarrowInfo {
+	switch subExp := exp.(type) {
+	case *ast.ParensExp:
+		return getCondNarrowInfo(subExp.Exp, strName, trueFlag)
+	case *ast.NameExp:
+		if subExp.Name != strName {
+			return nil
+		}
+
+		if trueFlag {
+			return createNarrowInfo(true, "nil")
+		}
+		return createNarrowInfo(false, "nil", "boolean")
+	case *ast.UnopExp:
+		if subExp.Op == lexer.TkOpNot {
+			return getCondNarrowInfo(subExp.Exp, strName, !trueFlag)
+		}
+	case *ast.BinopExp:
+		switch subExp.Op {
+		case lexer.TkOpAnd:
+			one := getCondNarrowInfo(subExp.Exp1, strName, trueFlag)
+			two := getCondNarrowInfo(subExp.Exp2, strName, trueFlag)
+			if trueFlag {
+				return mergeNarrowInfo(one, two)
+			}
+			return unionNarrowInfo(one, two)
+		case lexer.TkOpOr:
+			one := getCondNarrowInfo(subExp.Exp1, strName, trueFlag)
+			two := getCondNarrowInfo(subExp.Exp2, strName, trueFlag)
+			if trueFlag {
+				return unionNarrowInfo(one, two)
+			}
+			return mergeNarrowInfo(one, two)
+		case lexer.TkOpEq, lexer.TkOpNe:
+			strType, ok := getCompareNarrowType(subExp.Exp1, subExp.Exp2, strName)
+			if !ok {
+				strType, ok = getCompareNarrowType(subExp.Exp2, subExp.Exp1, strName)
+			}
+			if !ok {
+				return nil
+			}
+
+			eqFlag := (subExp.Op == lexer.TkOpEq) == trueFlag
+			return createNarrowInfo(!eqFlag, strType)
+		}
+	}
+
+	return nil
+}
+
+// isNodeAssignName 语法节点中是否对变量重新赋值了
+func isNodeAssignName(node interface{}, strName string) (assignFlag bool) {
+	ast.Inspect(node, func(subNode interface{}) bool {
+		if assignFlag {
+			return false
+		}
+
+		assignStat, ok := subNode.(*ast.AssignStat)
+		if !ok {
+			return true
+		}
+
+		for _, varExp := range assignStat.VarList {
+			if nameExp, ok := varExp.(*ast.NameExp); ok && nameExp.Name == strName {
+				assignFlag = true
+				return false
+			}
+		}
+		return true
+	})
+
+	return assignFlag
+}
+
+// isNodeDeclareLocal 语法节点中是否定义了指定名称的局部变量，包括函数的参数以及for循环的变量
+func isNodeDeclareLocal(node interface{}, strName string) (declareFlag bool) {
+	ast.Inspect(node, func(subNode interface{}) bool {
+		if declareFlag {
+			return false
+		}
+
+		switch subExp := subNode.(type) {
+		case *ast.FuncDefExp:
+			declareFlag = isNameInList(strName, subExp.ParList)
+		case *ast.ForNumStat:
+			declareFlag = subExp.VarName == strName
+		case *ast.ForInStat:
+			declareFlag = isNameInList(strName, subExp.NameList)
+		case ast.Stat:
+			declareFlag = IsStatDeclareLocal(subExp, strName)
+		}
+		return !declareFlag
+	})
+
+	return declareFlag
+}
+
+// isNameInList 变量名是否在列表中
+func isNameInList(strName string, nameList []string) bool {
+	for _, oneName := range nameList {
+		if oneName == strName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isLocBeforePos 位置信息是否在传入的坐标之前
+func isLocBeforePos(loc lexer.Location, posLine int, posCol int) bool {
+	if loc.EndLine != posLine {
+		return loc.EndLine < posLine
+	}
+
+	return loc.EndColumn <= posCol
+}
+
+// isPosBeforeLoc 传入的坐标是否在位置信息的开始之前
+func isPosBeforeLoc(loc lexer.Location, posLine int, posCol int) bool {
+	if loc.StartLine != posLine {
+		return posLine < loc.StartLine
+	}
+
+	return posCol < loc.StartColumn
+}
+
+// narrowKey 收窄信息缓存的key，变量名与出现的位置
+type narrowKey struct {
+	strName string
+	posLine int
+	posCol  int
+}
+
+// NarrowCache 单个文件变量收窄信息的缓存，文件内容变化后ast重新生成，缓存也跟着重新创建
+// 各轮分析与lsp的请求会在多个协程中同时查找，用锁保护
+type NarrowCache struct {
+	block      *ast.Block
+	errorLocal bool // 文件中是否有名为error的局部变量，有时error()的调用不作为跳出
+	infoMap    map[narrowKey]*NarrowInfo
+	mutex      sync.Mutex
+}
+
+// CreateNarrowCache 创建文件变量收窄信息的缓存
+func CreateNarrowCache(block *ast.Block) *NarrowCache {
+	return &NarrowCache{
+		block:      block,
+		errorLocal: block != nil && isNodeDeclareLocal(block, "error"),
+		infoMap:    map[narrowKey]*NarrowInfo{},
+	}
+}
+
+// GetNarrowInfo 获取变量在指定位置，根据控制流收窄的类型信息，没有收窄时返回nil
+// 返回的收窄信息是共用的，不能修改。posLine 行号从1开始，posCol 列号从0开始
+func (n *NarrowCache) GetNarrowInfo(strName string, posLine int, posCol int) *NarrowInfo {
+	if n == nil || n.block == nil {
+		return nil
+	}
+
+	key := narrowKey{
+		strName: strName,
+		posLine: posLine,
+		posCol:  posCol,
+	}
+
+	n.mutex.Lock()
+	info, ok := n.infoMap[key]
+	n.mutex.Unlock()
+	if ok {
+		return info
+	}
+
+	info = n.narrowBlock(n.block, strName, posLine, posCol, nil)
+
+	n.mutex.Lock()
+	n.infoMap[key] = info
+	n.mutex.Unlock()
+	return info
+}
+
+// narrowBlock 在代码块中查找坐标所在的语句，前面的语句可能会收窄变量的类型
+func (n *NarrowCache) narrowBlock(block *ast.Block, strName string, posLine int, posCol int, info *NarrowInfo) *NarrowInfo {
+	if block == nil {
+		return info
+	}
+
+	for _, stat := range block.Stats {
+		loc := GetStatLoc(stat)
+		if loc.IsInitialLoc() {
+			continue
+		}
+
+		if loc.IsInLocStruct(posLine, posCol) {
+			return n.narrowStat(stat, strName, posLine, posCol, info)
+		}
+
+		if !isLocBeforePos(loc, posLine, posCol) {
+			return info
+		}
+
+		info = n.narrowAfterStat(stat, strName, info)
+	}
+
+	for _, exp := range block.RetExps {
+		loc := GetExpLoc(exp)
+		if loc.IsInLocStruct(posLine, posCol) {
+			return n.narrowExp(exp, strName, posLine, posCol, info)
+		}
+	}
+
+	return info
+}
+
+// narrowAfterStat 执行完一个语句后，变量收窄的信息
+func (n *NarrowCache) narrowAfterStat(stat ast.Stat, strName string, info *NarrowInfo) *NarrowInfo {
+	switch subStat := stat.(type) {
+	case *ast.LocalVarDeclStat:
+		if isNameInList(strName, subStat.NameList) {
+			return nil
+		}
+	case *ast.LocalFuncDefStat:
+		if subStat.Name == strName {
+			return nil
+		}
+	case *ast.AssignStat:
+		if isNodeAssignName(subStat, strName) {
+			return assignNarrowInfo()
+		}
+	case *ast.FuncCallStat:
+		// assert(x) 后面x不为nil
+		nameExp, ok := subStat.PrefixExp.(*ast.NameExp)
+		if ok && subStat.NameExp == nil && nameExp.Name == "assert" && len(subStat.Args) > 0 {
+			return mergeNarrowInfo(info, getCondNarrowInfo(subStat.Args[0], strName, true))
+		}
+	case *ast.IfStat:
+		if isNodeAssignName(subStat, strName) {
+			return assignNarrowInfo()
+		}
+
+		// 所有的分支都跳出了且没有else，后面的语句所有的条件都为假，例如 if not x then return end
+		for i, exp := range subStat.Exps {
+			if _, ok := exp.(*ast.TrueExp); ok || i >= len(subStat.Blocks) || !IsBlockTerminate(subStat.Blocks[i], false, n.errorLocal) {
+				return info
+			}
+		}
+
+		for _, exp := range subStat.Exps {
+			info = mergeNarrowInfo(info, getCondNarrowInfo(exp, strName, false))
+		}
+	case *ast.DoStat, *ast.WhileStat, *ast.RepeatStat, *ast.ForNumStat, *ast.ForInStat:
+		if isNodeAssignName(subStat, strName) {
+			return assignNarrowInfo()
+		}
+	}
+
+	return info
+}
+
+// narrowStat 坐标在语句内，获取变量收窄的信息
+func (n *NarrowCache) narrowStat(stat ast.Stat, strName string, posLine int, posCol int, info *NarrowInfo) *NarrowInfo {
+	switch subStat := stat.(type) {
+	case *ast.IfStat:
+		for i, exp := range subStat.Exps {
+			expLoc := GetExpLoc(exp)
+			if expLoc.IsInLocStruct(posLine, posCol) {
+				return n.narrowExp(exp, strName, posLine, posCol, info)
+			}
+
+			// 坐标在这个条件之后，下一个条件之前时，在这个分支的代码块内
+			if i < len(subStat.Blocks) && (i+1 == len(subStat.Exps) ||
+				isPosBeforeLoc(GetExpLoc(subStat.Exps[i+1]), posLine, posCol)) {
+				return n.narrowBlock(subStat.Blocks[i], strName, posLine, posCol,
+					mergeNarrowInfo(info, getCondNarrowInfo(exp, strName, true)))
+			}
+
+			info = mergeNarrowInfo(info, getCondNarrowInfo(exp, strName, false))
+		}
+	case *ast.WhileStat:
+		// 循环体内重新赋值了，条件只对第一次循环有效
+		if isNodeAssignName(subStat.Block, strName) {
+			info = assignNarrowInfo()
+		}
+
+		expLoc := GetExpLoc(subStat.Exp)
+		if expLoc.IsInLocStruct(posLine, posCol) {
+			return n.narrowExp(subStat.Exp, strName, posLine, posCol, info)
+		}
+
+		return n.narrowBlock(subStat.Block, strName, posLine, posCol,
+			mergeNarrowInfo(info, getCondNarrowInfo(subStat.Exp, strName, true)))
+	case *ast.RepeatStat:
+		if isNodeAssignName(subStat.Block, strName) {
+			info = assignNarrowInfo()
+		}
+
+		expLoc := GetExpLoc(subStat.Exp)
+		if expLoc.IsInLocStruct(posLine, posCol) {
+			return n.narrowExp(subStat.Exp, strName, posLine, posCol, info)
+		}
+
+		return n.narrowBlock(subStat.Block, strName, posLine, posCol, info)
+	case *ast.ForNumStat:
+		for _, exp := range []ast.Exp{subStat.InitExp, subStat.LimitExp, subStat.StepExp} {
+			expLoc := GetExpLoc(exp)
+			if expLoc.IsInLocStruct(posLine, posCol) {
+				return n.narrowExp(exp, strName, posLine, posCol, info)
+			}
+		}
+
+		if subStat.VarName == strName {
+			info = nil
+		} else if isNodeAssignName(subStat.Block, strName) {
+			info = assignNarrowInfo()
+		}
+		return n.narrowBlock(subStat.Block, strName, posLine, posCol, info)
+	case *ast.ForInStat:
+		for _, exp := range subStat.ExpList {
+			expLoc := GetExpLoc(exp)
+			if expLoc.IsInLocStruct(posLine, posCol) {
+				return n.narrowExp(exp, strName, posLine, posCol, info)
+			}
+		}
+
+		if isNameInList(strName, subStat.NameList) {
+			info = nil
+		} else if isNodeAssignName(subStat.Block, strName) {
+			info = assignNarrowInfo()
+		}
+		return n.narrowBlock(subStat.Block, strName, posLine, posCol, info)
+	case *ast.DoStat:
+		return n.narrowBlock(subStat.Block, strName, posLine, posCol, info)
+	case *ast.LocalFuncDefStat:
+		return n.narrowExp(subStat.Exp, strName, posLine, posCol, info)
+	case *ast.LocalVarDeclStat:
+		return n.narrowExpList(subStat.ExpList, strName, posLine, posCol, info)
+	case *ast.AssignStat:
+		if newInfo, ok := n.narrowInExpList(subStat.VarList, strName, posLine, posCol, info); ok {
+			return newInfo
+		}
+		return n.narrowExpList(subStat.ExpList, strName, posLine, posCol, info)
+	case *ast.FuncCallStat:
+		return n.narrowExp(subStat, strName, posLine, posCol, info)
+	}
+
+	return info
+}
+
+// narrowInExpList 坐标在表达式列表的某个表达式内时，获取变量收窄的信息
+func (n *NarrowCache) narrowInExpList(expList []ast.Exp, strName string, posLine int, posCol int,
+	info *NarrowInfo) (*NarrowInfo, bool) {
+	for _, exp := range expList {
+		expLoc := GetExpLoc(exp)
+		if expLoc.IsInLocStruct(posLine, posCol) {
+			return n.narrowExp(exp, strName, posLine, posCol, info), true
+		}
+	}
+
+	return info, false
+}
+
+// narrowExpList 坐标在表达式列表内，获取变量收窄的信息
+func (n *NarrowCache) narrowExpList(expList []ast.Exp, strName string, posLine int, posCol int,
+	info *NarrowInfo) *NarrowInfo {
+	info, _ = n.narrowInExpList(expList, strName, posLine, posCol, info)
+	return info
+}
+
+// narrowExp 坐标在表达式内，获取变量收窄的信息，例如 x and x.a 中后面的x不为nil
+func (n *NarrowCache) narrowExp(exp ast.Exp, strName string, posLine int, posCol int, info *NarrowInfo) *NarrowInfo {
+	switch subExp := exp.(type) {
+	case *ast.ParensExp:
+		return n.narrowExp(subExp.Exp, strName, posLine, posCol, info)
+	case *ast.UnopExp:
+		return n.narrowExp(subExp.Exp, strName, posLine, posCol, info)
+	case *ast.BinopExp:
+		expLoc := GetExpLoc(subExp.Exp1)
+		if expLoc.IsInLocStruct(posLine, posCol) {
+			return n.narrowExp(subExp.Exp1, strName, posLine, posCol, info)
+		}
+
+		switch subExp.Op {
+		case lexer.TkOpAnd:
+			info = mergeNarrowInfo(info, getCondNarrowInfo(subExp.Exp1, strName, true))
+		case lexer.TkOpOr:
+			info = mergeNarrowInfo(info, getCondNarrowInfo(subExp.Exp1, strName, false))
+		}
+		return n.narrowExp(subExp.Exp2, strName, posLine, posCol, info)
+	case *ast.FuncCallExp:
+		expLoc := GetExpLoc(subExp.PrefixExp)
+		if expLoc.IsInLocStruct(posLine, posCol) {
+			return n.narrowExp(subExp.PrefixExp, strName, posLine, posCol, info)
+		}
+		return n.narrowExpList(subExp.Args, strName, posLine, posCol, info)
+	case *ast.TableAccessExp:
+		expLoc := GetExpLoc(subExp.PrefixExp)
+		if expLoc.IsInLocStruct(posLine, posCol) {
+			return n.narrowExp(subExp.PrefixExp, strName, posLine, posCol, info)
+		}
+		return n.narrowExp(subExp.KeyExp, strName, posLine, posCol, info)
+	case *ast.TableConstructorExp:
+		if newInfo, ok := n.narrowInExpList(subExp.KeyExps, strName, posLine, posCol, info); ok {
+			return newInfo
+		}
+		return n.narrowExpList(subExp.ValExps, strName, posLine, posCol, info)
+	case *ast.FuncDefExp:
+		// 函数内的代码执行的时机不确定，外面的收窄信息不再有效
+		return n.narrowBlock(subExp.Block, strName, posLine, posCol, nil)
+	}
+
+	return info
+}
@@ -0,0 +1,234 @@
+package common
+
+import (
+	"luahelper-lsp/langserver/log"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// severityAllRule 配置中表示所有规则的名称
+const severityAllRule = "*"
+
+// SeverityOverride 指定目录下的告警级别，覆盖全局的告警级别
+type SeverityOverride struct {
+	Path     string            `json:"Path"`     // 目录，相对于工程的根目录
+	Severity map[string]string `json:"Severity"` // key为规则名称，*表示所有的规则；value为error、warning、information、hint、off
+}
+
+// severityRuleConf 解析后的一组告警级别
+type severityRuleConf struct {
+	ruleMap     map[CheckErrorType]CheckErrorSeverity // 每个规则的级别
+	allFlag     bool                                  // 是否配置了*
+	allSeverity CheckErrorSeverity                    // *对应的级别
+}
+
+// severityPathConf 解析后的单个目录的告警级别
+type severityPathConf struct {
+	path string // 目录，统一使用/分割，不以/结尾
+	conf severityRuleConf
+}
+
+// severityConf 解析后完整的告警级别配置
+type severityConf struct {
+	global  severityRuleConf
+	pathVec []severityPathConf // 按目录的长度从长到短排序，优先匹配更深的目录
+}
+
+// parseSeverityRuleConf 解析一组告警级别的配置，不合法的规则或级别忽略掉
+func parseSeverityRuleConf(severityMap map[string]string) (ruleConf severityRuleConf) {
+	ruleConf.ruleMap = map[CheckErrorType]CheckErrorSeverity{}
+	for ruleName, strSeverity := range severityMap {
+		var severity CheckErrorSeverity
+		if strings.ToLower(strings.TrimSpace(strSeverity)) == "off" {
+			severity = CESOff
+		} else {
+			var ok bool
+			if severity, ok = ParseCheckErrorSeverity(strSeverity); !ok {
+				log.Error("severity config rule=%s, severity=%s is not valid", ruleName, strSeverity)
+				continue
+			}
+		}
+
+		if ruleName == severityAllRule {
+			ruleConf.allFlag = true
+			ruleConf.allSeverity = severity
+			continue
+		}
+
+		errType, ok := GetCheckErrorTypeByName(ruleName)
+		if !ok {
+			log.Error("severity config rule=%s is not valid", ruleName)
+			continue
+		}
+		ruleConf.ruleMap[errType] = severity
+	}
+
+	return ruleConf
+}
+
+// get 获取规则配置的级别，ruleFlag 为true时只获取明确配置了规则名称的级别，不包含*的配置
+func (r *severityRuleConf) get(errType CheckErrorType, ruleFlag bool) (CheckErrorSeverity, bool) {
+	if severity, ok := r.ruleMap[errType]; ok {
+		return severity, true
+	}
+
+	if r.allFlag && !ruleFlag {
+		return r.allSeverity, true
+	}
+
+	return CESOff, false
+}
+
+// createSeverityConf 解析全局的告警级别以及目录的覆盖配置
+func createSeverityConf(severityMap map[string]string, overrideVec []SeverityOverride) (conf severityConf) {
+	conf.global = parseSeverityRuleConf(severityMap)
+	for _, oneOverride := range overrideVec {
+		strPath := strings.TrimSuffix(filepath.ToSlash(oneOverride.Path), "/")
+		strPath = strings.TrimPrefix(strPath, "./")
+		if strPath == "" || strPath == "." {
+			log.Error("severity override path=%s is not valid", oneOverride.Path)
+			continue
+		}
+
+		conf.pathVec = append(conf.pathVec, severityPathConf{
+			path: strPath,
+			conf: parseSeverityRuleConf(oneOverride.Severity),
+		})
+	}
+
+	sort.SliceStable(conf.pathVec, func(i, j int) bool {
+		return len(conf.pathVec[i].path) > len(conf.pathVec[j].path)
+	})
+	return conf
+}
+
+// isMatchPath 文件是否在配置的目录下面
+func (s *severityPathConf) isMatchPath(relFile string, absFile string) bool {
+	if filepath.IsAbs(filepath.FromSlash(s.path)) {
+		return strings.HasPrefix(absFile, s.path+"/")
+	}
+
+	return relFile != "" && strings.HasPrefix(relFile, s.path+"/")
+}
+
+// getFileSeverity 获取文件中规则配置的级别，目录的配置优先
+func (c *severityConf) getFileSeverity(strFile string, rootDir string, errType CheckErrorType, ruleFlag bool) (
+	CheckErrorSeverity, bool) {
+	if len(c.pathVec) > 0 && strFile != "" {
+		absFile := filepath.ToSlash(strFile)
+		relFile := ""
+		if rootDir != "" {
+			if oneRel, err := filepath.Rel(rootDir, strFile); err == nil && !strings.HasPrefix(oneRel, "..") {
+				relFile = filepath.ToSlash(oneRel)
+			}
+		}
+
+		for i := range c.pathVec {
+			onePath := &c.pathVec[i]
+			if !onePath.isMatchPath(relFile, absFile) {
+				continue
+			}
+
+			if severity, ok := onePath.conf.get(errType, ruleFlag); ok {
+				return severity, true
+			}
+		}
+	}
+
+	return c.global.get(errType, ruleFlag)
+}
+
+// isEmpty 是否没有任何告警级别的配置
+func (c *severityConf) isEmpty() bool {
+	return len(c.global.ruleMap) == 0 && !c.global.allFlag && len(c.pathVec) == 0
+}
+
+// SetJSONSeverityConfig 设置luahelper.json中配置的告警级别
+func (g *GlobalConfig) SetJSONSeverityConfig(severityMap map[string]string, overrideVec []SeverityOverride) {
+	g.jsonSeverity = createSeverityConf(severityMap, overrideVec)
+}
+
+// SetClientSeverityConfig 设置客户端配置的告警级别，读取了luahelper.json时以json中的为准
+// 返回配置是否有变化
+func (g *GlobalConfig) SetClientSeverityConfig(severityMap map[string]string, overrideVec []SeverityOverride) bool {
+	newConf := createSeverityConf(severityMap, overrideVec)
+	if reflect.DeepEqual(newConf, g.clientSeverity) {
+		return false
+	}
+
+	g.clientSeverity = newConf
+	return !g.ReadJSONFlag
+}
+
+// getSeverityConf 获取当前生效的告警级别配置
+func (g *GlobalConfig) getSeverityConf() *severityConf {
+	if g.ReadJSONFlag {
+		return &g.jsonSeverity
+	}
+
+	return &g.clientSeverity
+}
+
+// getConfigSeverity 获取文件中告警类型配置的级别，没有配置时返回false
+func (g *GlobalConfig) getConfigSeverity(strFile string, errType CheckErrorType) (CheckErrorSeverity, bool) {
+	conf := g.getSeverityConf()
+	if conf.isEmpty() {
+		return CESOff, false
+	}
+
+	return conf.getFileSeverity(strFile, g.dirManager.GetVsRootDir(), errType, false)
+}
+
+// IsOpenErrType 判断需要开启才检查的告警类型，在文件中是否开启
+// OpenErrorTypes中配置了，或是告警级别中明确配置了该规则且不为off时开启，*的配置不开启这些告警
+func (g *GlobalConfig) IsOpenErrType(strFile string, errType CheckErrorType) bool {
+	if _, ok := g.OpenErrorTypeMap[errType]; ok {
+		return true
+	}
+
+	conf := g.getSeverityConf()
+	if conf.isEmpty() {
+		return false
+	}
+
+	severity, ok := conf.getFileSeverity(strFile, g.dirManager.GetVsRootDir(), errType, true)
+	return ok && severity != CESOff
+}
+
+// GetErrorSeverity 获取文件中告警的级别，没有配置时为告警类型默认的级别
+func (g *GlobalConfig) GetErrorSeverity(strFile string, errType CheckErrorType) CheckErrorSeverity {
+	if severity, ok := g.getConfigSeverity(strFile, errType); ok && severity != CESOff {
+		return severity
+	}
+
+	return GetCheckErrorSeverity(errType)
+}
+
+// isSeverityEnable 告警级别的配置是否开启了告警类型，没有配置时返回false
+// 任何一个目录开启了该告警类型，都需要进行检查
+func (g *GlobalConfig) isSeverityEnable(errType CheckErrorType) (enable bool, ok bool) {
+	conf := g.getSeverityConf()
+	if conf.isEmpty() {
+		return false, false
+	}
+
+	if severity, globalOk := conf.global.get(errType, false); globalOk {
+		enable, ok = (severity != CESOff), true
+	} else {
+		_, ignoreFlag := g.IgnoreErrorTypeMap[errType]
+		enable = !ignoreFlag
+	}
+
+	for i := range conf.pathVec {
+		if severity, pathOk := conf.pathVec[i].conf.get(errType, false); pathOk {
+			ok = true
+			if severity != CESOff {
+				enable = true
+			}
+		}
+	}
+
+	return enable, ok
+}
@@ -986,6 +986,9 @@ func (g *GlobalConfig) InitSystemTips() {
 
 	g.SystemModuleTipsMap["utf8"] = utf8Module
 	g.insertSysVarInfo("utf8", nil, &utf8Module)
+
+	// 按照配置的Lua版本，调整系统的函数与模块
+	g.initVersionSystemTips()
 }
 
 // GetSysVar 根据传入的变量，看是否在SysVarMap 里面
@@ -0,0 +1,273 @@
+package common
+
+import (
+	"luahelper-lsp/langserver/check/compiler/lexer"
+)
+
+// 常用的版本集合
+var (
+	luaVersion51JIT   = []lexer.LuaVersion{lexer.LuaVersion51, lexer.LuaVersionJIT}
+	luaVersion52Plus  = []lexer.LuaVersion{lexer.LuaVersion52, lexer.LuaVersion53, lexer.LuaVersion54}
+	luaVersion53Plus  = []lexer.LuaVersion{lexer.LuaVersion53, lexer.LuaVersion54}
+	luaVersion53JIT   = []lexer.LuaVersion{lexer.LuaVersion53, lexer.LuaVersion54, lexer.LuaVersionJIT}
+	luaVersion52JIT   = []lexer.LuaVersion{lexer.LuaVersion52, lexer.LuaVersion53, lexer.LuaVersion54, lexer.LuaVersionJIT}
+	luaVersionJITOnly = []lexer.LuaVersion{lexer.LuaVersionJIT}
+)
+
+// sysVersionGlobal 只在部分Lua版本中存在的全局变量、函数或模块
+type sysVersionGlobal struct {
+	kind        string             // 与LuaInMap中的类型一致，var、function或module
+	versionList []lexer.LuaVersion // 支持的版本
+}
+
+// sysVersionGlobalMap 只在部分Lua版本中存在的全局符号，key为名称
+var sysVersionGlobalMap = map[string]sysVersionGlobal{
+	"unpack":     {"function", luaVersion51JIT},
+	"setfenv":    {"function", luaVersion51JIT},
+	"getfenv":    {"function", luaVersion51JIT},
+	"loadstring": {"function", luaVersion51JIT},
+	"module":     {"function", luaVersion51JIT},
+	"rawlen":     {"function", luaVersion52Plus},
+	"warn":       {"function", []lexer.LuaVersion{lexer.LuaVersion54}},
+	"_ENV":       {"var", luaVersion52Plus},
+	"bit32":      {"module", []lexer.LuaVersion{lexer.LuaVersion52}},
+	"utf8":       {"module", luaVersion53Plus},
+	"bit":        {"module", luaVersionJITOnly},
+	"jit":        {"module", luaVersionJITOnly},
+	"ffi":        {"module", luaVersionJITOnly},
+}
+
+// sysVersionMemberMap 系统模块中只在部分Lua版本中存在的成员，key为模块名，value的key为成员名
+var sysVersionMemberMap = map[string]map[string][]lexer.LuaVersion{
+	"table": {
+		"pack":   luaVersion52Plus,
+		"unpack": luaVersion52Plus,
+		"move":   luaVersion53JIT,
+		"maxn":   luaVersion51JIT,
+	},
+	"string": {
+		"pack":     luaVersion53Plus,
+		"packsize": luaVersion53Plus,
+		"unpack":   luaVersion53Plus,
+	},
+	"math": {
+		"tointeger":  luaVersion53Plus,
+		"type":       luaVersion53Plus,
+		"maxinteger": luaVersion53Plus,
+		"mininteger": luaVersion53Plus,
+	},
+	"coroutine": {
+		"isyieldable": luaVersion53JIT,
+	},
+	"package": {
+		"searchers":  luaVersion52Plus,
+		"searchpath": luaVersion52JIT,
+		"loaders":    luaVersion51JIT,
+	},
+	"debug": {
+		"getuservalue": luaVersion52Plus,
+		"setuservalue": luaVersion52Plus,
+		"upvalueid":    luaVersion52JIT,
+		"upvaluejoin":  luaVersion52JIT,
+	},
+}
+
+// getVersionSysTips 只在部分Lua版本中存在的全局函数的提示
+func getVersionSysTips() map[string]SystemNoticeInfo {
+	return map[string]SystemNoticeInfo{
+		"unpack": {
+			Detail:        "[_G] unpack(list, i, j)",
+			Documentation: "Returns the elements from the given list. This function is equivalent to return `list[i]`, `list[i+1]`, `···`, `list[j]`",
+			FuncParamVec:  []FuncParamInfo{{"list", "list : table"}, {"i", "i : number"}, {"j", "j : number"}},
+		},
+		"setfenv": {
+			Detail:        "[_G] setfenv(f, table)",
+			Documentation: "Sets the environment to be used by the given function. `f` can be a Lua function or a number that specifies the function at that stack level",
+			FuncParamVec:  []FuncParamInfo{{"f", "f : function"}, {"table", "table : table"}},
+		},
+		"getfenv": {
+			Detail:        "[_G] getfenv(f)",
+			Documentation: "Returns the current environment in use by the function. `f` can be a Lua function or a number that specifies the function at that stack level",
+			FuncParamVec:  []FuncParamInfo{{"f", "f : function"}},
+		},
+		"loadstring": {
+			Detail:        "[_G] loadstring(string, chunkname)",
+			Documentation: "Similar to `load`, but gets the chunk from the given string",
+			FuncParamVec:  []FuncParamInfo{{"string", "string : string"}, {"chunkname", "chunkname : string"}},
+		},
+		"module": {
+			Detail:        "[_G] module(name, ...)",
+			Documentation: "Creates a module. If there is a table in `package.loaded[name]`, this table is the module. Otherwise, if there is a global table `t` with the given name, this table is the module",
+			FuncParamVec:  []FuncParamInfo{{"name", "name : string"}, {"...", ""}},
+		},
+		"warn": {
+			Detail:        "[_G] warn(msg1, ...)",
+			Documentation: "Emits a warning with a message composed by the concatenation of all its arguments (which should be strings)",
+			FuncParamVec:  []FuncParamInfo{{"msg1", "msg1 : string"}, {"...", ""}},
+		},
+	}
+}
+
+// getVersionSysModules 只在部分Lua版本中存在的模块的提示
+func getVersionSysModules() map[string]OneModuleInfo {
+	bitModule := OneModuleInfo{
+		Detail:        "LuaJIT module",
+		Documentation: "bit module",
+		ModuleFuncMap: map[string]*SystemNoticeInfo{},
+	}
+	bitModule.ModuleFuncMap["tobit"] = &SystemNoticeInfo{"tobit(x)", "Normalizes a number to the numeric range for bit operations and returns it", []FuncParamInfo{{"x", "x : number"}}}
+	bitModule.ModuleFuncMap["tohex"] = &SystemNoticeInfo{"tohex(x, n)", "Converts its first argument to a hex string. The number of hex digits is given by the absolute value of the optional second argument", []FuncParamInfo{{"x", "x : number"}, {"n", "n : number"}}}
+	bitModule.ModuleFuncMap["bnot"] = &SystemNoticeInfo{"bnot(x)", "Returns the bitwise **not** of its argument", []FuncParamInfo{{"x", "x : number"}}}
+	bitModule.ModuleFuncMap["band"] = &SystemNoticeInfo{"band(x1, ...)", "Returns the bitwise **and** of all of its arguments", []FuncParamInfo{{"x1", "x1 : number"}, {"...", ""}}}
+	bitModule.ModuleFuncMap["bor"] = &SystemNoticeInfo{"bor(x1, ...)", "Returns the bitwise **or** of all of its arguments", []FuncParamInfo{{"x1", "x1 : number"}, {"...", ""}}}
+	bitModule.ModuleFuncMap["bxor"] = &SystemNoticeInfo{"bxor(x1, ...)", "Returns the bitwise **xor** of all of its arguments", []FuncParamInfo{{"x1", "x1 : number"}, {"...", ""}}}
+	bitModule.ModuleFuncMap["lshift"] = &SystemNoticeInfo{"lshift(x, n)", "Returns the bitwise logical left-shift of its first argument by the number of bits given by the second argument", []FuncParamInfo{{"x", "x : number"}, {"n", "n : number"}}}
+	bitModule.ModuleFuncMap["rshift"] = &SystemNoticeInfo{"rshift(x, n)", "Returns the bitwise logical right-shift of its first argument by the number of bits given by the second argument", []FuncParamInfo{{"x", "x : number"}, {"n", "n : number"}}}
+	bitModule.ModuleFuncMap["arshift"] = &SystemNoticeInfo{"arshift(x, n)", "Returns the bitwise arithmetic right-shift of its first argument by the number of bits given by the second argument", []FuncParamInfo{{"x", "x : number"}, {"n", "n : number"}}}
+	bitModule.ModuleFuncMap["rol"] = &SystemNoticeInfo{"rol(x, n)", "Returns the bitwise left rotation of its first argument by the number of bits given by the second argument", []FuncParamInfo{{"x", "x : number"}, {"n", "n : number"}}}
+	bitModule.ModuleFuncMap["ror"] = &SystemNoticeInfo{"ror(x, n)", "Returns the bitwise right rotation of its first argument by the number of bits given by the second argument", []FuncParamInfo{{"x", "x : number"}, {"n", "n : number"}}}
+	bitModule.ModuleFuncMap["bswap"] = &SystemNoticeInfo{"bswap(x)", "Swaps the bytes of its argument and returns it", []FuncParamInfo{{"x", "x : number"}}}
+
+	jitModule := OneModuleInfo{
+		Detail:        "LuaJIT module",
+		Documentation: "jit module",
+		ModuleFuncMap: map[string]*SystemNoticeInfo{},
+	}
+	jitModule.ModuleFuncMap["on"] = &SystemNoticeInfo{"on(func, recursive)", "Turns the whole JIT compiler on, or enables JIT compilation for a Lua function", []FuncParamInfo{{"func", "func : function"}, {"recursive", "recursive : boolean"}}}
+	jitModule.ModuleFuncMap["off"] = &SystemNoticeInfo{"off(func, recursive)", "Turns the whole JIT compiler off, or disables JIT compilation for a Lua function", []FuncParamInfo{{"func", "func : function"}, {"recursive", "recursive : boolean"}}}
+	jitModule.ModuleFuncMap["flush"] = &SystemNoticeInfo{"flush(func, recursive)", "Flushes the whole cache of compiled code, or the code for a Lua function", []FuncParamInfo{{"func", "func : function"}, {"recursive", "recursive : boolean"}}}
+	jitModule.ModuleFuncMap["status"] = &SystemNoticeInfo{"status()", "Returns the current status of the JIT compiler", []FuncParamInfo{}}
+	jitModule.ModuleVarVec = map[string]*SystemModuleVar{}
+	jitModule.ModuleVarVec["version"] = &SystemModuleVar{"version", "", "Contains the LuaJIT version string"}
+	jitModule.ModuleVarVec["version_num"] = &SystemModuleVar{"version_num", "", "Contains the version number of the LuaJIT core"}
+	jitModule.ModuleVarVec["os"] = &SystemModuleVar{"os", "", "Contains the target OS name"}
+	jitModule.ModuleVarVec["arch"] = &SystemModuleVar{"arch", "", "Contains the target architecture name"}
+	jitModule.ModuleVarVec["opt"] = &SystemModuleVar{"opt", "", "The JIT compiler optimization control module"}
+
+	ffiModule := OneModuleInfo{
+		Detail:        "LuaJIT module",
+		Documentation: "ffi module",
+		ModuleFuncMap: map[string]*SystemNoticeInfo{},
+	}
+	ffiModule.ModuleFuncMap["cdef"] = &SystemNoticeInfo{"cdef(def)", "Adds multiple C declarations for types or external symbols", []FuncParamInfo{{"def", "def : string"}}}
+	ffiModule.ModuleFuncMap["load"] = &SystemNoticeInfo{"load(name, global)", "Loads the dynamic library given by `name` and returns a new C library namespace which binds to its symbols", []FuncParamInfo{{"name", "name : string"}, {"global", "global : boolean"}}}
+	ffiModule.ModuleFuncMap["new"] = &SystemNoticeInfo{"new(ct, nelem, init, ...)", "Creates a cdata object for the given `ct`", []FuncParamInfo{{"ct", "ct : ctype"}, {"nelem", "nelem : number"}, {"init", "init : any"}, {"...", ""}}}
+	ffiModule.ModuleFuncMap["typeof"] = &SystemNoticeInfo{"typeof(ct)", "Creates a ctype object for the given `ct`", []FuncParamInfo{{"ct", "ct : ctype"}}}
+	ffiModule.ModuleFuncMap["cast"] = &SystemNoticeInfo{"cast(ct, init)", "Creates a scalar cdata object for the given `ct`, initialized with the C type conversion rules", []FuncParamInfo{{"ct", "ct : ctype"}, {"init", "init : any"}}}
+	ffiModule.ModuleFuncMap["metatype"] = &SystemNoticeInfo{"metatype(ct, metatable)", "Creates a ctype object for the given `ct` and associates it with a metatable", []FuncParamInfo{{"ct", "ct : ctype"}, {"metatable", "metatable : table"}}}
+	ffiModule.ModuleFuncMap["gc"] = &SystemNoticeInfo{"gc(cdata, finalizer)", "Associates a finalizer with a pointer or aggregate cdata object", []FuncParamInfo{{"cdata", "cdata : cdata"}, {"finalizer", "finalizer : function"}}}
+	ffiModule.ModuleFuncMap["sizeof"] = &SystemNoticeInfo{"sizeof(ct, nelem)", "Returns the size of `ct` in bytes", []FuncParamInfo{{"ct", "ct : ctype"}, {"nelem", "nelem : number"}}}
+	ffiModule.ModuleFuncMap["alignof"] = &SystemNoticeInfo{"alignof(ct)", "Returns the minimum required alignment for `ct` in bytes", []FuncParamInfo{{"ct", "ct : ctype"}}}
+	ffiModule.ModuleFuncMap["offsetof"] = &SystemNoticeInfo{"offsetof(ct, field)", "Returns the offset (in bytes) of `field` relative to the start of `ct`", []FuncParamInfo{{"ct", "ct : ctype"}, {"field", "field : string"}}}
+	ffiModule.ModuleFuncMap["istype"] = &SystemNoticeInfo{"istype(ct, obj)", "Returns true if `obj` has the C type given by `ct`", []FuncParamInfo{{"ct", "ct : ctype"}, {"obj", "obj : any"}}}
+	ffiModule.ModuleFuncMap["errno"] = &SystemNoticeInfo{"errno(newerr)", "Returns the error number set by the last C function call which indicated an error condition", []FuncParamInfo{{"newerr", "newerr : number"}}}
+	ffiModule.ModuleFuncMap["string"] = &SystemNoticeInfo{"string(ptr, len)", "Creates an interned Lua string from the data pointed to by `ptr`", []FuncParamInfo{{"ptr", "ptr : cdata"}, {"len", "len : number"}}}
+	ffiModule.ModuleFuncMap["copy"] = &SystemNoticeInfo{"copy(dst, src, len)", "Copies the data pointed to by `src` to `dst`", []FuncParamInfo{{"dst", "dst : cdata"}, {"src", "src : cdata"}, {"len", "len : number"}}}
+	ffiModule.ModuleFuncMap["fill"] = &SystemNoticeInfo{"fill(dst, len, c)", "Fills the data pointed to by `dst` with `len` constant bytes, given by `c`", []FuncParamInfo{{"dst", "dst : cdata"}, {"len", "len : number"}, {"c", "c : number"}}}
+	ffiModule.ModuleFuncMap["abi"] = &SystemNoticeInfo{"abi(param)", "Returns true if `param` (a Lua string) applies for the target ABI", []FuncParamInfo{{"param", "param : string"}}}
+	ffiModule.ModuleVarVec = map[string]*SystemModuleVar{}
+	ffiModule.ModuleVarVec["C"] = &SystemModuleVar{"C", "", "The default C library namespace"}
+	ffiModule.ModuleVarVec["os"] = &SystemModuleVar{"os", "", "Contains the target OS name"}
+	ffiModule.ModuleVarVec["arch"] = &SystemModuleVar{"arch", "", "Contains the target architecture name"}
+
+	return map[string]OneModuleInfo{
+		"bit": bitModule,
+		"jit": jitModule,
+		"ffi": ffiModule,
+	}
+}
+
+// insertVersionModuleMembers 已有的系统模块中，补充只在部分Lua版本中存在的成员
+func insertVersionModuleMembers(moduleMap map[string]OneModuleInfo) {
+	if tableModule, ok := moduleMap["table"]; ok {
+		tableModule.ModuleFuncMap["maxn"] = &SystemNoticeInfo{
+			Detail:        "maxn(table)",
+			Documentation: "Returns the largest positive numerical index of the given table, or zero if the table has no positive numerical indices",
+			FuncParamVec:  []FuncParamInfo{{"table", "table : table"}},
+		}
+	}
+
+	if packageModule, ok := moduleMap["package"]; ok && packageModule.ModuleVarVec != nil {
+		packageModule.ModuleVarVec["loaders"] = &SystemModuleVar{"loaders", "",
+			"A table used by `require` to control how to load modules"}
+	}
+}
+
+// initVersionSystemTips 按照配置的Lua版本，补充或删除系统的全局符号以及模块成员
+func (g *GlobalConfig) initVersionSystemTips() {
+	luaVersion := g.luaVersion
+
+	// 1) 全局函数
+	for strName, noticeInfo := range getVersionSysTips() {
+		if !luaVersion.IsIn(sysVersionGlobalMap[strName].versionList...) {
+			continue
+		}
+
+		noticeInfo := noticeInfo
+		g.SystemTipsMap[strName] = noticeInfo
+		g.insertSysVarInfo(strName, &noticeInfo, nil)
+	}
+
+	// 2) 模块
+	for strName, oneModule := range getVersionSysModules() {
+		if !luaVersion.IsIn(sysVersionGlobalMap[strName].versionList...) {
+			continue
+		}
+
+		oneModule := oneModule
+		g.SystemModuleTipsMap[strName] = oneModule
+		g.insertSysVarInfo(strName, nil, &oneModule)
+	}
+
+	// 3) 不支持的全局符号删除掉，LuaInMap中的全局符号不报未定义的告警
+	for strName, oneGlobal := range sysVersionGlobalMap {
+		if luaVersion.IsIn(oneGlobal.versionList...) {
+			g.LuaInMap[strName] = oneGlobal.kind
+			if oneGlobal.kind != "var" {
+				g.ignoreSysNoUseMap[strName] = true
+			}
+			continue
+		}
+
+		delete(g.SystemTipsMap, strName)
+		delete(g.SystemModuleTipsMap, strName)
+		delete(g.SysVarMap, strName)
+		delete(g.LuaInMap, strName)
+		delete(g.ignoreSysNoUseMap, strName)
+	}
+
+	// 4) 模块的成员
+	insertVersionModuleMembers(g.SystemModuleTipsMap)
+	for moduleName, memberMap := range sysVersionMemberMap {
+		oneModule, ok := g.SystemModuleTipsMap[moduleName]
+		if !ok {
+			continue
+		}
+
+		for memberName, versionList := range memberMap {
+			if luaVersion.IsIn(versionList...) {
+				continue
+			}
+
+			delete(oneModule.ModuleFuncMap, memberName)
+			delete(oneModule.ModuleVarVec, memberName)
+		}
+		g.insertOneModuleSubVars(moduleName, &oneModule)
+	}
+}
+
+// getUnsupportedSysGlobals 获取当前Lua版本中不存在，并且还没有被忽略的全局符号
+func (g *GlobalConfig) getUnsupportedSysGlobals() (nameVec []string) {
+	for strName, oneGlobal := range sysVersionGlobalMap {
+		if g.luaVersion.IsIn(oneGlobal.versionList...) {
+			continue
+		}
+
+		if _, ok := g.IgnoreVarMap[strName]; ok {
+			continue
+		}
+		nameVec = append(nameVec, strName)
+	}
+
+	return nameVec
+}
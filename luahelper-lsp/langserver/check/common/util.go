@@ -1031,6 +1031,42 @@ func GetExpLoc(node ast.Exp) (loc lexer.Location) {
 	return loc
 }
 
+// GetStatLoc 获取语句的位置信息
+func GetStatLoc(node ast.Stat) (loc lexer.Location) {
+	switch stat := node.(type) {
+	case *ast.LabelStat:
+		loc = stat.Loc
+	case *ast.GotoStat:
+		loc = stat.Loc
+	case *ast.ContinueStat:
+		loc = stat.Loc
+	case *ast.DoStat:
+		loc = stat.Loc
+	case *ast.IfStat:
+		loc = stat.Loc
+	case *ast.WhileStat:
+		loc = stat.Loc
+	case *ast.RepeatStat:
+		loc = stat.Loc
+	case *ast.ForNumStat:
+		loc = stat.Loc
+	case *ast.ForInStat:
+		loc = stat.Loc
+	case *ast.AssignStat:
+		loc = stat.Loc
+	case *ast.LocalVarDeclStat:
+		loc = stat.Loc
+	case *ast.LocalFuncDefStat:
+		loc = stat.Loc
+	case *ast.IllegalStat:
+		loc = stat.Loc
+	case *ast.FuncCallStat:
+		loc = stat.Loc
+	}
+
+	return loc
+}
+
 // ChangeFuncSelfToReferVar 冒号 函数，self语法进行转换
 // 判断是否为这样的在冒号函数内, self.b 这样的 self要进行转换为b，统一起来
 // a = {}
@@ -100,6 +100,10 @@ type VarInfo struct {
 	Loc             lexer.Location      // 初始定义的位置信息
 	NoUseAssignLocs []lexer.Location    // 局部变量定义了，未直接使用，后面有对其赋值，记录下所有的位置
 	ForCycle        *ForCycleInfo       // 关联的for循环的表达式
+	MetaExp         ast.Exp             // setmetatable(a, b)语句设置的原表表达式b，用于推导原表的继承关系
+	FactoryClass    *OneClassInfo       // 工厂函数构造的类，例如 local Foo = class("Foo", Base)，默认为nil
+	PassParamVec    []ParamPassInfo     // 变量作为实参传入文件内函数的信息，例如 fill(a)，默认为nil
+	ParamFunc       *FuncInfo           // 变量为函数的参数时，参数所在的函数，参数的序号为VarIndex-1，默认为nil
 	VarType         LuaType             // 变量定义的类型
 	VarIndex        uint8               // 当一行语句声明了多个变量时候，例如 local a, b 语句，显示变量的index，默认的为1，例子中a的index为1，b的index为2
 	IsParam         bool                // 是否为函数定义的参数，默认为false
@@ -134,6 +138,9 @@ type Symbol struct {
 	AnnotateComment string           // 注解引入的注释说明
 	StrPreComment   string           // 注解引入的hover或complete前置内容
 	StrPreClassName string           // 是注解class里面的field成员，值为class的name
+
+	// 泛型类实例化的成员，泛型名称对应的具体类型，例如 List<number> 的成员中T对应number
+	GenericMap map[string]annotateast.Type
 }
 
 // GetDefaultSymbol 获取默认的symbol指针
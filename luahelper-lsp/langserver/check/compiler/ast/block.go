@@ -14,5 +14,6 @@ import (
 type Block struct {
 	Stats   []Stat
 	RetExps []Exp
+	RetLoc  lexer.Location // return关键字的位置，没有return语句时为空
 	Loc     lexer.Location
 }
@@ -34,6 +34,12 @@ type BreakStat struct {
 	//Loc lexer.LocInfo
 }
 
+// ContinueStat continue语句，只有GLua支持
+// continue
+type ContinueStat struct {
+	Loc lexer.Location
+}
+
 // LabelStat goto对应的标识符
 // ‘::’ Name ‘::’
 type LabelStat struct {
@@ -0,0 +1,104 @@
+package ast
+
+// Visitor 遍历AST时，对每个节点调用的函数；返回false表示不再遍历该节点的子节点
+type Visitor func(node interface{}) bool
+
+// Inspect 深度优先遍历AST，node可以为*Block、Stat或Exp
+func Inspect(node interface{}, f Visitor) {
+	if isNilNode(node) {
+		return
+	}
+
+	if !f(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Block:
+		for _, stat := range n.Stats {
+			Inspect(stat, f)
+		}
+		for _, exp := range n.RetExps {
+			Inspect(exp, f)
+		}
+	case *DoStat:
+		Inspect(n.Block, f)
+	case *IfStat:
+		for i, exp := range n.Exps {
+			Inspect(exp, f)
+			if i < len(n.Blocks) {
+				Inspect(n.Blocks[i], f)
+			}
+		}
+	case *WhileStat:
+		Inspect(n.Exp, f)
+		Inspect(n.Block, f)
+	case *RepeatStat:
+		Inspect(n.Block, f)
+		Inspect(n.Exp, f)
+	case *ForNumStat:
+		Inspect(n.InitExp, f)
+		Inspect(n.LimitExp, f)
+		Inspect(n.StepExp, f)
+		Inspect(n.Block, f)
+	case *ForInStat:
+		for _, exp := range n.ExpList {
+			Inspect(exp, f)
+		}
+		Inspect(n.Block, f)
+	case *AssignStat:
+		for _, exp := range n.VarList {
+			Inspect(exp, f)
+		}
+		for _, exp := range n.ExpList {
+			Inspect(exp, f)
+		}
+	case *LocalVarDeclStat:
+		for _, exp := range n.ExpList {
+			Inspect(exp, f)
+		}
+	case *LocalFuncDefStat:
+		Inspect(n.Exp, f)
+	case *FuncCallExp:
+		Inspect(n.PrefixExp, f)
+		Inspect(n.NameExp, f)
+		for _, exp := range n.Args {
+			Inspect(exp, f)
+		}
+	case *FuncDefExp:
+		Inspect(n.Block, f)
+	case *ParensExp:
+		Inspect(n.Exp, f)
+	case *UnopExp:
+		Inspect(n.Exp, f)
+	case *BinopExp:
+		Inspect(n.Exp1, f)
+		Inspect(n.Exp2, f)
+	case *TableAccessExp:
+		Inspect(n.PrefixExp, f)
+		Inspect(n.KeyExp, f)
+	case *TableConstructorExp:
+		for i, valExp := range n.ValExps {
+			if i < len(n.KeyExps) {
+				Inspect(n.KeyExps[i], f)
+			}
+			Inspect(valExp, f)
+		}
+	}
+}
+
+// isNilNode 判断节点是否为空，包含interface里面为nil指针的情况
+func isNilNode(node interface{}) bool {
+	switch n := node.(type) {
+	case nil:
+		return true
+	case *Block:
+		return n == nil
+	case *StringExp:
+		return n == nil
+	case *FuncDefExp:
+		return n == nil
+	}
+
+	return false
+}
@@ -15,6 +15,12 @@ type ParseError struct {
 	ReadFileErr bool     // 读取文件是否失败
 }
 
+// NotEqualInfo GLua中不等于运算符的写法与位置
+type NotEqualInfo struct {
+	Str string   // 运算符的写法，!=或~=
+	Loc Location // 运算符的位置
+}
+
 // TooManyErr 当Parse太多语法错误的时候，终止
 type TooManyErr struct {
 	ErrNum int //  错误的数量
@@ -32,6 +38,7 @@ type CommentInfo struct {
 	LineVec   []CommentLine // 多行的内容存储
 	ShortFlag bool          // 是否是短注释，true表示短注释
 	HeadFlag  bool          // 是否为头部注释， 例如一行中 --这样开头的就为头部注释
+	StartLine int           // 注释开始的行号，长注释 --[[ ]] 可能跨越多行
 }
 
 // GetRangeLoc 获取两个位置的范围，为[]
@@ -26,23 +26,37 @@ type Token struct {
 	lineStartPos int    // this line start in all pos
 	rangeFromPos int    // token start in all pos
 	rangeToPos   int    // token end in all pos
+	byteFromPos  int    // token start byte offset in source
+	byteToPos    int    // token end byte offset in source
 }
 
 func (l *Token) GetLine() int {
 	return l.line
 }
 
+// GetKind 获取单词的类型
+func (l *Token) GetKind() TkKind {
+	return l.tokenKind
+}
+
+// GetByteRange 获取单词在源码中的字节范围[from, to)，源码包含可能的BOM头
+func (l *Token) GetByteRange() (from int, to int) {
+	return l.byteFromPos, l.byteToPos
+}
+
 // ErrorHandler 词法分析上报错误
 type ErrorHandler func(oneErr ParseError)
 
 // Lexer 词法分析的结构
 type Lexer struct {
-	chunk         string // source code
-	chunkName     string // source name
-	line          int    // current line number
-	lineStartPos  int    // this line start in all pos
-	tokenStartPos int    // token start in all pos
-	currentPos    int
+	source         string // all source code, chunk is the rest of it
+	chunk          string // source code
+	chunkName      string // source name
+	line           int    // current line number
+	lineStartPos   int    // this line start in all pos
+	tokenStartPos  int    // token start in all pos
+	currentPos     int
+	tokenStartByte int // token start byte offset in source
 
 	preToken   Token
 	nowToken   Token
@@ -51,12 +65,18 @@ type Lexer struct {
 	commentMap map[int]*CommentInfo // 保存所有的注释信息, key值为行号，从1开始。如果该注释有多行，为最后一行的行号。
 
 	errHandler ErrorHandler // error reporting; or nil
+
+	luaVersion LuaVersion // 分析的Lua版本，不支持的语法报错
+
+	notEqualVec []NotEqualInfo // GLua中所有的不等于运算符，用于检查!=与~=的混用
 }
 
 // NewLexer 创建一个词法分析器
 func NewLexer(chunk []byte, chunkName string) *Lexer {
+	source := strbytesconv.BytesToString(chunk)
 	return &Lexer{
-		chunk:     strbytesconv.BytesToString(chunk),
+		source:    source,
+		chunk:     source,
 		chunkName: chunkName,
 		line:      1,
 		preToken: Token{
@@ -80,6 +100,21 @@ func (l *Lexer) SetErrHandler(errHandler ErrorHandler) {
 	l.errHandler = errHandler
 }
 
+// SetLuaVersion 设置分析的Lua版本
+func (l *Lexer) SetLuaVersion(luaVersion LuaVersion) {
+	l.luaVersion = luaVersion
+}
+
+// GetLuaVersion 获取分析的Lua版本
+func (l *Lexer) GetLuaVersion() LuaVersion {
+	return l.luaVersion
+}
+
+// GetNotEqualVec 获取GLua中所有的不等于运算符，按出现的顺序
+func (l *Lexer) GetNotEqualVec() []NotEqualInfo {
+	return l.notEqualVec
+}
+
 // GetCommentMap 获取所有的注释map
 func (l *Lexer) GetCommentMap() map[int]*CommentInfo {
 	return l.commentMap
@@ -236,6 +271,8 @@ func (l *Lexer) setNowToken(kind TkKind, tokenStr string) {
 	l.nowToken.lineStartPos = l.lineStartPos
 	l.nowToken.rangeFromPos = l.tokenStartPos
 	l.nowToken.rangeToPos = l.currentPos
+	l.nowToken.byteFromPos = l.tokenStartByte
+	l.nowToken.byteToPos = len(l.source) - len(l.chunk)
 	l.nowToken.tokenKind = kind
 	l.nowToken.tokenStr = tokenStr
 }
@@ -251,6 +288,7 @@ func (l *Lexer) NextTokenStruct() {
 
 	l.skipWhiteSpaces()
 	l.tokenStartPos = l.currentPos
+	l.tokenStartByte = len(l.source) - len(l.chunk)
 
 	if len(l.chunk) == 0 {
 		// file end
@@ -309,17 +347,42 @@ func (l *Lexer) NextTokenStruct() {
 		l.setNowToken(TkOpMod, "%")
 		return
 	case '&':
+		if l.luaVersion.IsGLua() && l.test("&&") {
+			l.next(2)
+			l.setNowToken(TkOpAnd, "&&")
+			return
+		}
 		l.next(1)
 		l.setNowToken(TkOpBand, "&")
+		l.checkIntegerOp("&")
 		return
 	case '|':
+		if l.luaVersion.IsGLua() && l.test("||") {
+			l.next(2)
+			l.setNowToken(TkOpOr, "||")
+			return
+		}
 		l.next(1)
 		l.setNowToken(TkOpBor, "|")
+		l.checkIntegerOp("|")
 		return
 	case '#':
 		l.next(1)
 		l.setNowToken(TkOpNen, "#")
 		return
+	case '!':
+		// GLua中的!=与!，其他版本为非法的字符
+		if l.luaVersion.IsGLua() {
+			if l.test("!=") {
+				l.next(2)
+				l.setNowToken(TkOpNe, "!=")
+				l.insertNotEqual("!=")
+			} else {
+				l.next(1)
+				l.setNowToken(TkOpNot, "!")
+			}
+			return
+		}
 	case ':':
 		if l.test("::") {
 			l.next(2)
@@ -333,6 +396,7 @@ func (l *Lexer) NextTokenStruct() {
 		if l.test("//") {
 			l.next(2)
 			l.setNowToken(TkOpIdiv, "//")
+			l.checkIntegerOp("//")
 		} else {
 			l.next(1)
 			l.setNowToken(TkOpDiv, "/")
@@ -342,9 +406,11 @@ func (l *Lexer) NextTokenStruct() {
 		if l.test("~=") {
 			l.next(2)
 			l.setNowToken(TkOpNe, "~=")
+			l.insertNotEqual("~=")
 		} else {
 			l.next(1)
 			l.setNowToken(TkOpWave, "~")
+			l.checkIntegerOp("~")
 		}
 		return
 	case '=':
@@ -360,6 +426,7 @@ func (l *Lexer) NextTokenStruct() {
 		if l.test("<<") {
 			l.next(2)
 			l.setNowToken(TkOpShl, "<<")
+			l.checkIntegerOp("<<")
 		} else if l.test("<=") {
 			l.next(2)
 			l.setNowToken(TkOpLe, "<=")
@@ -372,6 +439,7 @@ func (l *Lexer) NextTokenStruct() {
 		if l.test(">>") {
 			l.next(2)
 			l.setNowToken(TkOpShr, ">>")
+			l.checkIntegerOp(">>")
 		} else if l.test(">=") {
 			l.next(2)
 			l.setNowToken(TkOpGe, ">=")
@@ -415,8 +483,11 @@ func (l *Lexer) NextTokenStruct() {
 
 	if c == '_' || isLetter(c) {
 		token := l.scanIdentifier()
-		if kind, ok := keywords[token]; ok {
+		if kind, ok := keywords[token]; ok && (kind != TkKwGoto || l.luaVersion.SupportGoto()) {
+			// Lua 5.1中goto不是关键字，可以作为变量名
 			l.setNowToken(kind, token)
+		} else if token == "continue" && l.luaVersion.IsGLua() {
+			l.setNowToken(TkKwContinue, token)
 		} else {
 			l.setNowToken(TkIdentifier, token)
 		}
@@ -432,6 +503,27 @@ func (l *Lexer) NextTokenStruct() {
 	}
 }
 
+// insertNotEqual GLua中记录不等于运算符的写法与位置
+func (l *Lexer) insertNotEqual(tokenStr string) {
+	if !l.luaVersion.IsGLua() {
+		return
+	}
+
+	l.notEqualVec = append(l.notEqualVec, NotEqualInfo{
+		Str: tokenStr,
+		Loc: l.GetNowTokenLoc(),
+	})
+}
+
+// checkIntegerOp 当前的Lua版本不支持整除以及位运算时，报错后继续分析
+func (l *Lexer) checkIntegerOp(tokenStr string) {
+	if l.luaVersion.SupportIntegerOp() {
+		return
+	}
+
+	l.errorPrint(l.GetNowTokenLoc(), "operator '%s' is not supported in %s", tokenStr, l.luaVersion)
+}
+
 func (l *Lexer) scanIllegalToken() (lineFlag bool, str string) {
 	i := 0
 	for i < len(l.chunk) {
@@ -521,6 +613,47 @@ func (l *Lexer) isPreComment() bool {
 	return false
 }
 
+// isCStyleComment 判断是否为GLua中 // 或 /* 开头的C风格注释
+func (l *Lexer) isCStyleComment() bool {
+	if !l.luaVersion.IsGLua() {
+		return false
+	}
+
+	return l.test("//") || l.test("/*")
+}
+
+// skipCStyleComment 跳过GLua中C风格的注释，注释的内容不用于注解
+func (l *Lexer) skipCStyleComment() {
+	if l.test("//") {
+		for len(l.chunk) > 0 && !isNewLine(l.chunk[0]) {
+			l.next(1)
+		}
+		return
+	}
+
+	l.next(2) // skip /*
+	for len(l.chunk) > 0 {
+		if l.test("*/") {
+			l.next(2)
+			return
+		}
+
+		if l.isEnterWrap() {
+			l.next(2)
+			l.line++
+			l.lineStartPos = l.currentPos
+		} else if isNewLine(l.chunk[0]) {
+			l.next(1)
+			l.line++
+			l.lineStartPos = l.currentPos
+		} else {
+			l.next(1)
+		}
+	}
+
+	l.errorPrint(l.GetHeardTokenLoc(), "unfinished long comment")
+}
+
 // skipWhiteSpaces 跳过空格
 func (l *Lexer) skipWhiteSpaces() {
 	var commentInfo *CommentInfo
@@ -540,6 +673,9 @@ func (l *Lexer) skipWhiteSpaces() {
 		} else if isWhiteSpace(l.chunk[0]) {
 			l.next(1)
 			continue
+		} else if l.isCStyleComment() {
+			l.skipCStyleComment()
+			continue
 		} else if !l.isPreComment() {
 			break
 		}
@@ -557,6 +693,7 @@ func (l *Lexer) skipWhiteSpaces() {
 		}
 
 		startCol := l.currentPos - l.lineStartPos + 2
+		startLine := l.line
 		shortFlag, skipComment := l.skipComment()
 
 		// 剔除掉首行的注释 \n-- 当为[[ ]] 这样的注释是，会存在
@@ -565,6 +702,7 @@ func (l *Lexer) skipWhiteSpaces() {
 			commentInfo = &CommentInfo{
 				ShortFlag: shortFlag,
 				HeadFlag:  headFlag,
+				StartLine: startLine,
 			}
 
 			lastLine = l.line
@@ -594,6 +732,7 @@ func (l *Lexer) skipWhiteSpaces() {
 			commentInfo = &CommentInfo{
 				ShortFlag: shortFlag,
 				HeadFlag:  headFlag,
+				StartLine: startLine,
 			}
 		}
 
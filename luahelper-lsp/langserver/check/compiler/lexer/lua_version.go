@@ -0,0 +1,111 @@
+package lexer
+
+import "strings"
+
+// LuaVersion 分析的Lua版本，不同的版本支持的语法不同
+type LuaVersion int
+
+const (
+	// LuaVersionAll 不区分版本，支持所有版本语法的并集
+	LuaVersionAll LuaVersion = 0
+
+	// LuaVersion51 Lua 5.1
+	LuaVersion51 LuaVersion = 1
+
+	// LuaVersion52 Lua 5.2
+	LuaVersion52 LuaVersion = 2
+
+	// LuaVersion53 Lua 5.3
+	LuaVersion53 LuaVersion = 3
+
+	// LuaVersion54 Lua 5.4
+	LuaVersion54 LuaVersion = 4
+
+	// LuaVersionJIT LuaJIT 2.x，语法以5.1为基础，支持goto以及64位整数的后缀
+	LuaVersionJIT LuaVersion = 5
+
+	// LuaVersionGLua Garry's Mod的GLua，以LuaJIT为基础，额外支持!=、&&、||、!、continue以及C风格的注释
+	LuaVersionGLua LuaVersion = 6
+)
+
+// luaVersionNames 版本的名称，下标为版本
+var luaVersionNames = []string{"", "Lua 5.1", "Lua 5.2", "Lua 5.3", "Lua 5.4", "LuaJIT", "GLua"}
+
+// String 版本的名称
+func (v LuaVersion) String() string {
+	if v <= LuaVersionAll || int(v) >= len(luaVersionNames) {
+		return "all versions"
+	}
+
+	return luaVersionNames[v]
+}
+
+// ParseLuaVersion 配置的字符串转换为版本，例如 5.1、5.4、LuaJIT；为空时表示不区分版本
+func ParseLuaVersion(strVersion string) (LuaVersion, bool) {
+	strVersion = strings.ToLower(strings.TrimSpace(strVersion))
+	strVersion = strings.TrimPrefix(strVersion, "lua")
+	strVersion = strings.TrimSpace(strVersion)
+
+	switch strVersion {
+	case "":
+		return LuaVersionAll, true
+	case "5.1":
+		return LuaVersion51, true
+	case "5.2":
+		return LuaVersion52, true
+	case "5.3":
+		return LuaVersion53, true
+	case "5.4":
+		return LuaVersion54, true
+	case "jit", "luajit":
+		return LuaVersionJIT, true
+	case "glua", "gmod":
+		return LuaVersionGLua, true
+	}
+
+	return LuaVersionAll, false
+}
+
+// IsIn 版本是否在列表中，不区分版本时总是返回true，GLua按照LuaJIT判断
+func (v LuaVersion) IsIn(versionList ...LuaVersion) bool {
+	if v == LuaVersionAll {
+		return true
+	}
+
+	if v == LuaVersionGLua {
+		v = LuaVersionJIT
+	}
+
+	for _, oneVersion := range versionList {
+		if v == oneVersion {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SupportGoto 是否支持goto以及::label::，Lua 5.2开始支持，LuaJIT也支持
+func (v LuaVersion) SupportGoto() bool {
+	return v != LuaVersion51
+}
+
+// SupportIntegerOp 是否支持整除//以及位运算 & | ~ << >>，Lua 5.3开始支持
+func (v LuaVersion) SupportIntegerOp() bool {
+	return v.IsIn(LuaVersion53, LuaVersion54)
+}
+
+// SupportAttrib 是否支持局部变量的属性<const>、<close>，Lua 5.4开始支持
+func (v LuaVersion) SupportAttrib() bool {
+	return v.IsIn(LuaVersion54)
+}
+
+// SupportInt64Suffix 是否支持数字的LL、ULL后缀，只有LuaJIT支持
+func (v LuaVersion) SupportInt64Suffix() bool {
+	return v.IsIn(LuaVersionJIT)
+}
+
+// IsGLua 是否为GLua方言
+func (v LuaVersion) IsGLua() bool {
+	return v == LuaVersionGLua
+}
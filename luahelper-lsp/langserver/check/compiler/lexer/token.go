@@ -64,6 +64,7 @@ const (
 	TkKwTrue                        // true
 	TkKwUntil                       // until
 	TkKwWhile                       // while
+	TkKwContinue                    // continue, only GLua
 	TkIdentifier                    // identifier
 	TkNumber                        // number literal
 	TkString                        // string literal
@@ -132,6 +133,7 @@ var tokenKinds = [...]string{
 	TkKwTrue:     "true",           // true
 	TkKwUntil:    "until",          // until
 	TkKwWhile:    "while",          // while
+	TkKwContinue: "continue",       // continue
 	TkIdentifier: "identifier",     // identifier
 	TkNumber:     "number literal", // number literal
 	TkString:     "string literal", // string literal
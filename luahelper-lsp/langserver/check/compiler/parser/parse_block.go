@@ -7,10 +7,11 @@ import (
 
 // block ::= {stat} [retstat]
 func (p *Parser) parseBlock() *ast.Block {
-	return &ast.Block{
-		Stats:   p.parseStats(),
-		RetExps: p.parseRetExps(),
+	block := &ast.Block{
+		Stats: p.parseStats(),
 	}
+	block.RetExps, block.RetLoc = p.parseRetExps()
+	return block
 }
 
 func (p *Parser) parseStats() []ast.Stat {
@@ -26,26 +27,28 @@ func (p *Parser) parseStats() []ast.Stat {
 
 // retstat ::= return [explist] [‘;’]
 // explist ::= exp {‘,’ exp}
-func (p *Parser) parseRetExps() []ast.Exp {
+// 同时返回return关键字的位置
+func (p *Parser) parseRetExps() ([]ast.Exp, lexer.Location) {
 	l := p.l
 	if l.LookAheadKind() != lexer.TkKwReturn {
-		return nil
+		return nil, lexer.Location{}
 	}
 
 	l.NextToken()
+	retLoc := l.GetNowTokenLoc()
 	switch l.LookAheadKind() {
 	case lexer.TkEOF, lexer.TkKwEnd,
 		lexer.TkKwElse, lexer.TkKwElseif, lexer.TkKwUntil:
-		return []ast.Exp{}
+		return []ast.Exp{}, retLoc
 	case lexer.TkSepSemi:
 		l.NextToken()
-		return []ast.Exp{}
+		return []ast.Exp{}, retLoc
 	default:
 		exps := p.parseExpList()
 		if l.LookAheadKind() == lexer.TkSepSemi {
 			l.NextToken()
 		}
-		return exps
+		return exps, retLoc
 	}
 }
 
@@ -186,6 +186,9 @@ func (p *Parser) parseNumberExp() ast.Exp {
 			Loc: l.GetNowTokenLoc(),
 		}
 	} else if n, ok := parseLuajitNum(token); ok {
+		if luaVersion := l.GetLuaVersion(); !luaVersion.SupportInt64Suffix() {
+			p.insertParserErr(l.GetNowTokenLoc(), "number suffix of '%s' is not supported in %s", token, luaVersion)
+		}
 		return &ast.IntegerExp{
 			Val: n,
 			Loc: l.GetNowTokenLoc(),
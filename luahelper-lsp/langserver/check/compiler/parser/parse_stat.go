@@ -30,6 +30,8 @@ func (p *Parser) parseStat() ast.Stat {
 		return p.parseEmptyStat()
 	case lexer.TkKwBreak:
 		return p.parseBreakStat()
+	case lexer.TkKwContinue:
+		return p.parseContinueStat()
 	case lexer.TkSepLabel:
 		return p.parseLabelStat()
 	case lexer.TkKwGoto:
@@ -70,9 +72,19 @@ func (p *Parser) parseBreakStat() *ast.BreakStat {
 	}
 }
 
+// continue，只有GLua支持
+func (p *Parser) parseContinueStat() *ast.ContinueStat {
+	p.l.NextTokenKind(lexer.TkKwContinue)
+
+	return &ast.ContinueStat{
+		Loc: p.l.GetNowTokenLoc(),
+	}
+}
+
 // ‘::’ Name ‘::’
 func (p *Parser) parseLabelStat() *ast.LabelStat {
 	p.l.NextTokenKind(lexer.TkSepLabel) // ::
+	p.checkGotoVersion("label")
 	_, name := p.l.NextIdentifier()     // name
 	loc := p.l.GetNowTokenLoc()
 	p.l.NextTokenKind(lexer.TkSepLabel) // ::
@@ -85,13 +97,24 @@ func (p *Parser) parseLabelStat() *ast.LabelStat {
 // goto Name
 func (p *Parser) parseGotoStat() *ast.GotoStat {
 	p.l.NextTokenKind(lexer.TkKwGoto) // goto
-	_, name := p.l.NextIdentifier()   // name
+	p.checkGotoVersion("goto")
+	_, name := p.l.NextIdentifier() // name
 	return &ast.GotoStat{
 		Name: name,
 		Loc:  p.l.GetNowTokenLoc(),
 	}
 }
 
+// checkGotoVersion 当前的Lua版本不支持goto以及label时，报错后继续分析
+func (p *Parser) checkGotoVersion(strKind string) {
+	luaVersion := p.l.GetLuaVersion()
+	if luaVersion.SupportGoto() {
+		return
+	}
+
+	p.insertParserErr(p.l.GetNowTokenLoc(), "%s is not supported in %s", strKind, luaVersion)
+}
+
 // do block end
 func (p *Parser) parseDoStat() *ast.DoStat {
 	l := p.l
@@ -327,6 +350,10 @@ func (p *Parser) getLocalAttribute() ast.LocalAttr {
 	if l.LookAheadKind() == lexer.TkOpLt {
 		l.NextToken()
 		_, attr := l.NextIdentifier()
+		if luaVersion := l.GetLuaVersion(); !luaVersion.SupportAttrib() {
+			p.insertParserErr(l.GetNowTokenLoc(), "local variable attribute '%s' is not supported in %s", attr,
+				luaVersion)
+		}
 
 		if attr == "close" {
 			l.NextTokenKind(lexer.TkOpGt)
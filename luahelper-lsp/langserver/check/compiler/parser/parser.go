@@ -24,6 +24,11 @@ func CreateParser(chunk []byte, chunkName string) *Parser {
 	return parser
 }
 
+// SetLuaVersion 设置分析的Lua版本，不支持的语法会报错
+func (p *Parser) SetLuaVersion(luaVersion lexer.LuaVersion) {
+	p.l.SetLuaVersion(luaVersion)
+}
+
 // BeginAnalyze 开始分析
 func (p *Parser) BeginAnalyze() (block *ast.Block, commentMap map[int]*lexer.CommentInfo, errList []lexer.ParseError) {
 	defer func() {
@@ -64,6 +69,26 @@ func (p *Parser) GetErrList() (errList []lexer.ParseError) {
 	return p.parseErrs
 }
 
+// GetMixedNotEqual GLua中同一个文件混用了!=与~=时，返回先使用的写法、另外一种写法以及另外一种写法出现的位置
+func (p *Parser) GetMixedNotEqual() (firstStr string, otherStr string, locVec []lexer.Location) {
+	notEqualVec := p.l.GetNotEqualVec()
+	if len(notEqualVec) == 0 {
+		return "", "", nil
+	}
+
+	firstStr = notEqualVec[0].Str
+	for _, oneInfo := range notEqualVec {
+		if oneInfo.Str == firstStr {
+			continue
+		}
+
+		otherStr = oneInfo.Str
+		locVec = append(locVec, oneInfo.Loc)
+	}
+
+	return firstStr, otherStr, locVec
+}
+
 // insert now token info
 func (p *Parser) insertParserErr(loc lexer.Location, f string, a ...interface{}) {
 	err := fmt.Sprintf(f, a...)
@@ -522,3 +522,74 @@ func TestParseIllega1(t *testing.T) {
 		t.Logf("is nil")
 	}
 }
+
+func TestParseLuaVersion(t *testing.T) {
+	type versionCase struct {
+		content  string
+		version  lexer.LuaVersion
+		errorNum int
+	}
+
+	caseVec := []versionCase{
+		{"local a = 1 & 2 | 3 ~ 4 // 5 << 1 >> 1", lexer.LuaVersionAll, 0},
+		{"local a = 1 & 2 | 3 ~ 4 // 5 << 1 >> 1", lexer.LuaVersion53, 0},
+		{"local a = 1 & 2 | 3 ~ 4 // 5 << 1 >> 1", lexer.LuaVersionJIT, 6},
+		{"local a = ~1; local b = a ~= 2", lexer.LuaVersion52, 1},
+		{"goto continue; ::continue::", lexer.LuaVersion51, 3},
+		{"goto continue; ::continue::", lexer.LuaVersionJIT, 0},
+		{"local goto = 1; goto = goto + 1", lexer.LuaVersion51, 0},
+		{"local goto = 1", lexer.LuaVersion52, 1},
+		{"local a <const> = 1; local b <close> = nil", lexer.LuaVersion53, 2},
+		{"local a <const> = 1", lexer.LuaVersion54, 0},
+		{"local a = 1LL; local b = 0x2aULL", lexer.LuaVersion54, 2},
+		{"local a = 1LL; local b = 0x2aULL", lexer.LuaVersionJIT, 0},
+	}
+
+	for _, oneCase := range caseVec {
+		parser := CreateParser([]byte(oneCase.content), "test")
+		parser.SetLuaVersion(oneCase.version)
+		_, _, errList := parser.BeginAnalyze()
+		if len(errList) != oneCase.errorNum {
+			t.Fatalf("parser %s in %s, error num=%d, expect=%d, errList=%v", oneCase.content, oneCase.version,
+				len(errList), oneCase.errorNum, errList)
+		}
+	}
+}
+
+func TestParseGLua(t *testing.T) {
+	content := `
+// C style line comment
+local a = 1 /* block
+comment */ local b = 2
+if a != b && !(a == 3) || b ~= 4 then
+	print(a)
+end
+for i = 1, 10 do
+	if i == 2 then
+		continue
+	end
+end
+`
+	parser := CreateParser([]byte(content), "test")
+	parser.SetLuaVersion(lexer.LuaVersionGLua)
+	block, _, errList := parser.BeginAnalyze()
+	if len(errList) != 0 {
+		t.Fatalf("parser glua error, errList=%v", errList)
+	}
+
+	if len(block.Stats) != 4 {
+		t.Fatalf("parser glua stat num=%d, expect=4", len(block.Stats))
+	}
+
+	firstStr, otherStr, locVec := parser.GetMixedNotEqual()
+	if firstStr != "!=" || otherStr != "~=" || len(locVec) != 1 || locVec[0].StartLine != 5 {
+		t.Fatalf("mixed not equal error, first=%s, other=%s, locVec=%v", firstStr, otherStr, locVec)
+	}
+
+	// 其他的版本不支持GLua的语法
+	parser = CreateParser([]byte(content), "test")
+	parser.SetLuaVersion(lexer.LuaVersionJIT)
+	if _, _, errList = parser.BeginAnalyze(); len(errList) == 0 {
+		t.Fatalf("parser glua in LuaJIT should have error")
+	}
+}
@@ -47,8 +47,17 @@ type Projects interface {
 
 	GetAnnotateTypeString(varInfo *common.VarInfo, varName string, keyName string, idx int) (retVec []string)
 
+	// GetAnnotateNilFlag 变量定义处注解的类型是否可能为nil，findFlag 表示是否找到了注解的类型
+	GetAnnotateNilFlag(varInfo *common.VarInfo, varName string, idx int) (nilFlag bool, findFlag bool)
+
+	// IsAnnotateMapType 变量定义处注解的类型是否为map，例如 ---@type table<string, Foo>
+	IsAnnotateMapType(varInfo *common.VarInfo, varName string) bool
+
 	GetFuncParamType(fileName string, lastLine int) (retMap map[string][]annotateast.Type)
 
+	// GetFuncGenericType 获取函数注解的泛型，key为泛型名称，value为泛型的父类型
+	GetFuncGenericType(fileName string, lastLine int) (retMap map[string]string)
+
 	GetFuncParamTypeByClass(className string, funcName string) (retMap map[string][]string)
 	GetFuncReturnTypeByClass(className string, funcName string) (retVec [][]string)
 
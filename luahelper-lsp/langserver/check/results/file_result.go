@@ -46,6 +46,10 @@ type FileResult struct {
 	FuncIDVec    []*common.FuncInfo         // 保存的所有funcInfo信息，可以通过id来查找
 	funcID       int                        // 自增的funcID，默认值为0，每产生一个新的funcID自增1
 	CommentMap   map[int]*lexer.CommentInfo // 第一轮分析时候，保存所有的注释信息, key值为行号
+	Suppress     *common.FileSuppress       // 文件中---@diagnostic 屏蔽告警的注解，各轮分析共用第一轮的
+	NarrowCache  *common.NarrowCache        // 变量根据控制流收窄类型的缓存，各轮分析共用第一轮的
+	FactoryVec   []*common.OneClassInfo     // 第一轮分析时候，工厂函数构造的类，例如 local Foo = class("Foo", Base)
+	ExportInfo   *common.ExportInfo         // 第一轮分析时候，文件被require时导出的返回信息
 }
 
 // CreateFileResult 创建一个新的文件分析结果
@@ -170,6 +174,13 @@ func (f *FileResult) InsertRelateError(errType common.CheckErrorType, errStr str
 		return
 	}
 
+	// 判断是否被文件中的---@diagnostic 注解屏蔽
+	if f.Suppress.IsSuppressed(errType, loc) {
+		log.Debug("ErrType:%d, luaFile:%s, errorInfo:%s, loc[(%d, %d), (%d, %d)] is suppressed", errType,
+			f.Name, errStr, loc.StartLine, loc.StartColumn, loc.EndLine, loc.EndColumn)
+		return
+	}
+
 	log.Error("ErrType:%d, luaFile:%s, errorInfo:%s, loc[(%d, %d), (%d, %d)]", errType,
 		f.Name, errStr, loc.StartLine, loc.StartColumn, loc.EndLine, loc.EndColumn)
 
@@ -185,6 +196,34 @@ func (f *FileResult) InsertError(errType common.CheckErrorType, errStr string, l
 	f.InsertRelateError(errType, errStr, loc, nil)
 }
 
+// GetExportVarList 获取文件被require时导出的所有变量，只处理导出的表达式为变量名的
+func (f *FileResult) GetExportVarList() (varList []*common.VarInfo) {
+	var expList []ast.Exp
+	if f.ExportInfo != nil {
+		expList = f.ExportInfo.ExpList
+	} else if find, returnExp := f.MainFunc.GetLastOneReturnExp(); find {
+		expList = append(expList, returnExp)
+	}
+
+	for _, exp := range expList {
+		nameExp, ok := exp.(*ast.NameExp)
+		if !ok {
+			continue
+		}
+
+		varInfo, ok := f.MainFunc.MainScope.FindLocVar(nameExp.Name, nameExp.Loc)
+		if !ok {
+			_, varInfo = f.FindGlobalVarInfo(nameExp.Name, false, "")
+		}
+
+		if varInfo != nil {
+			varList = append(varList, varInfo)
+		}
+	}
+
+	return varList
+}
+
 // 在全局变量中，查找变量是否存在、指向的refer、指向的函数定义
 func (f *FileResult) FindGlobalVarInfo(strName string, gFlag bool, strProPre string) (bool, *common.VarInfo) {
 	var globalVar *common.VarInfo
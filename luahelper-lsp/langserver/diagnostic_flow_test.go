@@ -0,0 +1,72 @@
+package langserver
+
+import (
+	"context"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestDiagnosticControlFlow(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/flowcheck"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	ctx := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(ctx, initializeParams)
+	lspServer.GetAllDiagnostics(ctx)
+
+	fileName := strRootPath + "/" + "test1.lua"
+
+	// 不可达的语句、缺少返回值的函数与遮蔽的局部变量，告警所在的行
+	errLineMap := map[common.CheckErrorType]map[int]bool{}
+	for _, oneErr := range lspServer.fileErrorMap[fileName] {
+		if errLineMap[oneErr.ErrType] == nil {
+			errLineMap[oneErr.ErrType] = map[int]bool{}
+		}
+		errLineMap[oneErr.ErrType][oneErr.Loc.StartLine] = true
+	}
+
+	expectMap := map[common.CheckErrorType][]int{
+		common.CheckErrorUnreachable:   {4, 13, 18},
+		common.CheckErrorMissingReturn: {26, 78, 92},
+		common.CheckErrorShadowVar:     {54, 55, 56},
+	}
+	for errType, lineList := range expectMap {
+		if len(errLineMap[errType]) != len(lineList) {
+			t.Fatalf("diagnostics type=%d error, %v", errType, errLineMap[errType])
+		}
+
+		for _, line := range lineList {
+			if !errLineMap[errType][line] {
+				t.Fatalf("diagnostics type=%d not find line=%d, %v", errType, line, errLineMap[errType])
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package langserver
+
+import (
+	"context"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestDiagnosticGeneric(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/generic"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	ctx := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(ctx, initializeParams)
+	lspServer.GetAllDiagnostics(ctx)
+
+	fileName := strRootPath + "/" + "test1.lua"
+
+	// 1) 泛型类的参数T，只在类的field与成员函数的注解中有效，普通函数中引用告警
+	// 2) 泛型T由第一个参数推导为number，第二个参数为string时告警
+	// 3) 泛型T的父类型为number，返回string时告警
+	errLineMap := map[common.CheckErrorType][]int{}
+	for _, oneErr := range lspServer.fileErrorMap[fileName] {
+		errLineMap[oneErr.ErrType] = append(errLineMap[oneErr.ErrType], oneErr.Loc.StartLine)
+	}
+
+	expectMap := map[common.CheckErrorType][]int{
+		common.CheckErrorAnnotate:      {15},
+		common.CheckErrorCallParamType: {28},
+		common.CheckErrorFuncRetErr:    {34},
+	}
+	for errType, lineVec := range expectMap {
+		if len(errLineMap[errType]) != len(lineVec) || errLineMap[errType][0] != lineVec[0] {
+			t.Fatalf("generic diagnostics error, errType=%d, lines=%v", errType, errLineMap[errType])
+		}
+	}
+}
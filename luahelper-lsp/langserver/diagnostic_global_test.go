@@ -0,0 +1,73 @@
+package langserver
+
+import (
+	"context"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestDiagnosticGlobalAssign(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/globalassign"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	ctx := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(ctx, initializeParams)
+	lspServer.GetAllDiagnostics(ctx)
+
+	// 声明文件与允许定义全局变量的文件中，不告警
+	for _, strFile := range []string{"globals.lua", "config/game_config.lua"} {
+		for _, oneErr := range lspServer.fileErrorMap[strRootPath+"/"+strFile] {
+			if oneErr.ErrType == common.CheckErrorGlobalAssign {
+				t.Fatalf("file=%s should not have global assign diagnostic, line=%d", strFile, oneErr.Loc.StartLine)
+			}
+		}
+	}
+
+	// 未声明的全局变量赋值，告警所在的行
+	errLineMap := map[int]bool{}
+	for _, oneErr := range lspServer.fileErrorMap[strRootPath+"/test1.lua"] {
+		if oneErr.ErrType == common.CheckErrorGlobalAssign {
+			errLineMap[oneErr.Loc.StartLine] = true
+		}
+	}
+
+	// 第25行为全局函数的定义，与赋值语句一样检查
+	lineList := []int{2, 10, 19, 25}
+	if len(errLineMap) != len(lineList) {
+		t.Fatalf("global assign diagnostics error, %v", errLineMap)
+	}
+
+	for _, line := range lineList {
+		if !errLineMap[line] {
+			t.Fatalf("global assign diagnostics not find line=%d, %v", line, errLineMap)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package langserver
+
+import (
+	"context"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestDiagnosticMixedNotEqual(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/glua"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	ctx := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(ctx, initializeParams)
+	lspServer.GetAllDiagnostics(ctx)
+
+	// 文件中先使用了~=，只有第5行的!=告警，GLua的语法没有语法错误
+	fileName := strRootPath + "/" + "test1.lua"
+	var mixedVec []common.CheckError
+	for _, oneErr := range lspServer.fileErrorMap[fileName] {
+		if oneErr.ErrType == common.CheckErrorSyntax {
+			t.Fatalf("glua file should not have syntax error, %s", oneErr.ErrStr)
+		}
+
+		if oneErr.ErrType == common.CheckErrorMixedNotEqual {
+			mixedVec = append(mixedVec, oneErr)
+		}
+	}
+
+	if len(mixedVec) != 1 {
+		t.Fatalf("mixed not equal diagnostics num=%d, %v", len(mixedVec), mixedVec)
+	}
+
+	loc := mixedVec[0].Loc
+	if loc.StartLine != 5 || loc.StartColumn != 5 || loc.EndColumn != 7 {
+		t.Fatalf("mixed not equal diagnostics loc error, %v", loc)
+	}
+}
@@ -0,0 +1,59 @@
+package langserver
+
+import (
+	"context"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestDiagnosticNarrow(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/narrowcheck"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	ctx := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(ctx, initializeParams)
+	lspServer.GetAllDiagnostics(ctx)
+
+	fileName := strRootPath + "/" + "test1.lua"
+
+	// 1) 分支内变量收窄为number，调用参数类型不告警，分支外告警
+	// 2) 分支内为string时提前返回，后面的语句中变量收窄为number，不告警
+	var lineVec []int
+	for _, oneErr := range lspServer.fileErrorMap[fileName] {
+		if oneErr.ErrType == common.CheckErrorCallParamType {
+			lineVec = append(lineVec, oneErr.Loc.StartLine)
+		}
+	}
+
+	if len(lineVec) != 1 || lineVec[0] != 11 {
+		t.Fatalf("narrow call param type diagnostics error, lines=%v", lineVec)
+	}
+}
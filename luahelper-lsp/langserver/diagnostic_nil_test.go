@@ -0,0 +1,59 @@
+package langserver
+
+import (
+	"context"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestDiagnosticPossibleNil(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/nilcheck"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	ctx := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(ctx, initializeParams)
+	lspServer.GetAllDiagnostics(ctx)
+
+	fileName := strRootPath + "/" + "test1.lua"
+
+	// 只有没有判断过的one、four、p告警，判断过或是重新赋值的不告警
+	// 函数内的five执行时机不确定，外面的判断不再有效，也需要告警
+	nilLineMap := map[int]bool{}
+	for _, oneErr := range lspServer.fileErrorMap[fileName] {
+		if oneErr.ErrType == common.CheckErrorPossibleNil {
+			nilLineMap[oneErr.Loc.StartLine] = true
+		}
+	}
+
+	if len(nilLineMap) != 4 || !nilLineMap[10] || !nilLineMap[29] || !nilLineMap[33] || !nilLineMap[43] {
+		t.Fatalf("possible nil diagnostics error, %v", nilLineMap)
+	}
+}
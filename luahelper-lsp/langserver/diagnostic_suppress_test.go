@@ -0,0 +1,166 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestDiagnosticSuppress(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/suppress"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	ctx := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(ctx, initializeParams)
+	lspServer.GetAllDiagnostics(ctx)
+
+	fileName := strRootPath + "/" + "test1.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	// 1) 只有c、e两个未使用的局部变量，以及c所在行没有用到的屏蔽注解
+	lineTypeMap := map[int]common.CheckErrorType{}
+	for _, oneErr := range lspServer.fileErrorMap[fileName] {
+		lineTypeMap[oneErr.Loc.StartLine] = oneErr.ErrType
+	}
+	if len(lineTypeMap) != 2 || lineTypeMap[8] != common.CheckErrorLocalNoUse {
+		t.Fatalf("suppress diagnostics error, %v", lineTypeMap)
+	}
+
+	unusedNum := 0
+	for _, oneErr := range lspServer.fileErrorMap[fileName] {
+		if oneErr.ErrType == common.CheckErrorUnusedSuppress {
+			unusedNum++
+		}
+	}
+	if unusedNum != 1 {
+		t.Fatalf("unused suppression num=%d", unusedNum)
+	}
+
+	// 2) 快速修复，屏蔽告警或是删除没用的注解
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	if err := lspServer.TextDocumentDidOpen(ctx, openParams); err != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err.Error())
+	}
+
+	actionParams := lsp.CodeActionParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(fileName),
+		},
+		Range: lsp.Range{
+			Start: lsp.Position{Line: 3, Character: 0},
+			End:   lsp.Position{Line: 3, Character: 50},
+		},
+	}
+	actionList, err := lspServer.TextDocumentCodeAction(ctx, actionParams)
+	if err != nil {
+		t.Fatalf("codeAction error")
+	}
+
+	titleMap := map[string]lsp.TextEdit{}
+	for _, oneAction := range actionList {
+		for _, editList := range oneAction.Edit.Changes {
+			if len(editList) > 0 {
+				titleMap[oneAction.Title] = editList[0]
+			}
+		}
+	}
+
+	lineEdit, ok := titleMap["Disable 'local-no-use' for this line"]
+	if !ok || lineEdit.Range.Start.Line != 3 || lineEdit.NewText != "---@diagnostic disable-next-line: local-no-use\n" {
+		t.Fatalf("disable line code action error, %+v", lineEdit)
+	}
+
+	fileEdit, ok := titleMap["Disable 'local-no-use' for this file"]
+	if !ok || fileEdit.Range.Start.Line != 0 || fileEdit.NewText != "---@diagnostic disable: local-no-use\n" {
+		t.Fatalf("disable file code action error, %+v", fileEdit)
+	}
+
+	removeEdit, ok := titleMap["Remove unused diagnostic suppression"]
+	if !ok || removeEdit.Range.Start.Line != 3 || removeEdit.Range.Start.Character != 11 || removeEdit.NewText != "" {
+		t.Fatalf("remove suppression code action error, %+v", removeEdit)
+	}
+}
+
+// 没有luahelper.json时，客户端的告警列表之后新增的告警类型保持默认开启
+func TestDiagnosticSuppressNoJSON(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/suppressnojson"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	ctx := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(ctx, initializeParams)
+	lspServer.GetAllDiagnostics(ctx)
+
+	if common.GConfig.ReadJSONFlag {
+		t.Fatalf("should not read luahelper.json")
+	}
+
+	fileName := strRootPath + "/" + "test1.lua"
+	unusedNum := 0
+	for _, oneErr := range lspServer.fileErrorMap[fileName] {
+		if oneErr.ErrType == common.CheckErrorUnusedSuppress && oneErr.Loc.StartLine == 1 {
+			unusedNum++
+		}
+	}
+	if unusedNum != 1 {
+		t.Fatalf("unused suppression num=%d, %v", unusedNum, lspServer.fileErrorMap[fileName])
+	}
+}
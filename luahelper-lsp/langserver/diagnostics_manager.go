@@ -14,10 +14,7 @@ import (
 func (l *LspServer) pushFileErrList(ctx context.Context, strFile string, fileErrVec []common.CheckError) {
 	var diagnostics lsp.PublishDiagnosticsParams
 	diagnostics.URI = lspcommon.GetFileDocumentURI(strFile)
-	diagnostics.Diagnostics = []lsp.Diagnostic{}
-	for _, oneErr := range fileErrVec {
-		diagnostics.Diagnostics = append(diagnostics.Diagnostics, changeErrToDiagnostic(&oneErr))
-	}
+	diagnostics.Diagnostics = l.getFileDiagnostics(strFile, fileErrVec, false)
 
 	// 发送单个文件的诊断信息
 	l.sendDiagnostics(ctx, diagnostics)
@@ -108,10 +105,7 @@ func (l *LspServer) pushFileChangeDiagnostic(ctx context.Context, strFile string
 
 	var diagnostics lsp.PublishDiagnosticsParams
 	diagnostics.URI = lspcommon.GetFileDocumentURI(strFile)
-	diagnostics.Diagnostics = []lsp.Diagnostic{}
-	for _, oneErr := range errList {
-		diagnostics.Diagnostics = append(diagnostics.Diagnostics, changeErrToDiagnostic(&oneErr))
-	}
+	diagnostics.Diagnostics = l.getFileDiagnostics(strFile, errList, false)
 
 	// 发送单个文件的诊断信息
 	l.sendDiagnostics(ctx, diagnostics)
@@ -128,13 +122,7 @@ func (l *LspServer) pushFileDiagnostic(ctx context.Context, strFile string, igno
 
 	var diagnostics lsp.PublishDiagnosticsParams
 	diagnostics.URI = lspcommon.GetFileDocumentURI(strFile)
-	diagnostics.Diagnostics = []lsp.Diagnostic{}
-	for _, oneErr := range fileErrVec {
-		if oneErr.ErrType == common.CheckErrorSyntax && ignoreSyntax {
-			continue
-		}
-		diagnostics.Diagnostics = append(diagnostics.Diagnostics, changeErrToDiagnostic(&oneErr))
-	}
+	diagnostics.Diagnostics = l.getFileDiagnostics(strFile, fileErrVec, ignoreSyntax)
 
 	// 发送单个文件的诊断信息
 	l.sendDiagnostics(ctx, diagnostics)
@@ -200,16 +188,10 @@ func (l *LspServer) pushAllChangeFileDiagnosticErr(ctx context.Context) {
 	}
 }
 
-// changeErrToDiagnostic 该文件为所有分析文件的诊断管理
-func changeErrToDiagnostic(checkErr *common.CheckError) lsp.Diagnostic {
+// changeErrToDiagnostic 告警信息转换为lsp的诊断信息，strFile为告警所在的文件，用于获取配置的告警级别
+func changeErrToDiagnostic(strFile string, checkErr *common.CheckError) lsp.Diagnostic {
 	var diagnostic lsp.Diagnostic
-	if checkErr.ErrType == common.CheckErrorSyntax {
-		diagnostic.Severity = lsp.SeverityError
-	} else if checkErr.ErrType == common.CheckErrorAnnotate {
-		diagnostic.Severity = lsp.SeverityInformation
-	} else {
-		diagnostic.Severity = lsp.SeverityWarning
-	}
+	diagnostic.Severity = lsp.DiagnosticSeverity(common.GConfig.GetErrorSeverity(strFile, checkErr.ErrType))
 	strPre := ""
 	if checkErr.ErrType == common.CheckErrorSyntax {
 		strPre = fmt.Sprintf("[Warn type:%d], ", checkErr.ErrType)
@@ -0,0 +1,855 @@
+package formatter
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"luahelper-lsp/langserver/check/compiler/parser"
+)
+
+// 格式化只调整单词之间的空白、缩进和注释的位置，单词本身只有字符串的引号和table构造的分隔符会修改。
+// 单词之间的注释从原始的空白片段中提取，与词法分析注释map的切分规则一致，保证注释不会丢失。
+
+// TextEdit 格式化产生的单个修改
+type TextEdit struct {
+	Loc     lexer.Location // 修改的位置，行从1开始，列从0开始（字符数）
+	NewText string         // 替换的内容
+}
+
+// fmtComment 两个单词之间的单个注释
+type fmtComment struct {
+	text     string // 注释的内容，包含前面的--
+	newLines int    // 注释前面的换行数
+}
+
+// fmtToken 格式化时的单个单词
+type fmtToken struct {
+	kind      lexer.TkKind
+	text      string       // 输出的内容
+	gapFrom   int          // 前面空白的起始字节偏移，即上一个单词的结束位置
+	from      int          // 单词的起始字节偏移
+	to        int          // 单词的结束字节偏移
+	comments  []fmtComment // 单词前面的注释
+	newLines  int          // 单词前面的换行数，在最后一个注释之后
+	deleted   bool         // 是否被删除
+	attrib    bool         // 是否为 <const> <close> 属性的一部分
+	unary     bool         // 是否为一元运算符
+	labelOpen bool         // 是否为 ::label:: 前面的::
+	gapText   string       // 格式化后单词前面的内容
+}
+
+// openEntry 缩进栈中的单个元素，为代码块或括号的开始
+type openEntry struct {
+	line    int  // 开始所在的输出行
+	root    int  // 代码块起始语句所在的原始行，从1开始，例如if语句中if所在的行
+	counted bool // 是否计入缩进，同一行有多个开始时，只计最后一个
+}
+
+// formatter 单个文件的格式化
+type formatter struct {
+	src        string
+	opts       Options
+	tokens     []*fmtToken
+	hasHead    bool        // 是否有首行的#注释
+	newLine    string      // 文件使用的换行符
+	match      map[int]int // 括号的匹配，key为左括号的下标，value为右括号的下标
+	blockRoot  map[int]int // end对应的代码块起始语句所在的原始行，key为end的下标
+	lineStarts []int       // 每一行的起始字节偏移
+}
+
+// Format 格式化整个文件，返回所有的修改
+func Format(contents []byte, opts Options) ([]TextEdit, error) {
+	f, err := createFormatter(contents, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.getEdits(1, len(f.lineStarts)), nil
+}
+
+// FormatRange 格式化文件中指定的行，beginLine与endLine从1开始，包含endLine
+func FormatRange(contents []byte, opts Options, beginLine, endLine int) ([]TextEdit, error) {
+	f, err := createFormatter(contents, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.getEdits(beginLine, endLine), nil
+}
+
+// FormatOnType 输入end之后，格式化end对应的整个代码块
+// line从1开始，col从0开始，为end结尾的位置
+func FormatOnType(contents []byte, opts Options, line, col int) ([]TextEdit, error) {
+	f, err := createFormatter(contents, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for index, tok := range f.tokens {
+		if tok.kind != lexer.TkKwEnd {
+			continue
+		}
+
+		endLine, endCol := f.offsetToPos(tok.to)
+		if endLine != line || endCol != col {
+			continue
+		}
+
+		rootLine, ok := f.blockRoot[index]
+		if !ok {
+			return nil, nil
+		}
+		return f.getEdits(rootLine, line), nil
+	}
+
+	return nil, nil
+}
+
+// createFormatter 创建格式化对象，并完成排版
+func createFormatter(contents []byte, opts Options) (*formatter, error) {
+	opts.normalize()
+
+	// 有语法错误的文件不进行格式化
+	newParser := parser.CreateParser(contents, "")
+	newParser.SetLuaVersion(opts.LuaVersion)
+	_, _, errList := newParser.BeginAnalyze()
+	if len(errList) > 0 {
+		return nil, errors.New("syntax error: " + errList[0].ErrStr)
+	}
+
+	f := &formatter{
+		src:       string(contents),
+		opts:      opts,
+		newLine:   "\n",
+		match:     map[int]int{},
+		blockRoot: map[int]int{},
+	}
+	f.initLineStarts()
+
+	if err := f.tokenize(contents); err != nil {
+		return nil, err
+	}
+
+	f.convertQuotes()
+	f.matchBrackets()
+	f.markAttribs()
+	f.handleTables()
+	f.layout()
+	return f, nil
+}
+
+// initLineStarts 计算每一行的起始位置，以及文件使用的换行符
+func (f *formatter) initLineStarts() {
+	f.lineStarts = []int{0}
+	crlfFlag := false
+	for i := 0; i < len(f.src); i++ {
+		ch := f.src[i]
+		if ch == '\n' {
+			f.lineStarts = append(f.lineStarts, i+1)
+			continue
+		}
+
+		if ch != '\r' {
+			continue
+		}
+
+		if i+1 < len(f.src) && f.src[i+1] == '\n' {
+			if len(f.lineStarts) == 1 {
+				crlfFlag = true
+			}
+			i++
+		}
+		f.lineStarts = append(f.lineStarts, i+1)
+	}
+
+	if crlfFlag {
+		f.newLine = "\r\n"
+	}
+}
+
+// tokenize 词法分析出所有的单词，以及单词前面的注释和换行
+func (f *formatter) tokenize(contents []byte) error {
+	var lexErr error
+	l := lexer.NewLexer(contents, "")
+	l.SetLuaVersion(f.opts.LuaVersion)
+	l.SetErrHandler(func(oneErr lexer.ParseError) {
+		if lexErr == nil {
+			lexErr = errors.New(oneErr.ErrStr)
+		}
+	})
+
+	// 跳过BOM头以及首行的#注释，与词法分析保持一致
+	headEnd := 0
+	if strings.HasPrefix(f.src, "\xef\xbb\xbf") {
+		headEnd = 3
+	}
+	if headEnd < len(f.src) && f.src[headEnd] == '#' {
+		f.hasHead = true
+		for headEnd < len(f.src) && f.src[headEnd] != '\n' && f.src[headEnd] != '\r' {
+			headEnd++
+		}
+	}
+	l.SkipFirstLineComment()
+
+	gapFrom := headEnd
+	for {
+		l.NextTokenStruct()
+		if lexErr != nil {
+			return lexErr
+		}
+
+		token := l.GetNowToken()
+		from, to := token.GetByteRange()
+		tok := &fmtToken{
+			kind:    token.GetKind(),
+			text:    f.src[from:to],
+			gapFrom: gapFrom,
+			from:    from,
+			to:      to,
+		}
+		tok.comments, tok.newLines = parseGap(f.src[gapFrom:from])
+		f.tokens = append(f.tokens, tok)
+
+		if tok.kind == lexer.TkEOF {
+			break
+		}
+		gapFrom = to
+	}
+
+	return nil
+}
+
+// parseGap 解析两个单词之间的空白片段，获取其中的注释以及换行数
+func parseGap(gap string) (comments []fmtComment, newLines int) {
+	for i := 0; i < len(gap); {
+		ch := gap[i]
+		if ch == '\n' || ch == '\r' {
+			// \r\n 与 \n\r 当成一个换行
+			if i+1 < len(gap) && (gap[i+1] == '\n' || gap[i+1] == '\r') && gap[i+1] != ch {
+				i++
+			}
+			newLines++
+			i++
+			continue
+		}
+
+		if !strings.HasPrefix(gap[i:], "--") {
+			i++
+			continue
+		}
+
+		end := commentEnd(gap, i)
+		comments = append(comments, fmtComment{
+			text:     strings.TrimRight(gap[i:end], " \t\v\f"),
+			newLines: newLines,
+		})
+		newLines = 0
+		i = end
+	}
+
+	return comments, newLines
+}
+
+// commentEnd 获取注释结束的位置，begin为--开始的位置
+func commentEnd(gap string, begin int) int {
+	index := begin + 2
+	if index < len(gap) && gap[index] == '[' {
+		// 判断是否为长注释 --[==[ ]==]
+		level := 0
+		for index+1+level < len(gap) && gap[index+1+level] == '=' {
+			level++
+		}
+
+		if index+1+level < len(gap) && gap[index+1+level] == '[' {
+			closeStr := "]" + strings.Repeat("=", level) + "]"
+			closeIndex := strings.Index(gap[index:], closeStr)
+			if closeIndex < 0 {
+				return len(gap)
+			}
+			return index + closeIndex + len(closeStr)
+		}
+	}
+
+	for index < len(gap) && gap[index] != '\n' && gap[index] != '\r' {
+		index++
+	}
+	return index
+}
+
+// convertQuotes 按照选项转换短字符串的引号
+func (f *formatter) convertQuotes() {
+	if f.opts.QuoteStyle == QuoteKeep {
+		return
+	}
+
+	target := byte('"')
+	if f.opts.QuoteStyle == QuoteSingle {
+		target = '\''
+	}
+
+	for _, tok := range f.tokens {
+		if tok.kind != lexer.TkString || len(tok.text) < 2 {
+			continue
+		}
+
+		quote := tok.text[0]
+		if (quote != '"' && quote != '\'') || quote == target || tok.text[len(tok.text)-1] != quote {
+			continue
+		}
+
+		// 字符串内包含引号时，转换需要修改转义，保持原样
+		body := tok.text[1 : len(tok.text)-1]
+		if strings.ContainsAny(body, "\"'") {
+			continue
+		}
+
+		tok.text = string(target) + body + string(target)
+	}
+}
+
+// matchBrackets 匹配所有的括号，以及function与对应的end
+func (f *formatter) matchBrackets() {
+	var stack []int
+	var blockStack []int
+	popBlock := func() int {
+		n := len(blockStack)
+		if n == 0 {
+			return -1
+		}
+
+		top := blockStack[n-1]
+		blockStack = blockStack[:n-1]
+		return top
+	}
+
+	for index, tok := range f.tokens {
+		switch tok.kind {
+		case lexer.TkSepLparen, lexer.TkSepLbrack, lexer.TkSepLcurly:
+			stack = append(stack, index)
+		case lexer.TkSepRparen, lexer.TkSepRbrack, lexer.TkSepRcurly:
+			if len(stack) > 0 {
+				f.match[stack[len(stack)-1]] = index
+				stack = stack[:len(stack)-1]
+			}
+		case lexer.TkKwFunction, lexer.TkKwDo, lexer.TkKwThen, lexer.TkKwRepeat:
+			blockStack = append(blockStack, index)
+		case lexer.TkKwElseif, lexer.TkKwUntil:
+			popBlock()
+		case lexer.TkKwEnd:
+			if top := popBlock(); top >= 0 && f.tokens[top].kind == lexer.TkKwFunction {
+				f.match[top] = index
+			}
+		}
+	}
+}
+
+// markAttribs 标记局部变量定义中的 <const> <close> 属性
+func (f *formatter) markAttribs() {
+	tokenLen := len(f.tokens)
+	for index, tok := range f.tokens {
+		if tok.kind != lexer.TkKwLocal {
+			continue
+		}
+
+		for i := index + 1; i < tokenLen && f.tokens[i].kind == lexer.TkIdentifier; {
+			i++
+			if i+2 < tokenLen && f.tokens[i].kind == lexer.TkOpLt && f.tokens[i+1].kind == lexer.TkIdentifier &&
+				f.tokens[i+2].kind == lexer.TkOpGt {
+				f.tokens[i].attrib = true
+				f.tokens[i+1].attrib = true
+				f.tokens[i+2].attrib = true
+				i += 3
+			}
+
+			if i >= tokenLen || f.tokens[i].kind != lexer.TkSepComma {
+				break
+			}
+			i++
+		}
+	}
+}
+
+// handleTables 处理所有table构造的换行以及最后的分隔符
+func (f *formatter) handleTables() {
+	for index, tok := range f.tokens {
+		if tok.kind != lexer.TkSepLcurly {
+			continue
+		}
+
+		end, ok := f.match[index]
+		if !ok || end == index+1 {
+			continue
+		}
+
+		seps := f.tableSeparators(index, end)
+		f.breakTable(index, end, seps)
+		f.handleTrailingSeparator(index, end, seps)
+	}
+}
+
+// tableSeparators 获取table构造第一层的所有分隔符的下标，跳过括号以及函数体内的内容
+func (f *formatter) tableSeparators(begin, end int) (seps []int) {
+	for i := begin + 1; i < end; i++ {
+		tok := f.tokens[i]
+		if matchIndex, ok := f.match[i]; ok {
+			i = matchIndex
+			continue
+		}
+
+		if tok.kind == lexer.TkSepComma || tok.kind == lexer.TkSepSemi {
+			seps = append(seps, i)
+		}
+	}
+
+	return seps
+}
+
+// breakTable 按照选项处理table构造的换行
+func (f *formatter) breakTable(begin, end int, seps []int) {
+	switch f.opts.TableLineBreak {
+	case TableBreakNever:
+		for i := begin + 1; i <= end; i++ {
+			tok := f.tokens[i]
+			if len(tok.comments) > 0 || tok.kind == lexer.TkKwFunction || strings.ContainsAny(tok.text, "\r\n") {
+				return
+			}
+		}
+
+		for i := begin + 1; i <= end; i++ {
+			f.tokens[i].newLines = 0
+		}
+
+	case TableBreakAuto:
+		if !f.isMultiLine(begin, end) && f.lineWidth(begin, end) <= f.opts.ColumnLimit {
+			return
+		}
+
+		fieldStarts := []int{begin + 1}
+		for _, sep := range seps {
+			if sep+1 < end {
+				fieldStarts = append(fieldStarts, sep+1)
+			}
+
+			// 分隔符跟随在成员的后面
+			if len(f.tokens[sep].comments) == 0 {
+				f.tokens[sep].newLines = 0
+			}
+		}
+
+		for _, start := range fieldStarts {
+			if f.tokens[start].newLines == 0 {
+				f.tokens[start].newLines = 1
+			}
+		}
+
+		if f.tokens[end].newLines == 0 {
+			f.tokens[end].newLines = 1
+		}
+	}
+}
+
+// isMultiLine 判断table构造原来是否为多行
+func (f *formatter) isMultiLine(begin, end int) bool {
+	for i := begin + 1; i <= end; i++ {
+		tok := f.tokens[i]
+		if tok.newLines > 0 {
+			return true
+		}
+
+		for _, comment := range tok.comments {
+			if comment.newLines > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// lineWidth 估算table构造所在行，到table结束时的长度
+func (f *formatter) lineWidth(begin, end int) int {
+	start := begin
+	for start > 0 && f.tokens[start].newLines == 0 && len(f.tokens[start].comments) == 0 {
+		start--
+	}
+
+	width := 0
+	for i := start; i <= end; i++ {
+		width += utf8.RuneCountInString(f.tokens[i].text) + 1
+	}
+
+	return width
+}
+
+// handleTrailingSeparator 按照选项处理table构造最后一个成员后面的分隔符
+func (f *formatter) handleTrailingSeparator(begin, end int, seps []int) {
+	last := end - 1
+	hasTrailing := len(seps) > 0 && seps[len(seps)-1] == last
+
+	switch f.opts.TrailingSeparator {
+	case SeparatorAdd:
+		if hasTrailing || f.tokens[end].newLines == 0 {
+			return
+		}
+
+		sepStr := ","
+		if len(seps) > 0 && f.tokens[seps[0]].kind == lexer.TkSepSemi {
+			sepStr = ";"
+		}
+		f.tokens[last].text += sepStr
+
+	case SeparatorRemove:
+		if !hasTrailing || len(f.tokens[last].comments) > 0 {
+			return
+		}
+		f.tokens[last].deleted = true
+	}
+}
+
+// layout 计算每个单词前面的内容，包含换行、缩进、空格以及注释
+func (f *formatter) layout() {
+	var stack []openEntry
+	var headRoots []int
+	var prev *fmtToken
+	pendingRoot := -1
+	labelOpen := false
+	line := 0
+
+	push := func(tokLine int, root int) {
+		if n := len(stack); n > 0 && stack[n-1].line == tokLine {
+			stack[n-1].counted = false
+		}
+		stack = append(stack, openEntry{
+			line:    tokLine,
+			root:    root,
+			counted: true,
+		})
+	}
+
+	pop := func(tokLine int) (root int, ok bool) {
+		n := len(stack)
+		if n == 0 {
+			return 0, false
+		}
+
+		// 在同一行开始并结束，前面同一行开始的恢复计入缩进，例如 function f(a) 中的()
+		entry := stack[n-1]
+		if entry.counted && entry.line == tokLine && n > 1 && stack[n-2].line == tokLine {
+			stack[n-2].counted = true
+		}
+
+		stack = stack[:n-1]
+		return entry.root, true
+	}
+
+	for index, tok := range f.tokens {
+		if tok.deleted {
+			tok.gapText = ""
+			continue
+		}
+
+		if tok.kind == lexer.TkOpMinus || tok.kind == lexer.TkOpWave {
+			tok.unary = prev == nil || !isValueEnd(prev.kind)
+		}
+		if tok.kind == lexer.TkSepLabel {
+			labelOpen = !labelOpen
+			tok.labelOpen = labelOpen
+		}
+
+		startLine := tok.newLines > 0 || (prev == nil && len(tok.comments) == 0)
+		bodyIndent := indentLevel(stack)
+		indent := bodyIndent
+		if startLine {
+			indent = indentLevel(stack[:len(stack)-f.leadingCloseNum(index, len(stack))])
+		}
+
+		var sb strings.Builder
+		for i, comment := range tok.comments {
+			if prev == nil && i == 0 && !f.hasHead {
+				// 文件开头的注释，去掉前面的空行
+				sb.WriteString(comment.text)
+			} else if comment.newLines == 0 {
+				sb.WriteString(" ")
+				sb.WriteString(comment.text)
+			} else {
+				sb.WriteString(f.lineBreaks(comment.newLines))
+				sb.WriteString(f.indentStr(bodyIndent))
+				sb.WriteString(comment.text)
+			}
+		}
+
+		hasContent := prev != nil || len(tok.comments) > 0 || f.hasHead
+		if tok.kind == lexer.TkEOF {
+			// 文件以一个换行结尾
+			if hasContent {
+				sb.WriteString(f.newLine)
+			}
+			tok.gapText = sb.String()
+			break
+		}
+
+		if startLine {
+			if tok.newLines > 0 && hasContent {
+				sb.WriteString(f.lineBreaks(tok.newLines))
+				sb.WriteString(f.indentStr(indent))
+			}
+		} else if len(tok.comments) > 0 || f.needSpace(prev, tok) {
+			sb.WriteString(" ")
+		}
+
+		tok.gapText = sb.String()
+		line += strings.Count(tok.gapText, "\n")
+		tokLine := line
+		line += strings.Count(tok.text, "\n")
+
+		// 更新缩进栈
+		switch tok.kind {
+		case lexer.TkKwEnd, lexer.TkKwUntil, lexer.TkSepRparen, lexer.TkSepRbrack, lexer.TkSepRcurly:
+			if root, ok := pop(tokLine); ok && tok.kind == lexer.TkKwEnd {
+				f.blockRoot[index] = root
+			}
+		case lexer.TkKwElse:
+			root, ok := pop(tokLine)
+			if !ok {
+				root = f.offsetToLine(tok.from)
+			}
+			push(tokLine, root)
+		case lexer.TkKwElseif:
+			if root, ok := pop(tokLine); ok {
+				pendingRoot = root
+			}
+		case lexer.TkKwIf, lexer.TkKwWhile, lexer.TkKwFor:
+			headRoots = append(headRoots, f.offsetToLine(tok.from))
+		case lexer.TkKwThen, lexer.TkKwDo:
+			root := f.offsetToLine(tok.from)
+			if tok.kind == lexer.TkKwThen && pendingRoot >= 0 {
+				root = pendingRoot
+				pendingRoot = -1
+			} else if n := len(headRoots); n > 0 {
+				root = headRoots[n-1]
+				headRoots = headRoots[:n-1]
+			}
+			push(tokLine, root)
+		case lexer.TkKwFunction, lexer.TkKwRepeat, lexer.TkSepLparen, lexer.TkSepLbrack, lexer.TkSepLcurly:
+			push(tokLine, f.offsetToLine(tok.from))
+		}
+
+		prev = tok
+	}
+}
+
+// leadingCloseNum 获取行首连续的结束单词的数量，例如 end) 为2，这些单词会减少当前行的缩进
+func (f *formatter) leadingCloseNum(index int, maxNum int) (num int) {
+	for i := index; i < len(f.tokens) && num < maxNum; i++ {
+		tok := f.tokens[i]
+		if tok.deleted {
+			continue
+		}
+
+		if i > index && (tok.newLines > 0 || len(tok.comments) > 0) {
+			break
+		}
+
+		switch tok.kind {
+		case lexer.TkKwEnd, lexer.TkKwUntil, lexer.TkSepRparen, lexer.TkSepRbrack, lexer.TkSepRcurly:
+			num++
+		case lexer.TkKwElse, lexer.TkKwElseif:
+			return num + 1
+		default:
+			return num
+		}
+	}
+
+	return num
+}
+
+// indentLevel 获取缩进的层数
+func indentLevel(stack []openEntry) (level int) {
+	for _, entry := range stack {
+		if entry.counted {
+			level++
+		}
+	}
+
+	return level
+}
+
+// indentStr 获取指定层数的缩进字符串
+func (f *formatter) indentStr(level int) string {
+	if f.opts.UseTab {
+		return strings.Repeat("\t", level)
+	}
+
+	return strings.Repeat(" ", level*f.opts.IndentWidth)
+}
+
+// lineBreaks 获取换行字符串，最多保留一个空行
+func (f *formatter) lineBreaks(num int) string {
+	if num > 2 {
+		num = 2
+	}
+
+	return strings.Repeat(f.newLine, num)
+}
+
+// isValueEnd 判断单词是否可以作为一个值的结尾，用于区分一元和二元运算符
+func isValueEnd(kind lexer.TkKind) bool {
+	switch kind {
+	case lexer.TkIdentifier, lexer.TkNumber, lexer.TkString, lexer.TkKwNil, lexer.TkKwTrue, lexer.TkKwFalse,
+		lexer.TkVararg, lexer.TkSepRparen, lexer.TkSepRbrack, lexer.TkSepRcurly, lexer.TkKwEnd:
+		return true
+	}
+
+	return false
+}
+
+// isCallPrefix 判断单词后面紧跟 ( { 字符串时，是否为函数调用
+func isCallPrefix(kind lexer.TkKind) bool {
+	switch kind {
+	case lexer.TkIdentifier, lexer.TkString, lexer.TkSepRparen, lexer.TkSepRbrack, lexer.TkSepRcurly:
+		return true
+	}
+
+	return false
+}
+
+// needSpace 判断同一行相邻的两个单词之间是否需要空格
+func (f *formatter) needSpace(prev, cur *fmtToken) bool {
+	// 防止两个单词粘连后含义改变，例如 - -1 变成注释，t[ [[str]] ] 变成长字符串
+	if strings.HasSuffix(prev.text, "-") && strings.HasPrefix(cur.text, "-") {
+		return true
+	}
+	if strings.HasSuffix(prev.text, "[") && strings.HasPrefix(cur.text, "[") {
+		return true
+	}
+
+	// 局部变量的属性，例如 local a <const> = 1
+	if cur.attrib && cur.kind == lexer.TkOpLt {
+		return true
+	}
+	if (prev.attrib && prev.kind == lexer.TkOpLt) || (cur.attrib && cur.kind == lexer.TkOpGt) {
+		return false
+	}
+
+	origSpace := cur.gapFrom < cur.from
+	switch cur.kind {
+	case lexer.TkSepComma, lexer.TkSepSemi, lexer.TkSepRparen, lexer.TkSepRbrack, lexer.TkSepDot,
+		lexer.TkSepColon:
+		return false
+	case lexer.TkSepRcurly:
+		return prev.kind != lexer.TkSepLcurly && f.opts.SpaceInsideBraces
+	case lexer.TkSepLabel:
+		if !cur.labelOpen {
+			return false
+		}
+	case lexer.TkSepLparen:
+		if isCallPrefix(prev.kind) || prev.kind == lexer.TkKwFunction || prev.kind == lexer.TkSepLparen ||
+			prev.kind == lexer.TkSepLbrack || prev.unary {
+			return false
+		}
+	case lexer.TkSepLbrack:
+		if isCallPrefix(prev.kind) || prev.kind == lexer.TkSepLparen || prev.kind == lexer.TkSepLbrack {
+			return false
+		}
+	case lexer.TkSepLcurly, lexer.TkString:
+		// 函数调用 f{...} f"..."，保持原来的空格
+		if prev.kind == lexer.TkIdentifier || prev.kind == lexer.TkSepRparen || prev.kind == lexer.TkSepRbrack ||
+			(cur.kind == lexer.TkSepLcurly && prev.kind == lexer.TkString) {
+			return origSpace
+		}
+	}
+
+	switch prev.kind {
+	case lexer.TkSepLparen, lexer.TkSepLbrack, lexer.TkSepDot, lexer.TkSepColon, lexer.TkOpNen:
+		return false
+	case lexer.TkSepLcurly:
+		return f.opts.SpaceInsideBraces
+	case lexer.TkSepLabel:
+		if prev.labelOpen {
+			return false
+		}
+	case lexer.TkOpMinus, lexer.TkOpWave:
+		if prev.unary {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getEdits 获取起始行在[beginLine, endLine]之间的修改，行从1开始
+func (f *formatter) getEdits(beginLine, endLine int) (edits []TextEdit) {
+	for _, tok := range f.tokens {
+		oldText := f.src[tok.gapFrom:tok.to]
+		newText := tok.gapText
+		if !tok.deleted {
+			newText += tok.text
+		}
+
+		if oldText == newText {
+			continue
+		}
+
+		prefix, suffix := commonPrefixSuffix(oldText, newText)
+		from := tok.gapFrom + prefix
+		to := tok.to - suffix
+		if line := f.offsetToLine(from); line < beginLine || line > endLine {
+			continue
+		}
+
+		startLine, startCol := f.offsetToPos(from)
+		endLine, endCol := f.offsetToPos(to)
+		edits = append(edits, TextEdit{
+			Loc: lexer.Location{
+				StartLine:   startLine,
+				StartColumn: startCol,
+				EndLine:     endLine,
+				EndColumn:   endCol,
+			},
+			NewText: newText[prefix : len(newText)-suffix],
+		})
+	}
+
+	return edits
+}
+
+// commonPrefixSuffix 获取两个字符串相同的前缀和后缀的长度，不会拆分字符以及\r\n
+func commonPrefixSuffix(oldText, newText string) (prefix int, suffix int) {
+	oldLen := len(oldText)
+	newLen := len(newText)
+	for prefix < oldLen && prefix < newLen && oldText[prefix] == newText[prefix] {
+		prefix++
+	}
+	for prefix > 0 && ((prefix < oldLen && !utf8.RuneStart(oldText[prefix])) ||
+		(prefix < newLen && !utf8.RuneStart(newText[prefix])) ||
+		(prefix < oldLen && oldText[prefix-1] == '\r' && oldText[prefix] == '\n')) {
+		prefix--
+	}
+
+	for suffix < oldLen-prefix && suffix < newLen-prefix && oldText[oldLen-1-suffix] == newText[newLen-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && (!utf8.RuneStart(oldText[oldLen-suffix]) || !utf8.RuneStart(newText[newLen-suffix]) ||
+		(oldText[oldLen-suffix] == '\n' && oldLen-suffix > 0 && oldText[oldLen-suffix-1] == '\r')) {
+		suffix--
+	}
+
+	return prefix, suffix
+}
+
+// offsetToLine 字节偏移转换为行号，行号从1开始
+func (f *formatter) offsetToLine(offset int) int {
+	return sort.Search(len(f.lineStarts), func(i int) bool {
+		return f.lineStarts[i] > offset
+	})
+}
+
+// offsetToPos 字节偏移转换为行号与列号，行号从1开始，列号从0开始，为字符的个数
+func (f *formatter) offsetToPos(offset int) (line int, col int) {
+	line = f.offsetToLine(offset)
+	col = utf8.RuneCountInString(f.src[f.lineStarts[line-1]:offset])
+	return line, col
+}
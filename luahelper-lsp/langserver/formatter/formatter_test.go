@@ -0,0 +1,180 @@
+package formatter
+
+import (
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"sort"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// applyEdits 把格式化的修改应用到源码上
+func applyEdits(src string, edits []TextEdit) string {
+	lineStarts := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	posToOffset := func(line, col int) int {
+		offset := lineStarts[line-1]
+		for col > 0 {
+			_, size := utf8.DecodeRuneInString(src[offset:])
+			offset += size
+			col--
+		}
+		return offset
+	}
+
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Loc.StartLine > edits[j].Loc.StartLine ||
+			(edits[i].Loc.StartLine == edits[j].Loc.StartLine && edits[i].Loc.StartColumn > edits[j].Loc.StartColumn)
+	})
+
+	for _, edit := range edits {
+		from := posToOffset(edit.Loc.StartLine, edit.Loc.StartColumn)
+		to := posToOffset(edit.Loc.EndLine, edit.Loc.EndColumn)
+		src = src[:from] + edit.NewText + src[to:]
+	}
+	return src
+}
+
+func formatStr(t *testing.T, src string, opts Options) string {
+	edits, err := Format([]byte(src), opts)
+	if err != nil {
+		t.Fatalf("format err=%s", err.Error())
+	}
+	return applyEdits(src, edits)
+}
+
+func TestFormatIndent(t *testing.T) {
+	src := `-- head comment
+local function test(a,b)
+if a==b then
+return a+b -- tail comment
+elseif a>b then
+  print( "a" )
+else
+    -- inner comment
+    for i=1,10 do print(i) end
+end
+local t = {1,2,3}
+foo(function()
+return -1
+end)
+end
+
+
+return test
+`
+	expect := `-- head comment
+local function test(a, b)
+    if a == b then
+        return a + b -- tail comment
+    elseif a > b then
+        print("a")
+    else
+        -- inner comment
+        for i = 1, 10 do print(i) end
+    end
+    local t = {1, 2, 3}
+    foo(function()
+        return -1
+    end)
+end
+
+return test
+`
+	result := formatStr(t, src, DefaultOptions())
+	if result != expect {
+		t.Fatalf("format indent error, result=\n%s", result)
+	}
+
+	// 格式化之后的结果再格式化，不应该有修改
+	edits, _ := Format([]byte(expect), DefaultOptions())
+	if len(edits) != 0 {
+		t.Fatalf("format again should no edit, edits=%v", edits)
+	}
+}
+
+func TestFormatTable(t *testing.T) {
+	src := `local t = {a = 1, b = 'str',
+  c = { 1, 2 }}
+local s = {
+  x = 1,
+  y = 2,
+}
+`
+	opts := DefaultOptions()
+	opts.QuoteStyle = QuoteDouble
+	opts.TableLineBreak = TableBreakAuto
+	opts.TrailingSeparator = SeparatorAdd
+	expect := `local t = {
+    a = 1,
+    b = "str",
+    c = {1, 2},
+}
+local s = {
+    x = 1,
+    y = 2,
+}
+`
+	if result := formatStr(t, src, opts); result != expect {
+		t.Fatalf("format table auto error, result=\n%s", result)
+	}
+
+	opts.TableLineBreak = TableBreakNever
+	opts.TrailingSeparator = SeparatorRemove
+	opts.SpaceInsideBraces = true
+	expect = `local t = { a = 1, b = "str", c = { 1, 2 } }
+local s = { x = 1, y = 2 }
+`
+	if result := formatStr(t, src, opts); result != expect {
+		t.Fatalf("format table never error, result=\n%s", result)
+	}
+}
+
+func TestFormatLuaVersion(t *testing.T) {
+	// Lua 5.1中goto不是关键字，可以作为变量名
+	src := "local goto=1\n"
+	opts := DefaultOptions()
+	if _, err := Format([]byte(src), opts); err == nil {
+		t.Fatalf("format goto variable should syntax error in all versions")
+	}
+
+	opts.LuaVersion = lexer.LuaVersion51
+	if result := formatStr(t, src, opts); result != "local goto = 1\n" {
+		t.Fatalf("format goto variable in Lua 5.1 error, result=\n%s", result)
+	}
+}
+
+func TestFormatRangeAndOnType(t *testing.T) {
+	src := `local a   =   1
+if a then
+print(a)
+end
+local b   =   2
+`
+	edits, err := FormatRange([]byte(src), DefaultOptions(), 2, 4)
+	if err != nil {
+		t.Fatalf("format range err=%s", err.Error())
+	}
+	expect := strings.Replace(src, "print(a)", "    print(a)", 1)
+	if result := applyEdits(src, edits); result != expect {
+		t.Fatalf("format range error, result=\n%s", result)
+	}
+
+	edits, err = FormatOnType([]byte(src), DefaultOptions(), 4, 3)
+	if err != nil {
+		t.Fatalf("format on type err=%s", err.Error())
+	}
+	if result := applyEdits(src, edits); result != expect {
+		t.Fatalf("format on type error, result=\n%s", result)
+	}
+
+	// 有语法错误时不进行格式化
+	if _, err = Format([]byte("if a then"), DefaultOptions()); err == nil {
+		t.Fatalf("syntax error should not format")
+	}
+}
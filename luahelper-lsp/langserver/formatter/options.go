@@ -0,0 +1,73 @@
+package formatter
+
+import "luahelper-lsp/langserver/check/compiler/lexer"
+
+// 字符串引号的风格
+const (
+	QuoteKeep   = "keep"   // 保持原样
+	QuoteDouble = "double" // 统一为双引号
+	QuoteSingle = "single" // 统一为单引号
+)
+
+// table构造的换行方式
+const (
+	TableBreakKeep  = "keep"  // 保持原有的换行
+	TableBreakAuto  = "auto"  // 原来为多行或是超过单行最大长度时，每个成员单独一行
+	TableBreakNever = "never" // 尽量合并为一行，包含注释或函数定义的table不合并
+)
+
+// table构造最后一个成员后面的分隔符
+const (
+	SeparatorKeep   = "keep"   // 保持原样
+	SeparatorAdd    = "add"    // 多行的table构造，最后一个成员后面增加分隔符
+	SeparatorRemove = "remove" // 删除最后一个成员后面的分隔符
+)
+
+// Options 格式化的选项
+type Options struct {
+	IndentWidth       int              // 缩进的宽度
+	UseTab            bool             // 是否用tab缩进
+	QuoteStyle        string           // 字符串引号的风格
+	TableLineBreak    string           // table构造的换行方式
+	TrailingSeparator string           // table构造最后一个成员后面的分隔符
+	ColumnLimit       int              // 单行的最大长度，TableBreakAuto时使用
+	SpaceInsideBraces bool             // 单行的table构造，大括号的内侧是否增加空格
+	LuaVersion        lexer.LuaVersion // 分析语法的Lua版本，与诊断时的版本保持一致
+}
+
+// DefaultOptions 默认的格式化选项
+func DefaultOptions() Options {
+	return Options{
+		IndentWidth:       4,
+		UseTab:            false,
+		QuoteStyle:        QuoteKeep,
+		TableLineBreak:    TableBreakKeep,
+		TrailingSeparator: SeparatorKeep,
+		ColumnLimit:       120,
+		SpaceInsideBraces: false,
+	}
+}
+
+// normalize 非法的选项值修正为默认值
+func (o *Options) normalize() {
+	defaultOpts := DefaultOptions()
+	if o.IndentWidth <= 0 {
+		o.IndentWidth = defaultOpts.IndentWidth
+	}
+
+	if o.QuoteStyle != QuoteDouble && o.QuoteStyle != QuoteSingle {
+		o.QuoteStyle = QuoteKeep
+	}
+
+	if o.TableLineBreak != TableBreakAuto && o.TableLineBreak != TableBreakNever {
+		o.TableLineBreak = TableBreakKeep
+	}
+
+	if o.TrailingSeparator != SeparatorAdd && o.TrailingSeparator != SeparatorRemove {
+		o.TrailingSeparator = SeparatorKeep
+	}
+
+	if o.ColumnLimit <= 0 {
+		o.ColumnLimit = defaultOpts.ColumnLimit
+	}
+}
@@ -49,6 +49,7 @@ type InitializationOptions struct {
 	IgnoreFileOrDir                []string `json:"IgnoreFileOrDir,omitempty"`
 	IgnoreFileOrDirError           []string `json:"IgnoreFileOrDirError,omitempty"`
 	RequirePathSeparator           string   `json:"RequirePathSeparator,omitempty"`
+	LuaVersion                     string   `json:"LuaVersion,omitempty"`
 	EnableReport                   bool     `json:"EnableReport,omitempty"`
 }
 
@@ -88,6 +89,9 @@ func (l *LspServer) Initialize(ctx context.Context, vs InitializeParams) (lsp.In
 	// 按顺序插入
 	checkFlagList := getCheckFlagList(initOptions)
 
+	// 客户端配置的Lua版本，luahelper.json中配置了时以json中的为准
+	common.GConfig.SetClientLuaVersion(initOptions.LuaVersion)
+
 	initErr := l.initialCheckProject(ctx, checkFlagList, initOptions.Client, workspaceFolderNum, vs.WorkspaceFolders,
 		initOptions.LocalRun, initOptions.IgnoreFileOrDir, initOptions.IgnoreFileOrDirError)
 	if initErr != nil {
@@ -100,6 +104,13 @@ func (l *LspServer) Initialize(ctx context.Context, vs InitializeParams) (lsp.In
 	common.GConfig.SetRequirePathSeparator(initOptions.RequirePathSeparator)
 	l.enableReport = initOptions.EnableReport
 
+	// vscode插件前端自带了格式化工具，其他的客户端使用后台的格式化
+	formatFlag := initOptions.Client != "vsc"
+	onTypeFormatting := lsp.DocumentOnTypeFormattingOptions{}
+	if formatFlag {
+		onTypeFormatting.FirstTriggerCharacter = "d"
+	}
+
 	return lsp.InitializeResult{
 		Capabilities: lsp.ServerCapabilities{
 			InnerServerCapabilities: lsp.InnerServerCapabilities{
@@ -120,6 +131,8 @@ func (l *LspServer) Initialize(ctx context.Context, vs InitializeParams) (lsp.In
 				HoverProvider:           true,
 				WorkspaceSymbolProvider: true,
 				DefinitionProvider:      true,
+				ImplementationProvider:  true,
+				TypeDefinitionProvider:  true,
 				ReferencesProvider:      true,
 				DocumentSymbolProvider:  true,
 				SignatureHelpProvider: lsp.SignatureHelpOptions{
@@ -133,6 +146,18 @@ func (l *LspServer) Initialize(ctx context.Context, vs InitializeParams) (lsp.In
 				},
 				RenameProvider:            true,
 				DocumentHighlightProvider: true,
+				CodeActionProvider: lsp.CodeActionOptions{
+					CodeActionKinds: []lsp.CodeActionKind{lsp.QuickFix},
+				},
+				DocumentFormattingProvider:       formatFlag,
+				DocumentRangeFormattingProvider:  formatFlag,
+				DocumentOnTypeFormattingProvider: onTypeFormatting,
+				SemanticTokensProvider:           getSemanticTokensOptions(),
+				InlayHintProvider:                true,
+				FoldingRangeProvider:             true,
+				SelectionRangeProvider:           true,
+				CallHierarchyProvider:            true,
+				TypeHierarchyProvider:            true,
 				Workspace: lsp.WorkspaceGn{
 					WorkspaceFolders: lsp.WorkspaceFoldersGn{
 						Supported:           true,
@@ -168,6 +193,9 @@ func (l *LspServer) initialCheckProject(ctx context.Context, checkFlagList []boo
 		return readErr
 	}
 
+	// 配置有变化时，重新加载基线文件
+	l.loadDiagBaseline()
+
 	if isLocal {
 		// 为本地运行，没有插件前端，插件前端无法传递额外的Lua文件夹，忽略系统的模块和变量
 		common.GConfig.InsertIngoreSystemModule()
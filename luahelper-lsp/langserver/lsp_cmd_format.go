@@ -0,0 +1,510 @@
+package langserver
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+)
+
+// 本地诊断模式支持的输出格式
+const (
+	localFormatText       = "text"
+	localFormatJSON       = "json"
+	localFormatSarif      = "sarif"
+	localFormatCheckstyle = "checkstyle"
+	localFormatJunit      = "junit"
+	localFormatGithub     = "github"
+)
+
+// localExitUsage 本地诊断模式参数错误或是工程分析失败时的退出码，与存在告警时的退出码1区分开
+const localExitUsage = 2
+
+// localDiagnostic 本地诊断模式输出的单个告警
+type localDiagnostic struct {
+	file        string                    // 告警所在的文件，相对于工程的根目录
+	fullFile    string                    // 告警所在文件的全路径
+	relateFiles []string                  // 关联告警的文件，相对于工程的根目录，与RelateVec一一对应
+	severity    common.CheckErrorSeverity // 严重级别
+	checkErr    common.CheckError         // 原始的告警
+}
+
+// collectLocalDiagnostics 所有文件的告警转换为按文件、行、列排好序的列表
+func collectLocalDiagnostics(rootDir string, fileErrorMap map[string][]common.CheckError) (diagVec []localDiagnostic) {
+	for strFile, errVec := range fileErrorMap {
+		relFile := getLocalRelativeFile(rootDir, strFile)
+		for _, oneErr := range errVec {
+			oneDiag := localDiagnostic{
+				file:     relFile,
+				fullFile: strFile,
+				severity: common.GConfig.GetErrorSeverity(strFile, oneErr.ErrType),
+				checkErr: oneErr,
+			}
+			for _, oneRelate := range oneErr.RelateVec {
+				oneDiag.relateFiles = append(oneDiag.relateFiles, getLocalRelativeFile(rootDir, oneRelate.LuaFile))
+			}
+			diagVec = append(diagVec, oneDiag)
+		}
+	}
+
+	sort.SliceStable(diagVec, func(i, j int) bool {
+		one, two := &diagVec[i], &diagVec[j]
+		if one.file != two.file {
+			return one.file < two.file
+		}
+		if one.checkErr.Loc.StartLine != two.checkErr.Loc.StartLine {
+			return one.checkErr.Loc.StartLine < two.checkErr.Loc.StartLine
+		}
+		if one.checkErr.Loc.StartColumn != two.checkErr.Loc.StartColumn {
+			return one.checkErr.Loc.StartColumn < two.checkErr.Loc.StartColumn
+		}
+		return one.checkErr.ErrType < two.checkErr.ErrType
+	})
+	return diagVec
+}
+
+// getLocalRelativeFile 文件转换为相对于工程根目录的路径，统一使用/分割
+func getLocalRelativeFile(rootDir string, strFile string) string {
+	if rootDir != "" {
+		if relFile, err := filepath.Rel(rootDir, strFile); err == nil && !strings.HasPrefix(relFile, "..") {
+			strFile = relFile
+		}
+	}
+
+	return filepath.ToSlash(strFile)
+}
+
+// checkLocalOptions 校验输出格式与-fail-on的级别是否合法，在分析工程之前提前报错
+func checkLocalOptions(format string, failOn string) error {
+	switch format {
+	case "", localFormatText, localFormatJSON, localFormatSarif, localFormatCheckstyle, localFormatJunit,
+		localFormatGithub:
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+
+	if failOn == "" || failOn == "none" {
+		return nil
+	}
+	if _, ok := common.ParseCheckErrorSeverity(failOn); !ok {
+		return fmt.Errorf("unknown fail-on level: %s", failOn)
+	}
+	return nil
+}
+
+// getLocalExitCode 根据-fail-on的级别，判断是否需要返回非0的退出码
+// failOn为空或是none时，始终返回0
+func getLocalExitCode(diagVec []localDiagnostic, failOn string) (int, error) {
+	if failOn == "" || failOn == "none" {
+		return 0, nil
+	}
+
+	threshold, ok := common.ParseCheckErrorSeverity(failOn)
+	if !ok {
+		return 0, fmt.Errorf("unknown fail-on level: %s", failOn)
+	}
+
+	for _, oneDiag := range diagVec {
+		if oneDiag.severity <= threshold {
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// writeLocalDiagnostics 按指定的格式输出所有的告警
+func writeLocalDiagnostics(w io.Writer, format string, diagVec []localDiagnostic) error {
+	switch format {
+	case "", localFormatText:
+		return writeLocalText(w, diagVec)
+	case localFormatJSON:
+		return writeLocalJSON(w, diagVec)
+	case localFormatSarif:
+		return writeLocalSarif(w, diagVec)
+	case localFormatCheckstyle:
+		return writeLocalCheckstyle(w, diagVec)
+	case localFormatJunit:
+		return writeLocalJunit(w, diagVec)
+	case localFormatGithub:
+		return writeLocalGithub(w, diagVec)
+	}
+
+	return fmt.Errorf("unknown format: %s", format)
+}
+
+// writeLocalText 原有的文本格式
+func writeLocalText(w io.Writer, diagVec []localDiagnostic) error {
+	for _, oneDiag := range diagVec {
+		errInfo := &oneDiag.checkErr
+		if _, err := fmt.Fprintf(w, "%v, line=%v, errType=%v, errStr=%s\n", oneDiag.fullFile, errInfo.Loc.StartLine,
+			(int)(errInfo.ErrType), errInfo.ErrStr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// localJSONPosition json格式的位置，行与列都从1开始
+type localJSONPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// localJSONRange json格式的范围，结束的列不包含
+type localJSONRange struct {
+	Start localJSONPosition `json:"start"`
+	End   localJSONPosition `json:"end"`
+}
+
+// localJSONRelated json格式的关联位置
+type localJSONRelated struct {
+	File    string         `json:"file"`
+	Range   localJSONRange `json:"range"`
+	Message string         `json:"message"`
+}
+
+// localJSONDiagnostic json格式的单个告警
+type localJSONDiagnostic struct {
+	File      string             `json:"file"`
+	Range     localJSONRange     `json:"range"`
+	Severity  string             `json:"severity"`
+	RuleID    string             `json:"ruleId"`
+	Code      int                `json:"code"`
+	Message   string             `json:"message"`
+	EntryFile string             `json:"entryFile,omitempty"`
+	Related   []localJSONRelated `json:"related,omitempty"`
+}
+
+// changeLocalJSONRange 位置信息转换为json格式的范围
+func changeLocalJSONRange(loc *lexer.Location) localJSONRange {
+	return localJSONRange{
+		Start: localJSONPosition{Line: loc.StartLine, Column: loc.StartColumn + 1},
+		End:   localJSONPosition{Line: loc.EndLine, Column: loc.EndColumn + 1},
+	}
+}
+
+// writeLocalJSON json格式
+func writeLocalJSON(w io.Writer, diagVec []localDiagnostic) error {
+	jsonVec := make([]localJSONDiagnostic, 0, len(diagVec))
+	for _, oneDiag := range diagVec {
+		errInfo := &oneDiag.checkErr
+		oneJSON := localJSONDiagnostic{
+			File:      oneDiag.file,
+			Range:     changeLocalJSONRange(&errInfo.Loc),
+			Severity:  oneDiag.severity.String(),
+			RuleID:    common.GetCheckErrorName(errInfo.ErrType),
+			Code:      (int)(errInfo.ErrType),
+			Message:   errInfo.ErrStr,
+			EntryFile: errInfo.EntryFile,
+		}
+
+		for i, oneRelate := range errInfo.RelateVec {
+			oneJSON.Related = append(oneJSON.Related, localJSONRelated{
+				File:    oneDiag.relateFiles[i],
+				Range:   changeLocalJSONRange(&oneRelate.Loc),
+				Message: oneRelate.ErrStr,
+			})
+		}
+		jsonVec = append(jsonVec, oneJSON)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonVec)
+}
+
+// sarif格式的结构，只包含用到的字段，版本为2.1.0
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// changeSarifLocation 位置信息转换为sarif格式
+func changeSarifLocation(strFile string, loc *lexer.Location) sarifLocation {
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: strFile},
+			Region: sarifRegion{
+				StartLine:   loc.StartLine,
+				StartColumn: loc.StartColumn + 1,
+				EndLine:     loc.EndLine,
+				EndColumn:   loc.EndColumn + 1,
+			},
+		},
+	}
+}
+
+// changeSarifLevel 严重级别转换为sarif的level
+func changeSarifLevel(severity common.CheckErrorSeverity) string {
+	switch severity {
+	case common.CESError:
+		return "error"
+	case common.CESWarning:
+		return "warning"
+	}
+
+	return "note"
+}
+
+// writeLocalSarif sarif格式
+func writeLocalSarif(w io.Writer, diagVec []localDiagnostic) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "LuaHelper",
+				InformationURI: "https://github.com/Tencent/LuaHelper",
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	for errType := common.CheckErrorType(common.CheckErrorSyntax); errType < common.CheckErrorMax; errType++ {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:               common.GetCheckErrorName(errType),
+			ShortDescription: sarifMessage{Text: common.GetCheckErrorDesc(errType)},
+		})
+	}
+
+	for _, oneDiag := range diagVec {
+		errInfo := &oneDiag.checkErr
+		oneResult := sarifResult{
+			RuleID:    common.GetCheckErrorName(errInfo.ErrType),
+			RuleIndex: (int)(errInfo.ErrType) - common.CheckErrorSyntax,
+			Level:     changeSarifLevel(oneDiag.severity),
+			Message:   sarifMessage{Text: errInfo.ErrStr},
+			Locations: []sarifLocation{changeSarifLocation(oneDiag.file, &errInfo.Loc)},
+		}
+
+		for i, oneRelate := range errInfo.RelateVec {
+			relateID := i + 1
+			relateLocation := changeSarifLocation(oneDiag.relateFiles[i], &oneRelate.Loc)
+			relateLocation.ID = &relateID
+			relateLocation.Message = &sarifMessage{Text: oneRelate.ErrStr}
+			oneResult.RelatedLocations = append(oneResult.RelatedLocations, relateLocation)
+		}
+		run.Results = append(run.Results, oneResult)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// checkstyle格式的结构
+type checkstyleOutput struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// writeLocalCheckstyle checkstyle格式，同一个文件的告警放在一起
+func writeLocalCheckstyle(w io.Writer, diagVec []localDiagnostic) error {
+	output := checkstyleOutput{
+		Version: "4.3",
+	}
+
+	for _, oneDiag := range diagVec {
+		fileLen := len(output.Files)
+		if fileLen == 0 || output.Files[fileLen-1].Name != oneDiag.file {
+			output.Files = append(output.Files, checkstyleFile{Name: oneDiag.file})
+			fileLen++
+		}
+
+		errInfo := &oneDiag.checkErr
+		oneFile := &output.Files[fileLen-1]
+		oneFile.Errors = append(oneFile.Errors, checkstyleError{
+			Line:     errInfo.Loc.StartLine,
+			Column:   errInfo.Loc.StartColumn + 1,
+			Severity: oneDiag.severity.String(),
+			Message:  errInfo.ErrStr,
+			Source:   "luahelper." + common.GetCheckErrorName(errInfo.ErrType),
+		})
+	}
+
+	return writeLocalXML(w, output)
+}
+
+// junit格式的结构，每个告警为一个失败的用例
+type junitTestSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string       `xml:"classname,attr"`
+	Name      string       `xml:"name,attr"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeLocalJunit junit格式
+func writeLocalJunit(w io.Writer, diagVec []localDiagnostic) error {
+	suite := junitSuite{
+		Name:      "luahelper",
+		Tests:     len(diagVec),
+		Failures:  len(diagVec),
+		TestCases: []junitTestCase{},
+	}
+
+	for _, oneDiag := range diagVec {
+		errInfo := &oneDiag.checkErr
+		strRule := common.GetCheckErrorName(errInfo.ErrType)
+		strPos := fmt.Sprintf("%s:%d:%d", oneDiag.file, errInfo.Loc.StartLine, errInfo.Loc.StartColumn+1)
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: oneDiag.file,
+			Name:      fmt.Sprintf("%s %s", strRule, strPos),
+			Failure: junitFailure{
+				Type:    oneDiag.severity.String(),
+				Message: errInfo.ErrStr,
+				Text:    fmt.Sprintf("%s: [%s] %s", strPos, strRule, errInfo.ErrStr),
+			},
+		})
+	}
+
+	return writeLocalXML(w, junitTestSuites{Suites: []junitSuite{suite}})
+}
+
+// writeLocalXML 输出带有头部的xml
+func writeLocalXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeLocalGithub github actions的workflow命令格式，在PR中直接标注告警，endColumn包含结束的列
+func writeLocalGithub(w io.Writer, diagVec []localDiagnostic) error {
+	for _, oneDiag := range diagVec {
+		errInfo := &oneDiag.checkErr
+		strLevel := "warning"
+		if oneDiag.severity == common.CESError {
+			strLevel = "error"
+		} else if oneDiag.severity > common.CESWarning {
+			strLevel = "notice"
+		}
+
+		if _, err := fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d,endLine=%d,endColumn=%d,title=%s::%s\n", strLevel,
+			escapeGithubProperty(oneDiag.file), errInfo.Loc.StartLine, errInfo.Loc.StartColumn+1, errInfo.Loc.EndLine,
+			errInfo.Loc.EndColumn, escapeGithubProperty(common.GetCheckErrorName(errInfo.ErrType)),
+			escapeGithubData(errInfo.ErrStr)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// escapeGithubData 转义workflow命令的消息内容
+func escapeGithubData(str string) string {
+	str = strings.ReplaceAll(str, "%", "%25")
+	str = strings.ReplaceAll(str, "\r", "%0D")
+	return strings.ReplaceAll(str, "\n", "%0A")
+}
+
+// escapeGithubProperty 转义workflow命令的属性值
+func escapeGithubProperty(str string) string {
+	str = escapeGithubData(str)
+	str = strings.ReplaceAll(str, ":", "%3A")
+	return strings.ReplaceAll(str, ",", "%2C")
+}
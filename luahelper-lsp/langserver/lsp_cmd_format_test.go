@@ -0,0 +1,136 @@
+package langserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalDiagnosticsFormat(t *testing.T) {
+	rootDir := filepath.FromSlash("/project")
+	fileErrorMap := map[string][]common.CheckError{
+		filepath.FromSlash("/project/b.lua"): {
+			{
+				ErrType: common.CheckErrorSyntax,
+				ErrStr:  "expected then, found '='",
+				Loc:     lexer.Location{StartLine: 5, StartColumn: 6, EndLine: 5, EndColumn: 7},
+			},
+		},
+		filepath.FromSlash("/project/a.lua"): {
+			{
+				ErrType: common.CheckErrorTableDuplicateKey,
+				ErrStr:  "the table contains duplicate keys: name",
+				Loc:     lexer.Location{StartLine: 2, StartColumn: 21, EndLine: 2, EndColumn: 25},
+				RelateVec: []common.RelateCheckInfo{
+					{
+						LuaFile: filepath.FromSlash("/project/a.lua"),
+						ErrStr:  "the table contains duplicate keys: name",
+						Loc:     lexer.Location{StartLine: 2, StartColumn: 11, EndLine: 2, EndColumn: 15},
+					},
+				},
+			},
+			{
+				ErrType: common.CheckErrorAnnotate,
+				ErrStr:  "annotate type not define: Foo",
+				Loc:     lexer.Location{StartLine: 1, StartColumn: 10, EndLine: 1, EndColumn: 13},
+			},
+		},
+	}
+
+	diagVec := collectLocalDiagnostics(rootDir, fileErrorMap)
+	if len(diagVec) != 3 || diagVec[0].file != "a.lua" || diagVec[0].checkErr.Loc.StartLine != 1 ||
+		diagVec[2].file != "b.lua" {
+		t.Fatalf("collect diagnostics order error, %v", diagVec)
+	}
+
+	// 1) json格式包含完整的位置、规则名称以及关联的位置
+	var buf bytes.Buffer
+	if err := writeLocalDiagnostics(&buf, localFormatJSON, diagVec); err != nil {
+		t.Fatalf("write json error, err=%v", err)
+	}
+
+	var jsonVec []localJSONDiagnostic
+	if err := json.Unmarshal(buf.Bytes(), &jsonVec); err != nil {
+		t.Fatalf("unmarshal json error, err=%v", err)
+	}
+	oneJSON := jsonVec[1]
+	if oneJSON.RuleID != "table-duplicate-key" || oneJSON.Severity != "warning" || oneJSON.Range.Start.Column != 22 ||
+		oneJSON.Range.End.Column != 26 || len(oneJSON.Related) != 1 || oneJSON.Related[0].File != "a.lua" {
+		t.Fatalf("json diagnostic error, %+v", oneJSON)
+	}
+	if jsonVec[0].Severity != "info" || jsonVec[2].Severity != "error" {
+		t.Fatalf("json severity error, %s, %s", jsonVec[0].Severity, jsonVec[2].Severity)
+	}
+
+	// 2) sarif格式
+	buf.Reset()
+	if err := writeLocalDiagnostics(&buf, localFormatSarif, diagVec); err != nil {
+		t.Fatalf("write sarif error, err=%v", err)
+	}
+
+	var sarif sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &sarif); err != nil {
+		t.Fatalf("unmarshal sarif error, err=%v", err)
+	}
+	results := sarif.Runs[0].Results
+	rules := sarif.Runs[0].Tool.Driver.Rules
+	if len(results) != 3 || results[0].Level != "note" || results[2].Level != "error" ||
+		rules[results[1].RuleIndex].ID != results[1].RuleID || len(results[1].RelatedLocations) != 1 {
+		t.Fatalf("sarif results error, %+v", results)
+	}
+
+	// 3) checkstyle与junit为合法的xml
+	for _, format := range []string{localFormatCheckstyle, localFormatJunit} {
+		buf.Reset()
+		if err := writeLocalDiagnostics(&buf, format, diagVec); err != nil {
+			t.Fatalf("write %s error, err=%v", format, err)
+		}
+
+		var node struct{}
+		if err := xml.Unmarshal(buf.Bytes(), &node); err != nil {
+			t.Fatalf("%s is not valid xml, err=%v", format, err)
+		}
+	}
+
+	// 4) github格式
+	buf.Reset()
+	if err := writeLocalDiagnostics(&buf, localFormatGithub, diagVec); err != nil {
+		t.Fatalf("write github error, err=%v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expectLine := "::error file=b.lua,line=5,col=7,endLine=5,endColumn=7,title=syntax::expected then, found '='"
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "::notice ") || lines[2] != expectLine {
+		t.Fatalf("github output error, %v", lines)
+	}
+
+	// 5) -fail-on的退出码
+	failOnVec := []struct {
+		failOn   string
+		exitCode int
+	}{
+		{"", 0},
+		{"none", 0},
+		{"error", 1},
+		{"warning", 1},
+		{"info", 1},
+	}
+	for _, oneFail := range failOnVec {
+		exitCode, err := getLocalExitCode(diagVec, oneFail.failOn)
+		if err != nil || exitCode != oneFail.exitCode {
+			t.Fatalf("fail-on %s exit code=%d, err=%v", oneFail.failOn, exitCode, err)
+		}
+	}
+
+	if exitCode, _ := getLocalExitCode(diagVec[:2], "error"); exitCode != 0 {
+		t.Fatalf("fail-on error without error diagnostics, exit code=%d", exitCode)
+	}
+
+	if err := checkLocalOptions("xml", ""); err == nil {
+		t.Fatalf("unknown format should fail")
+	}
+}
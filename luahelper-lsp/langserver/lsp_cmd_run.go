@@ -3,14 +3,38 @@ package langserver
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"luahelper-lsp/langserver/check/common"
 	"luahelper-lsp/langserver/log"
 	"luahelper-lsp/langserver/pathpre"
 )
 
+// LocalOptions 本地诊断模式的参数
+type LocalOptions struct {
+	Format        string // 输出的格式：text、json、sarif、checkstyle、junit、github
+	FailOn        string // 返回非0退出码的告警级别：error、warning、info、hint，为空或是none时始终返回0
+	Baseline      string // 基线文件，只输出基线中不存在的新告警
+	BaselineWrite string // 把当前所有的告警写入该基线文件，不输出告警
+}
+
 // RunLocalDiagnostices 运行本地模式，校验错误
-func (l *LspServer) RunLocalDiagnostices(localpath string) {
+func (l *LspServer) RunLocalDiagnostices(localpath string, options LocalOptions) (exitCode int) {
+	if err := checkLocalOptions(options.Format, options.FailOn); err != nil {
+		fmt.Fprintf(os.Stderr, "luahelper: %s\n", err.Error())
+		return localExitUsage
+	}
+
+	// 基线文件在分析工程之前读取，格式错误时提前报错
+	var baseline *diagBaseline
+	if options.Baseline != "" && options.BaselineWrite == "" {
+		var err error
+		if baseline, err = readBaselineFile(options.Baseline); err != nil {
+			fmt.Fprintf(os.Stderr, "luahelper: %s\n", err.Error())
+			return localExitUsage
+		}
+	}
+
 	RootPath := "file://" + localpath
 	RootURI := localpath
 
@@ -34,13 +58,13 @@ func (l *LspServer) RunLocalDiagnostices(localpath string) {
 	initErr := l.initialCheckProject(ctx, checkFlagList, "local", 0, nil, true, nil, nil)
 	if initErr != nil {
 		log.Error("initial luahelper err: " + initErr.Error())
-		return
+		return localExitUsage
 	}
 	log.Debug("initial luahelper ok")
 	project := l.getAllProject()
 	if project == nil {
 		log.Error("CheckProject is nil")
-		return
+		return localExitUsage
 	}
 
 	fileErrorMap := project.GetAllFileErrorInfo()
@@ -49,13 +73,33 @@ func (l *LspServer) RunLocalDiagnostices(localpath string) {
 	l.fileErrorMap = fileErrorMap
 	if len(fileErrorMap) == 0 {
 		log.Debug("GetAllFileErrorInfo is empty..")
-		return
 	}
 
-	for file, info := range fileErrorMap {
-		for _, errinfo := range info {
-			fmt.Printf("%v, line=%v, errType=%v, errStr=%s\n", file, errinfo.Loc.StartLine, (int)(errinfo.ErrType),
-				errinfo.ErrStr)
+	diagVec := collectLocalDiagnostics(vscodeRoot, fileErrorMap)
+	if options.BaselineWrite != "" {
+		if err := writeBaselineFile(options.BaselineWrite, createLocalBaseline(diagVec)); err != nil {
+			fmt.Fprintf(os.Stderr, "luahelper: write baseline error=%s\n", err.Error())
+			return localExitUsage
 		}
+		fmt.Fprintf(os.Stderr, "luahelper: wrote %d diagnostics to baseline %s\n", len(diagVec), options.BaselineWrite)
+		return 0
+	}
+
+	if baseline != nil {
+		var matchNum int
+		diagVec, matchNum = filterLocalBaseline(diagVec, baseline)
+		fmt.Fprintf(os.Stderr, "luahelper: %d diagnostics matched the baseline, %d new\n", matchNum, len(diagVec))
+	}
+
+	if err := writeLocalDiagnostics(os.Stdout, options.Format, diagVec); err != nil {
+		fmt.Fprintf(os.Stderr, "luahelper: %s\n", err.Error())
+		return localExitUsage
+	}
+
+	exitCode, err := getLocalExitCode(diagVec, options.FailOn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "luahelper: %s\n", err.Error())
+		return localExitUsage
 	}
+	return exitCode
 }
@@ -43,8 +43,20 @@ type LspServer struct {
 	// 所有文件的诊断错误信息, 动态的，文件实时修改了，但是没有保存的错误
 	fileChangeErrorMap map[string][]common.CheckError
 
-	// 请求互斥锁
-	requestMutex sync.Mutex
+	// luahelper.json中配置的基线，为nil表示没有配置
+	baseline *diagBaseline
+
+	// 基线中的告警的展示方式
+	baselineMode string
+
+	// 请求读写锁，查询类的请求加读锁并发执行，文件内容、配置的变化加写锁
+	requestMutex sync.RWMutex
+
+	// 代码补全缓冲的互斥锁，补全与补全的resolve共用工程中的同一份缓冲
+	completeMutex sync.Mutex
+
+	// 语义着色缓存的互斥锁
+	semanticMutex sync.Mutex
 
 	// 向中心服务器，需要上报统计的信息
 	onlineReport OnlineReport
@@ -52,6 +64,15 @@ type LspServer struct {
 	// 最后一次获取文档着色功能的时间
 	colorTime int64
 
+	// 文件最后一次返回的语义着色数据，key为文件名
+	semanticTokensMap map[string]semanticTokensCache
+
+	// 自增的语义着色resultID
+	semanticTokensID int64
+
+	// 每种inlay hint是否开启
+	inlayHintConfig common.InlayHintConfig
+
 	// 是否处理过ChangeConfiguration 标记
 	changeConfFlag bool
 
@@ -80,7 +101,13 @@ func CreateLspServer() *LspServer {
 			ClientVer:   clientVerStr,
 			FirstReport: 1,
 		},
-		colorTime:      0,
+		colorTime:         0,
+		semanticTokensMap: map[string]semanticTokensCache{},
+		inlayHintConfig: common.InlayHintConfig{
+			ParamName:  true,
+			LocalType:  true,
+			ReturnType: true,
+		},
 		changeConfFlag: false,
 	}
 
@@ -92,33 +119,51 @@ func CreateServer() *jrpc2.Server {
 	lspServer := CreateLspServer()
 
 	lspServer.server = jrpc2.NewServer(handler.Map{
-		"initialize":                          handler.New(lspServer.Initialize),
-		"initialized":                         handler.New(lspServer.Initialized),
-		"textDocument/didChange":              handler.New(lspServer.TextDocumentDidChange),
-		"textDocument/didSave":                handler.New(lspServer.TextDocumentDidSave),
-		"textDocument/didOpen":                handler.New(lspServer.TextDocumentDidOpen),
-		"textDocument/didClose":               handler.New(lspServer.TextDocumentDidClose),
-		"textDocument/definition":             handler.New(lspServer.TextDocumentDefine),
-		"textDocument/hover":                  handler.New(lspServer.TextDocumentHover),
-		"textDocument/references":             handler.New(lspServer.TextDocumentReferences),
-		"textDocument/documentSymbol":         handler.New(lspServer.TextDocumentSymbol),
-		"textDocument/rename":                 handler.New(lspServer.TextDocumentRename),
-		"textDocument/documentHighlight":      handler.New(lspServer.TextDocumentHighlight),
-		"textDocument/signatureHelp":          handler.New(lspServer.TextDocumentSignatureHelp),
-		"textDocument/documentColor":          handler.New(lspServer.TextDocumentColor),
-		"textDocument/codeLens":               handler.New(lspServer.TextDocumentCodeLens),
-		"textDocument/documentLink":           handler.New(lspServer.TextDocumentdocumentLink),
-		"textDocument/completion":             handler.New(lspServer.TextDocumentComplete),
-		"completionItem/resolve":              handler.New(lspServer.TextDocumentCompleteResolve),
-		"workspace/didChangeConfiguration":    handler.New(lspServer.ChangeConfiguration),
-		"workspace/didChangeWorkspaceFolders": handler.New(lspServer.WorkspaceChangeWorkspaceFolders),
-		"workspace/didChangeWatchedFiles":     handler.New(lspServer.WorkspaceChangeWatchedFiles),
-		"workspace/symbol":                    handler.New(lspServer.WorkspaceSymbolRequest),
-		"luahelper/getVarColor":               handler.New(lspServer.TextDocumentGetVarColor),
-		"luahelper/getOnlineReq":              handler.New(lspServer.GetOnlineReq),
-		"$/cancelRequest":                     handler.New(lspServer.CancelRequest),
-		"shutdown":                            handler.New(lspServer.Shutdown),
-		"exit":                                handler.New(lspServer.Exit),
+		"initialize":                             handler.New(lspServer.Initialize),
+		"initialized":                            handler.New(lspServer.Initialized),
+		"textDocument/didChange":                 handler.New(lspServer.TextDocumentDidChange),
+		"textDocument/didSave":                   handler.New(lspServer.TextDocumentDidSave),
+		"textDocument/didOpen":                   handler.New(lspServer.TextDocumentDidOpen),
+		"textDocument/didClose":                  handler.New(lspServer.TextDocumentDidClose),
+		"textDocument/definition":                handler.New(lspServer.TextDocumentDefine),
+		"textDocument/implementation":            handler.New(lspServer.TextDocumentImplementation),
+		"textDocument/typeDefinition":            handler.New(lspServer.TextDocumentTypeDefinition),
+		"textDocument/hover":                     handler.New(lspServer.TextDocumentHover),
+		"textDocument/references":                handler.New(lspServer.TextDocumentReferences),
+		"textDocument/documentSymbol":            handler.New(lspServer.TextDocumentSymbol),
+		"textDocument/rename":                    handler.New(lspServer.TextDocumentRename),
+		"textDocument/documentHighlight":         handler.New(lspServer.TextDocumentHighlight),
+		"textDocument/signatureHelp":             handler.New(lspServer.TextDocumentSignatureHelp),
+		"textDocument/documentColor":             handler.New(lspServer.TextDocumentColor),
+		"textDocument/codeLens":                  handler.New(lspServer.TextDocumentCodeLens),
+		"textDocument/codeAction":                handler.New(lspServer.TextDocumentCodeAction),
+		"textDocument/formatting":                handler.New(lspServer.TextDocumentFormatting),
+		"textDocument/rangeFormatting":           handler.New(lspServer.TextDocumentRangeFormatting),
+		"textDocument/onTypeFormatting":          handler.New(lspServer.TextDocumentOnTypeFormatting),
+		"textDocument/documentLink":              handler.New(lspServer.TextDocumentdocumentLink),
+		"textDocument/semanticTokens/full":       handler.New(lspServer.TextDocumentSemanticTokensFull),
+		"textDocument/semanticTokens/full/delta": handler.New(lspServer.TextDocumentSemanticTokensFullDelta),
+		"textDocument/semanticTokens/range":      handler.New(lspServer.TextDocumentSemanticTokensRange),
+		"textDocument/inlayHint":                 handler.New(lspServer.TextDocumentInlayHint),
+		"textDocument/foldingRange":              handler.New(lspServer.TextDocumentFoldingRange),
+		"textDocument/selectionRange":            handler.New(lspServer.TextDocumentSelectionRange),
+		"textDocument/prepareCallHierarchy":      handler.New(lspServer.TextDocumentPrepareCallHierarchy),
+		"callHierarchy/incomingCalls":            handler.New(lspServer.CallHierarchyIncomingCalls),
+		"callHierarchy/outgoingCalls":            handler.New(lspServer.CallHierarchyOutgoingCalls),
+		"textDocument/prepareTypeHierarchy":      handler.New(lspServer.TextDocumentPrepareTypeHierarchy),
+		"typeHierarchy/supertypes":               handler.New(lspServer.TypeHierarchySupertypes),
+		"typeHierarchy/subtypes":                 handler.New(lspServer.TypeHierarchySubtypes),
+		"textDocument/completion":                handler.New(lspServer.TextDocumentComplete),
+		"completionItem/resolve":                 handler.New(lspServer.TextDocumentCompleteResolve),
+		"workspace/didChangeConfiguration":       handler.New(lspServer.ChangeConfiguration),
+		"workspace/didChangeWorkspaceFolders":    handler.New(lspServer.WorkspaceChangeWorkspaceFolders),
+		"workspace/didChangeWatchedFiles":        handler.New(lspServer.WorkspaceChangeWatchedFiles),
+		"workspace/symbol":                       handler.New(lspServer.WorkspaceSymbolRequest),
+		"luahelper/getVarColor":                  handler.New(lspServer.TextDocumentGetVarColor),
+		"luahelper/getOnlineReq":                 handler.New(lspServer.GetOnlineReq),
+		"$/cancelRequest":                        handler.New(lspServer.CancelRequest),
+		"shutdown":                               handler.New(lspServer.Shutdown),
+		"exit":                                   handler.New(lspServer.Exit),
 	}, &jrpc2.ServerOptions{
 		AllowPush:   true,
 		Concurrency: 4,
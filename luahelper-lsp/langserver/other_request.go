@@ -2,6 +2,7 @@ package langserver
 
 import (
 	"context"
+	"encoding/json"
 
 	"luahelper-lsp/langserver/check"
 	"luahelper-lsp/langserver/check/common"
@@ -9,26 +10,45 @@ import (
 
 	"luahelper-lsp/langserver/log"
 	lsp "luahelper-lsp/langserver/protocol"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/code"
 )
 
-// CancelRequest 取消一个请求
+// requestCancelledCode LSP协议约定的请求被取消的错误码
+var requestCancelledCode = code.Register(-32800, "request cancelled")
+
+// CancelRequest 取消一个请求，正在执行的请求的ctx会被取消
 func (l *LspServer) CancelRequest(ctx context.Context, vs lsp.CancelParams) error {
 	log.Debug("CancelRequest, id=%v", vs.ID)
+	if l.server == nil {
+		return nil
+	}
+
+	// jrpc2中请求的id为原始的json内容，数字为 5，字符串为 "5"
+	switch vs.ID.(type) {
+	case float64, string:
+	default:
+		return nil
+	}
+
+	rawID, err := json.Marshal(vs.ID)
+	if err != nil {
+		log.Error("CancelRequest marshal id error, id=%v, err=%v", vs.ID, err)
+		return nil
+	}
+
+	l.server.CancelRequest(string(rawID))
 	return nil
-	// var strId string
-	// switch id := vs.ID.(type) {
-	// case float64:
-	// 	strId = fmt.Sprintf("%.f", id)
-	// 	break
-	// case string:
-	// 	strId = id
-	// 	break
-	// default:
-	// 	return nil
-	// }
-
-	// l.server.CancelRequest(strId)
-	// return nil
+}
+
+// checkRequestCancelled 判断请求是否已经被客户端取消，取消了返回LSP约定的RequestCancelled错误
+func checkRequestCancelled(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+
+	return jrpc2.Errorf(requestCancelledCode, "request cancelled")
 }
 
 // TextDocumentCodeLens 请求
@@ -57,6 +77,7 @@ type BaseParams struct {
 	IgnoreFileOrDir      []string `json:"IgnoreFileOrDir,omitempty"`
 	IgnoreFileOrDirError []string `json:"IgnoreFileOrDirError,omitempty"`
 	RequirePathSeparator string   `json:"RequirePathSeparator,omitempty"`
+	LuaVersion           string   `json:"LuaVersion,omitempty"`
 	ReferenceMaxNum      int      `json:"ReferenceMaxNum,omitempty"`
 	ReferenceDefineFlag  bool     `json:"ReferenceIncudeDefine,omitempty"`
 	PreviewFieldsNum     int      `json:"PreviewFieldsNum,omitempty"`
@@ -93,10 +114,21 @@ type WarnParams struct {
 	CheckFuncReturnType            bool `json:"CheckFuncReturnType,omitempty"`
 }
 
+// HintParams inlay hint的设置
+type HintParams struct {
+	ParamNameHint  bool `json:"ParamNameHint,omitempty"`
+	LocalTypeHint  bool `json:"LocalTypeHint,omitempty"`
+	ReturnTypeHint bool `json:"ReturnTypeHint,omitempty"`
+}
+
 // LuahelperParams 整体的设置
 type LuahelperParams struct {
-	Base      BaseParams `json:"base,omitempty"`
-	WarnParam WarnParams `json:"Warn,omitempty"`
+	Base              BaseParams                `json:"base,omitempty"`
+	WarnParam         WarnParams                `json:"Warn,omitempty"`
+	Format            common.FormatConfig       `json:"Format,omitempty"`
+	HintParam         *HintParams               `json:"Hint,omitempty"`
+	Severity          map[string]string         `json:"Severity,omitempty"`
+	SeverityOverrides []common.SeverityOverride `json:"SeverityOverrides,omitempty"`
 }
 
 // SettingsParam 设置参数
@@ -153,21 +185,44 @@ func getAssociationList(associationData interface{}) (associalList []string) {
 
 // ChangeConfiguration 修改配置请求
 func (l *LspServer) ChangeConfiguration(ctx context.Context, vs ChangeConfigurationParams) error {
+	l.requestMutex.Lock()
+	defer l.requestMutex.Unlock()
+
 	base := vs.Settings.Luahelper.Base
 	setConfigSet(base.ReferenceMaxNum, base.ReferenceDefineFlag)
 	l.enableReport = base.EnableReport
 
 	// 设置预览table成员的数量
 	common.GConfig.SetPreviewFieldsNum(base.PreviewFieldsNum)
+
+	// 代码格式化的配置，读取了luahelper.json时以json中的为准
+	if !common.GConfig.ReadJSONFlag {
+		common.GConfig.SetFormatConfig(vs.Settings.Luahelper.Format)
+	}
+
+	// inlay hint的配置，没有设置时保持默认的全部开启
+	if vs.Settings.Luahelper.HintParam != nil {
+		l.inlayHintConfig = changeInlayHintConfig(vs.Settings.Luahelper.HintParam)
+	}
+
+	// 每个规则的告警级别，读取了luahelper.json时以json中的为准
+	severityChange := common.GConfig.SetClientSeverityConfig(vs.Settings.Luahelper.Severity,
+		vs.Settings.Luahelper.SeverityOverrides)
+
+	// Lua版本，读取了luahelper.json并且配置了版本时以json中的为准
+	versionChange := common.GConfig.SetClientLuaVersion(base.LuaVersion)
 	if !l.changeConfFlag {
 		l.changeConfFlag = true
+		if severityChange || versionChange {
+			return l.handleChange(ctx)
+		}
 		return nil
 	}
 
-	l.requestMutex.Lock()
-	defer l.requestMutex.Unlock()
-
 	if common.GConfig.ReadJSONFlag {
+		if versionChange {
+			return l.handleChange(ctx)
+		}
 		return nil
 	}
 
@@ -0,0 +1,83 @@
+package langserver
+
+import (
+	"context"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestSeverityConfig(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/severity"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	ctx := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(ctx, initializeParams)
+	lspServer.GetAllDiagnostics(ctx)
+
+	getSeverityMap := func(strFile string) map[common.CheckErrorType]lsp.DiagnosticSeverity {
+		severityMap := map[common.CheckErrorType]lsp.DiagnosticSeverity{}
+		fileErrVec := lspServer.fileErrorMap[strFile]
+		for i, oneDiagnostic := range lspServer.getFileDiagnostics(strFile, fileErrVec, false) {
+			severityMap[fileErrVec[i].ErrType] = oneDiagnostic.Severity
+		}
+		return severityMap
+	}
+
+	// 1) 根目录下使用全局配置的级别，关闭的规则不报告警
+	severityMap := getSeverityMap(strRootPath + "/test1.lua")
+	if severityMap[common.CheckErrorLocalNoUse] != lsp.SeverityHint ||
+		severityMap[common.CheckErrorNoDefine] != lsp.SeverityError {
+		t.Fatalf("global severity error, %v", severityMap)
+	}
+	if _, ok := severityMap[common.CheckErrorSelfAssign]; ok {
+		t.Fatalf("self-assign should be off, %v", severityMap)
+	}
+
+	// 需要开启的告警类型，没有配置OpenErrorTypes，配置了告警级别也开启
+	if severityMap[common.CheckErrorPossibleNil] != lsp.SeverityWarning {
+		t.Fatalf("possible-nil should be enabled by severity, %v", severityMap)
+	}
+	if _, ok := severityMap[common.CheckErrorUnreachable]; ok {
+		t.Fatalf("unreachable-code should not be enabled, %v", severityMap)
+	}
+
+	// 2) 目录的配置覆盖全局的配置
+	severityMap = getSeverityMap(strRootPath + "/core/test2.lua")
+	if len(severityMap) != 1 || severityMap[common.CheckErrorLocalNoUse] != lsp.SeverityError {
+		t.Fatalf("core severity error, %v", severityMap)
+	}
+
+	// 3) *关闭目录下所有的规则
+	severityMap = getSeverityMap(strRootPath + "/tools/test3.lua")
+	if len(severityMap) != 0 {
+		t.Fatalf("tools severity error, %v", severityMap)
+	}
+}
@@ -0,0 +1,135 @@
+package langserver
+
+import (
+	"context"
+
+	"luahelper-lsp/langserver/check"
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"luahelper-lsp/langserver/log"
+	"luahelper-lsp/langserver/lspcommon"
+	"luahelper-lsp/langserver/pathpre"
+	lsp "luahelper-lsp/langserver/protocol"
+)
+
+// TextDocumentPrepareCallHierarchy 获取光标处的函数，作为调用层级的起点
+func (l *LspServer) TextDocumentPrepareCallHierarchy(ctx context.Context, vs lsp.CallHierarchyPrepareParams) (
+	itemList []lsp.CallHierarchyItem, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	fileRequest := l.beginFileRequest(vs.TextDocument.URI, vs.Position)
+	if !fileRequest.result {
+		log.Error("TextDocumentPrepareCallHierarchy beginFileRequest false, uri=%s", vs.TextDocument.URI)
+		return
+	}
+
+	if len(fileRequest.contents) == 0 || fileRequest.offset >= len(fileRequest.contents) {
+		return
+	}
+
+	varStruct := check.GetVarStruct(fileRequest.contents, fileRequest.offset, fileRequest.pos.Line,
+		fileRequest.pos.Character)
+	if !varStruct.ValidFlag || len(varStruct.StrVec) == 0 {
+		log.Error("TextDocumentPrepareCallHierarchy not valid")
+		return
+	}
+
+	project := l.getAllProject()
+	funcVec := project.PrepareCallHierarchy(fileRequest.strFile, &varStruct)
+	for _, oneFunc := range funcVec {
+		itemList = append(itemList, changeCallHierarchyItem(&oneFunc))
+	}
+
+	return itemList, nil
+}
+
+// CallHierarchyIncomingCalls 查找调用了该函数的所有函数
+func (l *LspServer) CallHierarchyIncomingCalls(ctx context.Context, vs lsp.CallHierarchyIncomingCallsParams) (
+	callList []lsp.CallHierarchyIncomingCall, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	callList = []lsp.CallHierarchyIncomingCall{}
+	project := l.getAllProject()
+	if project == nil {
+		return
+	}
+
+	callVec := project.FindIncomingCalls(changeCallHierarchyInfo(&vs.Item))
+	for _, oneCall := range callVec {
+		callList = append(callList, lsp.CallHierarchyIncomingCall{
+			From:       changeCallHierarchyItem(&oneCall.Func),
+			FromRanges: changeCallLocVec(oneCall.CallLocVec),
+		})
+	}
+
+	return callList, nil
+}
+
+// CallHierarchyOutgoingCalls 查找该函数调用的所有函数
+func (l *LspServer) CallHierarchyOutgoingCalls(ctx context.Context, vs lsp.CallHierarchyOutgoingCallsParams) (
+	callList []lsp.CallHierarchyOutgoingCall, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	callList = []lsp.CallHierarchyOutgoingCall{}
+	project := l.getAllProject()
+	if project == nil {
+		return
+	}
+
+	callVec := project.FindOutgoingCalls(changeCallHierarchyInfo(&vs.Item))
+	for _, oneCall := range callVec {
+		callList = append(callList, lsp.CallHierarchyOutgoingCall{
+			To:         changeCallHierarchyItem(&oneCall.Func),
+			FromRanges: changeCallLocVec(oneCall.CallLocVec),
+		})
+	}
+
+	return callList, nil
+}
+
+// changeCallHierarchyItem 调用层级的函数转换为lsp的格式
+func changeCallHierarchyItem(funcInfo *common.CallHierarchyInfo) lsp.CallHierarchyItem {
+	kind := lsp.Function
+	if funcInfo.MainFlag {
+		kind = lsp.File
+	} else if funcInfo.ClassName != "" {
+		kind = lsp.Method
+	}
+
+	return lsp.CallHierarchyItem{
+		Name:           funcInfo.Name,
+		Kind:           kind,
+		Detail:         funcInfo.ClassName,
+		URI:            lspcommon.GetFileDocumentURI(funcInfo.FileName),
+		Range:          lspcommon.LocToRange(&funcInfo.Loc),
+		SelectionRange: lspcommon.LocToRange(&funcInfo.NameLoc),
+	}
+}
+
+// changeCallHierarchyInfo 客户端传入的lsp格式的函数，转换为调用层级的函数
+func changeCallHierarchyInfo(item *lsp.CallHierarchyItem) common.CallHierarchyInfo {
+	return common.CallHierarchyInfo{
+		Name:     item.Name,
+		FileName: pathpre.VscodeURIToString(string(item.URI)),
+		Loc: lexer.Location{
+			StartLine:   (int)(item.Range.Start.Line) + 1,
+			StartColumn: (int)(item.Range.Start.Character),
+			EndLine:     (int)(item.Range.End.Line) + 1,
+			EndColumn:   (int)(item.Range.End.Character),
+		},
+		MainFlag: item.Kind == lsp.File,
+	}
+}
+
+// changeCallLocVec 调用发生的位置转换为lsp的格式
+func changeCallLocVec(locVec []lexer.Location) []lsp.Range {
+	rangeList := make([]lsp.Range, 0, len(locVec))
+	for _, oneLoc := range locVec {
+		rangeList = append(rangeList, lspcommon.LocToRange(&oneLoc))
+	}
+
+	return rangeList
+}
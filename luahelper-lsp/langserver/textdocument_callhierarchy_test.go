@@ -0,0 +1,251 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestCallHierarchy(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/callhierarchy"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	context := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(context, initializeParams)
+
+	fileName := strRootPath + "/" + "net.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(context, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	// function net.send(msg)
+	prepareParams := lsp.CallHierarchyPrepareParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(fileName),
+			},
+			Position: lsp.Position{
+				Line:      6,
+				Character: 15,
+			},
+		},
+	}
+	itemList, _ := lspServer.TextDocumentPrepareCallHierarchy(context, prepareParams)
+	if len(itemList) != 1 || itemList[0].Name != "send" || itemList[0].Detail != "net" {
+		t.Fatalf("prepareCallHierarchy error, len=%d", len(itemList))
+	}
+
+	incomingList, _ := lspServer.CallHierarchyIncomingCalls(context, lsp.CallHierarchyIncomingCallsParams{
+		Item: itemList[0],
+	})
+	expectIncoming := []string{"onLogin", "onLogout", "handler.lua", "broadcast"}
+	if len(incomingList) != len(expectIncoming) {
+		t.Fatalf("incomingCalls len error, len=%d", len(incomingList))
+	}
+	for index, oneCall := range incomingList {
+		if oneCall.From.Name != expectIncoming[index] || len(oneCall.FromRanges) != 1 {
+			t.Fatalf("incomingCalls error, index=%d, name=%s", index, oneCall.From.Name)
+		}
+	}
+	if incomingList[2].From.Kind != lsp.File || incomingList[1].From.Kind != lsp.Method {
+		t.Fatalf("incomingCalls kind error")
+	}
+
+	outgoingList, _ := lspServer.CallHierarchyOutgoingCalls(context, lsp.CallHierarchyOutgoingCallsParams{
+		Item: itemList[0],
+	})
+	if len(outgoingList) != 1 || outgoingList[0].To.Name != "encode" || outgoingList[0].FromRanges[0].Start.Line != 7 {
+		t.Fatalf("outgoingCalls error, len=%d", len(outgoingList))
+	}
+
+	// handler.lua 文件最外层的net.send修改为net.broadcast，调用关系需要更新
+	handlerFile := strRootPath + "/" + "handler.lua"
+	handlerData, _ := ioutil.ReadFile(handlerFile)
+	lspServer.TextDocumentDidOpen(context, lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(handlerFile),
+			Text: string(handlerData),
+		},
+	})
+	lspServer.TextDocumentDidChange(context, lsp.DidChangeTextDocumentParams{
+		TextDocument: lsp.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(handlerFile),
+			},
+		},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{
+			{
+				Range: &lsp.Range{
+					Start: lsp.Position{
+						Line:      13,
+						Character: 4,
+					},
+					End: lsp.Position{
+						Line:      13,
+						Character: 8,
+					},
+				},
+				RangeLength: 4,
+				Text:        "broadcast",
+			},
+		},
+	})
+
+	incomingList, _ = lspServer.CallHierarchyIncomingCalls(context, lsp.CallHierarchyIncomingCallsParams{
+		Item: itemList[0],
+	})
+	if len(incomingList) != 3 {
+		t.Fatalf("incomingCalls after change len error, len=%d", len(incomingList))
+	}
+	for _, oneCall := range incomingList {
+		if oneCall.From.Kind == lsp.File {
+			t.Fatalf("incomingCalls after change error, name=%s", oneCall.From.Name)
+		}
+	}
+
+	// util.lua 中新定义了caller.lua之前找不到的util.flush，caller.lua的调用关系也需要更新
+	utilFile := strRootPath + "/" + "util.lua"
+	utilData, _ := ioutil.ReadFile(utilFile)
+	lspServer.TextDocumentDidOpen(context, lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(utilFile),
+			Text: string(utilData),
+		},
+	})
+	lspServer.TextDocumentDidChange(context, lsp.DidChangeTextDocumentParams{
+		TextDocument: lsp.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(utilFile),
+			},
+		},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{
+			{
+				Range: &lsp.Range{
+					Start: lsp.Position{
+						Line:      1,
+						Character: 0,
+					},
+					End: lsp.Position{
+						Line:      1,
+						Character: 0,
+					},
+				},
+				RangeLength: 0,
+				Text:        "function util.flush()\nend\n",
+			},
+		},
+	})
+
+	itemList, _ = lspServer.TextDocumentPrepareCallHierarchy(context, lsp.CallHierarchyPrepareParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(utilFile),
+			},
+			Position: lsp.Position{
+				Line:      1,
+				Character: 15,
+			},
+		},
+	})
+	if len(itemList) != 1 || itemList[0].Name != "flush" {
+		t.Fatalf("prepareCallHierarchy after define error, len=%d", len(itemList))
+	}
+
+	incomingList, _ = lspServer.CallHierarchyIncomingCalls(context, lsp.CallHierarchyIncomingCallsParams{
+		Item: itemList[0],
+	})
+	if len(incomingList) != 1 || incomingList[0].From.Name != "flushAll" {
+		t.Fatalf("incomingCalls after define error, len=%d", len(incomingList))
+	}
+
+	// caller.lua 只修改了函数体，定义的函数没有变化，只清除caller.lua自身的调用关系，其他文件的保留
+	callerFile := strRootPath + "/" + "caller.lua"
+	callerData, _ := ioutil.ReadFile(callerFile)
+	lspServer.TextDocumentDidOpen(context, lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(callerFile),
+			Text: string(callerData),
+		},
+	})
+	lspServer.TextDocumentDidChange(context, lsp.DidChangeTextDocumentParams{
+		TextDocument: lsp.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(callerFile),
+			},
+		},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{
+			{
+				Range: &lsp.Range{
+					Start: lsp.Position{
+						Line:      4,
+						Character: 0,
+					},
+					End: lsp.Position{
+						Line:      4,
+						Character: 0,
+					},
+				},
+				RangeLength: 0,
+				Text:        "    util.flush()\n",
+			},
+		},
+	})
+
+	project := lspServer.project
+	if project.HasFileCallGraph(callerFile) {
+		t.Fatalf("call graph of changed file still cached, file=%s", callerFile)
+	}
+	for _, strFile := range []string{fileName, handlerFile, utilFile} {
+		if !project.HasFileCallGraph(strFile) {
+			t.Fatalf("call graph of unrelated file not cached, file=%s", strFile)
+		}
+	}
+
+	incomingList, _ = lspServer.CallHierarchyIncomingCalls(context, lsp.CallHierarchyIncomingCallsParams{
+		Item: itemList[0],
+	})
+	if len(incomingList) != 1 || len(incomingList[0].FromRanges) != 2 {
+		t.Fatalf("incomingCalls after body change error, len=%d", len(incomingList))
+	}
+}
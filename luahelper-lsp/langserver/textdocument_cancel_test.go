@@ -0,0 +1,116 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/code"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestCancelRequest(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/cancel"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	ctx := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(ctx, initializeParams)
+
+	fileName := strRootPath + "/a.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(ctx, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	referenceParams := lsp.ReferenceParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(fileName),
+			},
+			Position: lsp.Position{
+				Line:      3,
+				Character: 6,
+			},
+		},
+	}
+
+	// 1) 多个查找引用的请求并发执行
+	var wg sync.WaitGroup
+	resultVec := make([][]lsp.Location, 4)
+	for i := range resultVec {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			resultVec[index], _ = lspServer.TextDocumentReferences(ctx, referenceParams)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, locList := range resultVec {
+		if len(locList) != len(resultVec[0]) || len(locList) < 3 {
+			t.Fatalf("concurrent references %d error, len=%d", i, len(locList))
+		}
+	}
+
+	// 2) 请求已经被取消，返回RequestCancelled错误
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = lspServer.TextDocumentReferences(cancelCtx, referenceParams)
+	if code.FromError(err) != requestCancelledCode {
+		t.Fatalf("cancelled references error, err=%v", err)
+	}
+
+	symbolParams := lsp.WorkspaceSymbolParams{
+		Query: "gCount",
+	}
+	_, err = lspServer.WorkspaceSymbolRequest(cancelCtx, symbolParams)
+	if code.FromError(err) != requestCancelledCode {
+		t.Fatalf("cancelled workspace symbol error, err=%v", err)
+	}
+
+	// 3) 未取消的请求正常返回
+	symbolVec, err := lspServer.WorkspaceSymbolRequest(ctx, symbolParams)
+	if err != nil || len(symbolVec) == 0 {
+		t.Fatalf("workspace symbol error, err=%v, len=%d", err, len(symbolVec))
+	}
+}
@@ -0,0 +1,548 @@
+package langserver
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"luahelper-lsp/langserver/check"
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/log"
+	"luahelper-lsp/langserver/lspcommon"
+	"luahelper-lsp/langserver/pathpre"
+	lsp "luahelper-lsp/langserver/protocol"
+)
+
+// codeActionFile 快速修复时，单个文件的内容，按行拆分
+type codeActionFile struct {
+	strFile  string   // 文件名
+	contents []byte   // 文件的内容
+	lines    []string // 按行拆分后的内容，不包含换行符
+}
+
+// TextDocumentCodeAction 针对诊断错误，给出快速修复的代码
+func (l *LspServer) TextDocumentCodeAction(ctx context.Context, vs lsp.CodeActionParams) (actionList []lsp.CodeAction,
+	err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	strFile := pathpre.VscodeURIToString(string(vs.TextDocument.URI))
+	project := l.getAllProject()
+	if project == nil || !project.IsNeedHandle(strFile) {
+		log.Debug("not need to handle strFile=%s", strFile)
+		return
+	}
+
+	contents, found := l.getFileCache().GetFileContent(strFile)
+	if !found {
+		log.Error("TextDocumentCodeAction file %s not find contents", strFile)
+		return
+	}
+
+	actionFile := &codeActionFile{
+		strFile:  strFile,
+		contents: contents,
+		lines:    splitContentLines(contents),
+	}
+
+	for _, oneErr := range l.fileErrorMap[strFile] {
+		errRange := lspcommon.LocToRange(&oneErr.Loc)
+		if !isRangeOverlap(errRange, vs.Range) {
+			continue
+		}
+
+		diagnostic := changeErrToDiagnostic(strFile, &oneErr)
+		errActions := l.getErrCodeActions(ctx, actionFile, &oneErr)
+		errActions = append(errActions, getSuppressCodeActions(actionFile, &oneErr)...)
+		for _, oneAction := range errActions {
+			oneAction.Kind = lsp.QuickFix
+			oneAction.Diagnostics = []lsp.Diagnostic{diagnostic}
+			actionList = append(actionList, oneAction)
+		}
+	}
+
+	return actionList, nil
+}
+
+// getErrCodeActions 获取单个诊断错误对应的快速修复
+func (l *LspServer) getErrCodeActions(ctx context.Context, actionFile *codeActionFile,
+	checkErr *common.CheckError) []lsp.CodeAction {
+	switch checkErr.ErrType {
+	case common.CheckErrorLocalNoUse:
+		return l.codeActionLocalNoUse(ctx, actionFile, checkErr)
+	case common.CheckErrorSelfAssign:
+		return l.codeActionSelfAssign(actionFile, checkErr)
+	case common.CheckErrorTableDuplicateKey:
+		return l.codeActionDuplicateKey(actionFile, checkErr)
+	case common.CheckErrorNoDefine:
+		return l.codeActionNoDefine(actionFile, checkErr)
+	case common.CheckErrorNoFile:
+		return l.codeActionNoFile(actionFile, checkErr)
+	case common.CheckErrorUnusedSuppress:
+		return l.codeActionUnusedSuppress(actionFile, checkErr)
+	}
+
+	return nil
+}
+
+// codeActionLocalNoUse 定义了未使用的局部变量，名称前面增加_，所有的引用一起修改
+func (l *LspServer) codeActionLocalNoUse(ctx context.Context, actionFile *codeActionFile, checkErr *common.CheckError) (
+	actionList []lsp.CodeAction) {
+	errRange := lspcommon.LocToRange(&checkErr.Loc)
+	varName := getRangeText(actionFile.lines, errRange)
+	if varName == "" || strings.HasPrefix(varName, "_") {
+		return
+	}
+
+	offset, err := lspcommon.OffsetForPosition(actionFile.contents, (int)(errRange.Start.Line), (int)(errRange.Start.Character))
+	if err != nil {
+		log.Error("codeActionLocalNoUse position error=%s", err.Error())
+		return
+	}
+
+	project := l.getAllProject()
+	varStruct := check.GetVarStruct(actionFile.contents, offset, errRange.Start.Line, errRange.Start.Character)
+	if !varStruct.ValidFlag {
+		return
+	}
+
+	edit := lsp.WorkspaceEdit{
+		Changes: map[string][]lsp.TextEdit{},
+	}
+	referenVecs := project.FindReferences(ctx, actionFile.strFile, &varStruct, common.CRSRename)
+	if len(referenVecs) == 0 {
+		// 没有找到引用，至少修改定义的地方
+		referenVecs = append(referenVecs, check.DefineStruct{
+			StrFile: actionFile.strFile,
+			Loc:     checkErr.Loc,
+		})
+	}
+
+	for _, referVarInfo := range referenVecs {
+		referRange := lspcommon.LocToRange(&referVarInfo.Loc)
+		uriStr := string(lspcommon.GetFileDocumentURI(referVarInfo.StrFile))
+		edit.Changes[uriStr] = append(edit.Changes[uriStr], lsp.TextEdit{
+			Range: lsp.Range{
+				Start: referRange.Start,
+				End:   referRange.Start,
+			},
+			NewText: "_",
+		})
+	}
+
+	actionList = append(actionList, lsp.CodeAction{
+		Title:       fmt.Sprintf("Prefix '%s' with '_'", varName),
+		IsPreferred: true,
+		Edit:        edit,
+	})
+	return
+}
+
+// codeActionSelfAssign 删除自身赋值的语句
+func (l *LspServer) codeActionSelfAssign(actionFile *codeActionFile, checkErr *common.CheckError) (actionList []lsp.CodeAction) {
+	errRange := lspcommon.LocToRange(&checkErr.Loc)
+	deleteRange := expandToWholeLines(actionFile.lines, errRange)
+
+	actionList = append(actionList, lsp.CodeAction{
+		Title:       "Remove self assignment",
+		IsPreferred: true,
+		Edit:        getFileWorkspaceEdit(actionFile.strFile, deleteRange, ""),
+	})
+	return
+}
+
+// codeActionDuplicateKey 删除table中重复的key
+func (l *LspServer) codeActionDuplicateKey(actionFile *codeActionFile, checkErr *common.CheckError) (actionList []lsp.CodeAction) {
+	project := l.getAllProject()
+	fieldLoc, flag := project.FindTableFieldLoc(actionFile.strFile, checkErr.Loc)
+	if !flag {
+		return
+	}
+
+	fieldRange := lspcommon.LocToRange(&fieldLoc)
+	if fieldRange.Start.Line != fieldRange.End.Line {
+		// 跨多行的field，只处理起始和结尾都在行内的情况
+		fieldRange = expandToWholeLines(actionFile.lines, fieldRange)
+	}
+
+	// key为['key'] 这样的形式时，包含前面的[
+	startLine := []rune(getLineStr(actionFile.lines, fieldRange.Start.Line))
+	startCh := (int)(fieldRange.Start.Character)
+	if startCh > 0 && startCh <= len(startLine) {
+		index := startCh - 1
+		for index >= 0 && isSpaceRune(startLine[index]) {
+			index--
+		}
+		if index >= 0 && startLine[index] == '[' {
+			fieldRange.Start.Character = uint32(index)
+		}
+	}
+
+	// 包含后面的分隔符
+	endLine := []rune(getLineStr(actionFile.lines, fieldRange.End.Line))
+	endCh := (int)(fieldRange.End.Character)
+	index := endCh
+	for index < len(endLine) && isSpaceRune(endLine[index]) {
+		index++
+	}
+	if index < len(endLine) && (endLine[index] == ',' || endLine[index] == ';') {
+		index++
+		for index < len(endLine) && isSpaceRune(endLine[index]) {
+			index++
+		}
+		fieldRange.End.Character = uint32(index)
+	}
+
+	actionList = append(actionList, lsp.CodeAction{
+		Title:       "Remove duplicate table key",
+		IsPreferred: true,
+		Edit:        getFileWorkspaceEdit(actionFile.strFile, expandToWholeLines(actionFile.lines, fieldRange), ""),
+	})
+	return
+}
+
+// codeActionNoDefine 未定义的全局变量，声明为local变量，或是在luahelper.json中忽略这个变量
+func (l *LspServer) codeActionNoDefine(actionFile *codeActionFile, checkErr *common.CheckError) (actionList []lsp.CodeAction) {
+	errRange := lspcommon.LocToRange(&checkErr.Loc)
+	varName := getRangeText(actionFile.lines, errRange)
+	if varName == "" || strings.ContainsAny(varName, ".:[( ") {
+		return
+	}
+
+	// 1) 声明为局部变量
+	lineRunes := []rune(getLineStr(actionFile.lines, errRange.Start.Line))
+	indentNum := 0
+	for indentNum < len(lineRunes) && isSpaceRune(lineRunes[indentNum]) {
+		indentNum++
+	}
+
+	var localEdit lsp.WorkspaceEdit
+	afterStr := ""
+	if (int)(errRange.End.Character) <= len(lineRunes) {
+		afterStr = strings.TrimSpace(string(lineRunes[errRange.End.Character:]))
+	}
+	if indentNum == (int)(errRange.Start.Character) && strings.HasPrefix(afterStr, "=") &&
+		!strings.HasPrefix(afterStr, "==") {
+		// 为赋值语句的左边，直接在前面增加local
+		insertPos := errRange.Start
+		localEdit = getFileWorkspaceEdit(actionFile.strFile, lsp.Range{Start: insertPos, End: insertPos}, "local ")
+	} else {
+		// 在当前行的前面插入局部变量的定义
+		insertPos := lsp.Position{
+			Line:      errRange.Start.Line,
+			Character: 0,
+		}
+		newText := string(lineRunes[0:indentNum]) + "local " + varName + "\n"
+		localEdit = getFileWorkspaceEdit(actionFile.strFile, lsp.Range{Start: insertPos, End: insertPos}, newText)
+	}
+
+	actionList = append(actionList, lsp.CodeAction{
+		Title: fmt.Sprintf("Declare '%s' as local", varName),
+		Edit:  localEdit,
+	})
+
+	// 2) 增加到luahelper.json的IgnoreModules中
+	if ignoreEdit, ok := l.getIgnoreModuleEdit(varName); ok {
+		actionList = append(actionList, lsp.CodeAction{
+			Title: fmt.Sprintf("Add '%s' to IgnoreModules in luahelper.json", varName),
+			Edit:  ignoreEdit,
+		})
+	}
+
+	return
+}
+
+// getIgnoreModuleEdit 获取在luahelper.json中IgnoreModules增加一个忽略变量的修改
+func (l *LspServer) getIgnoreModuleEdit(varName string) (edit lsp.WorkspaceEdit, flag bool) {
+	configPath := common.GConfig.GetConfigFilePath()
+	if configPath == "" {
+		return
+	}
+
+	contents, found := l.getFileCache().GetFileContent(configPath)
+	if !found {
+		var err error
+		contents, err = ioutil.ReadFile(configPath)
+		if err != nil {
+			log.Error("getIgnoreModuleEdit read %s err=%s", configPath, err.Error())
+			return
+		}
+	}
+
+	lines := splitContentLines(contents)
+	strContent := strings.Join(lines, "\n")
+	newItem := "\"" + varName + "\""
+
+	var insertOffset int
+	var newText string
+	keyIndex := strings.Index(strContent, "\"IgnoreModules\"")
+	if keyIndex >= 0 {
+		beginIndex := strings.Index(strContent[keyIndex:], "[")
+		if beginIndex < 0 {
+			return
+		}
+		beginIndex += keyIndex
+		endIndex := strings.Index(strContent[beginIndex:], "]")
+		if endIndex < 0 {
+			return
+		}
+		endIndex += beginIndex
+
+		innerStr := strings.TrimRight(strContent[beginIndex+1:endIndex], " \t\n")
+		if strings.TrimSpace(innerStr) == "" {
+			insertOffset = beginIndex + 1
+			newText = newItem
+		} else {
+			insertOffset = beginIndex + 1 + len(innerStr)
+			newText = ", " + newItem
+		}
+	} else {
+		beginIndex := strings.Index(strContent, "{")
+		if beginIndex < 0 {
+			return
+		}
+
+		insertOffset = beginIndex + 1
+		newText = "\n\t\"IgnoreModules\": [" + newItem + "]"
+		if !strings.HasPrefix(strings.TrimSpace(strContent[beginIndex+1:]), "}") {
+			newText = newText + ","
+		}
+	}
+
+	insertPos := byteOffsetToPosition(strContent, insertOffset)
+	return getFileWorkspaceEdit(configPath, lsp.Range{Start: insertPos, End: insertPos}, newText), true
+}
+
+// codeActionNoFile 引入的文件不存在，修正为工程中最匹配的文件
+func (l *LspServer) codeActionNoFile(actionFile *codeActionFile, checkErr *common.CheckError) (actionList []lsp.CodeAction) {
+	project := l.getAllProject()
+	referStr, fixStr := project.FindReferFixStr(actionFile.strFile, checkErr.Loc)
+	if referStr == "" || fixStr == "" {
+		return
+	}
+
+	errRange := lspcommon.LocToRange(&checkErr.Loc)
+	if errRange.Start.Line != errRange.End.Line {
+		return
+	}
+
+	lineRunes := []rune(getLineStr(actionFile.lines, errRange.Start.Line))
+	if (int)(errRange.End.Character) > len(lineRunes) || errRange.Start.Character > errRange.End.Character {
+		return
+	}
+
+	rangeStr := string(lineRunes[errRange.Start.Character:errRange.End.Character])
+	index := strings.Index(rangeStr, referStr)
+	if index < 0 {
+		return
+	}
+
+	beginCh := errRange.Start.Character + uint32(len([]rune(rangeStr[0:index])))
+	replaceRange := lsp.Range{
+		Start: lsp.Position{
+			Line:      errRange.Start.Line,
+			Character: beginCh,
+		},
+		End: lsp.Position{
+			Line:      errRange.Start.Line,
+			Character: beginCh + uint32(len([]rune(referStr))),
+		},
+	}
+
+	actionList = append(actionList, lsp.CodeAction{
+		Title:       fmt.Sprintf("Change to '%s'", fixStr),
+		IsPreferred: true,
+		Edit:        getFileWorkspaceEdit(actionFile.strFile, replaceRange, fixStr),
+	})
+	return
+}
+
+// codeActionUnusedSuppress 删除没有屏蔽任何告警的---@diagnostic 注解
+func (l *LspServer) codeActionUnusedSuppress(actionFile *codeActionFile, checkErr *common.CheckError) (
+	actionList []lsp.CodeAction) {
+	errRange := lspcommon.LocToRange(&checkErr.Loc)
+	deleteRange := expandToWholeLines(actionFile.lines, errRange)
+	if deleteRange == errRange {
+		// 为尾部的注释，连同前面的空白一起删除
+		lineRunes := []rune(getLineStr(actionFile.lines, errRange.Start.Line))
+		index := (int)(errRange.Start.Character)
+		for index > 0 && index <= len(lineRunes) && isSpaceRune(lineRunes[index-1]) {
+			index--
+		}
+		deleteRange.Start.Character = uint32(index)
+	}
+
+	actionList = append(actionList, lsp.CodeAction{
+		Title:       "Remove unused diagnostic suppression",
+		IsPreferred: true,
+		Edit:        getFileWorkspaceEdit(actionFile.strFile, deleteRange, ""),
+	})
+	return
+}
+
+// getSuppressCodeActions 用---@diagnostic 注解屏蔽告警，分为屏蔽这一行与屏蔽整个文件
+func getSuppressCodeActions(actionFile *codeActionFile, checkErr *common.CheckError) (actionList []lsp.CodeAction) {
+	if checkErr.ErrType == common.CheckErrorSyntax || checkErr.ErrType == common.CheckErrorUnusedSuppress ||
+		checkErr.AnnotateSyntaxFlag {
+		return
+	}
+
+	ruleName := common.GetCheckErrorName(checkErr.ErrType)
+	errLine := uint32(checkErr.Loc.StartLine - 1)
+
+	// 1) 屏蔽这一行，上一行已经存在disable-next-line时，追加规则
+	var lineEdit lsp.WorkspaceEdit
+	preLine := ""
+	if errLine > 0 {
+		preLine = getLineStr(actionFile.lines, errLine-1)
+	}
+	trimPreLine := strings.TrimSpace(preLine)
+	if strings.HasPrefix(trimPreLine, "---@diagnostic disable-next-line:") {
+		endPos := lsp.Position{
+			Line:      errLine - 1,
+			Character: uint32(len([]rune(strings.TrimRight(preLine, " \t")))),
+		}
+		lineEdit = getFileWorkspaceEdit(actionFile.strFile, lsp.Range{Start: endPos, End: endPos}, ", "+ruleName)
+	} else {
+		lineRunes := []rune(getLineStr(actionFile.lines, errLine))
+		indentNum := 0
+		for indentNum < len(lineRunes) && isSpaceRune(lineRunes[indentNum]) {
+			indentNum++
+		}
+
+		insertPos := lsp.Position{Line: errLine, Character: 0}
+		newText := string(lineRunes[0:indentNum]) + "---@diagnostic disable-next-line: " + ruleName + "\n"
+		lineEdit = getFileWorkspaceEdit(actionFile.strFile, lsp.Range{Start: insertPos, End: insertPos}, newText)
+	}
+	actionList = append(actionList, lsp.CodeAction{
+		Title: fmt.Sprintf("Disable '%s' for this line", ruleName),
+		Edit:  lineEdit,
+	})
+
+	// 2) 屏蔽整个文件，插入在文件的开头，跳过#!开头的行
+	insertPos := lsp.Position{Line: 0, Character: 0}
+	if strings.HasPrefix(getLineStr(actionFile.lines, 0), "#!") {
+		insertPos.Line = 1
+	}
+	actionList = append(actionList, lsp.CodeAction{
+		Title: fmt.Sprintf("Disable '%s' for this file", ruleName),
+		Edit: getFileWorkspaceEdit(actionFile.strFile, lsp.Range{Start: insertPos, End: insertPos},
+			"---@diagnostic disable: "+ruleName+"\n"),
+	})
+	return
+}
+
+// getFileWorkspaceEdit 获取单个文件，单个修改的WorkspaceEdit
+func getFileWorkspaceEdit(strFile string, editRange lsp.Range, newText string) lsp.WorkspaceEdit {
+	uriStr := string(lspcommon.GetFileDocumentURI(strFile))
+	return lsp.WorkspaceEdit{
+		Changes: map[string][]lsp.TextEdit{
+			uriStr: {
+				{
+					Range:   editRange,
+					NewText: newText,
+				},
+			},
+		},
+	}
+}
+
+// splitContentLines 文件内容按行拆分，去掉行尾的\r
+func splitContentLines(contents []byte) []string {
+	lines := strings.Split(string(contents), "\n")
+	for i, oneLine := range lines {
+		lines[i] = strings.TrimSuffix(oneLine, "\r")
+	}
+
+	return lines
+}
+
+// getLineStr 获取指定行的内容，行号从0开始
+func getLineStr(lines []string, line uint32) string {
+	if (int)(line) >= len(lines) {
+		return ""
+	}
+
+	return lines[line]
+}
+
+// getRangeText 获取单行范围内的文本
+func getRangeText(lines []string, oneRange lsp.Range) string {
+	if oneRange.Start.Line != oneRange.End.Line {
+		return ""
+	}
+
+	lineRunes := []rune(getLineStr(lines, oneRange.Start.Line))
+	if (int)(oneRange.End.Character) > len(lineRunes) || oneRange.Start.Character > oneRange.End.Character {
+		return ""
+	}
+
+	return string(lineRunes[oneRange.Start.Character:oneRange.End.Character])
+}
+
+// expandToWholeLines 如果范围的前后只有空白字符，扩展为删除整行
+func expandToWholeLines(lines []string, oneRange lsp.Range) lsp.Range {
+	startRunes := []rune(getLineStr(lines, oneRange.Start.Line))
+	endRunes := []rune(getLineStr(lines, oneRange.End.Line))
+	if (int)(oneRange.Start.Character) > len(startRunes) || (int)(oneRange.End.Character) > len(endRunes) {
+		return oneRange
+	}
+
+	beforeStr := string(startRunes[0:oneRange.Start.Character])
+	afterStr := string(endRunes[oneRange.End.Character:])
+	if strings.TrimSpace(beforeStr) != "" || strings.TrimSpace(afterStr) != "" {
+		return oneRange
+	}
+
+	if (int)(oneRange.End.Line)+1 >= len(lines) {
+		// 最后一行，删除到行尾
+		return lsp.Range{
+			Start: lsp.Position{Line: oneRange.Start.Line, Character: 0},
+			End:   lsp.Position{Line: oneRange.End.Line, Character: uint32(len(endRunes))},
+		}
+	}
+
+	return lsp.Range{
+		Start: lsp.Position{Line: oneRange.Start.Line, Character: 0},
+		End:   lsp.Position{Line: oneRange.End.Line + 1, Character: 0},
+	}
+}
+
+// isRangeOverlap 判断两个范围是否有交集
+func isRangeOverlap(oneRange, twoRange lsp.Range) bool {
+	if isPositionBefore(oneRange.End, twoRange.Start) || isPositionBefore(twoRange.End, oneRange.Start) {
+		return false
+	}
+
+	return true
+}
+
+// isPositionBefore 判断位置one是否严格在two之前
+func isPositionBefore(one, two lsp.Position) bool {
+	if one.Line != two.Line {
+		return one.Line < two.Line
+	}
+
+	return one.Character < two.Character
+}
+
+// byteOffsetToPosition 内容的字节偏移转换为位置
+func byteOffsetToPosition(strContent string, offset int) lsp.Position {
+	if offset > len(strContent) {
+		offset = len(strContent)
+	}
+
+	beforeStr := strContent[0:offset]
+	line := strings.Count(beforeStr, "\n")
+	lineBegin := strings.LastIndex(beforeStr, "\n") + 1
+	return lsp.Position{
+		Line:      uint32(line),
+		Character: uint32(len([]rune(beforeStr[lineBegin:]))),
+	}
+}
+
+// isSpaceRune 是否为空白字符
+func isSpaceRune(ch rune) bool {
+	return ch == ' ' || ch == '\t'
+}
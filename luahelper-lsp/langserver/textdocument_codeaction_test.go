@@ -0,0 +1,220 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestCodeAction(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/codeaction"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	context := context.Background()
+	initOptions := getDefaultIntialOptions()
+	initOptions.CheckLocalNoUse = true
+	initOptions.CheckTableDuplicateKey = true
+	initOptions.CheckSelfAssign = true
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: initOptions,
+	}
+	lspServer.Initialize(context, initializeParams)
+	lspServer.GetAllDiagnostics(context)
+
+	fileName := strRootPath + "/" + "test1.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(context, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	actionParams := lsp.CodeActionParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(fileName),
+		},
+		Range: lsp.Range{
+			Start: lsp.Position{Line: 0, Character: 0},
+			End:   lsp.Position{Line: 12, Character: 0},
+		},
+	}
+
+	actionList, err2 := lspServer.TextDocumentCodeAction(context, actionParams)
+	if err2 != nil {
+		t.Fatalf("codeAction error")
+	}
+
+	titleMap := map[string]lsp.TextEdit{}
+	for _, oneAction := range actionList {
+		for _, editList := range oneAction.Edit.Changes {
+			if len(editList) > 0 {
+				titleMap[oneAction.Title] = editList[0]
+			}
+		}
+	}
+
+	noUseEdit, ok := titleMap["Prefix 'unused' with '_'"]
+	if !ok || noUseEdit.NewText != "_" || noUseEdit.Range.Start.Line != 1 || noUseEdit.Range.Start.Character != 10 {
+		t.Fatalf("local no use code action error")
+	}
+
+	selfEdit, ok := titleMap["Remove self assignment"]
+	if !ok || selfEdit.Range.Start.Line != 3 || selfEdit.Range.Start.Character != 0 ||
+		selfEdit.Range.End.Line != 4 || selfEdit.Range.End.Character != 0 {
+		t.Fatalf("self assign code action error")
+	}
+
+	keyEdit, ok := titleMap["Remove duplicate table key"]
+	if !ok || keyEdit.Range.Start.Line != 6 || keyEdit.Range.End.Line != 7 || keyEdit.Range.End.Character != 0 {
+		t.Fatalf("duplicate key code action error")
+	}
+}
+
+func TestCodeActionNoDefineAndNoFile(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/codeactionfix"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	context := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(context, initializeParams)
+	lspServer.GetAllDiagnostics(context)
+
+	fileName := strRootPath + "/" + "test1.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(context, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	actionParams := lsp.CodeActionParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(fileName),
+		},
+		Range: lsp.Range{
+			Start: lsp.Position{Line: 0, Character: 0},
+			End:   lsp.Position{Line: 6, Character: 0},
+		},
+	}
+
+	getTitleMap := func() map[string]lsp.TextEdit {
+		actionList, err2 := lspServer.TextDocumentCodeAction(context, actionParams)
+		if err2 != nil {
+			t.Fatalf("codeAction error")
+		}
+
+		titleMap := map[string]lsp.TextEdit{}
+		for _, oneAction := range actionList {
+			for _, editList := range oneAction.Edit.Changes {
+				if len(editList) > 0 {
+					titleMap[oneAction.Title] = editList[0]
+				}
+			}
+		}
+		return titleMap
+	}
+	titleMap := getTitleMap()
+
+	// require("wrongdir.helper") 修正为工程中同名的文件
+	fileEdit, ok := titleMap["Change to 'sub.helper'"]
+	if !ok || fileEdit.NewText != "sub.helper" || fileEdit.Range.Start.Line != 0 ||
+		fileEdit.Range.Start.Character != 24 || fileEdit.Range.End.Character != 39 {
+		t.Fatalf("no file code action error")
+	}
+
+	// return helper, notDefined 不是赋值语句，在前面插入局部变量的定义
+	localEdit, ok := titleMap["Declare 'notDefined' as local"]
+	if !ok || localEdit.NewText != "    local notDefined\n" || localEdit.Range.Start.Line != 3 ||
+		localEdit.Range.Start.Character != 0 || localEdit.Range.End != localEdit.Range.Start {
+		t.Fatalf("no define local code action error")
+	}
+
+	// luahelper.json 中IgnoreModules不存在、为空、不为空时，插入的位置与内容
+	type expectIgnore struct {
+		content string
+		line    uint32
+		char    uint32
+		newText string
+	}
+	expectList := []expectIgnore{
+		{"{\n\t\"ShowWarnFlag\": 1\n}\n", 0, 1, "\n\t\"IgnoreModules\": [\"notDefined\"],"},
+		{"{\n\t\"IgnoreModules\": []\n}\n", 1, 19, "\"notDefined\""},
+		{"{\n\t\"IgnoreModules\": [\"a\", \"b\"\n\t]\n}\n", 1, 27, ", \"notDefined\""},
+	}
+
+	ignoreTitle := "Add 'notDefined' to IgnoreModules in luahelper.json"
+	configPath := common.GConfig.GetConfigFilePath()
+	for index, oneExpect := range expectList {
+		lspServer.getFileCache().SetFileContent(configPath, []byte(oneExpect.content))
+		ignoreEdit, ok := getTitleMap()[ignoreTitle]
+		if !ok || ignoreEdit.NewText != oneExpect.newText || ignoreEdit.Range.Start.Line != oneExpect.line ||
+			ignoreEdit.Range.Start.Character != oneExpect.char || ignoreEdit.Range.End != ignoreEdit.Range.Start {
+			t.Fatalf("ignore module code action error, index=%d, newText=%s", index, ignoreEdit.NewText)
+		}
+	}
+}
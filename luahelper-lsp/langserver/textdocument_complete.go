@@ -30,8 +30,10 @@ type CompletionListTmp struct {
 
 // TextDocumentComplete  代码只能补全（提示）interface{}, error   comList lsp.CompletionListTmp
 func (l *LspServer) TextDocumentComplete(ctx context.Context, vs lsp.CompletionParams) (compltionReturn interface{}, err error) {
-	l.requestMutex.Lock()
-	defer l.requestMutex.Unlock()
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+	l.completeMutex.Lock()
+	defer l.completeMutex.Unlock()
 
 	// 判断打开的文件，是否是需要分析的文件
 	comResult := l.beginFileRequest(vs.TextDocument.URI, vs.Position)
@@ -129,7 +131,12 @@ func (l *LspServer) TextDocumentComplete(ctx context.Context, vs lsp.CompletionP
 	compVar.SplitByte = splitByte
 	compVar.ParamCandidateType = paramCandidateType
 
-	project.CodeComplete(strFile, compVar)
+	project.CodeComplete(ctx, strFile, compVar)
+	if err = checkRequestCancelled(ctx); err != nil {
+		log.Debug("TextDocumentComplete cancelled, str=%s", preCompStr)
+		return nil, err
+	}
+
 	items := l.convertToCompItems(preStr)
 	log.Debug("TextDocumentComplete str=%s, veclen=%d", preCompStr, len(items))
 	return CompletionListTmp{
@@ -644,6 +651,11 @@ func (l *LspServer) convertToCompItems(preStr string) (items []CompletionItemTmp
 // 当代码补全，客户端预览其中某一个结果时候，提示部分信息
 func (l *LspServer) TextDocumentCompleteResolve(ctx context.Context, vs lsp.CompletionItem) (compItem lsp.CompletionItem,
 	err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+	l.completeMutex.Lock()
+	defer l.completeMutex.Unlock()
+
 	compItem = vs
 	log.Debug("TextDocumentCompleteResolve sss...")
 	floatValue, flag := vs.Data.(float64)
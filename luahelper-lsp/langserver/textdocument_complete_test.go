@@ -3215,3 +3215,533 @@ func TestCompleteVarAndAnnotate(t *testing.T) {
 		}
 	}
 }
+
+// 没有注解时，根据原表推导的继承关系补全父类型的成员
+func TestCompleteMetatable(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/complete"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	lspServer := createLspTest(strRootPath, strRootURI)
+	context := context.Background()
+
+	fileName := strRootPath + "/" + "test_metatable.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	var testCompleteList []TestCompleteInfo = []TestCompleteInfo{}
+	changeMap := map[string][]string{
+		"animal:": {"speak"},
+		"dog:":    {"bark", "speak"},
+		"dog.":    {"tail", "name", "bark", "speak"},
+		"puppy:":  {"play", "bark", "speak"},
+		"Proxy.":  {"bark", "speak"},
+		"Cat:":    {"meow", "speak"},
+	}
+	for changText, resultList := range changeMap {
+		var oneComplete TestCompleteInfo
+		oneComplete.changeRange = lsp.Range{
+			Start: lsp.Position{
+				Line:      47,
+				Character: 0,
+			},
+		}
+		oneComplete.changeRange.End = oneComplete.changeRange.Start
+		oneComplete.changText = changText
+		oneComplete.compLoc = lsp.Position{
+			Line:      oneComplete.changeRange.Start.Line,
+			Character: oneComplete.changeRange.Start.Character + (uint32)(len(oneComplete.changText)),
+		}
+		oneComplete.resultList = resultList
+		testCompleteList = append(testCompleteList, oneComplete)
+	}
+
+	for _, oneComplete := range testCompleteList {
+		openParams := lsp.DidOpenTextDocumentParams{
+			TextDocument: lsp.TextDocumentItem{
+				URI:  lsp.DocumentURI(fileName),
+				Text: string(data),
+			},
+		}
+		err1 := lspServer.TextDocumentDidOpen(context, openParams)
+		if err1 != nil {
+			t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+		}
+
+		changParams := lsp.DidChangeTextDocumentParams{
+			TextDocument: lsp.VersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+			},
+			ContentChanges: []lsp.TextDocumentContentChangeEvent{
+				{
+					Range:       &oneComplete.changeRange,
+					RangeLength: 0,
+					Text:        oneComplete.changText,
+				},
+			},
+		}
+		lspServer.TextDocumentDidChange(context, changParams)
+
+		completionParams := lsp.CompletionParams{
+			TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+				TextDocument: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+				Position: oneComplete.compLoc,
+			},
+			Context: lsp.CompletionContext{
+				TriggerKind: lsp.CompletionTriggerKind(1),
+			},
+		}
+
+		completionReturn, err2 := lspServer.TextDocumentComplete(context, completionParams)
+		if err2 != nil {
+			t.Fatalf("complete file:%s err=%s", fileName, err2.Error())
+		}
+
+		completionListTmp, _ := completionReturn.(CompletionListTmp)
+		for _, resultStr := range oneComplete.resultList {
+			findFlag := false
+			for _, oneCompReturn := range completionListTmp.Items {
+				if resultStr == oneCompReturn.Label {
+					findFlag = true
+					break
+				}
+			}
+
+			if !findFlag {
+				t.Fatalf("not find complete text=%s, str=%s", oneComplete.changText, resultStr)
+			}
+		}
+	}
+}
+
+func TestCompleteClassFactory(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/classfactory"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	lspServer := createLspTest(strRootPath, strRootURI)
+	context := context.Background()
+
+	fileName := strRootPath + "/" + "test1.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	var testCompleteList []TestCompleteInfo = []TestCompleteInfo{}
+	changeMap := map[string][]string{
+		"a:":   {"speak"},
+		"d:":   {"bark", "speak"},
+		"d2:":  {"bark", "speak"},
+		"d3:":  {"bark", "speak"},
+		"w:":   {"draw", "speak"},
+		"Dog:": {"new", "bark", "speak"},
+		"Dog.": {"new", "create"},
+		"p.":   {"target"},
+	}
+	for changText, resultList := range changeMap {
+		var oneComplete TestCompleteInfo
+		oneComplete.changeRange = lsp.Range{
+			Start: lsp.Position{
+				Line:      42,
+				Character: 0,
+			},
+		}
+		oneComplete.changeRange.End = oneComplete.changeRange.Start
+		oneComplete.changText = changText
+		oneComplete.compLoc = lsp.Position{
+			Line:      oneComplete.changeRange.Start.Line,
+			Character: oneComplete.changeRange.Start.Character + (uint32)(len(oneComplete.changText)),
+		}
+		oneComplete.resultList = resultList
+		testCompleteList = append(testCompleteList, oneComplete)
+	}
+
+	for _, oneComplete := range testCompleteList {
+		openParams := lsp.DidOpenTextDocumentParams{
+			TextDocument: lsp.TextDocumentItem{
+				URI:  lsp.DocumentURI(fileName),
+				Text: string(data),
+			},
+		}
+		err1 := lspServer.TextDocumentDidOpen(context, openParams)
+		if err1 != nil {
+			t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+		}
+
+		changParams := lsp.DidChangeTextDocumentParams{
+			TextDocument: lsp.VersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+			},
+			ContentChanges: []lsp.TextDocumentContentChangeEvent{
+				{
+					Range:       &oneComplete.changeRange,
+					RangeLength: 0,
+					Text:        oneComplete.changText,
+				},
+			},
+		}
+		lspServer.TextDocumentDidChange(context, changParams)
+
+		completionParams := lsp.CompletionParams{
+			TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+				TextDocument: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+				Position: oneComplete.compLoc,
+			},
+			Context: lsp.CompletionContext{
+				TriggerKind: lsp.CompletionTriggerKind(1),
+			},
+		}
+
+		completionReturn, err2 := lspServer.TextDocumentComplete(context, completionParams)
+		if err2 != nil {
+			t.Fatalf("complete file:%s err=%s", fileName, err2.Error())
+		}
+
+		completionListTmp, _ := completionReturn.(CompletionListTmp)
+		for _, resultStr := range oneComplete.resultList {
+			findFlag := false
+			for _, oneCompReturn := range completionListTmp.Items {
+				if resultStr == oneCompReturn.Label {
+					findFlag = true
+					break
+				}
+			}
+
+			if !findFlag {
+				t.Fatalf("not find complete text=%s, str=%s", oneComplete.changText, resultStr)
+			}
+		}
+	}
+}
+
+func TestCompleteRequireExport(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/complete"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	lspServer := createLspTest(strRootPath, strRootURI)
+	context := context.Background()
+
+	fileName := strRootPath + "/" + "test_export.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	var testCompleteList []TestCompleteInfo = []TestCompleteInfo{}
+	changeMap := map[string][]string{
+		"cond.":   {"fast", "slow"},
+		"helper.": {"extra", "run"},
+		"logger.": {"info", "warn"},
+		"tracer.": {"trace"},
+	}
+	for changText, resultList := range changeMap {
+		var oneComplete TestCompleteInfo
+		oneComplete.changeRange = lsp.Range{
+			Start: lsp.Position{
+				Line:      4,
+				Character: 0,
+			},
+		}
+		oneComplete.changeRange.End = oneComplete.changeRange.Start
+		oneComplete.changText = changText
+		oneComplete.compLoc = lsp.Position{
+			Line:      oneComplete.changeRange.Start.Line,
+			Character: oneComplete.changeRange.Start.Character + (uint32)(len(oneComplete.changText)),
+		}
+		oneComplete.resultList = resultList
+		testCompleteList = append(testCompleteList, oneComplete)
+	}
+
+	for _, oneComplete := range testCompleteList {
+		openParams := lsp.DidOpenTextDocumentParams{
+			TextDocument: lsp.TextDocumentItem{
+				URI:  lsp.DocumentURI(fileName),
+				Text: string(data),
+			},
+		}
+		err1 := lspServer.TextDocumentDidOpen(context, openParams)
+		if err1 != nil {
+			t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+		}
+
+		changParams := lsp.DidChangeTextDocumentParams{
+			TextDocument: lsp.VersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+			},
+			ContentChanges: []lsp.TextDocumentContentChangeEvent{
+				{
+					Range:       &oneComplete.changeRange,
+					RangeLength: 0,
+					Text:        oneComplete.changText,
+				},
+			},
+		}
+		lspServer.TextDocumentDidChange(context, changParams)
+
+		completionParams := lsp.CompletionParams{
+			TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+				TextDocument: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+				Position: oneComplete.compLoc,
+			},
+			Context: lsp.CompletionContext{
+				TriggerKind: lsp.CompletionTriggerKind(1),
+			},
+		}
+
+		completionReturn, err2 := lspServer.TextDocumentComplete(context, completionParams)
+		if err2 != nil {
+			t.Fatalf("complete file:%s err=%s", fileName, err2.Error())
+		}
+
+		completionListTmp, _ := completionReturn.(CompletionListTmp)
+		for _, resultStr := range oneComplete.resultList {
+			findFlag := false
+			for _, oneCompReturn := range completionListTmp.Items {
+				if resultStr == oneCompReturn.Label {
+					findFlag = true
+					break
+				}
+			}
+
+			if !findFlag {
+				t.Fatalf("not find complete text=%s, str=%s", oneComplete.changText, resultStr)
+			}
+		}
+	}
+}
+
+func TestCompleteTableShape(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/complete"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	lspServer := createLspTest(strRootPath, strRootURI)
+	context := context.Background()
+
+	fileName := strRootPath + "/" + "test_table_shape.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	var testCompleteList []TestCompleteInfo = []TestCompleteInfo{}
+	changeMap := map[string][]string{
+		"cfg.": {"host", "port", "timeout", "retry", "name", "level"},
+		"opt.": {"loopKey"},
+		// fill(other) 中的other为fresh的别名，成员也作用于fresh
+		"fresh.": {"timeout", "retry"},
+		// 函数返回的r传入了fill
+		"built.": {"timeout", "retry"},
+	}
+	lineMap := map[string]uint32{
+		"cfg.":   2,
+		"opt.":   2,
+		"fresh.": 38,
+		"built.": 38,
+	}
+	for changText, resultList := range changeMap {
+		var oneComplete TestCompleteInfo
+		oneComplete.changeRange = lsp.Range{
+			Start: lsp.Position{
+				Line:      lineMap[changText],
+				Character: 0,
+			},
+		}
+		oneComplete.changeRange.End = oneComplete.changeRange.Start
+		oneComplete.changText = changText
+		oneComplete.compLoc = lsp.Position{
+			Line:      oneComplete.changeRange.Start.Line,
+			Character: oneComplete.changeRange.Start.Character + (uint32)(len(oneComplete.changText)),
+		}
+		oneComplete.resultList = resultList
+		testCompleteList = append(testCompleteList, oneComplete)
+	}
+
+	for _, oneComplete := range testCompleteList {
+		openParams := lsp.DidOpenTextDocumentParams{
+			TextDocument: lsp.TextDocumentItem{
+				URI:  lsp.DocumentURI(fileName),
+				Text: string(data),
+			},
+		}
+		err1 := lspServer.TextDocumentDidOpen(context, openParams)
+		if err1 != nil {
+			t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+		}
+
+		changParams := lsp.DidChangeTextDocumentParams{
+			TextDocument: lsp.VersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+			},
+			ContentChanges: []lsp.TextDocumentContentChangeEvent{
+				{
+					Range:       &oneComplete.changeRange,
+					RangeLength: 0,
+					Text:        oneComplete.changText,
+				},
+			},
+		}
+		lspServer.TextDocumentDidChange(context, changParams)
+
+		completionParams := lsp.CompletionParams{
+			TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+				TextDocument: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+				Position: oneComplete.compLoc,
+			},
+			Context: lsp.CompletionContext{
+				TriggerKind: lsp.CompletionTriggerKind(1),
+			},
+		}
+
+		completionReturn, err2 := lspServer.TextDocumentComplete(context, completionParams)
+		if err2 != nil {
+			t.Fatalf("complete file:%s err=%s", fileName, err2.Error())
+		}
+
+		completionListTmp, _ := completionReturn.(CompletionListTmp)
+		for _, resultStr := range oneComplete.resultList {
+			findFlag := false
+			for _, oneCompReturn := range completionListTmp.Items {
+				if resultStr == oneCompReturn.Label {
+					findFlag = true
+					break
+				}
+			}
+
+			if !findFlag {
+				t.Fatalf("not find complete text=%s, str=%s", oneComplete.changText, resultStr)
+			}
+		}
+	}
+}
+
+func TestCompleteGeneric(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/generic"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	lspServer := createLspTest(strRootPath, strRootURI)
+	context := context.Background()
+
+	fileName := strRootPath + "/" + "test1.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	if err1 := lspServer.TextDocumentDidOpen(context, openParams); err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	changeRange := lsp.Range{
+		Start: lsp.Position{
+			Line:      41,
+			Character: 0,
+		},
+	}
+	changeRange.End = changeRange.Start
+	lspServer.TextDocumentDidChange(context, lsp.DidChangeTextDocumentParams{
+		TextDocument: lsp.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(fileName),
+			},
+		},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{
+			{
+				Range:       &changeRange,
+				RangeLength: 0,
+				Text:        "strList:",
+			},
+		},
+	})
+
+	completionReturn, err2 := lspServer.TextDocumentComplete(context, lsp.CompletionParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(fileName),
+			},
+			Position: lsp.Position{
+				Line:      41,
+				Character: 8,
+			},
+		},
+		Context: lsp.CompletionContext{
+			TriggerKind: lsp.CompletionTriggerKind(1),
+		},
+	})
+	if err2 != nil {
+		t.Fatalf("complete file:%s err=%s", fileName, err2.Error())
+	}
+
+	// List<string> 的成员函数，泛型T替换为string
+	expectMap := map[string]string{
+		"get":  "->1. string",
+		"push": "push(v: string)",
+	}
+	completionListTmp, _ := completionReturn.(CompletionListTmp)
+	for _, oneItem := range completionListTmp.Items {
+		strExpect, ok := expectMap[oneItem.Label]
+		if !ok {
+			continue
+		}
+		delete(expectMap, oneItem.Label)
+
+		resultItem, err3 := lspServer.TextDocumentCompleteResolve(context, lsp.CompletionItem{
+			Label: oneItem.Label,
+			Kind:  oneItem.Kind,
+			Data:  oneItem.Data,
+		})
+		if err3 != nil {
+			t.Fatalf("TextDocumentCompleteResolve err, label=%s", oneItem.Label)
+		}
+		if !strings.Contains(resultItem.Documentation.Value, strExpect) {
+			t.Fatalf("complete generic error, label=%s, documentation=%s", oneItem.Label,
+				resultItem.Documentation.Value)
+		}
+	}
+
+	if len(expectMap) > 0 {
+		t.Fatalf("not find complete generic items, %v", expectMap)
+	}
+}
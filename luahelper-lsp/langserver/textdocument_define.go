@@ -13,8 +13,8 @@ import (
 
 // TextDocumentDefine 文件中查找变量的的定义
 func (l *LspServer) TextDocumentDefine(ctx context.Context, vs lsp.TextDocumentPositionParams) (locList []lsp.Location, err error) {
-	l.requestMutex.Lock()
-	defer l.requestMutex.Unlock()
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
 
 	fileRequest := l.beginFileRequest(vs.TextDocument.URI, vs.Position)
 	if !fileRequest.result {
@@ -71,6 +71,18 @@ func (l *LspServer) TextDocumentDefine(ctx context.Context, vs lsp.TextDocumentP
 // handleAnnotateTypeDefine 处理注解系统带来的类型定义
 func (l *LspServer) handleAnnotateTypeDefine(strFile string, contents []byte, offset int,
 	posLine int, posCharacter int) (defineVecs []check.DefineStruct, flag bool) {
+	annotateStr, col, flag := getAnnotateStrAndCol(contents, offset, posCharacter)
+	if !flag {
+		return
+	}
+
+	project := l.getAllProject()
+	defineVecs = project.AnnotateTypeDefine(strFile, annotateStr, posLine, col)
+	return
+}
+
+// getAnnotateStrAndCol 光标所在的行是否为---@注解，是的话返回注解的内容，以及光标在注解内容中的列
+func getAnnotateStrAndCol(contents []byte, offset int, posCharacter int) (annotateStr string, col int, flag bool) {
 	strLine := stringutil.GetCompeleteLineStr(contents, offset)
 	if strLine == "" {
 		return
@@ -87,12 +99,9 @@ func (l *LspServer) handleAnnotateTypeDefine(strFile string, contents []byte, of
 		return
 	}
 
-	flag = true
-	col := posCharacter - (beginIndex + 2)
-	annotateStr := strLine[beginIndex+2:]
-	project := l.getAllProject()
-	defineVecs = project.AnnotateTypeDefine(strFile, annotateStr, posLine, col)
-	return
+	col = posCharacter - (beginIndex + 2)
+	annotateStr = strLine[beginIndex+2:]
+	return annotateStr, col, true
 }
 
 // defineVecConvert 转换为返回的定义结构
@@ -172,6 +172,7 @@ func (l *LspServer) TextDocumentDidClose(ctx context.Context, vs lsp.DidCloseTex
 
 	// 文件关闭，删除cache的内容
 	project.RemoveCacheContent(strFile)
+	delete(l.semanticTokensMap, strFile)
 
 	// 文件关闭了，清除临时的错误显示
 	l.ClearChangeFileErr(ctx, strFile)
@@ -0,0 +1,92 @@
+package langserver
+
+import (
+	"context"
+
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/log"
+	"luahelper-lsp/langserver/lspcommon"
+	"luahelper-lsp/langserver/pathpre"
+	lsp "luahelper-lsp/langserver/protocol"
+)
+
+// TextDocumentFoldingRange 获取文件所有的折叠区域
+func (l *LspServer) TextDocumentFoldingRange(ctx context.Context, vs lsp.FoldingRangeParams) (
+	rangeList []lsp.FoldingRange, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	rangeList = []lsp.FoldingRange{}
+	strFile := pathpre.VscodeURIToString(string(vs.TextDocument.URI))
+	project := l.getAllProject()
+	if project == nil || !project.IsNeedHandle(strFile) {
+		log.Debug("not need to handle strFile=%s", strFile)
+		return
+	}
+
+	rangeVec := project.FindFoldingRanges(strFile)
+	for _, oneRange := range rangeVec {
+		rangeList = append(rangeList, lsp.FoldingRange{
+			StartLine: (uint32)(oneRange.StartLine - 1),
+			EndLine:   (uint32)(oneRange.EndLine - 1),
+			Kind:      changeFoldingKind(oneRange.Kind),
+		})
+	}
+
+	return
+}
+
+// TextDocumentSelectionRange 获取每个光标处由内到外的选择范围
+func (l *LspServer) TextDocumentSelectionRange(ctx context.Context, vs lsp.SelectionRangeParams) (
+	rangeList []lsp.SelectionRange, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	rangeList = []lsp.SelectionRange{}
+	strFile := pathpre.VscodeURIToString(string(vs.TextDocument.URI))
+	project := l.getAllProject()
+	if project == nil || !project.IsNeedHandle(strFile) {
+		log.Debug("not need to handle strFile=%s", strFile)
+		return
+	}
+
+	for _, onePos := range vs.Positions {
+		locVec := project.FindSelectionRanges(strFile, (int)(onePos.Line)+1, (int)(onePos.Character))
+
+		// locVec由内到外，从最外层开始构造父节点
+		var selection *lsp.SelectionRange
+		for i := len(locVec) - 1; i >= 0; i-- {
+			selection = &lsp.SelectionRange{
+				Range:  lspcommon.LocToRange(&locVec[i]),
+				Parent: selection,
+			}
+		}
+
+		// 每个位置都要返回一个结果，没有找到时返回光标处的空范围
+		if selection == nil {
+			selection = &lsp.SelectionRange{
+				Range: lsp.Range{
+					Start: onePos,
+					End:   onePos,
+				},
+			}
+		}
+		rangeList = append(rangeList, *selection)
+	}
+
+	return
+}
+
+// changeFoldingKind 折叠区域的种类转换为lsp的格式，代码块不设置种类
+func changeFoldingKind(kind common.FoldingKind) string {
+	switch kind {
+	case common.FKComment:
+		return string(lsp.Comment)
+	case common.FKRegion:
+		return string(lsp.Region)
+	case common.FKImports:
+		return string(lsp.Imports)
+	}
+
+	return ""
+}
@@ -0,0 +1,126 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestFoldingRange(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/folding"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	context := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(context, initializeParams)
+
+	fileName := strRootPath + "/fold.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(context, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	rangeList, _ := lspServer.TextDocumentFoldingRange(context, lsp.FoldingRangeParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(fileName),
+		},
+	})
+
+	expectList := []lsp.FoldingRange{
+		{StartLine: 0, EndLine: 2, Kind: string(lsp.Imports)},
+		{StartLine: 4, EndLine: 6, Kind: string(lsp.Comment)},
+		{StartLine: 7, EndLine: 14},
+		{StartLine: 8, EndLine: 9},
+		{StartLine: 10, EndLine: 11},
+		{StartLine: 12, EndLine: 13},
+		{StartLine: 17, EndLine: 19},
+		{StartLine: 22, EndLine: 25, Kind: string(lsp.Region)},
+		{StartLine: 28, EndLine: 30, Kind: string(lsp.Comment)},
+	}
+	if len(rangeList) != len(expectList) {
+		t.Fatalf("folding range len=%d, expect=%d, %v", len(rangeList), len(expectList), rangeList)
+	}
+	for i, oneRange := range rangeList {
+		if oneRange != expectList[i] {
+			t.Fatalf("folding range %d error, %v, expect=%v", i, oneRange, expectList[i])
+		}
+	}
+
+	// print(1) 中的1
+	selectionList, _ := lspServer.TextDocumentSelectionRange(context, lsp.SelectionRangeParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(fileName),
+		},
+		Positions: []lsp.Position{{Line: 9, Character: 14}},
+	})
+	if len(selectionList) != 1 {
+		t.Fatalf("selection range len=%d, expect=1", len(selectionList))
+	}
+
+	selection := &selectionList[0]
+	if selection.Range.Start != (lsp.Position{Line: 9, Character: 14}) || selection.Range.End.Character != 15 {
+		t.Fatalf("selection range first error, %v", selection.Range)
+	}
+	if selection.Parent == nil || selection.Parent.Range.Start != (lsp.Position{Line: 9, Character: 8}) {
+		t.Fatalf("selection range parent error, %v", selection.Parent)
+	}
+
+	deep := 0
+	for ; selection.Parent != nil; selection = selection.Parent {
+		deep++
+		childRange := selection.Range
+		parentRange := selection.Parent.Range
+		if comparePosition(parentRange.Start, childRange.Start) > 0 || comparePosition(parentRange.End, childRange.End) < 0 {
+			t.Fatalf("selection range parent %v not contain %v", parentRange, childRange)
+		}
+	}
+	if deep < 5 {
+		t.Fatalf("selection range deep=%d, expect at least 5", deep)
+	}
+}
+
+func comparePosition(one, two lsp.Position) int {
+	if one.Line != two.Line {
+		return int(one.Line) - int(two.Line)
+	}
+	return int(one.Character) - int(two.Character)
+}
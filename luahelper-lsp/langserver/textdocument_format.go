@@ -0,0 +1,157 @@
+package langserver
+
+import (
+	"context"
+	"strings"
+
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/formatter"
+	"luahelper-lsp/langserver/log"
+	"luahelper-lsp/langserver/lspcommon"
+	"luahelper-lsp/langserver/pathpre"
+	lsp "luahelper-lsp/langserver/protocol"
+)
+
+// TextDocumentFormatting 格式化整个文件
+func (l *LspServer) TextDocumentFormatting(ctx context.Context, vs lsp.DocumentFormattingParams) (edits []lsp.TextEdit,
+	err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	contents, ok := l.getFormatContents(vs.TextDocument.URI)
+	if !ok {
+		return
+	}
+
+	fmtEdits, fmtErr := formatter.Format(contents, getFormatOptions(vs.Options))
+	if fmtErr != nil {
+		log.Debug("TextDocumentFormatting not format, err=%s", fmtErr.Error())
+		return
+	}
+
+	return changeFormatEdits(fmtEdits), nil
+}
+
+// TextDocumentRangeFormatting 格式化文件中选中的行
+func (l *LspServer) TextDocumentRangeFormatting(ctx context.Context, vs lsp.DocumentRangeFormattingParams) (
+	edits []lsp.TextEdit, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	contents, ok := l.getFormatContents(vs.TextDocument.URI)
+	if !ok {
+		return
+	}
+
+	// 选中的结尾在行首时，不包含该行
+	beginLine := (int)(vs.Range.Start.Line) + 1
+	endLine := (int)(vs.Range.End.Line) + 1
+	if vs.Range.End.Character == 0 && endLine > beginLine {
+		endLine--
+	}
+
+	fmtEdits, fmtErr := formatter.FormatRange(contents, getFormatOptions(vs.Options), beginLine, endLine)
+	if fmtErr != nil {
+		log.Debug("TextDocumentRangeFormatting not format, err=%s", fmtErr.Error())
+		return
+	}
+
+	return changeFormatEdits(fmtEdits), nil
+}
+
+// TextDocumentOnTypeFormatting 输入end之后，格式化end对应的代码块
+func (l *LspServer) TextDocumentOnTypeFormatting(ctx context.Context, vs lsp.DocumentOnTypeFormattingParams) (
+	edits []lsp.TextEdit, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	contents, ok := l.getFormatContents(vs.TextDocument.URI)
+	if !ok {
+		return
+	}
+
+	// 判断光标前面是否刚好输入完end关键字
+	offset, posErr := lspcommon.OffsetForPosition(contents, (int)(vs.Position.Line), (int)(vs.Position.Character))
+	if posErr != nil || offset < 3 || string(contents[offset-3:offset]) != "end" {
+		return
+	}
+	if offset > 3 && isIdentifierByte(contents[offset-4]) {
+		return
+	}
+	if offset < len(contents) && isIdentifierByte(contents[offset]) {
+		return
+	}
+
+	fmtEdits, fmtErr := formatter.FormatOnType(contents, getFormatOptions(vs.Options), (int)(vs.Position.Line)+1,
+		(int)(vs.Position.Character))
+	if fmtErr != nil {
+		log.Debug("TextDocumentOnTypeFormatting not format, err=%s", fmtErr.Error())
+		return
+	}
+
+	return changeFormatEdits(fmtEdits), nil
+}
+
+// getFormatContents 获取需要格式化的文件内容
+func (l *LspServer) getFormatContents(uri lsp.DocumentURI) (contents []byte, ok bool) {
+	strFile := pathpre.VscodeURIToString(string(uri))
+	project := l.getAllProject()
+	if project == nil || !project.IsNeedHandle(strFile) {
+		log.Debug("not need to handle strFile=%s", strFile)
+		return
+	}
+
+	contents, ok = l.getFileCache().GetFileContent(strFile)
+	if !ok {
+		log.Error("format file %s not find contents", strFile)
+	}
+	return
+}
+
+// getFormatOptions 合并客户端请求中的设置与配置文件中的格式化配置
+func getFormatOptions(lspOptions lsp.FormattingOptions) formatter.Options {
+	opts := formatter.DefaultOptions()
+	if lspOptions.TabSize > 0 {
+		opts.IndentWidth = (int)(lspOptions.TabSize)
+	}
+	opts.UseTab = !lspOptions.InsertSpaces
+
+	formatConfig := common.GConfig.GetFormatConfig()
+	if formatConfig.IndentWidth > 0 {
+		opts.IndentWidth = formatConfig.IndentWidth
+	}
+	if formatConfig.QuoteStyle != "" {
+		opts.QuoteStyle = strings.ToLower(formatConfig.QuoteStyle)
+	}
+	if formatConfig.TableLineBreak != "" {
+		opts.TableLineBreak = strings.ToLower(formatConfig.TableLineBreak)
+	}
+	if formatConfig.TrailingSeparator != "" {
+		opts.TrailingSeparator = strings.ToLower(formatConfig.TrailingSeparator)
+	}
+	if formatConfig.ColumnLimit > 0 {
+		opts.ColumnLimit = formatConfig.ColumnLimit
+	}
+	opts.SpaceInsideBraces = formatConfig.SpaceInsideBraces
+	opts.LuaVersion = common.GConfig.GetLuaVersion()
+
+	return opts
+}
+
+// changeFormatEdits 格式化的修改转换为lsp的格式
+func changeFormatEdits(fmtEdits []formatter.TextEdit) []lsp.TextEdit {
+	edits := make([]lsp.TextEdit, 0, len(fmtEdits))
+	for _, oneEdit := range fmtEdits {
+		edits = append(edits, lsp.TextEdit{
+			Range:   lspcommon.LocToRange(&oneEdit.Loc),
+			NewText: oneEdit.NewText,
+		})
+	}
+
+	return edits
+}
+
+// isIdentifierByte 是否为标识符中的字符
+func isIdentifierByte(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
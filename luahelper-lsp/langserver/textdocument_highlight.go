@@ -12,8 +12,8 @@ import (
 // TextDocumentHighlight 对变量单击选中着色
 func (l *LspServer) TextDocumentHighlight(ctx context.Context, vs lsp.TextDocumentPositionParams) (retVec []lsp.DocumentHighlight,
 	err error) {
-	l.requestMutex.Lock()
-	defer l.requestMutex.Unlock()
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
 
 	if !l.isCanHighlight() {
 		log.Error("IsCanHighlight is false")
@@ -37,7 +37,7 @@ func (l *LspServer) TextDocumentHighlight(ctx context.Context, vs lsp.TextDocume
 	}
 
 	// 去掉前缀后的名字
-	referenVecs := project.FindReferences(comResult.strFile, &varStruct, common.CRSHighlight)
+	referenVecs := project.FindReferences(ctx, comResult.strFile, &varStruct, common.CRSHighlight)
 	retVec = make([]lsp.DocumentHighlight, 0, len(referenVecs))
 	for _, referVarInfo := range referenVecs {
 		retVec = append(retVec, lsp.DocumentHighlight{
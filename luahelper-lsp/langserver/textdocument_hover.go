@@ -26,6 +26,9 @@ type MarkupHover struct {
 
 // TextDocumentHover 文件中查找变量的的定义
 func (l *LspServer) TextDocumentHover(ctx context.Context, vs lsp.TextDocumentPositionParams) (hoverReturn interface{}, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
 	comResult := l.beginFileRequest(vs.TextDocument.URI, vs.Position)
 	if !comResult.result {
 		return nil, nil
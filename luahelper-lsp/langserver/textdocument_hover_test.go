@@ -1488,3 +1488,157 @@ func TestHoverFuncReturn(t *testing.T) {
 		}
 	}
 }
+
+// hover 泛型推导出来的类型
+func TestHoverGeneric(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/hover"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	lspServer := createLspTest(strRootPath, strRootURI)
+	context := context.Background()
+
+	fileName := strRootPath + "/" + "hover_generic.lua"
+	data, err := ioutil.ReadFile(fileName)
+
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(context, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	var resultList [][]string = [][]string{}
+	var positionList []lsp.Position = []lsp.Position{}
+	positionList = append(positionList, lsp.Position{
+		Line:      26,
+		Character: 8,
+	})
+	resultList = append(resultList, []string{"list1 : number[]"})
+
+	positionList = append(positionList, lsp.Position{
+		Line:      30,
+		Character: 8,
+	})
+	resultList = append(resultList, []string{"first1 : string"})
+
+	positionList = append(positionList, lsp.Position{
+		Line:      31,
+		Character: 8,
+	})
+	resultList = append(resultList, []string{"get1 : string"})
+
+	positionList = append(positionList, lsp.Position{
+		Line:      35,
+		Character: 8,
+	})
+	resultList = append(resultList, []string{"dog1 : Animal"})
+
+	for index, onePoisiton := range positionList {
+		hoverParams := lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(fileName),
+			},
+			Position: onePoisiton,
+		}
+		hoverReturn1, err1 := lspServer.TextDocumentHover(context, hoverParams)
+		if err1 != nil {
+			t.Fatalf("TextDocumentHover file:%s err=%s", fileName, err1.Error())
+		}
+
+		hoverMarkUpReturn1, _ := hoverReturn1.(MarkupHover)
+
+		for _, oneStr := range resultList[index] {
+			if !strings.Contains(hoverMarkUpReturn1.Contents.Value, oneStr) {
+				t.Fatalf("hover error, not find str=%s, index=%d, value=%s", oneStr, index, hoverMarkUpReturn1.Contents.Value)
+			}
+		}
+	}
+}
+
+// hover 根据控制流收窄的类型
+func TestHoverNarrow(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/hover"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	lspServer := createLspTest(strRootPath, strRootURI)
+	context := context.Background()
+
+	fileName := strRootPath + "/" + "hover_narrow.lua"
+	data, err := ioutil.ReadFile(fileName)
+
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(context, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	var resultList [][]string = [][]string{}
+	var positionList []lsp.Position = []lsp.Position{}
+	positionList = append(positionList, lsp.Position{
+		Line:      6,
+		Character: 14,
+	})
+	resultList = append(resultList, []string{"x : Foo ="})
+
+	positionList = append(positionList, lsp.Position{
+		Line:      8,
+		Character: 14,
+	})
+	resultList = append(resultList, []string{"x : string\n"})
+
+	positionList = append(positionList, lsp.Position{
+		Line:      14,
+		Character: 10,
+	})
+	resultList = append(resultList, []string{"x : string | Foo ="})
+
+	positionList = append(positionList, lsp.Position{
+		Line:      20,
+		Character: 6,
+	})
+	resultList = append(resultList, []string{"y : string\n"})
+
+	for index, onePoisiton := range positionList {
+		hoverParams := lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(fileName),
+			},
+			Position: onePoisiton,
+		}
+		hoverReturn1, err1 := lspServer.TextDocumentHover(context, hoverParams)
+		if err1 != nil {
+			t.Fatalf("TextDocumentHover file:%s err=%s", fileName, err1.Error())
+		}
+
+		hoverMarkUpReturn1, _ := hoverReturn1.(MarkupHover)
+
+		for _, oneStr := range resultList[index] {
+			if !strings.Contains(hoverMarkUpReturn1.Contents.Value, oneStr) {
+				t.Fatalf("hover error, not find str=%s, index=%d, value=%s", oneStr, index, hoverMarkUpReturn1.Contents.Value)
+			}
+		}
+	}
+}
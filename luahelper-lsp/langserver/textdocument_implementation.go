@@ -0,0 +1,52 @@
+package langserver
+
+import (
+	"context"
+
+	"luahelper-lsp/langserver/check"
+	"luahelper-lsp/langserver/log"
+	lsp "luahelper-lsp/langserver/protocol"
+)
+
+// TextDocumentImplementation 查找类型方法的所有实现，包括子类型重写的方法，以及---@field声明的成员具体赋值的函数
+func (l *LspServer) TextDocumentImplementation(ctx context.Context, vs lsp.ImplementationParams) (
+	locList []lsp.Location, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	fileRequest := l.beginFileRequest(vs.TextDocument.URI, vs.Position)
+	if !fileRequest.result {
+		log.Error("TextDocumentImplementation beginFileRequest false, uri=%s", vs.TextDocument.URI)
+		return
+	}
+
+	if len(fileRequest.contents) == 0 || fileRequest.offset >= len(fileRequest.contents) {
+		return
+	}
+
+	project := l.getAllProject()
+	var defineVecs []check.DefineStruct
+
+	// 1) 判断是否为---@field 注解声明的成员
+	annotateStr, col, flag := getAnnotateStrAndCol(fileRequest.contents, fileRequest.offset,
+		(int)(fileRequest.pos.Character))
+	if flag {
+		defineVecs = project.FindAnnotateImplementations(fileRequest.strFile, annotateStr,
+			(int)(fileRequest.pos.Line), col)
+	} else {
+		// 2) 代码中类型的方法
+		varStruct := check.GetVarStruct(fileRequest.contents, fileRequest.offset, fileRequest.pos.Line,
+			fileRequest.pos.Character)
+		if !varStruct.ValidFlag || len(varStruct.StrVec) == 0 {
+			log.Error("TextDocumentImplementation not valid")
+			return
+		}
+		defineVecs = project.FindImplementations(fileRequest.strFile, &varStruct)
+	}
+
+	locList = defineVecConvert(defineVecs)
+	if locList == nil {
+		locList = []lsp.Location{}
+	}
+	return locList, nil
+}
@@ -0,0 +1,105 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestImplementation(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/implementation"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	context := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(context, initializeParams)
+
+	fileName := strRootPath + "/base.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(context, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	type implementationResult struct {
+		file string
+		line uint32
+	}
+	checkFunc := func(line, character uint32, expectVec []implementationResult) {
+		locList, _ := lspServer.TextDocumentImplementation(context, lsp.ImplementationParams{
+			TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+				TextDocument: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+				Position: lsp.Position{
+					Line:      line,
+					Character: character,
+				},
+			},
+		})
+
+		if len(locList) != len(expectVec) {
+			t.Fatalf("line=%d implementation len=%d, expect=%d, %v", line, len(locList), len(expectVec), locList)
+		}
+		for i, oneLoc := range locList {
+			if !strings.HasSuffix(string(oneLoc.URI), expectVec[i].file) || oneLoc.Range.Start.Line != expectVec[i].line {
+				t.Fatalf("line=%d implementation %d error, uri=%s line=%d, expect=%s line=%d", line, i, oneLoc.URI,
+					oneLoc.Range.Start.Line, expectVec[i].file, expectVec[i].line)
+			}
+		}
+	}
+
+	overrideVec := []implementationResult{
+		{"base.lua", 5},
+		{"child.lua", 3},
+		{"grand.lua", 3},
+	}
+
+	// 1) 父类型定义的方法，列出所有子类型重写的方法，父类型用---@field声明了，也包括自身的实现
+	checkFunc(5, 20, overrideVec)
+
+	// 2) ---@field 声明的方法，包括自身的实现以及子类型重写的方法
+	checkFunc(1, 12, overrideVec)
+
+	// 3) ---@field fun(...) 声明的成员，列出具体赋值的函数
+	checkFunc(2, 12, []implementationResult{{"base.lua", 8}})
+}
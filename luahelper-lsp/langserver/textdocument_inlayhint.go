@@ -0,0 +1,62 @@
+package langserver
+
+import (
+	"context"
+
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/log"
+	"luahelper-lsp/langserver/pathpre"
+	lsp "luahelper-lsp/langserver/protocol"
+)
+
+// TextDocumentInlayHint 获取文件指定范围内的inlay hint
+func (l *LspServer) TextDocumentInlayHint(ctx context.Context, vs lsp.InlayHintParams) (hintList []lsp.InlayHint,
+	err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	hintList = []lsp.InlayHint{}
+	hintConfig := l.inlayHintConfig
+	if !hintConfig.ParamName && !hintConfig.LocalType && !hintConfig.ReturnType {
+		return
+	}
+
+	strFile := pathpre.VscodeURIToString(string(vs.TextDocument.URI))
+	project := l.getAllProject()
+	if project == nil || !project.IsNeedHandle(strFile) {
+		log.Debug("not need to handle strFile=%s", strFile)
+		return
+	}
+
+	beginLine := (int)(vs.Range.Start.Line) + 1
+	endLine := (int)(vs.Range.End.Line) + 1
+	hintVec := project.FindInlayHints(strFile, beginLine, endLine, hintConfig)
+	for _, oneHint := range hintVec {
+		hint := lsp.InlayHint{
+			Position: lsp.Position{
+				Line:      (uint32)(oneHint.Line - 1),
+				Character: (uint32)(oneHint.Character),
+			},
+			Label: oneHint.Label,
+		}
+
+		if oneHint.HintType == common.IHParamName {
+			hint.Kind = lsp.ParameterInlayHint
+			hint.PaddingRight = true
+		} else {
+			hint.Kind = lsp.TypeInlayHint
+		}
+		hintList = append(hintList, hint)
+	}
+
+	return
+}
+
+// changeInlayHintConfig 客户端的设置转换为inlay hint的配置
+func changeInlayHintConfig(hintParam *HintParams) common.InlayHintConfig {
+	return common.InlayHintConfig{
+		ParamName:  hintParam.ParamNameHint,
+		LocalType:  hintParam.LocalTypeHint,
+		ReturnType: hintParam.ReturnTypeHint,
+	}
+}
@@ -0,0 +1,120 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestInlayHint(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/inlayhint"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	context := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(context, initializeParams)
+
+	fileName := strRootPath + "/" + "test1.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(context, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	hintParams := lsp.InlayHintParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(fileName),
+		},
+		Range: lsp.Range{
+			Start: lsp.Position{Line: 0, Character: 0},
+			End:   lsp.Position{Line: 32, Character: 0},
+		},
+	}
+	hintList, err2 := lspServer.TextDocumentInlayHint(context, hintParams)
+	if err2 != nil {
+		t.Fatalf("inlayHint error")
+	}
+
+	type expectHint struct {
+		line  uint32
+		char  uint32
+		label string
+		kind  lsp.InlayHintKind
+	}
+	expectList := []expectHint{
+		{18, 16, "first:", lsp.ParameterInlayHint},  // add(1, 2)
+		{18, 19, "second:", lsp.ParameterInlayHint}, // add(1, 2)
+		{20, 25, "second:", lsp.ParameterInlayHint}, // add(first, sum)，first同名不显示
+		{21, 9, ": number", lsp.TypeInlayHint},      // local num, str = pair()
+		{21, 14, ": string", lsp.TypeInlayHint},     // local num, str = pair()
+		{22, 8, ": Point", lsp.TypeInlayHint},       // local pt = Point:new(5)
+		{22, 21, "px:", lsp.ParameterInlayHint},     // Point:new(5)，忽略self
+		{24, 18, "first:", lsp.ParameterInlayHint},  // ---@type注解了的不显示类型
+		{24, 21, "second:", lsp.ParameterInlayHint},
+		{31, 11, ": 红色的按钮 | 绿色的按钮 | 蓝色的按钮 | 黄色的按钮 | 白色的按钮 | ...", lsp.TypeInlayHint}, // 按字符截断
+	}
+	if len(hintList) != len(expectList) {
+		t.Fatalf("inlayHint num error, num=%d, expect=%d", len(hintList), len(expectList))
+	}
+	for index, oneExpect := range expectList {
+		oneHint := hintList[index]
+		if oneHint.Position.Line != oneExpect.line || oneHint.Position.Character != oneExpect.char ||
+			oneHint.Label != oneExpect.label || oneHint.Kind != oneExpect.kind {
+			t.Fatalf("inlayHint error, index=%d, line=%d, char=%d, label=%s", index, oneHint.Position.Line,
+				oneHint.Position.Character, oneHint.Label)
+		}
+	}
+
+	// 关闭参数名称的hint，只剩下类型的hint
+	lspServer.inlayHintConfig = changeInlayHintConfig(&HintParams{
+		LocalTypeHint:  true,
+		ReturnTypeHint: true,
+	})
+	hintList, _ = lspServer.TextDocumentInlayHint(context, hintParams)
+	if len(hintList) != 4 {
+		t.Fatalf("inlayHint config error, num=%d", len(hintList))
+	}
+	for _, oneHint := range hintList {
+		if oneHint.Kind != lsp.TypeInlayHint {
+			t.Fatalf("inlayHint config error, label=%s", oneHint.Label)
+		}
+	}
+}
@@ -11,6 +11,9 @@ import (
 
 // TextDocumentReferences 文件中查找符合的所有的引用
 func (l *LspServer) TextDocumentReferences(ctx context.Context, vs protocol.ReferenceParams) (locList []protocol.Location, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
 	comResult := l.beginFileRequest(vs.TextDocument.URI, vs.Position)
 	if !comResult.result {
 		return
@@ -27,20 +30,13 @@ func (l *LspServer) TextDocumentReferences(ctx context.Context, vs protocol.Refe
 		return
 	}
 
-	// subCtx, cancel := context.WithTimeout(ctx, 1 * time.Second)
-	// defer cancel()
-
-	// time.Sleep(time.Second * 2)
-
-	// select {
-	// case <-subCtx.Done():
-	// 	fmt.Println("main", ctx.Err())
-	// default:
-	// 	log.Error("not hit")
-	// }
-
 	// 去掉前缀后的名字
-	referenVecs := project.FindReferences(comResult.strFile, &varStruct, common.CRSReference)
+	referenVecs := project.FindReferences(ctx, comResult.strFile, &varStruct, common.CRSReference)
+	if err = checkRequestCancelled(ctx); err != nil {
+		log.Debug("TextDocumentReferences cancelled, file=%s", comResult.strFile)
+		return nil, err
+	}
+
 	locList = make([]protocol.Location, 0, len(referenVecs))
 	referenceNum := common.GConfig.ReferenceMaxNum
 	for i, referVarInfo := range referenVecs {
@@ -11,6 +11,9 @@ import (
 
 // TextDocumentRename 批量更改名字
 func (l *LspServer) TextDocumentRename(ctx context.Context, vs lsp.RenameParams) (edit lsp.WorkspaceEdit, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
 	// 判断打开的文件，是否是需要分析的文件
 	comResult := l.beginFileRequest(vs.TextDocument.URI, vs.Position)
 	if !comResult.result {
@@ -29,7 +32,12 @@ func (l *LspServer) TextDocumentRename(ctx context.Context, vs lsp.RenameParams)
 	}
 
 	// 去掉前缀后的名字
-	referenVecs := project.FindReferences(comResult.strFile, &varStruct, common.CRSRename)
+	referenVecs := project.FindReferences(ctx, comResult.strFile, &varStruct, common.CRSRename)
+	if err = checkRequestCancelled(ctx); err != nil {
+		log.Debug("TextDocumentRename cancelled, file=%s", comResult.strFile)
+		return edit, err
+	}
+
 	edit.Changes = map[string][]lsp.TextEdit{}
 
 	for _, referVarInfo := range referenVecs {
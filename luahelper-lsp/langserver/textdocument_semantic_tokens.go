@@ -0,0 +1,251 @@
+package langserver
+
+import (
+	"context"
+	"strconv"
+
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/log"
+	"luahelper-lsp/langserver/pathpre"
+	lsp "luahelper-lsp/langserver/protocol"
+)
+
+// 语义着色legend中的类型，顺序与下标对应
+var semanticTokenTypes = []string{
+	"variable",
+	"parameter",
+	"function",
+	"class",
+	"enumMember",
+}
+
+// 语义着色legend中的修饰，第几个修饰对应第几个bit位
+var semanticTokenModifiers = []string{
+	"declaration",
+	"readonly",
+	"deprecated",
+	"global",
+	"upvalue",
+}
+
+const (
+	stTypeVariable   uint32 = 0
+	stTypeParameter  uint32 = 1
+	stTypeFunction   uint32 = 2
+	stTypeClass      uint32 = 3
+	stTypeEnumMember uint32 = 4
+
+	stModDeclaration uint32 = 1 << 0
+	stModReadonly    uint32 = 1 << 1
+	stModDeprecated  uint32 = 1 << 2
+	stModGlobal      uint32 = 1 << 3
+	stModUpvalue     uint32 = 1 << 4
+)
+
+// semanticTokensCache 文件最后一次返回的语义着色数据，用于计算delta
+type semanticTokensCache struct {
+	resultID string
+	data     []uint32
+}
+
+// getSemanticTokensOptions 初始化时返回的语义着色能力
+func getSemanticTokensOptions() lsp.SemanticTokensOptions {
+	return lsp.SemanticTokensOptions{
+		Legend: lsp.SemanticTokensLegend{
+			TokenTypes:     semanticTokenTypes,
+			TokenModifiers: semanticTokenModifiers,
+		},
+		Range: true,
+		Full: map[string]bool{
+			"delta": true,
+		},
+	}
+}
+
+// TextDocumentSemanticTokensFull 获取整个文件的语义着色
+func (l *LspServer) TextDocumentSemanticTokensFull(ctx context.Context, vs lsp.SemanticTokensParams) (
+	result lsp.SemanticTokens, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	result.Data = []uint32{}
+	strFile, tokenVec, ok := l.getSemanticTokens(vs.TextDocument.URI)
+	if !ok {
+		return
+	}
+
+	result.Data = encodeSemanticTokens(tokenVec, nil)
+	l.semanticMutex.Lock()
+	result.ResultID = l.saveSemanticTokens(strFile, result.Data)
+	l.semanticMutex.Unlock()
+	return
+}
+
+// TextDocumentSemanticTokensFullDelta 获取文件的语义着色，与上一次的结果比较，只返回变化的部分
+func (l *LspServer) TextDocumentSemanticTokensFullDelta(ctx context.Context, vs lsp.SemanticTokensDeltaParams) (
+	result interface{}, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	strFile, tokenVec, ok := l.getSemanticTokens(vs.TextDocument.URI)
+	if !ok {
+		return lsp.SemanticTokens{Data: []uint32{}}, nil
+	}
+
+	data := encodeSemanticTokens(tokenVec, nil)
+	l.semanticMutex.Lock()
+	oldCache, hasOld := l.semanticTokensMap[strFile]
+	resultID := l.saveSemanticTokens(strFile, data)
+	l.semanticMutex.Unlock()
+
+	// 之前的结果不存在，返回完整的数据
+	if !hasOld || oldCache.resultID != vs.PreviousResultID {
+		return lsp.SemanticTokens{
+			ResultID: resultID,
+			Data:     data,
+		}, nil
+	}
+
+	delta := lsp.SemanticTokensDelta{
+		ResultID: resultID,
+		Edits:    []lsp.SemanticTokensEdit{},
+	}
+	if edit, changed := diffSemanticTokens(oldCache.data, data); changed {
+		delta.Edits = append(delta.Edits, edit)
+	}
+	return delta, nil
+}
+
+// TextDocumentSemanticTokensRange 获取文件指定范围的语义着色
+func (l *LspServer) TextDocumentSemanticTokensRange(ctx context.Context, vs lsp.SemanticTokensRangeParams) (
+	result lsp.SemanticTokens, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	result.Data = []uint32{}
+	_, tokenVec, ok := l.getSemanticTokens(vs.TextDocument.URI)
+	if !ok {
+		return
+	}
+
+	result.Data = encodeSemanticTokens(tokenVec, &vs.Range)
+	return
+}
+
+// getSemanticTokens 获取文件所有的语义着色符号
+func (l *LspServer) getSemanticTokens(uri lsp.DocumentURI) (strFile string, tokenVec []common.SemanticToken, ok bool) {
+	strFile = pathpre.VscodeURIToString(string(uri))
+	project := l.getAllProject()
+	if project == nil || !project.IsNeedHandle(strFile) {
+		log.Debug("not need to handle strFile=%s", strFile)
+		return
+	}
+
+	tokenVec = project.FindSemanticTokens(strFile)
+	return strFile, tokenVec, true
+}
+
+// saveSemanticTokens 保存文件最新的语义着色数据，返回新的resultID，调用者需要持有semanticMutex
+func (l *LspServer) saveSemanticTokens(strFile string, data []uint32) string {
+	l.semanticTokensID++
+	resultID := strconv.FormatInt(l.semanticTokensID, 10)
+	l.semanticTokensMap[strFile] = semanticTokensCache{
+		resultID: resultID,
+		data:     data,
+	}
+
+	return resultID
+}
+
+// encodeSemanticTokens 语义着色符号转换为lsp的相对位置编码，rangeLimit不为nil时只保留范围内的符号
+func encodeSemanticTokens(tokenVec []common.SemanticToken, rangeLimit *lsp.Range) []uint32 {
+	data := make([]uint32, 0, len(tokenVec)*5)
+	var lastLine, lastChar uint32
+	for _, oneToken := range tokenVec {
+		line := (uint32)(oneToken.Loc.StartLine - 1)
+		char := (uint32)(oneToken.Loc.StartColumn)
+		length := (uint32)(oneToken.Loc.EndColumn - oneToken.Loc.StartColumn)
+
+		if rangeLimit != nil {
+			if line < rangeLimit.Start.Line || line > rangeLimit.End.Line {
+				continue
+			}
+			if line == rangeLimit.Start.Line && char+length <= rangeLimit.Start.Character {
+				continue
+			}
+			if line == rangeLimit.End.Line && char >= rangeLimit.End.Character {
+				continue
+			}
+		}
+
+		deltaLine := line - lastLine
+		deltaChar := char
+		if deltaLine == 0 {
+			deltaChar = char - lastChar
+		}
+
+		tokenType, modifiers := changeSemanticType(&oneToken)
+		data = append(data, deltaLine, deltaChar, length, tokenType, modifiers)
+		lastLine = line
+		lastChar = char
+	}
+
+	return data
+}
+
+// changeSemanticType 语义着色类型转换为legend中的类型与修饰
+func changeSemanticType(oneToken *common.SemanticToken) (tokenType uint32, modifiers uint32) {
+	switch oneToken.Type {
+	case common.STParameter:
+		tokenType = stTypeParameter
+	case common.STUpvalue:
+		tokenType = stTypeVariable
+		modifiers |= stModUpvalue
+	case common.STGlobal:
+		tokenType = stTypeVariable
+		modifiers |= stModGlobal
+	case common.STFunction:
+		tokenType = stTypeFunction
+	case common.STClass:
+		tokenType = stTypeClass
+	case common.STConst:
+		tokenType = stTypeVariable
+		modifiers |= stModReadonly
+	case common.STEnumMember:
+		tokenType = stTypeEnumMember
+	default:
+		tokenType = stTypeVariable
+	}
+
+	if oneToken.Declaration {
+		modifiers |= stModDeclaration
+	}
+	if oneToken.Deprecated {
+		modifiers |= stModDeprecated
+	}
+
+	return tokenType, modifiers
+}
+
+// diffSemanticTokens 比较新旧两份语义着色数据，生成一个编辑；数据相同时changed为false
+func diffSemanticTokens(oldData, newData []uint32) (edit lsp.SemanticTokensEdit, changed bool) {
+	prefix := 0
+	for prefix < len(oldData) && prefix < len(newData) && oldData[prefix] == newData[prefix] {
+		prefix++
+	}
+
+	if prefix == len(oldData) && prefix == len(newData) {
+		return edit, false
+	}
+
+	suffix := 0
+	for suffix < len(oldData)-prefix && suffix < len(newData)-prefix &&
+		oldData[len(oldData)-1-suffix] == newData[len(newData)-1-suffix] {
+		suffix++
+	}
+
+	edit.Start = (uint32)(prefix)
+	edit.DeleteCount = (uint32)(len(oldData) - prefix - suffix)
+	edit.Data = append([]uint32{}, newData[prefix:len(newData)-suffix]...)
+	return edit, true
+}
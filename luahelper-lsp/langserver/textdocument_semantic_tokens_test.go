@@ -0,0 +1,180 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+// semanticTokenItem 解码后的单个语义着色
+type semanticTokenItem struct {
+	line      uint32
+	char      uint32
+	length    uint32
+	tokenType uint32
+	modifiers uint32
+}
+
+func decodeSemanticTokens(data []uint32) (itemVec []semanticTokenItem) {
+	var line, char uint32
+	for i := 0; i+4 < len(data); i += 5 {
+		if data[i] != 0 {
+			char = 0
+		}
+		line += data[i]
+		char += data[i+1]
+		itemVec = append(itemVec, semanticTokenItem{line, char, data[i+2], data[i+3], data[i+4]})
+	}
+	return itemVec
+}
+
+func findSemanticToken(itemVec []semanticTokenItem, line, char uint32) (semanticTokenItem, bool) {
+	for _, oneItem := range itemVec {
+		if oneItem.line == line && oneItem.char == char {
+			return oneItem, true
+		}
+	}
+	return semanticTokenItem{}, false
+}
+
+func TestSemanticTokens(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/semantic"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	context := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(context, initializeParams)
+
+	fileName := strRootPath + "/" + "test1.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(context, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	fullParams := lsp.SemanticTokensParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(fileName),
+		},
+	}
+	fullResult, err2 := lspServer.TextDocumentSemanticTokensFull(context, fullParams)
+	if err2 != nil || fullResult.ResultID == "" {
+		t.Fatalf("semanticTokens/full error")
+	}
+	itemVec := decodeSemanticTokens(fullResult.Data)
+
+	type expectItem struct {
+		line      uint32
+		char      uint32
+		tokenType uint32
+		modifiers uint32
+	}
+	expectList := []expectItem{
+		{0, 10, stTypeClass, stModDeclaration},                     // ---@class Animal
+		{1, 6, stTypeVariable, stModDeclaration},                   // local Animal
+		{3, 6, stTypeVariable, stModDeclaration | stModReadonly},   // local count <const>
+		{6, 9, stTypeFunction, stModDeprecated | stModDeclaration}, // function oldFunc
+		{6, 17, stTypeParameter, stModDeclaration},                 // param
+		{7, 11, stTypeParameter, 0},                                // return param
+		{7, 19, stTypeVariable, stModReadonly},                     // count
+		{13, 15, stTypeVariable, stModUpvalue},                     // return inner
+		{17, 0, stTypeVariable, stModGlobal | stModDeclaration},    // gValue
+		{17, 9, stTypeFunction, stModDeprecated},                   // oldFunc(1)
+		{18, 0, stTypeVariable, stModGlobal},                       // print
+		{18, 6, stTypeFunction, 0},                                 // outer
+		{22, 15, stTypeParameter, 0},                               // return v
+		{22, 19, stTypeVariable, stModUpvalue},                     // step
+	}
+	for _, oneExpect := range expectList {
+		oneItem, ok := findSemanticToken(itemVec, oneExpect.line, oneExpect.char)
+		if !ok {
+			t.Fatalf("not find semantic token, line=%d, char=%d", oneExpect.line, oneExpect.char)
+		}
+		if oneItem.tokenType != oneExpect.tokenType || oneItem.modifiers != oneExpect.modifiers {
+			t.Fatalf("semantic token error, line=%d, char=%d, type=%d, modifiers=%d", oneExpect.line,
+				oneExpect.char, oneItem.tokenType, oneItem.modifiers)
+		}
+	}
+
+	// 文件没有修改，delta返回空的编辑
+	deltaParams := lsp.SemanticTokensDeltaParams{
+		TextDocument:     fullParams.TextDocument,
+		PreviousResultID: fullResult.ResultID,
+	}
+	deltaResult, err3 := lspServer.TextDocumentSemanticTokensFullDelta(context, deltaParams)
+	if err3 != nil {
+		t.Fatalf("semanticTokens/full/delta error")
+	}
+	delta, ok := deltaResult.(lsp.SemanticTokensDelta)
+	if !ok || len(delta.Edits) != 0 {
+		t.Fatalf("semanticTokens/full/delta result error")
+	}
+
+	// 只获取第18行之后的
+	rangeParams := lsp.SemanticTokensRangeParams{
+		TextDocument: fullParams.TextDocument,
+		Range: lsp.Range{
+			Start: lsp.Position{Line: 17, Character: 0},
+			End:   lsp.Position{Line: 19, Character: 0},
+		},
+	}
+	rangeResult, err4 := lspServer.TextDocumentSemanticTokensRange(context, rangeParams)
+	if err4 != nil {
+		t.Fatalf("semanticTokens/range error")
+	}
+	rangeItemVec := decodeSemanticTokens(rangeResult.Data)
+	if len(rangeItemVec) == 0 || rangeItemVec[0].line != 17 || rangeItemVec[0].char != 0 {
+		t.Fatalf("semanticTokens/range result error")
+	}
+}
+
+func TestDiffSemanticTokens(t *testing.T) {
+	oldData := []uint32{0, 1, 2, 0, 0, 1, 0, 3, 1, 0}
+	newData := []uint32{0, 1, 2, 0, 0, 0, 4, 3, 2, 0, 1, 0, 3, 1, 0}
+	edit, changed := diffSemanticTokens(oldData, newData)
+	if !changed || edit.Start != 5 || edit.DeleteCount != 0 || len(edit.Data) != 5 {
+		t.Fatalf("diffSemanticTokens error, edit=%v", edit)
+	}
+
+	if _, changed := diffSemanticTokens(oldData, oldData); changed {
+		t.Fatalf("diffSemanticTokens same data error")
+	}
+}
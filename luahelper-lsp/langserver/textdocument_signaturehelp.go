@@ -13,8 +13,8 @@ import (
 
 // TextDocumentSignatureHelp 补全函数的参数
 func (l *LspServer) TextDocumentSignatureHelp(ctx context.Context, vs lsp.TextDocumentPositionParams) (signatureHelp lsp.SignatureHelp, err error) {
-	l.requestMutex.Lock()
-	defer l.requestMutex.Unlock()
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
 
 	comResult, activeParameter := l.doSignatureHelp(ctx, vs.TextDocument.URI, vs.Position)
 	if !comResult.result {
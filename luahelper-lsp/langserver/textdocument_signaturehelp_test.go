@@ -0,0 +1,89 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSignatureHelpGeneric(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/generic"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	lspServer := createLspTest(strRootPath, strRootURI)
+	context := context.Background()
+
+	fileName := strRootPath + "/" + "test1.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	if err1 := lspServer.TextDocumentDidOpen(context, openParams); err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	changeRange := lsp.Range{
+		Start: lsp.Position{
+			Line:      41,
+			Character: 0,
+		},
+	}
+	changeRange.End = changeRange.Start
+	changeText := "strList:push("
+	lspServer.TextDocumentDidChange(context, lsp.DidChangeTextDocumentParams{
+		TextDocument: lsp.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: lsp.TextDocumentIdentifier{
+				URI: lsp.DocumentURI(fileName),
+			},
+		},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{
+			{
+				Range:       &changeRange,
+				RangeLength: 0,
+				Text:        changeText,
+			},
+		},
+	})
+
+	signatureHelp, err2 := lspServer.TextDocumentSignatureHelp(context, lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{
+			URI: lsp.DocumentURI(fileName),
+		},
+		Position: lsp.Position{
+			Line:      41,
+			Character: uint32(len(changeText)),
+		},
+	})
+	if err2 != nil {
+		t.Fatalf("signature help file:%s err=%s", fileName, err2.Error())
+	}
+
+	if len(signatureHelp.Signatures) != 1 {
+		t.Fatalf("signature help len=%d, not 1", len(signatureHelp.Signatures))
+	}
+
+	// 泛型类实例化的成员函数，参数类型替换为具体的类型
+	oneSign := signatureHelp.Signatures[0]
+	if !strings.Contains(oneSign.Label, "v: string") {
+		t.Fatalf("signature label=%s, not contain generic binding", oneSign.Label)
+	}
+
+	if len(oneSign.Parameters) != 1 || !strings.Contains(oneSign.Parameters[0].Documentation.Value, "v : string") {
+		t.Fatalf("signature param not contain generic binding")
+	}
+}
@@ -11,6 +11,9 @@ import (
 
 // TextDocumentSymbol 提示文件中生成所有的符合 @使用
 func (l *LspServer)TextDocumentSymbol(ctx context.Context, vs lsp.DocumentSymbolParams) (itemsResult []lsp.DocumentSymbol, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
 	strFile := pathpre.VscodeURIToString(string(vs.TextDocument.URI))
 	project := l.getAllProject()
 	if !project.IsNeedHandle(strFile) {
@@ -0,0 +1,49 @@
+package langserver
+
+import (
+	"context"
+
+	"luahelper-lsp/langserver/check"
+	"luahelper-lsp/langserver/log"
+	lsp "luahelper-lsp/langserver/protocol"
+)
+
+// TextDocumentTypeDefinition 查找变量关联的注解类型的定义
+func (l *LspServer) TextDocumentTypeDefinition(ctx context.Context, vs lsp.TypeDefinitionParams) (
+	locList []lsp.Location, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	fileRequest := l.beginFileRequest(vs.TextDocument.URI, vs.Position)
+	if !fileRequest.result {
+		log.Error("TextDocumentTypeDefinition beginFileRequest false, uri=%s", vs.TextDocument.URI)
+		return
+	}
+
+	if len(fileRequest.contents) == 0 || fileRequest.offset >= len(fileRequest.contents) {
+		return
+	}
+
+	strFile := fileRequest.strFile
+	project := l.getAllProject()
+
+	// 1) 判断是否为---@ 注解中的类型，与查找定义相同
+	defineAnnotateVecs, flag := l.handleAnnotateTypeDefine(strFile, fileRequest.contents, fileRequest.offset,
+		(int)(fileRequest.pos.Line), (int)(fileRequest.pos.Character))
+	if flag {
+		locList = defineVecConvert(defineAnnotateVecs)
+		return locList, nil
+	}
+
+	// 2) 代码中的变量、参数或是成员
+	varStruct := check.GetVarStruct(fileRequest.contents, fileRequest.offset, fileRequest.pos.Line,
+		fileRequest.pos.Character)
+	if !varStruct.ValidFlag || len(varStruct.StrVec) == 0 {
+		log.Error("TextDocumentTypeDefinition not valid")
+		return
+	}
+
+	defineVecs := project.FindVarTypeDefine(strFile, &varStruct)
+	locList = defineVecConvert(defineVecs)
+	return locList, nil
+}
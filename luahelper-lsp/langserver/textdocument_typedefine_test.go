@@ -0,0 +1,97 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestTypeDefinition(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/typedefine"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	context := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(context, initializeParams)
+
+	fileName := strRootPath + "/types.lua"
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("read file:%s err=%s", fileName, err.Error())
+	}
+
+	openParams := lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{
+			URI:  lsp.DocumentURI(fileName),
+			Text: string(data),
+		},
+	}
+	err1 := lspServer.TextDocumentDidOpen(context, openParams)
+	if err1 != nil {
+		t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+	}
+
+	checkFunc := func(line, character uint32, expectVec []uint32) {
+		locList, _ := lspServer.TextDocumentTypeDefinition(context, lsp.TypeDefinitionParams{
+			TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+				TextDocument: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+				Position: lsp.Position{
+					Line:      line,
+					Character: character,
+				},
+			},
+		})
+
+		if len(locList) != len(expectVec) {
+			t.Fatalf("line=%d type definition len=%d, expect=%d, %v", line, len(locList), len(expectVec), locList)
+		}
+		for i, oneLoc := range locList {
+			if oneLoc.Range.Start.Line != expectVec[i] {
+				t.Fatalf("line=%d type definition %d error, line=%d, expect=%d", line, i, oneLoc.Range.Start.Line,
+					expectVec[i])
+			}
+		}
+	}
+
+	// 1) 多种类型，返回每一种类型的定义
+	checkFunc(20, 8, []uint32{0, 3})
+
+	// 2) 函数参数，跳转到alias的定义
+	checkFunc(13, 11, []uint32{6})
+
+	// 3) ---@field 定义的成员
+	checkFunc(20, 21, []uint32{0})
+
+	// 4) 关联了class的变量
+	checkFunc(18, 7, []uint32{16})
+}
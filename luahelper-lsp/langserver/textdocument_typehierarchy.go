@@ -0,0 +1,119 @@
+package langserver
+
+import (
+	"context"
+
+	"luahelper-lsp/langserver/check"
+	"luahelper-lsp/langserver/check/common"
+	"luahelper-lsp/langserver/check/compiler/lexer"
+	"luahelper-lsp/langserver/log"
+	"luahelper-lsp/langserver/lspcommon"
+	"luahelper-lsp/langserver/pathpre"
+	lsp "luahelper-lsp/langserver/protocol"
+)
+
+// TextDocumentPrepareTypeHierarchy 获取光标处的类型，作为类型层级的起点
+func (l *LspServer) TextDocumentPrepareTypeHierarchy(ctx context.Context, vs lsp.TypeHierarchyPrepareParams) (
+	itemList []lsp.TypeHierarchyItem, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	fileRequest := l.beginFileRequest(vs.TextDocument.URI, vs.Position)
+	if !fileRequest.result {
+		log.Error("TextDocumentPrepareTypeHierarchy beginFileRequest false, uri=%s", vs.TextDocument.URI)
+		return
+	}
+
+	if len(fileRequest.contents) == 0 || fileRequest.offset >= len(fileRequest.contents) {
+		return
+	}
+
+	project := l.getAllProject()
+	var typeVec []common.TypeHierarchyInfo
+
+	// 1) 判断是否为---@ 注解中的类型
+	annotateStr, col, flag := getAnnotateStrAndCol(fileRequest.contents, fileRequest.offset,
+		(int)(fileRequest.pos.Character))
+	if flag {
+		typeVec = project.PrepareAnnotateTypeHierarchy(annotateStr, col)
+	} else {
+		// 2) 代码中的变量
+		varStruct := check.GetVarStruct(fileRequest.contents, fileRequest.offset, fileRequest.pos.Line,
+			fileRequest.pos.Character)
+		if !varStruct.ValidFlag || len(varStruct.StrVec) == 0 {
+			log.Error("TextDocumentPrepareTypeHierarchy not valid")
+			return
+		}
+		typeVec = project.PrepareTypeHierarchy(fileRequest.strFile, &varStruct)
+	}
+
+	return changeTypeHierarchyItemList(typeVec), nil
+}
+
+// TypeHierarchySupertypes 查找类型所有的父类型
+func (l *LspServer) TypeHierarchySupertypes(ctx context.Context, vs lsp.TypeHierarchySupertypesParams) (
+	itemList []lsp.TypeHierarchyItem, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	project := l.getAllProject()
+	if project == nil {
+		return []lsp.TypeHierarchyItem{}, nil
+	}
+
+	typeVec := project.FindSupertypes(changeTypeHierarchyInfo(&vs.Item))
+	return changeTypeHierarchyItemList(typeVec), nil
+}
+
+// TypeHierarchySubtypes 查找直接继承了该类型的所有子类型
+func (l *LspServer) TypeHierarchySubtypes(ctx context.Context, vs lsp.TypeHierarchySubtypesParams) (
+	itemList []lsp.TypeHierarchyItem, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
+	project := l.getAllProject()
+	if project == nil {
+		return []lsp.TypeHierarchyItem{}, nil
+	}
+
+	typeVec := project.FindSubtypes(changeTypeHierarchyInfo(&vs.Item))
+	return changeTypeHierarchyItemList(typeVec), nil
+}
+
+// changeTypeHierarchyItemList 类型层级的类型转换为lsp的格式
+func changeTypeHierarchyItemList(typeVec []common.TypeHierarchyInfo) []lsp.TypeHierarchyItem {
+	itemList := make([]lsp.TypeHierarchyItem, 0, len(typeVec))
+	for _, oneType := range typeVec {
+		detail := "class"
+		if !oneType.ClassFlag {
+			detail = "setmetatable"
+		}
+
+		itemList = append(itemList, lsp.TypeHierarchyItem{
+			Name:           oneType.Name,
+			Kind:           lsp.Class,
+			Detail:         detail,
+			URI:            lspcommon.GetFileDocumentURI(oneType.FileName),
+			Range:          lspcommon.LocToRange(&oneType.Loc),
+			SelectionRange: lspcommon.LocToRange(&oneType.Loc),
+		})
+	}
+
+	return itemList
+}
+
+// changeTypeHierarchyInfo 客户端传入的lsp格式的类型，转换为类型层级的类型
+// setmetatable推导出的类型按照文件与位置区分，需要还原出位置信息
+func changeTypeHierarchyInfo(item *lsp.TypeHierarchyItem) common.TypeHierarchyInfo {
+	return common.TypeHierarchyInfo{
+		Name:     item.Name,
+		FileName: pathpre.VscodeURIToString(string(item.URI)),
+		Loc: lexer.Location{
+			StartLine:   int(item.SelectionRange.Start.Line) + 1,
+			StartColumn: int(item.SelectionRange.Start.Character),
+			EndLine:     int(item.SelectionRange.End.Line) + 1,
+			EndColumn:   int(item.SelectionRange.End.Character),
+		},
+		ClassFlag: item.Detail == "class",
+	}
+}
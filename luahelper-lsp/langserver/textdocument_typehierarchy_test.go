@@ -0,0 +1,145 @@
+package langserver
+
+import (
+	"context"
+	"io/ioutil"
+	"luahelper-lsp/langserver/check/common"
+	lsp "luahelper-lsp/langserver/protocol"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/yinfei8/jrpc2"
+	"github.com/yinfei8/jrpc2/handler"
+)
+
+func TestTypeHierarchy(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	paths, _ := filepath.Split(filename)
+
+	strRootPath := paths + "../testdata/typehierarchy"
+	strRootPath, _ = filepath.Abs(strRootPath)
+
+	strRootURI := "file://" + strRootPath
+	common.GlobalConfigDefautInit()
+	common.GConfig.IntialGlobalVar()
+
+	lspServer := CreateLspServer()
+	lspServer.server = jrpc2.NewServer(handler.Map{}, &jrpc2.ServerOptions{
+		AllowPush:   false,
+		Concurrency: 1,
+	})
+
+	context := context.Background()
+	initializeParams := InitializeParams{
+		InitializeParams: lsp.InitializeParams{
+			InnerInitializeParams: lsp.InnerInitializeParams{
+				RootPath: strRootPath,
+				RootURI:  lsp.DocumentURI(strRootURI),
+			},
+		},
+		InitializationOptions: getDefaultIntialOptions(),
+	}
+	lspServer.Initialize(context, initializeParams)
+
+	openFile := func(strName string) string {
+		fileName := strRootPath + "/" + strName
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			t.Fatalf("read file:%s err=%s", fileName, err.Error())
+		}
+
+		openParams := lsp.DidOpenTextDocumentParams{
+			TextDocument: lsp.TextDocumentItem{
+				URI:  lsp.DocumentURI(fileName),
+				Text: string(data),
+			},
+		}
+		err1 := lspServer.TextDocumentDidOpen(context, openParams)
+		if err1 != nil {
+			t.Fatalf("didopen file:%s err=%s", fileName, err1.Error())
+		}
+		return fileName
+	}
+	prepareFunc := func(fileName string, line, character uint32) []lsp.TypeHierarchyItem {
+		itemList, _ := lspServer.TextDocumentPrepareTypeHierarchy(context, lsp.TypeHierarchyPrepareParams{
+			TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+				TextDocument: lsp.TextDocumentIdentifier{
+					URI: lsp.DocumentURI(fileName),
+				},
+				Position: lsp.Position{
+					Line:      line,
+					Character: character,
+				},
+			},
+		})
+		return itemList
+	}
+
+	// 1) 注解中的父类型 ---@class Button : BaseWidget
+	buttonFile := openFile("button.lua")
+	itemList := prepareFunc(buttonFile, 0, 22)
+	if len(itemList) != 1 || itemList[0].Name != "BaseWidget" || itemList[0].Detail != "class" {
+		t.Fatalf("prepareTypeHierarchy annotate error, len=%d", len(itemList))
+	}
+
+	subList, _ := lspServer.TypeHierarchySubtypes(context, lsp.TypeHierarchySubtypesParams{
+		Item: itemList[0],
+	})
+	// menu.lua与toolbar.lua中的M为不同的类型
+	expectSub := []string{"Button", "Label", "M", "M", "Panel"}
+	if len(subList) != len(expectSub) {
+		t.Fatalf("subtypes len error, len=%d", len(subList))
+	}
+	for index, oneItem := range subList {
+		if oneItem.Name != expectSub[index] {
+			t.Fatalf("subtypes error, index=%d, name=%s", index, oneItem.Name)
+		}
+	}
+	if subList[2].URI == subList[3].URI {
+		t.Fatalf("subtypes M error, same uri=%s", subList[2].URI)
+	}
+
+	superList, _ := lspServer.TypeHierarchySupertypes(context, lsp.TypeHierarchySupertypesParams{
+		Item: subList[2],
+	})
+	if len(superList) != 1 || superList[0].Name != "BaseWidget" {
+		t.Fatalf("supertypes M error, len=%d", len(superList))
+	}
+
+	// 2) setmetatable推导出的类型 local Label = setmetatable({}, {__index = BaseWidget})
+	labelFile := openFile("label.lua")
+	itemList = prepareFunc(labelFile, 2, 8)
+	if len(itemList) != 1 || itemList[0].Name != "Label" || itemList[0].Detail != "setmetatable" {
+		t.Fatalf("prepareTypeHierarchy setmetatable error, len=%d", len(itemList))
+	}
+
+	superList, _ = lspServer.TypeHierarchySupertypes(context, lsp.TypeHierarchySupertypesParams{
+		Item: itemList[0],
+	})
+	if len(superList) != 1 || superList[0].Name != "BaseWidget" || superList[0].Range.Start.Line != 0 {
+		t.Fatalf("supertypes error, len=%d", len(superList))
+	}
+
+	// setmetatable({}, Label) 创建的实例不是Label的子类型
+	subList, _ = lspServer.TypeHierarchySubtypes(context, lsp.TypeHierarchySubtypesParams{
+		Item: itemList[0],
+	})
+	if len(subList) != 0 {
+		t.Fatalf("subtypes instance error, len=%d", len(subList))
+	}
+
+	// 3) 注解的class，父类型来自setmetatable
+	panelFile := openFile("panel.lua")
+	itemList = prepareFunc(panelFile, 3, 8)
+	if len(itemList) != 1 || itemList[0].Name != "Panel" || itemList[0].Detail != "class" {
+		t.Fatalf("prepareTypeHierarchy class error, len=%d", len(itemList))
+	}
+
+	superList, _ = lspServer.TypeHierarchySupertypes(context, lsp.TypeHierarchySupertypesParams{
+		Item: itemList[0],
+	})
+	if len(superList) != 1 || superList[0].Name != "BaseWidget" {
+		t.Fatalf("supertypes class error, len=%d", len(superList))
+	}
+}
@@ -22,6 +22,9 @@ type IAnnotator struct {
 
 // TextDocumentGetVarColor 获取文档中变量的颜色
 func (l *LspServer)TextDocumentGetVarColor(ctx context.Context, vs GetColorParams) (annolist []IAnnotator, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
 	project := l.getAllProject()
 
 	// 判断打开的文件，是否是需要分析的文件
@@ -55,6 +58,9 @@ func (l *LspServer)TextDocumentGetVarColor(ctx context.Context, vs GetColorParam
 }
 
 func (l *LspServer)TextDocumentColor(ctx context.Context,colorParams lsp.DocumentColorParams) (colorList []lsp.ColorInformation,err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
 	log.Debug("not need to handle strFile=%s", colorParams.TextDocument.URI)
 
 	project := l.getAllProject()
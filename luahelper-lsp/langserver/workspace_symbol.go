@@ -9,8 +9,14 @@ import (
 
 // WorkspaceSymbolRequest 全工程符合查找提示，返回多个符合
 func (l *LspServer) WorkspaceSymbolRequest(ctx context.Context, vs lsp.WorkspaceSymbolParams) (items []lsp.SymbolInformation, err error) {
+	l.requestMutex.RLock()
+	defer l.requestMutex.RUnlock()
+
 	project := l.getAllProject()
-	fileSymbolVec := project.FindWorkspaceAllSymbol(vs.Query)
+	fileSymbolVec := project.FindWorkspaceAllSymbol(ctx, vs.Query)
+	if err = checkRequestCancelled(ctx); err != nil {
+		return nil, err
+	}
 
 	vecLen := len(fileSymbolVec)
 	items = make([]lsp.SymbolInformation, 0, vecLen)
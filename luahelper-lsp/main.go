@@ -20,6 +20,11 @@ func main() {
 	modeFlag := flag.Int("mode", 0, "mode type, 0 is run cmd, 1 is local rpc, 2 is socket rpc")
 	logFlag := flag.Int("logflag", 0, "0 is not open log, 1 is open log")
 	localpath := flag.String("localpath", "", "local project path")
+	format := flag.String("format", "text", "mode 0 output format: text, json, sarif, checkstyle, junit or github")
+	failOn := flag.String("fail-on", "none", "mode 0 exits with 1 when any diagnostic is at least this severe: "+
+		"error, warning, info, hint or none")
+	baseline := flag.String("baseline", "", "mode 0 only reports diagnostics that are not in this baseline file")
+	baselineWrite := flag.String("baseline-write", "", "mode 0 writes all current diagnostics to this baseline file")
 	flag.Parse()
 
 	// 是否开启日志
@@ -52,7 +57,15 @@ func main() {
 	} else if *modeFlag == 2 {
 		socketRPC()
 	} else if *modeFlag == 0 {
-		runLocalDiagnostices(*localpath)
+		options := langserver.LocalOptions{
+			Format:        *format,
+			FailOn:        *failOn,
+			Baseline:      *baseline,
+			BaselineWrite: *baselineWrite,
+		}
+		if exitCode := runLocalDiagnostices(*localpath, options); exitCode != 0 {
+			os.Exit(exitCode)
+		}
 	}
 }
 
@@ -107,9 +120,10 @@ func socketRPC() {
 	}
 }
 
-func runLocalDiagnostices(localpath string) {
+func runLocalDiagnostices(localpath string, options langserver.LocalOptions) int {
 	log.Debug("local Diagnostices running ....")
 	lspServer := langserver.CreateLspServer()
-	lspServer.RunLocalDiagnostices(localpath)
-	log.Debug("local Diagnostices exited ")
+	exitCode := lspServer.RunLocalDiagnostices(localpath, options)
+	log.Debug("local Diagnostices exited, exitCode=%d", exitCode)
+	return exitCode
 }
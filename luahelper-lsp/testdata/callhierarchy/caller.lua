@@ -0,0 +1,7 @@
+local util = require("util")
+
+local function flushAll()
+    util.flush()
+end
+
+return flushAll
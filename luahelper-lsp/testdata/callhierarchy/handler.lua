@@ -0,0 +1,16 @@
+local net = require("net")
+
+local handler = {}
+
+function handler:onLogin(msg)
+    net.send(msg)
+end
+
+function handler:onLogout(msg)
+    net.send(msg)
+    net.broadcast({msg})
+end
+
+net.send("init")
+
+return handler
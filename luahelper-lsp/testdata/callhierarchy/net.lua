@@ -0,0 +1,18 @@
+local net = {}
+
+local function encode(msg)
+    return msg
+end
+
+function net.send(msg)
+    local data = encode(msg)
+    return data
+end
+
+function net.broadcast(msgList)
+    for _, msg in ipairs(msgList) do
+        net.send(msg)
+    end
+end
+
+return net
@@ -0,0 +1,3 @@
+local util = {}
+
+return util
@@ -0,0 +1,5 @@
+gCount = 1
+
+function AddCount(num)
+    gCount = gCount + num
+end
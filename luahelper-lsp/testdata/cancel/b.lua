@@ -0,0 +1,2 @@
+AddCount(2)
+print(gCount)
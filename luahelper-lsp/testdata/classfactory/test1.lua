@@ -0,0 +1,43 @@
+local Animal = class("Animal")
+
+function Animal:initialize(name)
+    self.name = name
+end
+
+function Animal:speak()
+    return self.name
+end
+
+Animal.static.create = function(name)
+    return Animal:new(name)
+end
+
+local Dog = class("Dog", Animal)
+
+function Dog:bark()
+    print("bark")
+end
+
+local Widget = Animal:extend("Widget")
+
+function Widget:draw()
+end
+
+local a = Animal:new("a")
+local d = Dog:new("d")
+local d2 = Dog.new("d2")
+local d3 = Dog("d3")
+local w = Widget:new()
+
+---@class Proxy
+---@field target string
+
+local Factory = class("Factory")
+
+---@return Proxy
+function Factory:new()
+    return {target = "t"}
+end
+
+local p = Factory:new()
+
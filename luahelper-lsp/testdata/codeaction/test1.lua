@@ -0,0 +1,12 @@
+local function test_func()
+    local unused = 1
+    local a = 1
+    a = a
+    local t = {
+        name = 1,
+        name = 2,
+    }
+    return t
+end
+
+return test_func
@@ -0,0 +1,3 @@
+local helper = {}
+
+return helper
@@ -0,0 +1,7 @@
+local helper = require("wrongdir.helper")
+
+local function test_func()
+    return helper, notDefined
+end
+
+return test_func
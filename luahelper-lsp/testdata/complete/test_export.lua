@@ -0,0 +1,5 @@
+local cond = require("test_export_cond")
+local helper = require("test_export_helper")
+local logger = require("test_export_module")
+local tracer = require("test_export_module_split")
+
@@ -0,0 +1,8 @@
+local Impl = {}
+function Impl.fast() end
+
+local Fallback = {}
+function Fallback.slow() end
+
+local useFast = true
+return useFast and Impl or Fallback
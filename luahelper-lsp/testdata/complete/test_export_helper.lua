@@ -0,0 +1,9 @@
+local function build(t)
+    t.extra = 1
+    return t
+end
+
+local M = {}
+function M.run() end
+
+return build(M)
@@ -0,0 +1,8 @@
+---@class ExportLogger
+---@field info fun(msg:string)
+---@field warn fun(msg:string)
+
+local impl = _G.loggerImpl
+
+---@module ExportLogger
+return impl
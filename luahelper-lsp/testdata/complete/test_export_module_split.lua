@@ -0,0 +1,8 @@
+---@class ExportTracer
+---@field trace fun(msg:string)
+
+local tracer = _G.tracerImpl
+
+---@module ExportTracer
+return
+    tracer
@@ -0,0 +1,48 @@
+local Animal = {}
+Animal.__index = Animal
+
+function Animal.new(name)
+    local self = setmetatable({}, Animal)
+    self.name = name
+    return self
+end
+
+function Animal:speak()
+end
+
+local Dog = setmetatable({}, {__index = Animal})
+Dog.__index = Dog
+
+function Dog.new(name)
+    local self = setmetatable(Animal.new(name), Dog)
+    self.tail = 1
+    return self
+end
+
+function Dog:bark()
+end
+
+local Puppy = setmetatable({}, Dog)
+Puppy.__index = Puppy
+
+function Puppy.new()
+    return setmetatable({}, Puppy)
+end
+
+function Puppy:play()
+end
+
+local Proxy = setmetatable({}, {__index = function(t, k)
+    return Dog[k]
+end})
+
+local Cat = {}
+setmetatable(Cat, {__index = Animal})
+
+function Cat:meow()
+end
+
+local animal = Animal.new("a")
+local dog = Dog.new("d")
+local puppy = Puppy.new()
+
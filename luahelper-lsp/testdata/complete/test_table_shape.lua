@@ -0,0 +1,39 @@
+local function consume(cfg, opt)
+    print(cfg, opt)
+
+end
+
+local function make()
+    local c = {}
+    c.host = "127.0.0.1"
+    c.port = 8080
+    return c
+end
+
+local function fill(conf)
+    conf.timeout = 3
+    conf.retry = 2
+end
+
+local base = {}
+for i = 1, 3 do
+    base.loopKey = i
+end
+local alias = base
+
+local made = make()
+fill(made)
+consume(made, alias)
+consume({name = "x", level = 2})
+
+local fresh = {}
+local other = fresh
+fill(other)
+
+local function build()
+    local r = {}
+    fill(r)
+    return r
+end
+local built = build()
+
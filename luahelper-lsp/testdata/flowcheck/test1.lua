@@ -0,0 +1,96 @@
+local function update(state)
+    if state == 1 then
+        do return end
+        print("skip")
+    end
+
+    for i = 1, 3 do
+        if i == 2 then
+            break
+        else
+            goto continue
+        end
+        print(i)
+        ::continue::
+    end
+
+    error("invalid state")
+    print(state)
+end
+
+---@return number
+local function calc(a)
+    if a > 0 then
+        return a
+    end
+end
+
+---@return number
+local function calc2(a)
+    if a > 0 then
+        return a
+    else
+        error("negative")
+    end
+end
+
+---@return number?
+local function calc3(a)
+    if a > 0 then
+        return a
+    end
+end
+
+---@return number
+local function loop()
+    while true do
+        coroutine.yield()
+    end
+end
+
+local function shadow(count, state)
+    local total = 0
+    if count > 0 then
+        local total = 1
+        local state = 2
+        for count = 1, total do
+            print(count, state)
+        end
+    end
+
+    local total = 3
+    return total
+end
+
+update(1)
+calc(1)
+calc2(1)
+calc3(1)
+loop()
+shadow(1, 2)
+
+---@return number
+local function calc4(a)
+    if a > 0 then
+        return a
+    end
+    return
+end
+
+local function custom(error)
+    error("custom")
+    print("reachable")
+end
+
+---@return number
+local function calc5(a)
+    local error = print
+    if a > 0 then
+        return a
+    end
+    error("negative")
+end
+
+calc4(1)
+custom(print)
+calc5(1)
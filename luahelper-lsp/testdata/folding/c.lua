@@ -0,0 +1 @@
+return {sub = 1}
@@ -0,0 +1,31 @@
+local a = require("a")
+local b = require("b")
+local c = require("c").sub
+
+-- first comment line
+-- second comment line
+-- third comment line
+local function check(n)
+    if n == 1 then
+        print(1)
+    elseif n == 2 then
+        print(2)
+    else
+        print(3)
+    end
+end
+
+local config = {
+    name = "fold",
+    size = 3,
+}
+
+---@enum start
+Red = 1
+Green = 2
+---@enum end
+
+return {a, b, c, check, config}
+--[[
+long comment
+]]
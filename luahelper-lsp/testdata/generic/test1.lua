@@ -0,0 +1,42 @@
+---@class List<T>
+---@field first T
+local List = {}
+
+---@return T
+function List:get()
+    return self.first
+end
+
+---@param v T
+function List:push(v)
+    self.first = v
+end
+
+---@param v T
+local function notMember(v)
+    return v
+end
+
+---@generic T
+---@param a T
+---@param b T
+local function same(a, b)
+    return a == b
+end
+
+same(1, 2)
+same(1, "x")
+
+---@generic T : number
+---@param a T
+---@return T
+local function keep(a)
+    return "x"
+end
+
+---@type List<string>
+local strList = {}
+strList:push("a")
+notMember(1)
+keep(1)
+
@@ -0,0 +1,7 @@
+GameConfig = {
+    maxLevel = 10,
+}
+
+function GetMaxLevel()
+    return GameConfig.maxLevel
+end
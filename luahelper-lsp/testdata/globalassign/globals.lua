@@ -0,0 +1,8 @@
+---@meta
+
+---@class Player
+---@field name string
+---@field level number
+player = {}
+
+score = 0
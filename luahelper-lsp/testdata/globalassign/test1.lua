@@ -0,0 +1,26 @@
+local function update(dt)
+    playr = dt
+    player = dt
+    score = score + 1
+    g_debug = true
+    GameConfig = {}
+    _G.explicit = 1
+end
+
+counter = 0
+counter = counter + 1
+
+local t = {}
+t.value = 1
+
+local function reset()
+    local level = 1
+    level = 2
+    lvel = level
+end
+
+update(1)
+reset()
+
+function playr2()
+end
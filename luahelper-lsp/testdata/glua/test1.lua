@@ -0,0 +1,7 @@
+local a = 1
+if a ~= 2 then
+    print(a)
+end
+if a != 3 && a ~= 4 then
+    print(a)
+end
@@ -0,0 +1,36 @@
+---@class Animal
+---@field name string
+
+---@generic T
+---@param one T
+---@return T[]
+local function wrapList(one)
+    return { one }
+end
+
+---@generic T : Animal
+---@param one T
+---@return T
+local function identity(one)
+    return one
+end
+
+---@class List<T>
+---@field first T
+local List = {}
+
+---@return T
+function List:get()
+    return self.first
+end
+
+local list1 = wrapList(1)
+
+---@type List<string>
+local strList = {}
+local first1 = strList.first
+local get1 = strList:get()
+
+---@type Animal
+local dog = {}
+local dog1 = identity(dog)
@@ -0,0 +1,21 @@
+---@class Foo
+---@field a number
+
+---@param x string|Foo|nil
+local function test1(x)
+    if type(x) == "table" then
+        print(x)
+    elseif type(x) == "string" then
+        print(x)
+    end
+
+    if not x then
+        return
+    end
+    print(x)
+end
+
+---@type string|nil
+local y = nil
+assert(y)
+print(y)
@@ -0,0 +1,12 @@
+---@class BaseView
+---@field OnInit fun(self:BaseView)
+---@field onClick fun(x:number, y:number)
+local BaseView = {}
+
+function BaseView:OnInit()
+end
+
+BaseView.onClick = function(x, y)
+end
+
+return BaseView
@@ -0,0 +1,7 @@
+---@class ChildView : BaseView
+local ChildView = {}
+
+function ChildView:OnInit()
+end
+
+return ChildView
@@ -0,0 +1,10 @@
+---@class GrandView : ChildView
+local GrandView = {}
+
+function GrandView:OnInit()
+end
+
+function GrandView:OnShow()
+end
+
+return GrandView
@@ -0,0 +1,7 @@
+---@class OtherView : BaseView
+local OtherView = {}
+
+function OtherView:OnShow()
+end
+
+return OtherView
@@ -0,0 +1,32 @@
+local function add(first, second)
+    return first + second
+end
+
+local function pair()
+    return 1, "name"
+end
+
+---@class Point
+---@field x number
+local Point = {}
+
+---@param px number
+---@return Point
+function Point:new(px)
+    return self
+end
+
+local sum = add(1, 2)
+local first = 3
+local total = add(first, sum)
+local num, str = pair()
+local pt = Point:new(5)
+---@type number
+local typed = add(1, 2)
+
+---@return "红色的按钮"|"绿色的按钮"|"蓝色的按钮"|"黄色的按钮"|"白色的按钮"|"黑色的按钮"
+local function getColor()
+    return "红色的按钮"
+end
+
+local color = getColor()
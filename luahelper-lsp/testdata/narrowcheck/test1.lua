@@ -0,0 +1,26 @@
+---@param n number
+local function useNum(n)
+    return n + 1
+end
+
+---@param v string|number
+local function test1(v)
+    if type(v) == "number" then
+        useNum(v)
+    end
+    useNum(v)
+end
+
+---@param v string|number
+local function test2(v)
+    if type(v) == "string" then
+        if v == "" then
+            error("empty")
+        end
+        return
+    end
+    useNum(v)
+end
+
+test1(1)
+test2(2)
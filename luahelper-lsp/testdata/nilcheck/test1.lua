@@ -0,0 +1,47 @@
+---@class NilFoo
+---@field name string
+
+---@return NilFoo?
+local function find(name)
+    return nil
+end
+
+local one = find("a")
+print(one.name)
+
+if one then
+    print(one.name)
+end
+
+local two = find("b")
+if not two then
+    return
+end
+print(two.name)
+
+local three = find("c")
+assert(three)
+print(three.name)
+
+---@type table<string, NilFoo>
+local map = {}
+local four = map["k"]
+four.name = "x"
+
+---@param p? NilFoo
+local function check(p)
+    p:go()
+    p = p or {}
+    print(p.name)
+end
+check()
+
+local five = find("e")
+local callback
+if five then
+    callback = function()
+        print(five.name)
+    end
+end
+five = nil
+callback()
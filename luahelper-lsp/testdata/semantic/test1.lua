@@ -0,0 +1,25 @@
+---@class Animal
+local Animal = {}
+
+local count <const> = 10
+
+---@deprecated
+function oldFunc(param)
+    return param + count
+end
+
+local function outer()
+    local inner = 1
+    return function()
+        return inner
+    end
+end
+
+gValue = oldFunc(1)
+print(outer, Animal)
+
+local function adder(step)
+    return function(v)
+        return v + step
+    end
+end
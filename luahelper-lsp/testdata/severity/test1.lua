@@ -0,0 +1,16 @@
+local a = 1
+print(undefined_var)
+local b = 2
+b = b
+print(b)
+
+---@class SevFoo
+---@field name string
+
+---@return SevFoo?
+local function find()
+    return nil
+end
+
+local c = find()
+print(c.name)
@@ -0,0 +1,8 @@
+---@diagnostic disable-next-line: local-no-use
+local a = 1
+local b = 2 ---@diagnostic disable-line: local-no-use
+local c = 3 ---@diagnostic disable-line: no-define
+---@diagnostic disable: local-no-use
+local d = 4
+---@diagnostic enable: local-no-use
+local e = 5
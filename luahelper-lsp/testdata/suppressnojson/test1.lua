@@ -0,0 +1,2 @@
+---@diagnostic disable-next-line: local-no-use
+print("suppress nothing")
@@ -0,0 +1,21 @@
+---@class Cat
+local Cat = {}
+
+---@class Dog
+local Dog = {}
+
+---@alias Pet Cat|Dog
+
+---@type Cat|Dog
+local animal = nil
+
+---@param pet Pet
+local function feed(pet)
+    print(pet)
+end
+
+---@class Owner
+---@field cat Cat
+local owner = {}
+
+print(animal, owner.cat, feed)
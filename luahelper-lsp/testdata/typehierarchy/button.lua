@@ -0,0 +1,4 @@
+---@class Button : BaseWidget
+local Button = {}
+
+return Button
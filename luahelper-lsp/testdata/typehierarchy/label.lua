@@ -0,0 +1,14 @@
+local BaseWidget = require("widget")
+
+local Label = setmetatable({}, {__index = BaseWidget})
+Label.__index = Label
+
+function Label:setText(text)
+    self.text = text
+end
+
+-- 创建的实例，不是Label的子类型
+local defaultLabel = setmetatable({}, Label)
+defaultLabel:setText("")
+
+return Label
@@ -0,0 +1,6 @@
+local BaseWidget = require("widget")
+
+local M = setmetatable({}, {__index = BaseWidget})
+M.__index = M
+
+return M
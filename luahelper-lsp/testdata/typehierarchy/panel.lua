@@ -0,0 +1,6 @@
+local BaseWidget = require("widget")
+
+---@class Panel
+local Panel = setmetatable({}, BaseWidget)
+
+return Panel
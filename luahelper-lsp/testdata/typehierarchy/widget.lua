@@ -0,0 +1,5 @@
+---@class BaseWidget
+local BaseWidget = {}
+BaseWidget.__index = BaseWidget
+
+return BaseWidget